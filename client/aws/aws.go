@@ -14,6 +14,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/kms"
@@ -44,6 +46,7 @@ type Clients struct {
 	CloudFormation *cloudformation.CloudFormation
 	EC2            ec2iface.EC2API
 	ELB            elbiface.ELBAPI
+	ELBv2          elbv2iface.ELBV2API
 	IAM            iamiface.IAMAPI
 	KMS            kmsiface.KMSAPI
 	Route53        *route53.Route53
@@ -100,6 +103,7 @@ func newClients(p client.ConfigProvider, cfgs ...*aws.Config) Clients {
 		CloudFormation: cloudformation.New(p, cfgs...),
 		EC2:            ec2.New(p, cfgs...),
 		ELB:            elb.New(p, cfgs...),
+		ELBv2:          elbv2.New(p, cfgs...),
 		IAM:            iam.New(p, cfgs...),
 		KMS:            kms.New(p, cfgs...),
 		Route53:        route53.New(p, cfgs...),
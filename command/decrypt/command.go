@@ -0,0 +1,140 @@
+// Package decrypt implements the decrypt command guest cluster nodes run to
+// decrypt their TLS assets and random keys.
+package decrypt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter"
+)
+
+const (
+	// encryptedSuffix is the suffix of the paths of encrypted assets. The
+	// decrypted assets are written to the paths without it.
+	encryptedSuffix = ".enc"
+)
+
+type Config struct {
+	// Stdin is where the base64 encoded plaintext data key is read from.
+	Stdin io.Reader
+}
+
+type Command struct {
+	cobraCommand *cobra.Command
+
+	stdin io.Reader
+}
+
+func New(config Config) (*Command, error) {
+	if config.Stdin == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Stdin must not be empty", config)
+	}
+
+	newCommand := &Command{
+		cobraCommand: nil,
+
+		stdin: config.Stdin,
+	}
+
+	newCommand.cobraCommand = &cobra.Command{
+		Use:   "decrypt <path>...",
+		Short: "Decrypt the assets of guest cluster nodes.",
+		Long: "Decrypt the given assets encrypted with AES-GCM using the data key of the guest cluster. " +
+			"The base64 encoded plaintext data key is read from stdin, so that it does not show up in the process list. " +
+			"Every asset is written to its path without the .enc suffix once its authentication tag is verified.",
+		Args: cobra.MinimumNArgs(1),
+		Run:  newCommand.Execute,
+	}
+
+	return newCommand, nil
+}
+
+func (c *Command) CobraCommand() *cobra.Command {
+	return c.cobraCommand
+}
+
+func (c *Command) Execute(cmd *cobra.Command, args []string) {
+	err := c.execute(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func (c *Command) execute(paths []string) error {
+	var dataKey encrypter.DataKey
+	{
+		b, err := ioutil.ReadAll(c.stdin)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		dataKey.Plaintext, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+		if err != nil {
+			return microerror.Maskf(invalidDataKeyError, "data key must be base64 encoded")
+		}
+		if len(dataKey.Plaintext) != encrypter.DataKeySize {
+			return microerror.Maskf(invalidDataKeyError, "data key must have %d bytes, got %d", encrypter.DataKeySize, len(dataKey.Plaintext))
+		}
+	}
+
+	for _, p := range paths {
+		err := decryptFile(dataKey, p)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// decryptFile decrypts the asset at the given path. The plaintext is written to
+// a temporary file first, which is only moved to the path without the .enc
+// suffix once the asset is decrypted completely.
+func decryptFile(dataKey encrypter.DataKey, path string) error {
+	if !strings.HasSuffix(path, encryptedSuffix) || path == encryptedSuffix {
+		return microerror.Maskf(invalidPathError, "path %#q must end with %#q", path, encryptedSuffix)
+	}
+
+	ciphertext, err := ioutil.ReadFile(path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	plaintext, err := encrypter.Open(dataKey, ciphertext)
+	if err != nil {
+		return microerror.Maskf(decryptionFailedError, "decrypting %#q: %s", path, err.Error())
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(plaintext)
+	if err != nil {
+		f.Close()
+		return microerror.Mask(err)
+	}
+	err = f.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = os.Rename(f.Name(), strings.TrimSuffix(path, encryptedSuffix))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package decrypt
+
+import "github.com/giantswarm/microerror"
+
+var decryptionFailedError = &microerror.Error{
+	Kind: "decryptionFailedError",
+}
+
+// IsDecryptionFailed asserts decryptionFailedError.
+func IsDecryptionFailed(err error) bool {
+	return microerror.Cause(err) == decryptionFailedError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidDataKeyError = &microerror.Error{
+	Kind: "invalidDataKeyError",
+}
+
+// IsInvalidDataKey asserts invalidDataKeyError.
+func IsInvalidDataKey(err error) bool {
+	return microerror.Cause(err) == invalidDataKeyError
+}
+
+var invalidPathError = &microerror.Error{
+	Kind: "invalidPathError",
+}
+
+// IsInvalidPath asserts invalidPathError.
+func IsInvalidPath(err error) bool {
+	return microerror.Cause(err) == invalidPathError
+}
@@ -2,14 +2,18 @@ package aws
 
 import (
 	"github.com/giantswarm/aws-operator/flag/service/aws/accesskey"
+	"github.com/giantswarm/aws-operator/flag/service/aws/dns"
 	"github.com/giantswarm/aws-operator/flag/service/aws/loggingbucket"
 	"github.com/giantswarm/aws-operator/flag/service/aws/route53"
+	"github.com/giantswarm/aws-operator/flag/service/aws/transitgateway"
 	"github.com/giantswarm/aws-operator/flag/service/aws/trustedadvisor"
+	"github.com/giantswarm/aws-operator/flag/service/aws/vault"
 )
 
 type AWS struct {
 	AccessKey              accesskey.AccessKey
 	AdvancedMonitoringEC2  string
+	DNS                    dns.DNS
 	Encrypter              string
 	HostAccessKey          accesskey.AccessKey
 	IncludeTags            string
@@ -20,6 +24,8 @@ type AWS struct {
 	Region                 string
 	Route53                route53.Route53
 	S3AccessLogsExpiration string
+	TransitGateway         transitgateway.TransitGateway
 	TrustedAdvisor         trustedadvisor.TrustedAdvisor
+	Vault                  vault.Vault
 	VaultAddress           string
 }
@@ -0,0 +1,10 @@
+package dns
+
+import (
+	"github.com/giantswarm/aws-operator/flag/service/aws/dns/rfc2136"
+)
+
+type DNS struct {
+	Provider string
+	RFC2136  rfc2136.RFC2136
+}
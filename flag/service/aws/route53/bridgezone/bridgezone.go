@@ -0,0 +1,6 @@
+package bridgezone
+
+type BridgeZone struct {
+	RetirementEnabled     string
+	RetirementGracePeriod string
+}
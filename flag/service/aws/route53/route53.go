@@ -1,5 +1,10 @@
 package route53
 
+import (
+	"github.com/giantswarm/aws-operator/flag/service/aws/route53/bridgezone"
+)
+
 type Route53 struct {
-	Enabled string
+	BridgeZone bridgezone.BridgeZone
+	Enabled    string
 }
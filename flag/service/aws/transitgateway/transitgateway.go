@@ -0,0 +1,6 @@
+package transitgateway
+
+type TransitGateway struct {
+	ID           string
+	RouteTableID string
+}
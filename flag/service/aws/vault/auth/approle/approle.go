@@ -0,0 +1,7 @@
+package approle
+
+type AppRole struct {
+	MountPath string
+	RoleID    string
+	SecretID  string
+}
@@ -0,0 +1,14 @@
+package auth
+
+import (
+	"github.com/giantswarm/aws-operator/flag/service/aws/vault/auth/approle"
+	"github.com/giantswarm/aws-operator/flag/service/aws/vault/auth/aws"
+	"github.com/giantswarm/aws-operator/flag/service/aws/vault/auth/kubernetes"
+)
+
+type Auth struct {
+	AppRole    approle.AppRole
+	AWS        aws.AWS
+	Kubernetes kubernetes.Kubernetes
+	Method     string
+}
@@ -0,0 +1,6 @@
+package aws
+
+type AWS struct {
+	MountPath string
+	Role      string
+}
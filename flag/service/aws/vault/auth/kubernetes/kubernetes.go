@@ -0,0 +1,7 @@
+package kubernetes
+
+type Kubernetes struct {
+	MountPath string
+	Role      string
+	TokenFile string
+}
@@ -0,0 +1,12 @@
+package vault
+
+import (
+	"github.com/giantswarm/aws-operator/flag/service/aws/vault/auth"
+)
+
+type Vault struct {
+	Auth             auth.Auth
+	CAFile           string
+	Namespace        string
+	TransitMountPath string
+}
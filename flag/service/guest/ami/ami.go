@@ -0,0 +1,12 @@
+package ami
+
+import (
+	"github.com/giantswarm/aws-operator/flag/service/guest/ami/configmap"
+	"github.com/giantswarm/aws-operator/flag/service/guest/ami/ec2"
+)
+
+type AMI struct {
+	Catalog   string
+	ConfigMap configmap.ConfigMap
+	EC2       ec2.EC2
+}
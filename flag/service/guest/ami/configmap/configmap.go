@@ -0,0 +1,6 @@
+package configmap
+
+type ConfigMap struct {
+	Name      string
+	Namespace string
+}
@@ -0,0 +1,6 @@
+package ec2
+
+type EC2 struct {
+	NamePattern string
+	Owner       string
+}
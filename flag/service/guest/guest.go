@@ -1,11 +1,13 @@
 package guest
 
 import (
+	"github.com/giantswarm/aws-operator/flag/service/guest/ami"
 	"github.com/giantswarm/aws-operator/flag/service/guest/ssh"
 	"github.com/giantswarm/aws-operator/flag/service/guest/update"
 )
 
 type Guest struct {
+	AMI    ami.AMI
 	SSH    ssh.SSH
 	Update update.Update
 }
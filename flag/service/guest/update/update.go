@@ -1,5 +1,6 @@
 package update
 
 type Update struct {
-	Enabled string
+	Enabled             string
+	ReplacementApproval string
 }
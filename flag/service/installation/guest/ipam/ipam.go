@@ -0,0 +1,6 @@
+package ipam
+
+type IPAM struct {
+	Network   string
+	VPCPrefix string
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/microkit/command"
@@ -12,6 +13,7 @@ import (
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/viper"
 
+	"github.com/giantswarm/aws-operator/command/decrypt"
 	"github.com/giantswarm/aws-operator/flag"
 	"github.com/giantswarm/aws-operator/server"
 	"github.com/giantswarm/aws-operator/service"
@@ -117,6 +119,20 @@ func mainError() error {
 	daemonCommand.PersistentFlags().String(f.Service.AWS.HostAccessKey.Session, "", "Session token of the AWS access key for the host cluster account. If empty, guest cluster token is used.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.PublicRouteTables, "", "Names of the public route tables in host cluster separated by commas, required for accessing public ELBs from guest nodes.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.Region, "", "Region for checking for orphan AWS resources.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.TransitGateway.ID, "", "ID of the Transit Gateway in the host cluster account new guest cluster VPCs are attached to instead of being peered with the host cluster VPC. Guest cluster accounts must be part of the AWS Organization of the host cluster account with resource sharing enabled for it. The host cluster route tables have to route the IPAM network to the Transit Gateway, which is why the IPAM network is required when a Transit Gateway is configured. If empty, VPC peering is used.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.TransitGateway.RouteTableID, "", "ID of the Transit Gateway route table guest cluster VPC attachments are associated with and propagate their routes to, required when a Transit Gateway is configured.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.AppRole.MountPath, "approle", "Mount path of the Vault AppRole auth method when authenticating using the approle auth method.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.AppRole.RoleID, "", "Role ID to authenticate with when using the approle Vault auth method.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.AppRole.SecretID, "", "Secret ID to authenticate with when using the approle Vault auth method. Can be empty if the role does not bind secret IDs.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.AWS.MountPath, "aws", "Mount path of the Vault AWS auth method. Guest cluster nodes always authenticate using it for decrypting their assets.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.AWS.Role, "encrypter", "Vault role to authenticate with when using the aws Vault auth method.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.Kubernetes.MountPath, "kubernetes", "Mount path of the Vault Kubernetes auth method when authenticating using the kubernetes auth method.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.Kubernetes.Role, "", "Vault role to authenticate with when using the kubernetes Vault auth method.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.Kubernetes.TokenFile, "/var/run/secrets/kubernetes.io/serviceaccount/token", "Service account token file to authenticate with when using the kubernetes Vault auth method.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Auth.Method, "aws", "Auth method the operator authenticates against Vault with. One of approle, aws or kubernetes.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.CAFile, "", "CA file used to verify the Vault server certificate. If empty, the system CAs are used.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.Namespace, "", "Vault namespace the auth methods and the transit secrets engine are mounted in. If empty, the root namespace is used.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.Vault.TransitMountPath, "transit", "Mount path of the Vault transit secrets engine managing the guest cluster encryption keys.")
 	daemonCommand.PersistentFlags().String(f.Service.AWS.VaultAddress, "", "Server address for Vault encryption.")
 
 	daemonCommand.PersistentFlags().String(f.Service.RegistryDomain, "quay.io", "Image registry.")
@@ -124,19 +140,36 @@ func mainError() error {
 	// TODO(nhlfr): Deprecate these options when cert-operator will be implemented.
 	daemonCommand.PersistentFlags().String(f.Service.AWS.PubKeyFile, path.Join(string(os.PathSeparator), ".ssh", "id_rsa.pub"), "Public key to be imported as a keypair in AWS.")
 
+	daemonCommand.PersistentFlags().String(f.Service.Guest.AMI.Catalog, "builtin", "Catalog resolving the AMIs of guest cluster nodes not configuring their AMI in the custom object. One of builtin, configmap or ec2.")
+	daemonCommand.PersistentFlags().String(f.Service.Guest.AMI.ConfigMap.Name, "aws-operator-ami-catalog", "Name of the config map mapping regions to AMIs when using the configmap AMI catalog.")
+	daemonCommand.PersistentFlags().String(f.Service.Guest.AMI.ConfigMap.Namespace, "giantswarm", "Namespace of the config map mapping regions to AMIs when using the configmap AMI catalog.")
+	daemonCommand.PersistentFlags().String(f.Service.Guest.AMI.EC2.NamePattern, "CoreOS-stable-*-hvm", "Name pattern of the AMIs to choose the latest one from when using the ec2 AMI catalog.")
+	daemonCommand.PersistentFlags().String(f.Service.Guest.AMI.EC2.Owner, "595879546273", "ID of the AWS account owning the AMIs when using the ec2 AMI catalog.")
+
 	daemonCommand.PersistentFlags().Bool(f.Service.Guest.Update.Enabled, false, "Whether updates of guest cluster nodes are allowed to be processed upon reconciliation.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Guest.Update.ReplacementApproval, false, "Whether updates of guest clusters replacing resources have to be approved by annotating the custom object with the name of the change set to execute.")
 
 	daemonCommand.PersistentFlags().String(f.Service.Guest.SSH.SSOPublicKey, "", "Public key for trusted SSO CA.")
 
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.LoggingBucket.Delete, false, "Should be logging bucket deleted.")
 
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.Route53.Enabled, true, "Should Route53 be enabled.")
+	daemonCommand.PersistentFlags().Bool(f.Service.AWS.Route53.BridgeZone.RetirementEnabled, false, "Whether the intermediate bridgezone delegation should be retired once no guest cluster relies on it anymore.")
+	daemonCommand.PersistentFlags().Duration(f.Service.AWS.Route53.BridgeZone.RetirementGracePeriod, 48*time.Hour, "Time to wait in addition to the TTL of the removed intermediate zone delegation before the intermediate zone is deleted.")
+
+	daemonCommand.PersistentFlags().String(f.Service.AWS.DNS.Provider, "route53", "DNS provider managing the zone of the installation base domain guest cluster zones are delegated from. One of route53 or rfc2136.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.DNS.RFC2136.Server, "", "Address of the DNS server accepting dynamic updates of the base domain zone when using the rfc2136 DNS provider, e.g. 10.0.0.2:53.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.DNS.RFC2136.TSIGAlgorithm, "hmac-sha256", "Algorithm of the TSIG key dynamic updates are signed with when using the rfc2136 DNS provider. One of hmac-sha1, hmac-sha256 or hmac-sha512.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.DNS.RFC2136.TSIGKeyName, "", "Name of the TSIG key dynamic updates are signed with when using the rfc2136 DNS provider. If empty, updates are not signed.")
+	daemonCommand.PersistentFlags().String(f.Service.AWS.DNS.RFC2136.TSIGSecret, "", "Base64 encoded secret of the TSIG key dynamic updates are signed with when using the rfc2136 DNS provider.")
 
 	daemonCommand.PersistentFlags().String(f.Service.AWS.PodInfraContainerImage, "", "Image to be used for the pause container. If empty, default image from gcr.io/google_containers/pause-amd64 is used.")
 
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.IncludeTags, true, "Should resource tags be included (especially for restricted regions, like S3 buckets in China regions).")
 
 	daemonCommand.PersistentFlags().String(f.Service.Installation.Name, "", "Installation name for tagging AWS resources.")
+	daemonCommand.PersistentFlags().String(f.Service.Installation.Guest.IPAM.Network, "", "Network the VPC CIDRs of guest clusters not configuring them in the custom object are allocated from. If empty, VPC CIDRs have to be configured in the custom object.")
+	daemonCommand.PersistentFlags().Int(f.Service.Installation.Guest.IPAM.VPCPrefix, 24, "Prefix length of the VPC CIDRs allocated for guest clusters, between 16 and 27. The VPC CIDRs have to fit a public and a private subnet of at least /28 per availability zone.")
 	daemonCommand.PersistentFlags().String(f.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.ClientID, "", "OIDC authorization provider ClientID.")
 	daemonCommand.PersistentFlags().String(f.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.IssuerURL, "", "OIDC authorization provider IssuerURL.")
 	daemonCommand.PersistentFlags().String(f.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.UsernameClaim, "", "OIDC authorization provider UsernameClaim.")
@@ -156,6 +189,22 @@ func mainError() error {
 	daemonCommand.PersistentFlags().Int(f.Service.AWS.S3AccessLogsExpiration, 365, "S3 access logs expiration policy.")
 	daemonCommand.PersistentFlags().Bool(f.Service.AWS.AdvancedMonitoringEC2, false, "Advanced EC2 monitoring.")
 
+	// Guest cluster nodes run the decrypt command from the operator image to
+	// decrypt their assets.
+	var decryptCommand *decrypt.Command
+	{
+		c := decrypt.Config{
+			Stdin: os.Stdin,
+		}
+
+		decryptCommand, err = decrypt.New(c)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	newCommand.CobraCommand().AddCommand(decryptCommand.CobraCommand())
+
 	newCommand.CobraCommand().Execute()
 
 	return nil
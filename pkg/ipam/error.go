@@ -0,0 +1,21 @@
+package ipam
+
+import "github.com/giantswarm/microerror"
+
+var invalidParameterError = &microerror.Error{
+	Kind: "invalidParameterError",
+}
+
+// IsInvalidParameter asserts invalidParameterError.
+func IsInvalidParameter(err error) bool {
+	return microerror.Cause(err) == invalidParameterError
+}
+
+var spaceExhaustedError = &microerror.Error{
+	Kind: "spaceExhaustedError",
+}
+
+// IsSpaceExhausted asserts spaceExhaustedError.
+func IsSpaceExhausted(err error) bool {
+	return microerror.Cause(err) == spaceExhaustedError
+}
@@ -0,0 +1,116 @@
+// Package ipam provides primitives for computing IPv4 network segments used
+// by guest clusters.
+package ipam
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"net"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Free returns the first subnet of the given network using the given mask
+// which does not overlap any of the given allocated subnets. Subnets are
+// allocated in ascending order, so that freed subnets are reused first.
+//
+//     Free(10.1.0.0/16, /24, [10.1.0.0/24, 10.1.2.0/24]) => 10.1.1.0/24
+//
+func Free(network net.IPNet, mask net.IPMask, allocated []net.IPNet) (net.IPNet, error) {
+	ip := network.IP.To4()
+	if ip == nil {
+		return net.IPNet{}, microerror.Maskf(invalidParameterError, "network %s must be IPv4", network.String())
+	}
+
+	ones, size := network.Mask.Size()
+	subnetOnes, subnetSize := mask.Size()
+	if size != net.IPv4len*8 || subnetSize != net.IPv4len*8 {
+		return net.IPNet{}, microerror.Maskf(invalidParameterError, "network %s and mask must be IPv4", network.String())
+	}
+	if subnetOnes < ones {
+		return net.IPNet{}, microerror.Maskf(invalidParameterError, "network %s too small for subnets of size /%d", network.String(), subnetOnes)
+	}
+
+	base := binary.BigEndian.Uint32(ip.Mask(network.Mask))
+	count := uint64(1) << uint(subnetOnes-ones)
+	step := uint64(1) << uint(size-subnetOnes)
+
+	for i := uint64(0); i < count; i++ {
+		subnetIP := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(subnetIP, base+uint32(i*step))
+
+		subnet := net.IPNet{
+			IP:   subnetIP,
+			Mask: mask,
+		}
+
+		free := true
+		for _, a := range allocated {
+			if Overlap(subnet, a) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return subnet, nil
+		}
+	}
+
+	return net.IPNet{}, microerror.Maskf(spaceExhaustedError, "no free subnet of size /%d left in network %s", subnetOnes, network.String())
+}
+
+// Overlap returns true if the given networks share at least one IP address.
+func Overlap(a, b net.IPNet) bool {
+	return a.Contains(b.IP.Mask(b.Mask)) || b.Contains(a.IP.Mask(a.Mask))
+}
+
+// Split divides the given network into n equally sized subnets. In case n is
+// not a power of two, the network is divided into the next power of two and
+// the first n subnets are returned. The subnets are returned in ascending
+// order.
+//
+//     Split(10.1.0.0/16, 2) => [10.1.0.0/17, 10.1.128.0/17]
+//     Split(10.1.0.0/16, 3) => [10.1.0.0/18, 10.1.64.0/18, 10.1.128.0/18]
+//
+func Split(network net.IPNet, n uint) ([]net.IPNet, error) {
+	if n == 0 {
+		return nil, microerror.Maskf(invalidParameterError, "n must be greater than 0")
+	}
+
+	ip := network.IP.To4()
+	if ip == nil {
+		return nil, microerror.Maskf(invalidParameterError, "network %s must be IPv4", network.String())
+	}
+
+	ones, size := network.Mask.Size()
+	if size != net.IPv4len*8 {
+		return nil, microerror.Maskf(invalidParameterError, "network %s must have an IPv4 mask", network.String())
+	}
+
+	// additionalBits is the number of bits we need to borrow from the host part
+	// of the network in order to fit n subnets.
+	additionalBits := bits.Len(n - 1)
+	if ones+additionalBits > size {
+		return nil, microerror.Maskf(invalidParameterError, "network %s too small to be split into %d subnets", network.String(), n)
+	}
+
+	subnetOnes := ones + additionalBits
+	subnetMask := net.CIDRMask(subnetOnes, size)
+	subnetSize := uint32(1) << uint(size-subnetOnes)
+
+	base := binary.BigEndian.Uint32(ip.Mask(network.Mask))
+
+	var subnets []net.IPNet
+	for i := uint32(0); i < uint32(n); i++ {
+		subnetIP := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(subnetIP, base+i*subnetSize)
+
+		subnet := net.IPNet{
+			IP:   subnetIP,
+			Mask: subnetMask,
+		}
+		subnets = append(subnets, subnet)
+	}
+
+	return subnets, nil
+}
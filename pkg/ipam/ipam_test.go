@@ -0,0 +1,243 @@
+package ipam
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func Test_Free(t *testing.T) {
+	testCases := []struct {
+		name           string
+		network        string
+		mask           int
+		allocated      []string
+		expectedSubnet string
+		errorMatcher   func(error) bool
+	}{
+		{
+			name:           "case 0: nothing allocated",
+			network:        "10.1.0.0/16",
+			mask:           24,
+			expectedSubnet: "10.1.0.0/24",
+		},
+		{
+			name:           "case 1: fill the gap between allocated subnets",
+			network:        "10.1.0.0/16",
+			mask:           24,
+			allocated:      []string{"10.1.0.0/24", "10.1.2.0/24"},
+			expectedSubnet: "10.1.1.0/24",
+		},
+		{
+			name:           "case 2: skip larger allocated networks",
+			network:        "10.1.0.0/16",
+			mask:           24,
+			allocated:      []string{"10.1.0.0/23", "10.1.2.128/25"},
+			expectedSubnet: "10.1.3.0/24",
+		},
+		{
+			name:           "case 3: ignore allocated networks outside of the network",
+			network:        "10.1.0.0/16",
+			mask:           24,
+			allocated:      []string{"10.2.0.0/16", "172.31.0.0/16"},
+			expectedSubnet: "10.1.0.0/24",
+		},
+		{
+			name:         "case 4: network exhausted",
+			network:      "10.1.0.0/23",
+			mask:         24,
+			allocated:    []string{"10.1.0.0/24", "10.1.1.0/24"},
+			errorMatcher: IsSpaceExhausted,
+		},
+		{
+			name:         "case 5: network overlapped by allocated network",
+			network:      "10.1.0.0/16",
+			mask:         24,
+			allocated:    []string{"10.0.0.0/8"},
+			errorMatcher: IsSpaceExhausted,
+		},
+		{
+			name:         "case 6: mask larger than network",
+			network:      "10.1.0.0/16",
+			mask:         8,
+			errorMatcher: IsInvalidParameter,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, network, err := net.ParseCIDR(tc.network)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			var allocated []net.IPNet
+			for _, a := range tc.allocated {
+				_, n, err := net.ParseCIDR(a)
+				if err != nil {
+					t.Fatalf("unexpected error %#v", err)
+				}
+				allocated = append(allocated, *n)
+			}
+
+			subnet, err := Free(*network, net.CIDRMask(tc.mask, 32), allocated)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher == nil && subnet.String() != tc.expectedSubnet {
+				t.Fatalf("subnet == %s, want %s", subnet.String(), tc.expectedSubnet)
+			}
+		})
+	}
+}
+
+func Test_Overlap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{
+			name:     "case 0: equal networks",
+			a:        "10.1.0.0/16",
+			b:        "10.1.0.0/16",
+			expected: true,
+		},
+		{
+			name:     "case 1: network contains the other one",
+			a:        "10.0.0.0/8",
+			b:        "10.1.2.0/24",
+			expected: true,
+		},
+		{
+			name:     "case 2: network contained by the other one",
+			a:        "10.1.2.0/24",
+			b:        "10.0.0.0/8",
+			expected: true,
+		},
+		{
+			name:     "case 3: adjacent networks",
+			a:        "10.1.0.0/24",
+			b:        "10.1.1.0/24",
+			expected: false,
+		},
+		{
+			name:     "case 4: distinct networks",
+			a:        "10.1.0.0/16",
+			b:        "172.31.0.0/16",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, a, err := net.ParseCIDR(tc.a)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			_, b, err := net.ParseCIDR(tc.b)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			if Overlap(*a, *b) != tc.expected {
+				t.Fatalf("Overlap(%s, %s) == %t, want %t", tc.a, tc.b, !tc.expected, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_Split(t *testing.T) {
+	testCases := []struct {
+		name            string
+		network         string
+		n               uint
+		expectedSubnets []string
+		errorMatcher    func(error) bool
+	}{
+		{
+			name:            "case 0: split /16 into 1 subnet",
+			network:         "10.1.0.0/16",
+			n:               1,
+			expectedSubnets: []string{"10.1.0.0/16"},
+		},
+		{
+			name:            "case 1: split /16 into 2 subnets",
+			network:         "10.1.0.0/16",
+			n:               2,
+			expectedSubnets: []string{"10.1.0.0/17", "10.1.128.0/17"},
+		},
+		{
+			name:            "case 2: split /16 into 3 subnets",
+			network:         "10.1.0.0/16",
+			n:               3,
+			expectedSubnets: []string{"10.1.0.0/18", "10.1.64.0/18", "10.1.128.0/18"},
+		},
+		{
+			name:    "case 3: split /24 into 6 subnets",
+			network: "10.1.1.0/24",
+			n:       6,
+			expectedSubnets: []string{
+				"10.1.1.0/27",
+				"10.1.1.32/27",
+				"10.1.1.64/27",
+				"10.1.1.96/27",
+				"10.1.1.128/27",
+				"10.1.1.160/27",
+			},
+		},
+		{
+			name:         "case 4: split into 0 subnets",
+			network:      "10.1.0.0/16",
+			n:            0,
+			errorMatcher: IsInvalidParameter,
+		},
+		{
+			name:         "case 5: network too small",
+			network:      "10.1.1.0/31",
+			n:            4,
+			errorMatcher: IsInvalidParameter,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, network, err := net.ParseCIDR(tc.network)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			subnets, err := Split(*network, tc.n)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			var actual []string
+			for _, s := range subnets {
+				actual = append(actual, s.String())
+			}
+
+			if !reflect.DeepEqual(actual, tc.expectedSubnets) {
+				t.Fatalf("subnets == %v, want %v", actual, tc.expectedSubnets)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"time"
+
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
 	"github.com/giantswarm/legacycerts/legacy"
@@ -58,25 +60,85 @@ type ClusterConfig struct {
 	K8sExtClient apiextensionsclient.Interface
 	Logger       micrologger.Logger
 
-	AccessLogsExpiration   int
-	AdvancedMonitoringEC2  bool
-	APIWhitelist           FrameworkConfigAPIWhitelistConfig
-	DeleteLoggingBucket    bool
-	EncrypterBackend       string
-	GuestAWSConfig         ClusterConfigAWSConfig
-	GuestUpdateEnabled     bool
-	HostAWSConfig          ClusterConfigAWSConfig
-	IncludeTags            bool
-	InstallationName       string
-	OIDC                   ClusterConfigOIDC
-	PodInfraContainerImage string
-	ProjectName            string
-	PubKeyFile             string
-	PublicRouteTables      string
-	RegistryDomain         string
-	Route53Enabled         bool
-	SSOPublicKey           string
-	VaultAddress           string
+	AccessLogsExpiration           int
+	AdvancedMonitoringEC2          bool
+	APIWhitelist                   FrameworkConfigAPIWhitelistConfig
+	BridgeZoneRetirement           ClusterConfigBridgeZoneRetirement
+	DeleteLoggingBucket            bool
+	DNS                            ClusterConfigDNS
+	EncrypterBackend               string
+	GuestAMI                       ClusterConfigAMI
+	GuestAWSConfig                 ClusterConfigAWSConfig
+	GuestUpdateEnabled             bool
+	GuestUpdateReplacementApproval bool
+	HostAWSConfig                  ClusterConfigAWSConfig
+	IncludeTags                    bool
+	InstallationName               string
+	IPAM                           ClusterConfigIPAM
+	OIDC                           ClusterConfigOIDC
+	PodInfraContainerImage         string
+	ProjectName                    string
+	PubKeyFile                     string
+	PublicRouteTables              string
+	RegistryDomain                 string
+	Route53Enabled                 bool
+	SSOPublicKey                   string
+	TransitGateway                 ClusterConfigTransitGateway
+	Vault                          ClusterConfigVault
+	VaultAddress                   string
+}
+
+// ClusterConfigAMI represents the configuration of the catalog resolving the
+// AMIs of guest cluster nodes.
+type ClusterConfigAMI struct {
+	Catalog            string
+	ConfigMapName      string
+	ConfigMapNamespace string
+	EC2NamePattern     string
+	EC2Owner           string
+}
+
+// ClusterConfigBridgeZoneRetirement represents the configuration of the
+// retirement of the intermediate zone guest cluster domains were delegated
+// through before they got delegated from the host cluster zone directly.
+type ClusterConfigBridgeZoneRetirement struct {
+	Enabled     bool
+	GracePeriod time.Duration
+}
+
+// ClusterConfigDNS represents the configuration of the DNS provider managing
+// the zone of the installation base domain guest cluster zones are delegated
+// from.
+type ClusterConfigDNS struct {
+	Provider             string
+	RFC2136Server        string
+	RFC2136TSIGAlgorithm string
+	RFC2136TSIGKeyName   string
+	RFC2136TSIGSecret    string
+}
+
+// ClusterConfigIPAM represents the configuration of the allocation of the VPC
+// CIDRs of guest clusters not configuring them in the custom object.
+type ClusterConfigIPAM struct {
+	Network   string
+	VPCPrefix int
+}
+
+// ClusterConfigVault represents the configuration of the Vault encrypter
+// backend in addition to the Vault address.
+type ClusterConfigVault struct {
+	AppRoleMountPath    string
+	AppRoleID           string
+	AppRoleSecretID     string
+	AuthMethod          string
+	AWSMountPath        string
+	AWSRole             string
+	CAFile              string
+	KubernetesMountPath string
+	KubernetesRole      string
+	KubernetesTokenFile string
+	Namespace           string
+	TransitMountPath    string
 }
 
 type ClusterConfigAWSConfig struct {
@@ -86,6 +148,14 @@ type ClusterConfigAWSConfig struct {
 	SessionToken    string
 }
 
+// ClusterConfigTransitGateway represents the configuration of the Transit
+// Gateway guest cluster VPCs are attached to instead of being peered with the
+// host cluster VPC.
+type ClusterConfigTransitGateway struct {
+	ID           string
+	RouteTableID string
+}
+
 // ClusterConfigOIDC represents the configuration of the OIDC authorization
 // provider.
 type ClusterConfigOIDC struct {
@@ -719,15 +789,38 @@ func newClusterResourceSets(config ClusterConfig) ([]*controller.ResourceSet, er
 			Logger:             config.Logger,
 			RandomkeysSearcher: randomKeySearcher,
 
-			AccessLogsExpiration:   config.AccessLogsExpiration,
-			AdvancedMonitoringEC2:  config.AdvancedMonitoringEC2,
-			DeleteLoggingBucket:    config.DeleteLoggingBucket,
-			EncrypterBackend:       config.EncrypterBackend,
-			GuestUpdateEnabled:     config.GuestUpdateEnabled,
-			PodInfraContainerImage: config.PodInfraContainerImage,
-			Route53Enabled:         config.Route53Enabled,
-			IncludeTags:            config.IncludeTags,
-			InstallationName:       config.InstallationName,
+			AccessLogsExpiration:  config.AccessLogsExpiration,
+			AdvancedMonitoringEC2: config.AdvancedMonitoringEC2,
+			AMI: v18.AMIConfig{
+				Catalog:            config.GuestAMI.Catalog,
+				ConfigMapName:      config.GuestAMI.ConfigMapName,
+				ConfigMapNamespace: config.GuestAMI.ConfigMapNamespace,
+				EC2NamePattern:     config.GuestAMI.EC2NamePattern,
+				EC2Owner:           config.GuestAMI.EC2Owner,
+			},
+			BridgeZoneRetirement: v18.BridgeZoneRetirementConfig{
+				Enabled:     config.BridgeZoneRetirement.Enabled,
+				GracePeriod: config.BridgeZoneRetirement.GracePeriod,
+			},
+			DeleteLoggingBucket: config.DeleteLoggingBucket,
+			DNS: v18.DNSConfig{
+				Provider:             config.DNS.Provider,
+				RFC2136Server:        config.DNS.RFC2136Server,
+				RFC2136TSIGAlgorithm: config.DNS.RFC2136TSIGAlgorithm,
+				RFC2136TSIGKeyName:   config.DNS.RFC2136TSIGKeyName,
+				RFC2136TSIGSecret:    config.DNS.RFC2136TSIGSecret,
+			},
+			EncrypterBackend:               config.EncrypterBackend,
+			GuestUpdateEnabled:             config.GuestUpdateEnabled,
+			GuestUpdateReplacementApproval: config.GuestUpdateReplacementApproval,
+			PodInfraContainerImage:         config.PodInfraContainerImage,
+			Route53Enabled:                 config.Route53Enabled,
+			IncludeTags:                    config.IncludeTags,
+			InstallationName:               config.InstallationName,
+			IPAM: v18.IPAMConfig{
+				Network:   config.IPAM.Network,
+				VPCPrefix: config.IPAM.VPCPrefix,
+			},
 			OIDC: v18cloudconfig.OIDCConfig{
 				ClientID:      config.OIDC.ClientID,
 				IssuerURL:     config.OIDC.IssuerURL,
@@ -742,7 +835,25 @@ func newClusterResourceSets(config ClusterConfig) ([]*controller.ResourceSet, er
 			PublicRouteTables: config.PublicRouteTables,
 			RegistryDomain:    config.RegistryDomain,
 			SSOPublicKey:      config.SSOPublicKey,
-			VaultAddress:      config.VaultAddress,
+			TransitGateway: v18adapter.TransitGateway{
+				ID:           config.TransitGateway.ID,
+				RouteTableID: config.TransitGateway.RouteTableID,
+			},
+			Vault: v18.VaultConfig{
+				AppRoleMountPath:    config.Vault.AppRoleMountPath,
+				AppRoleID:           config.Vault.AppRoleID,
+				AppRoleSecretID:     config.Vault.AppRoleSecretID,
+				AuthMethod:          config.Vault.AuthMethod,
+				AWSMountPath:        config.Vault.AWSMountPath,
+				AWSRole:             config.Vault.AWSRole,
+				CAFile:              config.Vault.CAFile,
+				KubernetesMountPath: config.Vault.KubernetesMountPath,
+				KubernetesRole:      config.Vault.KubernetesRole,
+				KubernetesTokenFile: config.Vault.KubernetesTokenFile,
+				Namespace:           config.Vault.Namespace,
+				TransitMountPath:    config.Vault.TransitMountPath,
+			},
+			VaultAddress: config.VaultAddress,
 		}
 
 		resourceSetV18, err = v18.NewClusterResourceSet(c)
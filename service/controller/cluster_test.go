@@ -17,6 +17,12 @@ func newTestClusterConfig() ClusterConfig {
 		Logger:       microloggertest.New(),
 
 		AccessLogsExpiration: 365,
+		DNS: ClusterConfigDNS{
+			Provider: "route53",
+		},
+		GuestAMI: ClusterConfigAMI{
+			Catalog: "builtin",
+		},
 		GuestAWSConfig: ClusterConfigAWSConfig{
 			AccessKeyID:     "guest-key",
 			AccessKeySecret: "guest-secret",
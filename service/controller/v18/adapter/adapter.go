@@ -20,7 +20,7 @@
 // `service/template/cloudformation/main.yaml` to include the new template.
 // * Add the adapter logic file in `service/resource/cloudformation/adapter` with the type
 // definition and the hydrater function to fill the fields (like asg.go or
-// launch_configuration.go).
+// launch_template.go).
 // * Add the new type to the Adapter type in `service/resource/cloudformation/adapter/adapter.go`
 // and include the hydrater function in the `hydraters` slice.
 package adapter
@@ -34,6 +34,7 @@ type Config struct {
 	APIWhitelist      APIWhitelist
 	CustomObject      v1alpha1.AWSConfig
 	Clients           Clients
+	DNSProvider       string
 	EncrypterBackend  string
 	GuestAccountID    string
 	HostAccountID     string
@@ -42,6 +43,7 @@ type Config struct {
 	PublicRouteTables string
 	Route53Enabled    bool
 	StackState        StackState
+	TransitGateway    TransitGateway
 }
 
 type Adapter struct {
@@ -58,7 +60,7 @@ func NewGuest(cfg Config) (Adapter, error) {
 		a.Guest.IAMPolicies.Adapt,
 		a.Guest.InternetGateway.Adapt,
 		a.Guest.Instance.Adapt,
-		a.Guest.LaunchConfiguration.Adapt,
+		a.Guest.LaunchTemplate.Adapt,
 		a.Guest.LifecycleHooks.Adapt,
 		a.Guest.LoadBalancers.Adapt,
 		a.Guest.NATGateway.Adapt,
@@ -68,6 +70,8 @@ func NewGuest(cfg Config) (Adapter, error) {
 		a.Guest.SecurityGroups.Adapt,
 		a.Guest.Subnets.Adapt,
 		a.Guest.VPC.Adapt,
+		a.Guest.VPCConnections.Adapt,
+		a.Guest.VPCEndpoints.Adapt,
 	}
 
 	for _, h := range hydraters {
@@ -84,6 +88,7 @@ func NewHostPre(cfg Config) (Adapter, error) {
 
 	hydraters := []hydrater{
 		a.HostPre.IAMRoles.Adapt,
+		a.HostPre.ResourceShares.Adapt,
 	}
 
 	for _, h := range hydraters {
@@ -101,6 +106,7 @@ func NewHostPost(cfg Config) (Adapter, error) {
 	hydraters := []hydrater{
 		a.HostPost.RecordSets.Adapt,
 		a.HostPost.RouteTables.Adapt,
+		a.HostPost.TransitGateway.Adapt,
 	}
 
 	for _, h := range hydraters {
@@ -113,29 +119,33 @@ func NewHostPost(cfg Config) (Adapter, error) {
 }
 
 type GuestAdapter struct {
-	AutoScalingGroup    GuestAutoScalingGroupAdapter
-	IAMPolicies         GuestIAMPoliciesAdapter
-	InternetGateway     GuestInternetGatewayAdapter
-	Instance            GuestInstanceAdapter
-	LaunchConfiguration GuestLaunchConfigAdapter
-	LifecycleHooks      GuestLifecycleHooksAdapter
-	LoadBalancers       GuestLoadBalancersAdapter
-	NATGateway          GuestNATGatewayAdapter
-	Outputs             GuestOutputsAdapter
-	RecordSets          GuestRecordSetsAdapter
-	RouteTables         GuestRouteTablesAdapter
-	SecurityGroups      GuestSecurityGroupsAdapter
-	Subnets             GuestSubnetsAdapter
-	VPC                 GuestVPCAdapter
+	AutoScalingGroup GuestAutoScalingGroupAdapter
+	IAMPolicies      GuestIAMPoliciesAdapter
+	InternetGateway  GuestInternetGatewayAdapter
+	Instance         GuestInstanceAdapter
+	LaunchTemplate   GuestLaunchTemplateAdapter
+	LifecycleHooks   GuestLifecycleHooksAdapter
+	LoadBalancers    GuestLoadBalancersAdapter
+	NATGateway       GuestNATGatewayAdapter
+	Outputs          GuestOutputsAdapter
+	RecordSets       GuestRecordSetsAdapter
+	RouteTables      GuestRouteTablesAdapter
+	SecurityGroups   GuestSecurityGroupsAdapter
+	Subnets          GuestSubnetsAdapter
+	VPC              GuestVPCAdapter
+	VPCConnections   GuestVPCConnectionsAdapter
+	VPCEndpoints     GuestVPCEndpointsAdapter
 }
 
 type HostPostAdapter struct {
-	RouteTables HostPostRouteTablesAdapter
-	RecordSets  HostPostRecordSetsAdapter
+	RouteTables    HostPostRouteTablesAdapter
+	RecordSets     HostPostRecordSetsAdapter
+	TransitGateway HostPostTransitGatewayAdapter
 }
 
 type HostPreAdapter struct {
-	IAMRoles HostPreIAMRolesAdapter
+	IAMRoles       HostPreIAMRolesAdapter
+	ResourceShares HostPreResourceSharesAdapter
 }
 
 type hydrater func(Config) error
@@ -1,11 +1,26 @@
 package adapter
 
-import "github.com/giantswarm/aws-operator/service/controller/v18/key"
+import (
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// apiWhitelistEnabled returns true when API whitelisting is enabled for the
+// installation or the custom object whitelists CIDRs of its own.
+func apiWhitelistEnabled(config Config) bool {
+	return config.APIWhitelist.Enabled || len(key.APIWhitelist(config.CustomObject)) > 0
+}
 
 func asgType(config Config) string {
 	return prefixWorker
 }
 
+// nodePoolASGType returns the ASG type of the node pool with the given name.
+// It is keyed on the node pool name, so that removing or reordering node pools
+// does not replace the ASGs of the other node pools.
+func nodePoolASGType(config Config, nodePoolName string) string {
+	return key.NodePoolResourceName(asgType(config), nodePoolName)
+}
+
 func baseDomain(config Config) string {
 	return key.BaseDomain(config.CustomObject)
 }
@@ -18,14 +33,112 @@ func hostedZoneNameServers(config Config) string {
 	return config.StackState.HostedZoneNameServers
 }
 
-func masterInstanceResourceName(config Config) string {
-	return config.StackState.MasterInstanceResourceName
+func masterInstanceResourceNames(config Config) []string {
+	var names []string
+	for _, m := range masters(config) {
+		names = append(names, m.InstanceResourceName)
+	}
+
+	return names
 }
 
 func route53Enabled(config Config) bool {
 	return config.Route53Enabled
 }
 
+// transitGatewayID returns the ID of the Transit Gateway the guest cluster VPC
+// is attached to. It is empty when the guest cluster VPC is peered with the
+// host cluster VPC.
+func transitGatewayID(config Config) string {
+	if !config.StackState.TransitGateway {
+		return ""
+	}
+
+	return config.TransitGateway.ID
+}
+
 func workerImageID(config Config) string {
 	return config.StackState.WorkerImageID
 }
+
+// loadBalancerTypes returns the types of the load balancers rendered for the
+// Kubernetes API and the ingress controller. Stack states not providing any
+// load balancer types result in the load balancer type of the custom object.
+func loadBalancerTypes(config Config) []string {
+	if len(config.StackState.LoadBalancerTypes) > 0 {
+		return config.StackState.LoadBalancerTypes
+	}
+
+	return []string{key.LoadBalancerType(config.CustomObject)}
+}
+
+// hasLoadBalancerType returns true when load balancers of the given type are
+// rendered for the Kubernetes API and the ingress controller.
+func hasLoadBalancerType(config Config, loadBalancerType string) bool {
+	for _, t := range loadBalancerTypes(config) {
+		if t == loadBalancerType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// masters returns the state of all master instances. Stack states not
+// providing any master list, e.g. the ones of guest clusters created before
+// multiple masters were supported, result in a single master.
+func masters(config Config) []StackStateMaster {
+	if len(config.StackState.Masters) > 0 {
+		return config.StackState.Masters
+	}
+
+	master := StackStateMaster{
+		CloudConfigVersion:       config.StackState.MasterCloudConfigVersion,
+		DockerVolumeResourceName: config.StackState.DockerVolumeResourceName,
+		ImageID:                  config.StackState.MasterImageID,
+		InstanceResourceName:     config.StackState.MasterInstanceResourceName,
+		InstanceType:             config.StackState.MasterInstanceType,
+		VersionBundleVersion:     config.StackState.VersionBundleVersion,
+	}
+
+	return []StackStateMaster{master}
+}
+
+// workerPools returns the state of all node pools. Stack states not providing
+// any node pool list result in the node pools defined by the custom object.
+func workerPools(config Config) []StackStateWorkerPool {
+	if len(config.StackState.WorkerPools) > 0 {
+		return config.StackState.WorkerPools
+	}
+
+	var pools []StackStateWorkerPool
+	for _, p := range key.NodePools(config.CustomObject) {
+		imageID := key.NodePoolImageID(p)
+		if imageID == "" {
+			imageID = config.StackState.WorkerImageID
+		}
+
+		pool := StackStateWorkerPool{
+			Count:              key.NodePoolCount(p),
+			DockerVolumeSizeGB: key.NodePoolDockerVolumeSizeGB(p),
+			ImageID:            imageID,
+			InstanceType:       key.NodePoolInstanceType(p),
+			Name:               p.Name,
+			Scaling: StackStateWorkerPoolScaling{
+				Enabled: key.NodePoolScalingEnabled(p),
+				Max:     key.NodePoolScalingMax(p),
+				Min:     key.NodePoolScalingMin(p),
+			},
+			Spot: StackStateWorkerPoolSpot{
+				Enabled:                             p.Spot.Enabled,
+				InstanceTypes:                       key.NodePoolInstanceTypes(p),
+				MaxPrice:                            p.Spot.MaxPrice,
+				OnDemandBaseCapacity:                p.Spot.OnDemandBaseCapacity,
+				OnDemandPercentageAboveBaseCapacity: p.Spot.OnDemandPercentageAboveBaseCapacity,
+			},
+		}
+		pools = append(pools, pool)
+	}
+
+	return pools
+}
@@ -80,6 +80,31 @@ func TestAdapterGuestMain(t *testing.T) {
 			expectedMasterImageID:    "master-image-id",
 			expectedWorkerImageID:    "worker-image-id",
 		},
+		{
+			description: "worker image id from custom object",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: defaultCluster,
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AZ:     "eu-north-1a",
+						Region: "eu-north-1",
+						Masters: []v1alpha1.AWSConfigSpecAWSNode{
+							{},
+						},
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								ImageID: "ami-0ab45b7cd3d3fd8b3",
+							},
+						},
+					},
+				},
+			},
+			errorMatcher:             nil,
+			expectedASGType:          "worker",
+			expectedEC2ServiceDomain: "ec2.amazonaws.com",
+			expectedMasterImageID:    "master-image-id",
+			expectedWorkerImageID:    "ami-0ab45b7cd3d3fd8b3",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -114,19 +139,19 @@ func TestAdapterGuestMain(t *testing.T) {
 				t.Fatal("expected", true, "got", false)
 			}
 
-			if tc.expectedASGType != a.Guest.AutoScalingGroup.ASGType {
-				t.Fatalf("unexpected ASG type, expected %q, got %q", tc.expectedASGType, a.Guest.AutoScalingGroup.ASGType)
+			if tc.expectedASGType != a.Guest.AutoScalingGroup.NodePools[0].ASGType {
+				t.Fatalf("unexpected ASG type, expected %q, got %q", tc.expectedASGType, a.Guest.AutoScalingGroup.NodePools[0].ASGType)
 			}
-			if tc.expectedASGType != a.Guest.LaunchConfiguration.ASGType {
-				t.Fatalf("unexpected ASG type, expected %q, got %q", tc.expectedASGType, a.Guest.LaunchConfiguration.ASGType)
+			if tc.expectedASGType != a.Guest.LaunchTemplate.NodePools[0].ASGType {
+				t.Fatalf("unexpected ASG type, expected %q, got %q", tc.expectedASGType, a.Guest.LaunchTemplate.NodePools[0].ASGType)
 			}
 
 			if tc.expectedEC2ServiceDomain != a.Guest.IAMPolicies.EC2ServiceDomain {
 				t.Fatalf("unexpected EC2 service domain, expected %q, got %q", tc.expectedEC2ServiceDomain, a.Guest.IAMPolicies.EC2ServiceDomain)
 			}
 
-			if tc.expectedWorkerImageID != a.Guest.LaunchConfiguration.WorkerImageID {
-				t.Fatalf("unexpected WorkerImageID, expected %q, got %q", tc.expectedWorkerImageID, a.Guest.LaunchConfiguration.WorkerImageID)
+			if tc.expectedWorkerImageID != a.Guest.LaunchTemplate.NodePools[0].WorkerImageID {
+				t.Fatalf("unexpected WorkerImageID, expected %q, got %q", tc.expectedWorkerImageID, a.Guest.LaunchTemplate.NodePools[0].WorkerImageID)
 			}
 		})
 	}
@@ -9,31 +9,106 @@ import (
 )
 
 type GuestAutoScalingGroupAdapter struct {
-	ASGMaxSize             int
-	ASGMinSize             int
-	ASGType                string
+	ClusterAutoscalerTags  GuestAutoScalingGroupAdapterClusterAutoscalerTags
 	ClusterID              string
 	HealthCheckGracePeriod int
-	MaxBatchSize           string
-	MinInstancesInService  string
+	// IngressLoadBalancer is true when the workers are registered with the
+	// classic ingress ELB.
+	IngressLoadBalancer bool
+	// IngressTargetGroups are the target groups of the ingress NLB the workers
+	// are registered with.
+	IngressTargetGroups    []string
+	NodePools              []GuestAutoScalingGroupAdapterNodePool
+	PrivateSubnets         []string
 	RollingUpdatePauseTime string
-	WorkerAZ               string
+	WorkerAZs              []string
+}
+
+// GuestAutoScalingGroupAdapterNodePool holds the configuration of the ASG of a
+// single node pool.
+type GuestAutoScalingGroupAdapterNodePool struct {
+	ASGMaxSize            int
+	ASGMinSize            int
+	ASGType               string
+	MaxBatchSize          string
+	MinInstancesInService string
+	Name                  string
+	// ScalingEnabled is true when cluster-autoscaler manages the desired
+	// capacity of the ASG within its minimum and maximum size.
+	ScalingEnabled bool
+	Spot           GuestAutoScalingGroupAdapterSpot
+}
+
+// GuestAutoScalingGroupAdapterClusterAutoscalerTags holds the tag keys
+// cluster-autoscaler uses to discover the ASGs it may scale.
+type GuestAutoScalingGroupAdapterClusterAutoscalerTags struct {
+	Cluster string
+	Enabled string
+}
+
+// GuestAutoScalingGroupAdapterSpot holds the mixed instances policy of a node
+// pool running on spot instances. The on-demand capacity configured here keeps
+// the node pool available when spot capacity is short.
+type GuestAutoScalingGroupAdapterSpot struct {
+	Enabled                             bool
+	InstanceTypes                       []string
+	MaxPrice                            string
+	OnDemandBaseCapacity                int
+	OnDemandPercentageAboveBaseCapacity int
 }
 
 func (a *GuestAutoScalingGroupAdapter) Adapt(cfg Config) error {
-	workers := key.WorkerCount(cfg.CustomObject)
-	if workers <= 0 {
-		return microerror.Maskf(invalidConfigError, "at least 1 worker required, found %d", workers)
+	// The worker ASGs span the private subnets of all availability zones.
+	a.WorkerAZs = key.AvailabilityZones(cfg.CustomObject)
+	for i := range a.WorkerAZs {
+		a.PrivateSubnets = append(a.PrivateSubnets, key.IndexedName("PrivateSubnet", i))
 	}
 
-	a.WorkerAZ = key.AvailabilityZone(cfg.CustomObject)
-	a.ASGMaxSize = workers + 1
-	a.ASGMinSize = workers
-	a.ASGType = asgType(cfg)
+	for _, p := range workerPools(cfg) {
+		workers := p.Count
+		if workers <= 0 {
+			return microerror.Maskf(invalidConfigError, "at least 1 worker required for node pool %#q, found %d", p.Name, workers)
+		}
+
+		// Node pools scaled by cluster-autoscaler are bound by their scaling
+		// configuration. Their rolling updates are computed based on the minimum
+		// size, since their actual number of workers is not known upfront.
+		asgMaxSize := workers + 1
+		asgMinSize := workers
+		if p.Scaling.Enabled {
+			asgMaxSize = p.Scaling.Max
+			asgMinSize = p.Scaling.Min
+		}
+
+		nodePool := GuestAutoScalingGroupAdapterNodePool{
+			ASGMaxSize:            asgMaxSize,
+			ASGMinSize:            asgMinSize,
+			ASGType:               nodePoolASGType(cfg, p.Name),
+			MaxBatchSize:          workerCountRatio(asgMinSize, asgMaxBatchSizeRatio),
+			MinInstancesInService: workerCountRatio(asgMinSize, asgMinInstancesRatio),
+			Name:                  p.Name,
+			ScalingEnabled:        p.Scaling.Enabled,
+			Spot: GuestAutoScalingGroupAdapterSpot{
+				Enabled:                             p.Spot.Enabled,
+				InstanceTypes:                       p.Spot.InstanceTypes,
+				MaxPrice:                            p.Spot.MaxPrice,
+				OnDemandBaseCapacity:                p.Spot.OnDemandBaseCapacity,
+				OnDemandPercentageAboveBaseCapacity: p.Spot.OnDemandPercentageAboveBaseCapacity,
+			},
+		}
+		a.NodePools = append(a.NodePools, nodePool)
+	}
+
+	a.ClusterAutoscalerTags.Cluster = key.ClusterAutoscalerTag(cfg.CustomObject)
+	a.ClusterAutoscalerTags.Enabled = key.ClusterAutoscalerEnabledTagName
 	a.ClusterID = clusterID(cfg)
-	a.MaxBatchSize = workerCountRatio(workers, asgMaxBatchSizeRatio)
-	a.MinInstancesInService = workerCountRatio(workers, asgMinInstancesRatio)
 	a.HealthCheckGracePeriod = gracePeriodSeconds
+	a.IngressLoadBalancer = hasLoadBalancerType(cfg, key.LoadBalancerTypeClassic)
+	if hasLoadBalancerType(cfg, key.LoadBalancerTypeNetwork) {
+		for _, tg := range ingressTargetGroups(cfg) {
+			a.IngressTargetGroups = append(a.IngressTargetGroups, tg.ResourceName)
+		}
+	}
 	a.RollingUpdatePauseTime = rollingUpdatePauseTime
 
 	return nil
@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
@@ -12,12 +13,13 @@ func TestAdapterAutoScalingGroupRegularFields(t *testing.T) {
 		description                    string
 		customObject                   v1alpha1.AWSConfig
 		expectedError                  bool
-		expectedAZ                     string
+		expectedAZs                    []string
 		expectedASGMaxSize             int
 		expectedASGMinSize             int
 		expectedHealthCheckGracePeriod int
 		expectedMaxBatchSize           string
 		expectedMinInstancesInService  string
+		expectedPrivateSubnets         []string
 		expectedRollingUpdatePauseTime string
 	}{
 		{
@@ -47,12 +49,13 @@ func TestAdapterAutoScalingGroupRegularFields(t *testing.T) {
 				},
 			},
 			expectedError:                  false,
-			expectedAZ:                     "myaz",
+			expectedAZs:                    []string{"myaz"},
 			expectedASGMaxSize:             2, // headroom is +1
 			expectedASGMinSize:             1,
 			expectedHealthCheckGracePeriod: gracePeriodSeconds,
 			expectedMaxBatchSize:           "1",
 			expectedMinInstancesInService:  "1",
+			expectedPrivateSubnets:         []string{"PrivateSubnet"},
 			expectedRollingUpdatePauseTime: rollingUpdatePauseTime,
 		},
 		{
@@ -71,12 +74,13 @@ func TestAdapterAutoScalingGroupRegularFields(t *testing.T) {
 				},
 			},
 			expectedError:                  false,
-			expectedAZ:                     "myaz",
+			expectedAZs:                    []string{"myaz"},
 			expectedASGMaxSize:             4,
 			expectedASGMinSize:             3,
 			expectedHealthCheckGracePeriod: gracePeriodSeconds,
 			expectedMaxBatchSize:           "1",
 			expectedMinInstancesInService:  "2",
+			expectedPrivateSubnets:         []string{"PrivateSubnet"},
 			expectedRollingUpdatePauseTime: rollingUpdatePauseTime,
 		},
 		{
@@ -99,12 +103,43 @@ func TestAdapterAutoScalingGroupRegularFields(t *testing.T) {
 				},
 			},
 			expectedError:                  false,
-			expectedAZ:                     "myaz",
+			expectedAZs:                    []string{"myaz"},
 			expectedASGMaxSize:             8,
 			expectedASGMinSize:             7,
 			expectedHealthCheckGracePeriod: gracePeriodSeconds,
 			expectedMaxBatchSize:           "2",
 			expectedMinInstancesInService:  "5",
+			expectedPrivateSubnets:         []string{"PrivateSubnet"},
+			expectedRollingUpdatePauseTime: rollingUpdatePauseTime,
+		},
+		{
+			description: "multiple availability zones, ASG spans all private subnets",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: defaultCluster,
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AZ: "eu-central-1a",
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+							"eu-central-1c",
+						},
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{},
+							{},
+							{},
+						},
+					},
+				},
+			},
+			expectedError:                  false,
+			expectedAZs:                    []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			expectedASGMaxSize:             4,
+			expectedASGMinSize:             3,
+			expectedHealthCheckGracePeriod: gracePeriodSeconds,
+			expectedMaxBatchSize:           "1",
+			expectedMinInstancesInService:  "2",
+			expectedPrivateSubnets:         []string{"PrivateSubnet", "PrivateSubnet01", "PrivateSubnet02"},
 			expectedRollingUpdatePauseTime: rollingUpdatePauseTime,
 		},
 	}
@@ -127,32 +162,36 @@ func TestAdapterAutoScalingGroupRegularFields(t *testing.T) {
 			}
 
 			if !tc.expectedError {
-				if a.Guest.AutoScalingGroup.ASGMaxSize != tc.expectedASGMaxSize {
-					t.Errorf("unexpected output, got %d, want %d", a.Guest.AutoScalingGroup.ASGMaxSize, tc.expectedASGMaxSize)
+				if a.Guest.AutoScalingGroup.NodePools[0].ASGMaxSize != tc.expectedASGMaxSize {
+					t.Errorf("unexpected output, got %d, want %d", a.Guest.AutoScalingGroup.NodePools[0].ASGMaxSize, tc.expectedASGMaxSize)
 				}
 
-				if a.Guest.AutoScalingGroup.ASGMinSize != tc.expectedASGMinSize {
-					t.Errorf("unexpected output, got %d, want %d", a.Guest.AutoScalingGroup.ASGMinSize, tc.expectedASGMinSize)
+				if a.Guest.AutoScalingGroup.NodePools[0].ASGMinSize != tc.expectedASGMinSize {
+					t.Errorf("unexpected output, got %d, want %d", a.Guest.AutoScalingGroup.NodePools[0].ASGMinSize, tc.expectedASGMinSize)
 				}
 
 				if a.Guest.AutoScalingGroup.HealthCheckGracePeriod != tc.expectedHealthCheckGracePeriod {
 					t.Errorf("unexpected output, got %d, want %d", a.Guest.AutoScalingGroup.HealthCheckGracePeriod, tc.expectedHealthCheckGracePeriod)
 				}
 
-				if a.Guest.AutoScalingGroup.MaxBatchSize != tc.expectedMaxBatchSize {
-					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.MaxBatchSize, tc.expectedMaxBatchSize)
+				if a.Guest.AutoScalingGroup.NodePools[0].MaxBatchSize != tc.expectedMaxBatchSize {
+					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.NodePools[0].MaxBatchSize, tc.expectedMaxBatchSize)
 				}
 
-				if a.Guest.AutoScalingGroup.MinInstancesInService != tc.expectedMinInstancesInService {
-					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.MinInstancesInService, tc.expectedMinInstancesInService)
+				if a.Guest.AutoScalingGroup.NodePools[0].MinInstancesInService != tc.expectedMinInstancesInService {
+					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.NodePools[0].MinInstancesInService, tc.expectedMinInstancesInService)
 				}
 
 				if a.Guest.AutoScalingGroup.RollingUpdatePauseTime != tc.expectedRollingUpdatePauseTime {
 					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.RollingUpdatePauseTime, tc.expectedRollingUpdatePauseTime)
 				}
 
-				if a.Guest.AutoScalingGroup.WorkerAZ != tc.expectedAZ {
-					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.WorkerAZ, tc.expectedAZ)
+				if !reflect.DeepEqual(a.Guest.AutoScalingGroup.WorkerAZs, tc.expectedAZs) {
+					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.WorkerAZs, tc.expectedAZs)
+				}
+
+				if !reflect.DeepEqual(a.Guest.AutoScalingGroup.PrivateSubnets, tc.expectedPrivateSubnets) {
+					t.Errorf("unexpected output, got %q, want %q", a.Guest.AutoScalingGroup.PrivateSubnets, tc.expectedPrivateSubnets)
 				}
 
 			}
@@ -160,6 +199,107 @@ func TestAdapterAutoScalingGroupRegularFields(t *testing.T) {
 	}
 }
 
+func Test_Adapter_AutoScalingGroup_NodePools(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: defaultCluster,
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AZ: "myaz",
+				NodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+					{
+						Name: "general",
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{},
+						},
+					},
+					{
+						Name: "spot",
+						Spot: v1alpha1.AWSConfigSpecAWSNodePoolSpot{
+							Enabled:                             true,
+							InstanceTypes:                       []string{"r5.xlarge"},
+							MaxPrice:                            "0.2",
+							OnDemandBaseCapacity:                1,
+							OnDemandPercentageAboveBaseCapacity: 10,
+						},
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								InstanceType: "r4.xlarge",
+							},
+							{
+								InstanceType: "r4.xlarge",
+							},
+							{
+								InstanceType: "r4.xlarge",
+							},
+						},
+					},
+					{
+						Name: "autoscaled",
+						Scaling: v1alpha1.ClusterScaling{
+							Max: 10,
+							Min: 4,
+						},
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{},
+						},
+					},
+				},
+			},
+		},
+	}
+	expectedNodePools := []GuestAutoScalingGroupAdapterNodePool{
+		{
+			ASGMaxSize:            2,
+			ASGMinSize:            1,
+			ASGType:               "workerGeneral",
+			MaxBatchSize:          "1",
+			MinInstancesInService: "1",
+			Name:                  "general",
+		},
+		{
+			ASGMaxSize:            4,
+			ASGMinSize:            3,
+			ASGType:               "workerSpot",
+			MaxBatchSize:          "1",
+			MinInstancesInService: "2",
+			Name:                  "spot",
+			Spot: GuestAutoScalingGroupAdapterSpot{
+				Enabled:                             true,
+				InstanceTypes:                       []string{"r4.xlarge", "r5.xlarge"},
+				MaxPrice:                            "0.2",
+				OnDemandBaseCapacity:                1,
+				OnDemandPercentageAboveBaseCapacity: 10,
+			},
+		},
+		{
+			ASGMaxSize:            10,
+			ASGMinSize:            4,
+			ASGType:               "workerAutoscaled",
+			MaxBatchSize:          "1",
+			MinInstancesInService: "3",
+			Name:                  "autoscaled",
+			ScalingEnabled:        true,
+		},
+	}
+
+	a := Adapter{}
+	cfg := Config{
+		CustomObject: customObject,
+	}
+	err := a.Guest.AutoScalingGroup.Adapt(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if !reflect.DeepEqual(a.Guest.AutoScalingGroup.NodePools, expectedNodePools) {
+		t.Fatalf("unexpected node pools, got %#v, want %#v", a.Guest.AutoScalingGroup.NodePools, expectedNodePools)
+	}
+	if a.Guest.AutoScalingGroup.ClusterAutoscalerTags.Cluster != "k8s.io/cluster-autoscaler/test-cluster" {
+		t.Fatalf("unexpected cluster autoscaler tag, got %q", a.Guest.AutoScalingGroup.ClusterAutoscalerTags.Cluster)
+	}
+}
+
 func TestWorkerCountRatioMaxBatchSize(t *testing.T) {
 	t.Parallel()
 	tcs := []struct {
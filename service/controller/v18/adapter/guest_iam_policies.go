@@ -12,16 +12,19 @@ import (
 )
 
 type GuestIAMPoliciesAdapter struct {
-	EC2ServiceDomain  string
-	KMSKeyARN         string
-	MasterRoleName    string
-	MasterPolicyName  string
-	MasterProfileName string
-	RegionARN         string
-	S3Bucket          string
-	WorkerRoleName    string
-	WorkerPolicyName  string
-	WorkerProfileName string
+	// ClusterAutoscalerTag is only set when cluster-autoscaler may scale any
+	// node pool. It restricts the ASGs cluster-autoscaler is allowed to scale.
+	ClusterAutoscalerTag string
+	EC2ServiceDomain     string
+	KMSKeyARN            string
+	MasterRoleName       string
+	MasterPolicyName     string
+	MasterProfileName    string
+	RegionARN            string
+	S3Bucket             string
+	WorkerRoleName       string
+	WorkerPolicyName     string
+	WorkerProfileName    string
 }
 
 func (i *GuestIAMPoliciesAdapter) Adapt(cfg Config) error {
@@ -36,6 +39,10 @@ func (i *GuestIAMPoliciesAdapter) Adapt(cfg Config) error {
 	i.WorkerRoleName = key.RoleName(cfg.CustomObject, prefixWorker)
 	i.RegionARN = key.RegionARN(cfg.CustomObject)
 
+	if key.HasScalingNodePools(cfg.CustomObject) {
+		i.ClusterAutoscalerTag = key.ClusterAutoscalerTag(cfg.CustomObject)
+	}
+
 	// KMSKeyARN
 	if cfg.EncrypterBackend == encrypter.KMSBackend {
 		keyAlias := fmt.Sprintf("alias/%s", clusterID)
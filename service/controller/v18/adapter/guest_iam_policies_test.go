@@ -213,3 +213,55 @@ func TestAdapterIamPoliciesS3Bucket(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterIamPoliciesClusterAutoscalerTag(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description                  string
+		scaling                      v1alpha1.ClusterScaling
+		expectedClusterAutoscalerTag string
+	}{
+		{
+			description:                  "autoscaling disabled",
+			expectedClusterAutoscalerTag: "",
+		},
+		{
+			description: "autoscaling enabled",
+			scaling: v1alpha1.ClusterScaling{
+				Max: 10,
+				Min: 3,
+			},
+			expectedClusterAutoscalerTag: "k8s.io/cluster-autoscaler/test-cluster",
+		},
+	}
+
+	clients := Clients{
+		KMS: &KMSClientMock{},
+		IAM: &IAMClientMock{},
+		STS: &STSClientMock{},
+	}
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: defaultCluster,
+				},
+			}
+			customObject.Spec.Cluster.Scaling = tc.scaling
+
+			cfg := Config{
+				CustomObject: customObject,
+				Clients:      clients,
+			}
+			err := a.Guest.IAMPolicies.Adapt(cfg)
+			if err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+
+			if a.Guest.IAMPolicies.ClusterAutoscalerTag != tc.expectedClusterAutoscalerTag {
+				t.Errorf("unexpected ClusterAutoscalerTag, got %q, want %q", a.Guest.IAMPolicies.ClusterAutoscalerTag, tc.expectedClusterAutoscalerTag)
+			}
+		})
+	}
+}
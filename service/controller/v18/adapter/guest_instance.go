@@ -11,8 +11,7 @@ import (
 
 type GuestInstanceAdapter struct {
 	Cluster GuestInstanceAdapterCluster
-	Image   GuestInstanceAdapterImage
-	Master  GuestInstanceAdapterMaster
+	Masters []GuestInstanceAdapterMaster
 }
 
 type GuestInstanceAdapterCluster struct {
@@ -29,7 +28,9 @@ type GuestInstanceAdapterMaster struct {
 	EncrypterBackend string
 	DockerVolume     GuestInstanceAdapterMasterDockerVolume
 	EtcdVolume       GuestInstanceAdapterMasterEtcdVolume
+	Image            GuestInstanceAdapterImage
 	Instance         GuestInstanceAdapterMasterInstance
+	PrivateSubnet    string
 }
 
 type GuestInstanceAdapterMasterDockerVolume struct {
@@ -38,13 +39,15 @@ type GuestInstanceAdapterMasterDockerVolume struct {
 }
 
 type GuestInstanceAdapterMasterEtcdVolume struct {
-	Name string
+	Name         string
+	ResourceName string
 }
 
 type GuestInstanceAdapterMasterInstance struct {
+	Monitoring   bool
+	Name         string
 	ResourceName string
 	Type         string
-	Monitoring   bool
 }
 
 func (i *GuestInstanceAdapter) Adapt(config Config) error {
@@ -52,43 +55,69 @@ func (i *GuestInstanceAdapter) Adapt(config Config) error {
 		i.Cluster.ID = key.ClusterID(config.CustomObject)
 	}
 
-	{
-		i.Image.ID = config.StackState.MasterImageID
+	accountID, err := AccountID(config.Clients)
+	if err != nil {
+		return microerror.Mask(err)
 	}
 
-	{
-		i.Master.AZ = key.AvailabilityZone(config.CustomObject)
-
-		accountID, err := AccountID(config.Clients)
-		if err != nil {
-			return microerror.Mask(err)
+	azs := key.AvailabilityZones(config.CustomObject)
+	stackStateMasters := masters(config)
+
+	// Masters are spread across the availability zones of the guest cluster.
+	// Each master is placed into the private subnet of its availability zone.
+	for index, m := range stackStateMasters {
+		var master GuestInstanceAdapterMaster
+
+		azIndex := index % len(azs)
+		master.AZ = azs[azIndex]
+		master.PrivateSubnet = key.IndexedName("PrivateSubnet", azIndex)
+
+		// Masters which are not replaced during an update have to keep pointing
+		// to the cloud config of the version bundle version they were created
+		// with. Otherwise their user data would change and CloudFormation would
+		// restart them.
+		customObject := config.CustomObject
+		if m.VersionBundleVersion != "" {
+			customObject.Spec.VersionBundle.Version = m.VersionBundleVersion
 		}
+
 		c := SmallCloudconfigConfig{
-			Region:    key.Region(config.CustomObject),
-			Registry:  key.AWSCliContainerRegistry(config.CustomObject),
+			Region:    key.Region(customObject),
+			Registry:  key.AWSCliContainerRegistry(customObject),
 			Role:      prefixMaster,
-			S3HTTPURL: key.SmallCloudConfigS3HTTPURL(config.CustomObject, accountID, prefixMaster),
-			S3URL:     key.SmallCloudConfigS3URL(config.CustomObject, accountID, prefixMaster),
+			S3HTTPURL: key.SmallCloudConfigS3HTTPURL(customObject, accountID, prefixMaster),
+			S3URL:     key.SmallCloudConfigS3URL(customObject, accountID, prefixMaster),
+		}
+		if len(stackStateMasters) > 1 {
+			c.EtcdMemberName = key.EtcdMemberName(index)
 		}
 		rendered, err := templates.Render(key.CloudConfigSmallTemplates(), c)
 		if err != nil {
 			return microerror.Mask(err)
 		}
-		i.Master.CloudConfig = base64.StdEncoding.EncodeToString([]byte(rendered))
+		master.CloudConfig = base64.StdEncoding.EncodeToString([]byte(rendered))
+
+		master.EncrypterBackend = config.EncrypterBackend
+
+		master.DockerVolume.Name = key.IndexedName(key.DockerVolumeName(config.CustomObject), index)
+
+		master.DockerVolume.ResourceName = m.DockerVolumeResourceName
+
+		master.EtcdVolume.Name = key.IndexedName(key.EtcdVolumeName(config.CustomObject), index)
 
-		i.Master.EncrypterBackend = config.EncrypterBackend
+		master.EtcdVolume.ResourceName = key.IndexedName("EtcdVolume", index)
 
-		i.Master.DockerVolume.Name = key.DockerVolumeName(config.CustomObject)
+		master.Image.ID = m.ImageID
 
-		i.Master.DockerVolume.ResourceName = config.StackState.DockerVolumeResourceName
+		master.Instance.Monitoring = config.StackState.MasterInstanceMonitoring
 
-		i.Master.EtcdVolume.Name = key.EtcdVolumeName(config.CustomObject)
+		master.Instance.Name = key.IndexedName(key.MasterInstanceName(config.CustomObject), index)
 
-		i.Master.Instance.ResourceName = config.StackState.MasterInstanceResourceName
+		master.Instance.ResourceName = m.InstanceResourceName
 
-		i.Master.Instance.Type = config.StackState.MasterInstanceType
+		master.Instance.Type = m.InstanceType
 
-		i.Master.Instance.Monitoring = config.StackState.MasterInstanceMonitoring
+		i.Masters = append(i.Masters, master)
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package adapter
 import (
 	"encoding/base64"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -61,20 +62,20 @@ func Test_Adapter_Instance_RegularFields(t *testing.T) {
 				t.Fatal("expected", nil, "got", err)
 			}
 
-			if a.Master.AZ != tc.ExpectedAZ {
-				t.Fatalf("unexpected a.Master.AZ, got %q, want %q", a.Master.AZ, tc.ExpectedAZ)
+			if a.Masters[0].AZ != tc.ExpectedAZ {
+				t.Fatalf("unexpected a.Masters[0].AZ, got %q, want %q", a.Masters[0].AZ, tc.ExpectedAZ)
 			}
 
-			if a.Master.EtcdVolume.Name != tc.ExpectedEtcdVolumeName {
-				t.Fatalf("unexpected a.Master.EtcdVolume.Name, got %q, want %q", a.Master.EtcdVolume.Name, tc.ExpectedEtcdVolumeName)
+			if a.Masters[0].EtcdVolume.Name != tc.ExpectedEtcdVolumeName {
+				t.Fatalf("unexpected a.Masters[0].EtcdVolume.Name, got %q, want %q", a.Masters[0].EtcdVolume.Name, tc.ExpectedEtcdVolumeName)
 			}
 
-			if a.Master.Instance.Type != tc.ExpectedInstanceType {
-				t.Fatalf("unexpected a.Master.Instance.Type, got %q, want %q", a.Master.Instance.Type, tc.ExpectedInstanceType)
+			if a.Masters[0].Instance.Type != tc.ExpectedInstanceType {
+				t.Fatalf("unexpected a.Masters[0].Instance.Type, got %q, want %q", a.Masters[0].Instance.Type, tc.ExpectedInstanceType)
 			}
 
-			if a.Master.EncrypterBackend != tc.ExpectedEncrypterBackend {
-				t.Fatalf("unexpected a.Master.Instance.Type, got %q, want %q", a.Master.EncrypterBackend, tc.ExpectedEncrypterBackend)
+			if a.Masters[0].EncrypterBackend != tc.ExpectedEncrypterBackend {
+				t.Fatalf("unexpected a.Masters[0].Instance.Type, got %q, want %q", a.Masters[0].EncrypterBackend, tc.ExpectedEncrypterBackend)
 			}
 		})
 	}
@@ -140,9 +141,9 @@ func Test_Adapter_Instance_SmallCloudConfig(t *testing.T) {
 				t.Fatalf("unexpected error %v", err)
 			}
 
-			data, err := base64.StdEncoding.DecodeString(a.Master.CloudConfig)
+			data, err := base64.StdEncoding.DecodeString(a.Masters[0].CloudConfig)
 			if err != nil {
-				t.Fatalf("unexpected error decoding a.Master.CloudConfig %v", err)
+				t.Fatalf("unexpected error decoding a.Masters[0].CloudConfig %v", err)
 			}
 
 			if !strings.Contains(string(data), tc.ExpectedLine) {
@@ -151,3 +152,130 @@ func Test_Adapter_Instance_SmallCloudConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_Adapter_Instance_MultipleMasters(t *testing.T) {
+	t.Parallel()
+
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AvailabilityZones: []string{
+					"eu-central-1a",
+					"eu-central-1b",
+				},
+				Region: "eu-central-1",
+			},
+			VersionBundle: v1alpha1.AWSConfigSpecVersionBundle{
+				Version: "2.0.0",
+			},
+		},
+	}
+	cfg := Config{
+		Clients: Clients{
+			EC2: &EC2ClientMock{},
+			IAM: &IAMClientMock{},
+			STS: &STSClientMock{accountID: "000000000000"},
+		},
+		CustomObject: customObject,
+		StackState: StackState{
+			Masters: []StackStateMaster{
+				{InstanceResourceName: "MasterInstanceA", VersionBundleVersion: "2.0.0"},
+				{InstanceResourceName: "MasterInstanceB", VersionBundleVersion: "1.0.0"},
+				{InstanceResourceName: "MasterInstanceC", VersionBundleVersion: "1.0.0"},
+			},
+		},
+	}
+
+	a := &GuestInstanceAdapter{}
+	err := a.Adapt(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(a.Masters) != 3 {
+		t.Fatalf("expected %d masters got %d", 3, len(a.Masters))
+	}
+
+	testCases := []struct {
+		ExpectedAZ                   string
+		ExpectedCloudConfigVersion   string
+		ExpectedEtcdMemberName       string
+		ExpectedEtcdVolumeName       string
+		ExpectedEtcdVolumeResource   string
+		ExpectedInstanceName         string
+		ExpectedInstanceResourceName string
+		ExpectedPrivateSubnet        string
+	}{
+		{
+			ExpectedAZ:                   "eu-central-1a",
+			ExpectedCloudConfigVersion:   "2.0.0",
+			ExpectedEtcdMemberName:       "etcd0",
+			ExpectedEtcdVolumeName:       "test-cluster-etcd",
+			ExpectedEtcdVolumeResource:   "EtcdVolume",
+			ExpectedInstanceName:         "test-cluster-master",
+			ExpectedInstanceResourceName: "MasterInstanceA",
+			ExpectedPrivateSubnet:        "PrivateSubnet",
+		},
+		{
+			ExpectedAZ:                   "eu-central-1b",
+			ExpectedCloudConfigVersion:   "1.0.0",
+			ExpectedEtcdMemberName:       "etcd1",
+			ExpectedEtcdVolumeName:       "test-cluster-etcd01",
+			ExpectedEtcdVolumeResource:   "EtcdVolume01",
+			ExpectedInstanceName:         "test-cluster-master01",
+			ExpectedInstanceResourceName: "MasterInstanceB",
+			ExpectedPrivateSubnet:        "PrivateSubnet01",
+		},
+		{
+			ExpectedAZ:                   "eu-central-1a",
+			ExpectedCloudConfigVersion:   "1.0.0",
+			ExpectedEtcdMemberName:       "etcd2",
+			ExpectedEtcdVolumeName:       "test-cluster-etcd02",
+			ExpectedEtcdVolumeResource:   "EtcdVolume02",
+			ExpectedInstanceName:         "test-cluster-master02",
+			ExpectedInstanceResourceName: "MasterInstanceC",
+			ExpectedPrivateSubnet:        "PrivateSubnet",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			m := a.Masters[i]
+
+			if m.AZ != tc.ExpectedAZ {
+				t.Fatalf("unexpected AZ, got %q, want %q", m.AZ, tc.ExpectedAZ)
+			}
+			if m.EtcdVolume.Name != tc.ExpectedEtcdVolumeName {
+				t.Fatalf("unexpected EtcdVolume.Name, got %q, want %q", m.EtcdVolume.Name, tc.ExpectedEtcdVolumeName)
+			}
+			if m.EtcdVolume.ResourceName != tc.ExpectedEtcdVolumeResource {
+				t.Fatalf("unexpected EtcdVolume.ResourceName, got %q, want %q", m.EtcdVolume.ResourceName, tc.ExpectedEtcdVolumeResource)
+			}
+			if m.Instance.Name != tc.ExpectedInstanceName {
+				t.Fatalf("unexpected Instance.Name, got %q, want %q", m.Instance.Name, tc.ExpectedInstanceName)
+			}
+			if m.Instance.ResourceName != tc.ExpectedInstanceResourceName {
+				t.Fatalf("unexpected Instance.ResourceName, got %q, want %q", m.Instance.ResourceName, tc.ExpectedInstanceResourceName)
+			}
+			if m.PrivateSubnet != tc.ExpectedPrivateSubnet {
+				t.Fatalf("unexpected PrivateSubnet, got %q, want %q", m.PrivateSubnet, tc.ExpectedPrivateSubnet)
+			}
+
+			data, err := base64.StdEncoding.DecodeString(m.CloudConfig)
+			if err != nil {
+				t.Fatalf("unexpected error decoding CloudConfig %v", err)
+			}
+			expectedLine := fmt.Sprintf("s3://000000000000-g8s-test-cluster/version/%s/cloudconfig/%s/master", tc.ExpectedCloudConfigVersion, key.CloudConfigVersion)
+			if !strings.Contains(string(data), expectedLine) {
+				t.Fatalf("SmallCloudConfig didn't contain expected %q, complete: %q", expectedLine, string(data))
+			}
+			expectedLine = fmt.Sprintf("ETCD_MEMBER_NAME=%s", tc.ExpectedEtcdMemberName)
+			if !strings.Contains(string(data), expectedLine) {
+				t.Fatalf("SmallCloudConfig didn't contain expected %q, complete: %q", expectedLine, string(data))
+			}
+		})
+	}
+}
@@ -1,11 +1,19 @@
 package adapter
 
+import (
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
 type GuestInternetGatewayAdapter struct {
 	ClusterID string
+	// ExistingVPC is true when the guest cluster is deployed into an existing
+	// VPC, which already comes with its own internet gateway.
+	ExistingVPC bool
 }
 
 func (a *GuestInternetGatewayAdapter) Adapt(cfg Config) error {
 	a.ClusterID = clusterID(cfg)
+	a.ExistingVPC = key.IsExistingVPC(cfg.CustomObject)
 
 	return nil
 }
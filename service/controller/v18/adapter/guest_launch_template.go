@@ -0,0 +1,89 @@
+package adapter
+
+import (
+	"encoding/base64"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+	"github.com/giantswarm/aws-operator/service/controller/v18/templates"
+)
+
+type GuestLaunchTemplateAdapter struct {
+	ClusterID                string
+	NodePools                []GuestLaunchTemplateAdapterNodePool
+	WorkerInstanceMonitoring bool
+	WorkerSmallCloudConfig   string
+}
+
+// GuestLaunchTemplateAdapterNodePool holds the configuration of the launch
+// template of a single node pool. CloudFormation creates a new version of the
+// launch template only when its data changes, which is when the ASG of the
+// node pool rolls its instances.
+type GuestLaunchTemplateAdapterNodePool struct {
+	ASGType                   string
+	Name                      string
+	WorkerBlockDeviceMappings []BlockDeviceMapping
+	WorkerImageID             string
+	WorkerInstanceType        string
+}
+
+type BlockDeviceMapping struct {
+	DeleteOnTermination bool
+	DeviceName          string
+	VolumeSize          int
+	VolumeType          string
+}
+
+func (l *GuestLaunchTemplateAdapter) Adapt(config Config) error {
+	l.ClusterID = clusterID(config)
+	l.WorkerInstanceMonitoring = config.StackState.WorkerInstanceMonitoring
+
+	for _, p := range workerPools(config) {
+		dockerVolumeSizeGB := p.DockerVolumeSizeGB
+		if dockerVolumeSizeGB <= 0 {
+			dockerVolumeSizeGB = defaultEBSVolumeSize
+		}
+
+		imageID := p.ImageID
+		if imageID == "" {
+			imageID = workerImageID(config)
+		}
+
+		nodePool := GuestLaunchTemplateAdapterNodePool{
+			ASGType: nodePoolASGType(config, p.Name),
+			Name:    p.Name,
+			WorkerBlockDeviceMappings: []BlockDeviceMapping{
+				{
+					DeleteOnTermination: true,
+					DeviceName:          defaultEBSVolumeMountPoint,
+					VolumeSize:          dockerVolumeSizeGB,
+					VolumeType:          defaultEBSVolumeType,
+				},
+			},
+			WorkerImageID:      imageID,
+			WorkerInstanceType: p.InstanceType,
+		}
+		l.NodePools = append(l.NodePools, nodePool)
+	}
+
+	// small cloud config field.
+	accountID, err := AccountID(config.Clients)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	c := SmallCloudconfigConfig{
+		Region:    key.Region(config.CustomObject),
+		Registry:  key.AWSCliContainerRegistry(config.CustomObject),
+		Role:      prefixWorker,
+		S3HTTPURL: key.SmallCloudConfigS3HTTPURL(config.CustomObject, accountID, prefixWorker),
+		S3URL:     key.SmallCloudConfigS3URL(config.CustomObject, accountID, prefixWorker),
+	}
+	rendered, err := templates.Render(key.CloudConfigSmallTemplates(), c)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	l.WorkerSmallCloudConfig = base64.StdEncoding.EncodeToString([]byte(rendered))
+
+	return nil
+}
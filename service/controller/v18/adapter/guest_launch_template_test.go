@@ -11,15 +11,14 @@ import (
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
-func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
+func Test_AdapterLaunchTemplate_RegularFields(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
-		description                      string
-		customObject                     v1alpha1.AWSConfig
-		expectedError                    bool
-		expectedInstanceType             string
-		expectedAssociatePublicIPAddress bool
-		expectedBlockDeviceMappings      []BlockDeviceMapping
+		description                 string
+		customObject                v1alpha1.AWSConfig
+		expectedError               bool
+		expectedInstanceType        string
+		expectedBlockDeviceMappings []BlockDeviceMapping
 	}{
 		{
 			description: "basic matching, all fields present",
@@ -38,8 +37,7 @@ func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
 					},
 				},
 			},
-			expectedInstanceType:             "myinstancetype",
-			expectedAssociatePublicIPAddress: false,
+			expectedInstanceType: "myinstancetype",
 			expectedBlockDeviceMappings: []BlockDeviceMapping{
 				{
 					DeleteOnTermination: true,
@@ -65,8 +63,7 @@ func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
 					},
 				},
 			},
-			expectedInstanceType:             "myinstancetype",
-			expectedAssociatePublicIPAddress: false,
+			expectedInstanceType: "myinstancetype",
 			expectedBlockDeviceMappings: []BlockDeviceMapping{
 				{
 					DeleteOnTermination: true,
@@ -93,7 +90,7 @@ func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
 					WorkerDockerVolumeSizeGB: key.WorkerDockerVolumeSizeGB(tc.customObject),
 				},
 			}
-			err := a.Guest.LaunchConfiguration.Adapt(cfg)
+			err := a.Guest.LaunchTemplate.Adapt(cfg)
 			if tc.expectedError && err == nil {
 				t.Error("expected error didn't happen")
 			}
@@ -102,23 +99,20 @@ func Test_AdapterLaunchConfiguration_RegularFields(t *testing.T) {
 				t.Errorf("unexpected error %v", err)
 			}
 
-			if a.Guest.LaunchConfiguration.ASGType != prefixWorker {
-				t.Errorf("unexpected ASGType, got %q, want %q", a.Guest.LaunchConfiguration.ASGType, prefixWorker)
+			if a.Guest.LaunchTemplate.NodePools[0].ASGType != prefixWorker {
+				t.Errorf("unexpected ASGType, got %q, want %q", a.Guest.LaunchTemplate.NodePools[0].ASGType, prefixWorker)
 			}
-			if a.Guest.LaunchConfiguration.WorkerInstanceType != tc.expectedInstanceType {
-				t.Errorf("unexpected InstanceType, got %q, want %q", a.Guest.LaunchConfiguration.WorkerInstanceType, tc.expectedInstanceType)
+			if a.Guest.LaunchTemplate.NodePools[0].WorkerInstanceType != tc.expectedInstanceType {
+				t.Errorf("unexpected InstanceType, got %q, want %q", a.Guest.LaunchTemplate.NodePools[0].WorkerInstanceType, tc.expectedInstanceType)
 			}
-			if a.Guest.LaunchConfiguration.WorkerAssociatePublicIPAddress != tc.expectedAssociatePublicIPAddress {
-				t.Errorf("unexpected WorkerAssociatePublicIPAddress, got %t, want %t", a.Guest.LaunchConfiguration.WorkerAssociatePublicIPAddress, tc.expectedAssociatePublicIPAddress)
-			}
-			if !reflect.DeepEqual(a.Guest.LaunchConfiguration.WorkerBlockDeviceMappings, tc.expectedBlockDeviceMappings) {
-				t.Errorf("unexpected BlockDeviceMappings, got %v, want %v", a.Guest.LaunchConfiguration.WorkerBlockDeviceMappings, tc.expectedBlockDeviceMappings)
+			if !reflect.DeepEqual(a.Guest.LaunchTemplate.NodePools[0].WorkerBlockDeviceMappings, tc.expectedBlockDeviceMappings) {
+				t.Errorf("unexpected BlockDeviceMappings, got %v, want %v", a.Guest.LaunchTemplate.NodePools[0].WorkerBlockDeviceMappings, tc.expectedBlockDeviceMappings)
 			}
 		})
 	}
 }
 
-func Test_AdapterLaunchConfiguration_SmallCloudConfig(t *testing.T) {
+func Test_AdapterLaunchTemplate_SmallCloudConfig(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
 		description  string
@@ -163,13 +157,13 @@ func Test_AdapterLaunchConfiguration_SmallCloudConfig(t *testing.T) {
 		CustomObject: customObject,
 		Clients:      clients,
 	}
-	err := a.Guest.LaunchConfiguration.Adapt(cfg)
+	err := a.Guest.LaunchTemplate.Adapt(cfg)
 
 	if err != nil {
 		t.Errorf("unexpected error %v", err)
 	}
 
-	data, err := base64.StdEncoding.DecodeString(a.Guest.LaunchConfiguration.WorkerSmallCloudConfig)
+	data, err := base64.StdEncoding.DecodeString(a.Guest.LaunchTemplate.WorkerSmallCloudConfig)
 	if err != nil {
 		t.Errorf("unexpected error decoding SmallCloudConfig %v", err)
 	}
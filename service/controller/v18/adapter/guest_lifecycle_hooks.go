@@ -3,7 +3,7 @@ package adapter
 import "github.com/giantswarm/aws-operator/service/controller/v18/key"
 
 type GuestLifecycleHooksAdapter struct {
-	Worker GuestLifecycleHooksAdapterWorker
+	Workers []GuestLifecycleHooksAdapterWorker
 }
 
 type GuestLifecycleHooksAdapterWorker struct {
@@ -16,12 +16,25 @@ type GuestLifecycleHooksAdapterASG struct {
 }
 
 type GuestLifecycleHooksAdapterLifecycleHook struct {
-	Name string
+	Name         string
+	ResourceName string
 }
 
 func (a *GuestLifecycleHooksAdapter) Adapt(config Config) error {
-	a.Worker.ASG.Ref = key.WorkerASGRef
-	a.Worker.LifecycleHook.Name = key.NodeDrainerLifecycleHookName
+	// Every node pool ASG gets its own lifecycle hook. The hooks share the same
+	// name so that the drainer can complete lifecycle actions of any worker ASG.
+	for _, p := range workerPools(config) {
+		w := GuestLifecycleHooksAdapterWorker{
+			ASG: GuestLifecycleHooksAdapterASG{
+				Ref: nodePoolASGType(config, p.Name) + "AutoScalingGroup",
+			},
+			LifecycleHook: GuestLifecycleHooksAdapterLifecycleHook{
+				Name:         key.NodeDrainerLifecycleHookName,
+				ResourceName: key.NodePoolResourceName(key.NodeDrainerLifecycleHookName, p.Name),
+			},
+		}
+		a.Workers = append(a.Workers, w)
+	}
 
 	return nil
 }
@@ -9,28 +9,45 @@ import (
 )
 
 const (
-	// Default values for health checks.
-	healthCheckHealthyThreshold   = 2
-	healthCheckInterval           = 5
-	healthCheckTimeout            = 3
-	healthCheckUnhealthyThreshold = 2
+	// Values for the health checks of NLB target groups. NLBs only support
+	// intervals of 10 or 30 seconds and require the healthy and unhealthy
+	// thresholds to be equal.
+	nlbHealthCheckInterval  = 10
+	nlbHealthCheckThreshold = 2
 )
 
 type GuestLoadBalancersAdapter struct {
 	APIElbHealthCheckTarget          string
+	APIElbIdleTimeout                int
 	APIElbName                       string
 	APIElbPortsToOpen                []GuestLoadBalancersAdapterPortPair
 	APIElbScheme                     string
 	APIElbSecurityGroupID            string
+	APIElbSubnets                    []string
+	APITargetGroups                  []GuestLoadBalancersAdapterTargetGroup
+	ClassicLoadBalancers             bool
+	ELBAccessLogsBucketName          string
+	ELBAccessLogsEmitInterval        int
+	ELBAccessLogsEnabled             bool
+	ELBConnectionDrainingEnabled     bool
+	ELBConnectionDrainingTimeout     int
+	ELBCrossZone                     bool
 	ELBHealthCheckHealthyThreshold   int
 	ELBHealthCheckInterval           int
 	ELBHealthCheckTimeout            int
 	ELBHealthCheckUnhealthyThreshold int
+	ExistingVPC                      bool
 	IngressElbHealthCheckTarget      string
+	IngressElbIdleTimeout            int
 	IngressElbName                   string
 	IngressElbPortsToOpen            []GuestLoadBalancersAdapterPortPair
 	IngressElbScheme                 string
-	MasterInstanceResourceName       string
+	IngressTargetGroups              []GuestLoadBalancersAdapterTargetGroup
+	MasterInstanceResourceNames      []string
+	NetworkLoadBalancers             bool
+	NLBHealthCheckInterval           int
+	NLBHealthCheckThreshold          int
+	PublicSubnets                    []string
 }
 
 func (a *GuestLoadBalancersAdapter) Adapt(cfg Config) error {
@@ -41,6 +58,7 @@ func (a *GuestLoadBalancersAdapter) Adapt(cfg Config) error {
 	}
 
 	a.APIElbHealthCheckTarget = heathCheckTarget(cfg.CustomObject.Spec.Cluster.Kubernetes.API.SecurePort)
+	a.APIElbIdleTimeout = key.APIELBIdleTimeoutSeconds(cfg.CustomObject)
 	a.APIElbName = apiElbName
 	a.APIElbPortsToOpen = []GuestLoadBalancersAdapterPortPair{
 		{
@@ -49,6 +67,9 @@ func (a *GuestLoadBalancersAdapter) Adapt(cfg Config) error {
 		},
 	}
 	a.APIElbScheme = externalELBScheme
+	if key.IsAPIPrivate(cfg.CustomObject) {
+		a.APIElbScheme = internalELBScheme
+	}
 
 	// Ingress load balancer settings.
 	ingressElbName, err := key.LoadBalancerName(cfg.CustomObject.Spec.Cluster.Kubernetes.IngressController.Domain, cfg.CustomObject)
@@ -57,26 +78,54 @@ func (a *GuestLoadBalancersAdapter) Adapt(cfg Config) error {
 	}
 
 	a.IngressElbHealthCheckTarget = heathCheckTarget(key.IngressControllerSecurePort(cfg.CustomObject))
+	a.IngressElbIdleTimeout = key.IngressELBIdleTimeoutSeconds(cfg.CustomObject)
 	a.IngressElbName = ingressElbName
-	a.IngressElbPortsToOpen = []GuestLoadBalancersAdapterPortPair{
-		{
-			PortELB: httpsPort,
+	a.IngressElbPortsToOpen = ingressPortPairs(cfg)
+	a.IngressElbScheme = externalELBScheme
 
-			PortInstance: key.IngressControllerSecurePort(cfg.CustomObject),
-		},
-		{
-			PortELB:      httpPort,
-			PortInstance: key.IngressControllerInsecurePort(cfg.CustomObject),
-		},
+	// Classic ELBs and NLBs are both rendered while migrating between load
+	// balancer types. NLBs forward traffic to target groups, which the masters
+	// and the worker ASGs are registered with.
+	a.ClassicLoadBalancers = hasLoadBalancerType(cfg, key.LoadBalancerTypeClassic)
+	a.NetworkLoadBalancers = hasLoadBalancerType(cfg, key.LoadBalancerTypeNetwork)
+	if a.NetworkLoadBalancers {
+		a.APITargetGroups = apiTargetGroups(cfg)
+		a.IngressTargetGroups = ingressTargetGroups(cfg)
+		a.NLBHealthCheckInterval = nlbHealthCheckInterval
+		a.NLBHealthCheckThreshold = nlbHealthCheckThreshold
 	}
-	a.IngressElbScheme = externalELBScheme
 
 	// Load balancer health check settings.
-	a.ELBHealthCheckHealthyThreshold = healthCheckHealthyThreshold
-	a.ELBHealthCheckInterval = healthCheckInterval
-	a.ELBHealthCheckTimeout = healthCheckTimeout
-	a.ELBHealthCheckUnhealthyThreshold = healthCheckUnhealthyThreshold
-	a.MasterInstanceResourceName = masterInstanceResourceName(cfg)
+	healthCheck := key.ELBHealthCheck(cfg.CustomObject)
+	a.ELBHealthCheckHealthyThreshold = healthCheck.HealthyThreshold
+	a.ELBHealthCheckInterval = healthCheck.IntervalSeconds
+	a.ELBHealthCheckTimeout = healthCheck.TimeoutSeconds
+	a.ELBHealthCheckUnhealthyThreshold = healthCheck.UnhealthyThreshold
+
+	// Cross-zone balancing, connection draining and access logs of the classic
+	// ELBs. Access logs are written to the logging bucket of the guest cluster,
+	// prefixed with the name of the ELB.
+	a.ELBCrossZone = key.ELBCrossZone(cfg.CustomObject)
+	a.ELBConnectionDrainingEnabled = key.ELBConnectionDrainingEnabled(cfg.CustomObject)
+	a.ELBConnectionDrainingTimeout = key.ELBConnectionDrainingTimeoutSeconds(cfg.CustomObject)
+	a.ELBAccessLogsEnabled = key.ELBAccessLogsEnabled(cfg.CustomObject)
+	a.ELBAccessLogsBucketName = key.TargetLogBucketName(cfg.CustomObject)
+	a.ELBAccessLogsEmitInterval = key.ELBAccessLogsEmitIntervalMinutes(cfg.CustomObject)
+
+	a.ExistingVPC = key.IsExistingVPC(cfg.CustomObject)
+	a.MasterInstanceResourceNames = masterInstanceResourceNames(cfg)
+
+	// The load balancers span the public subnets of all availability zones.
+	// Private API load balancers span the private subnets instead.
+	for i := range key.AvailabilityZones(cfg.CustomObject) {
+		a.PublicSubnets = append(a.PublicSubnets, key.IndexedName("PublicSubnet", i))
+
+		if key.IsAPIPrivate(cfg.CustomObject) {
+			a.APIElbSubnets = append(a.APIElbSubnets, key.IndexedName("PrivateSubnet", i))
+		} else {
+			a.APIElbSubnets = append(a.APIElbSubnets, key.IndexedName("PublicSubnet", i))
+		}
+	}
 
 	return nil
 }
@@ -88,6 +137,56 @@ type GuestLoadBalancersAdapterPortPair struct {
 	PortInstance int
 }
 
+// GuestLoadBalancersAdapterTargetGroup is a target group of an NLB together
+// with the listener forwarding traffic to it.
+type GuestLoadBalancersAdapterTargetGroup struct {
+	ListenerResourceName string
+	PortELB              int
+	PortInstance         int
+	ResourceName         string
+}
+
+func apiTargetGroups(cfg Config) []GuestLoadBalancersAdapterTargetGroup {
+	return []GuestLoadBalancersAdapterTargetGroup{
+		{
+			ListenerResourceName: "ApiListener",
+			PortELB:              key.KubernetesAPISecurePort(cfg.CustomObject),
+			PortInstance:         key.KubernetesAPISecurePort(cfg.CustomObject),
+			ResourceName:         "ApiTargetGroup",
+		},
+	}
+}
+
+func ingressPortPairs(cfg Config) []GuestLoadBalancersAdapterPortPair {
+	return []GuestLoadBalancersAdapterPortPair{
+		{
+			PortELB:      httpsPort,
+			PortInstance: key.IngressControllerSecurePort(cfg.CustomObject),
+		},
+		{
+			PortELB:      httpPort,
+			PortInstance: key.IngressControllerInsecurePort(cfg.CustomObject),
+		},
+	}
+}
+
+// ingressTargetGroups returns a target group per port of the ingress
+// controller. The worker ASGs register their instances with all of them.
+func ingressTargetGroups(cfg Config) []GuestLoadBalancersAdapterTargetGroup {
+	var targetGroups []GuestLoadBalancersAdapterTargetGroup
+	for i, p := range ingressPortPairs(cfg) {
+		tg := GuestLoadBalancersAdapterTargetGroup{
+			ListenerResourceName: key.IndexedName("IngressListener", i),
+			PortELB:              p.PortELB,
+			PortInstance:         p.PortInstance,
+			ResourceName:         key.IndexedName("IngressTargetGroup", i),
+		}
+		targetGroups = append(targetGroups, tg)
+	}
+
+	return targetGroups
+}
+
 func heathCheckTarget(port int) string {
 	return fmt.Sprintf("TCP:%d", port)
 }
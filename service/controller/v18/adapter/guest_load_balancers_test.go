@@ -18,6 +18,7 @@ func TestAdapterLoadBalancersRegularFields(t *testing.T) {
 		expectedAPIElbPortsToOpen                []GuestLoadBalancersAdapterPortPair
 		expectedAPIElbScheme                     string
 		expectedAPIElbSecurityGroupID            string
+		expectedAPIElbSubnets                    []string
 		expectedAPIElbSubnetID                   string
 		expectedELBAZ                            string
 		expectedELBHealthCheckHealthyThreshold   int
@@ -65,6 +66,7 @@ func TestAdapterLoadBalancersRegularFields(t *testing.T) {
 				},
 			},
 			expectedAPIElbScheme:                     "internet-facing",
+			expectedAPIElbSubnets:                    []string{"PublicSubnet"},
 			expectedELBAZ:                            "eu-central-1a",
 			expectedELBHealthCheckHealthyThreshold:   2,
 			expectedELBHealthCheckInterval:           5,
@@ -83,6 +85,118 @@ func TestAdapterLoadBalancersRegularFields(t *testing.T) {
 			},
 			expectedIngressElbScheme: "internet-facing",
 		},
+		{
+			description: "private API",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								Domain:     "api.test-cluster.aws.giantswarm.io",
+								SecurePort: 443,
+							},
+							IngressController: v1alpha1.ClusterKubernetesIngressController{
+								Domain:       "ingress.test-cluster.aws.giantswarm.io",
+								InsecurePort: 30010,
+								SecurePort:   30011,
+							},
+						},
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Private: true,
+						},
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+					},
+				},
+			},
+			errorMatcher:       nil,
+			expectedAPIElbName: "test-cluster-api",
+			expectedAPIElbPortsToOpen: []GuestLoadBalancersAdapterPortPair{
+				{
+					PortELB:      443,
+					PortInstance: 443,
+				},
+			},
+			expectedAPIElbScheme:                     "internal",
+			expectedAPIElbSubnets:                    []string{"PrivateSubnet", "PrivateSubnet01"},
+			expectedELBHealthCheckHealthyThreshold:   2,
+			expectedELBHealthCheckInterval:           5,
+			expectedELBHealthCheckTimeout:            3,
+			expectedELBHealthCheckUnhealthyThreshold: 2,
+			expectedIngressElbName:                   "test-cluster-ingress",
+			expectedIngressElbPortsToOpen: []GuestLoadBalancersAdapterPortPair{
+				{
+					PortELB:      443,
+					PortInstance: 30011,
+				},
+				{
+					PortELB:      80,
+					PortInstance: 30010,
+				},
+			},
+			expectedIngressElbScheme: "internet-facing",
+		},
+		{
+			description: "custom health check",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								Domain:     "api.test-cluster.aws.giantswarm.io",
+								SecurePort: 443,
+							},
+							IngressController: v1alpha1.ClusterKubernetesIngressController{
+								Domain:       "ingress.test-cluster.aws.giantswarm.io",
+								InsecurePort: 30010,
+								SecurePort:   30011,
+							},
+						},
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AZ: "eu-central-1a",
+						ELB: v1alpha1.AWSConfigSpecAWSELB{
+							HealthCheck: v1alpha1.AWSConfigSpecAWSELBHealthCheck{
+								HealthyThreshold: 3,
+								IntervalSeconds:  10,
+							},
+						},
+					},
+				},
+			},
+			errorMatcher:       nil,
+			expectedAPIElbName: "test-cluster-api",
+			expectedAPIElbPortsToOpen: []GuestLoadBalancersAdapterPortPair{
+				{
+					PortELB:      443,
+					PortInstance: 443,
+				},
+			},
+			expectedAPIElbScheme:                     "internet-facing",
+			expectedAPIElbSubnets:                    []string{"PublicSubnet"},
+			expectedELBHealthCheckHealthyThreshold:   3,
+			expectedELBHealthCheckInterval:           10,
+			expectedELBHealthCheckTimeout:            3,
+			expectedELBHealthCheckUnhealthyThreshold: 2,
+			expectedIngressElbName:                   "test-cluster-ingress",
+			expectedIngressElbPortsToOpen: []GuestLoadBalancersAdapterPortPair{
+				{
+					PortELB:      443,
+					PortInstance: 30011,
+				},
+				{
+					PortELB:      80,
+					PortInstance: 30010,
+				},
+			},
+			expectedIngressElbScheme: "internet-facing",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -120,6 +234,10 @@ func TestAdapterLoadBalancersRegularFields(t *testing.T) {
 				t.Errorf("expected API ELB Scheme, got %q, want %q", a.Guest.LoadBalancers.APIElbScheme, tc.expectedAPIElbScheme)
 			}
 
+			if !reflect.DeepEqual(tc.expectedAPIElbSubnets, a.Guest.LoadBalancers.APIElbSubnets) {
+				t.Errorf("expected API ELB Subnets, got %q, want %q", a.Guest.LoadBalancers.APIElbSubnets, tc.expectedAPIElbSubnets)
+			}
+
 			if tc.expectedELBHealthCheckHealthyThreshold != a.Guest.LoadBalancers.ELBHealthCheckHealthyThreshold {
 				t.Errorf("expected ELB health check healthy threshold, got %q, want %q", a.Guest.LoadBalancers.ELBHealthCheckHealthyThreshold, tc.expectedELBHealthCheckHealthyThreshold)
 			}
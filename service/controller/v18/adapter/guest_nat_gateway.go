@@ -1,11 +1,48 @@
 package adapter
 
+import (
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
 type GuestNATGatewayAdapter struct {
 	ClusterID string
+	Gateways  []GuestNATGatewayAdapterGateway
+}
+
+type GuestNATGatewayAdapterGateway struct {
+	EIPName               string
+	Name                  string
+	PrivateRouteTableName string
+	PublicSubnetName      string
+	RouteName             string
 }
 
 func (a *GuestNATGatewayAdapter) Adapt(cfg Config) error {
 	a.ClusterID = clusterID(cfg)
 
+	// Existing VPCs come with their own egress, which is why their private
+	// subnets do not get any NAT gateway.
+	if key.IsExistingVPC(cfg.CustomObject) {
+		return nil
+	}
+
+	// Every availability zone gets its own NAT gateway in its public subnet, so
+	// that losing one availability zone does not cut the remaining private
+	// subnets off the internet.
+	for i := range key.AvailabilityZones(cfg.CustomObject) {
+		g := GuestNATGatewayAdapterGateway{
+			EIPName:               natEIPName(i),
+			Name:                  key.IndexedName("NATGateway", i),
+			PrivateRouteTableName: key.IndexedName("PrivateRouteTable", i),
+			PublicSubnetName:      key.IndexedName("PublicSubnet", i),
+			RouteName:             key.IndexedName("NATRoute", i),
+		}
+		a.Gateways = append(a.Gateways, g)
+	}
+
 	return nil
 }
+
+func natEIPName(index int) string {
+	return key.IndexedName("NATEIP", index)
+}
@@ -2,25 +2,103 @@ package adapter
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
 type GuestOutputsAdapter struct {
 	Master         GuestOutputsAdapterMaster
+	Masters        GuestOutputsAdapterMasters
 	Worker         GuestOutputsAdapterWorker
+	WorkerPools    GuestOutputsAdapterWorkerPools
 	Route53Enabled bool
 	VersionBundle  GuestOutputsAdapterVersionBundle
+
+	// ImageCatalog holds the AMI resolved from the AMI catalog and the catalog
+	// configuration it was resolved from, in order to reuse the AMI as long as
+	// neither the catalog configuration nor the version bundle version change.
+	ImageCatalog GuestOutputsAdapterImageCatalog
+
+	// LoadBalancerTypes holds the comma separated types of the load balancers
+	// of the guest cluster, which is necessary to migrate between load balancer
+	// types.
+	LoadBalancerTypes string
+	// APIPrivate is true when the Kubernetes API load balancer is internal,
+	// which is necessary to reject switching between private and public APIs.
+	APIPrivate bool
+	// LoadBalancerHash identifies the settings of the classic ELBs in order to
+	// detect changes to them.
+	LoadBalancerHash string
+	// LoadBalancerSwitchTime is the RFC 3339 time the DNS records switched to
+	// the load balancers of the first type, which is necessary to keep the load
+	// balancers of a second type until the TTL of the DNS records passed.
+	LoadBalancerSwitchTime string
+	// PrivateHostedZone is true when the guest cluster domain has a private
+	// hosted zone, whose ID is required to associate it with the host cluster
+	// VPC.
+	PrivateHostedZone bool
+	// SecurityGroupsHash identifies the API whitelist and the additional
+	// security group rules of the custom object in order to detect changes to
+	// them.
+	SecurityGroupsHash string
+	// TransitGateway is true when the guest cluster VPC is attached to the
+	// Transit Gateway, whose attachment ID is required by the host cluster post
+	// stack.
+	TransitGateway bool
+	// VPCConnectionsHash identifies the VPC peerings and the virtual private
+	// gateway of the custom object in order to detect changes to them.
+	VPCConnectionsHash string
+	// VPCEndpoints is true when the guest cluster VPC has endpoints for the AWS
+	// APIs used by the guest cluster nodes.
+	VPCEndpoints bool
+	// VPCFlowLogsTrafficType is the type of traffic captured by the VPC flow
+	// logs, which is necessary to detect changes to it.
+	VPCFlowLogsTrafficType string
 }
 
 func (a *GuestOutputsAdapter) Adapt(config Config) error {
+	a.APIPrivate = key.IsAPIPrivate(config.CustomObject)
+	a.ImageCatalog.DefaultImageID = config.StackState.DefaultImageID
+	a.ImageCatalog.Source = config.StackState.ImageCatalogSource
+	a.LoadBalancerHash = config.StackState.LoadBalancerHash
+	if !config.StackState.LoadBalancerSwitchTime.IsZero() {
+		a.LoadBalancerSwitchTime = config.StackState.LoadBalancerSwitchTime.UTC().Format(time.RFC3339)
+	}
+	a.LoadBalancerTypes = strings.Join(loadBalancerTypes(config), ",")
+	a.SecurityGroupsHash = config.StackState.SecurityGroupsHash
+	a.PrivateHostedZone = route53Enabled(config) && key.PrivateHostedZoneEnabled(config.CustomObject)
 	a.Route53Enabled = route53Enabled(config)
+	a.TransitGateway = transitGatewayID(config) != ""
+	a.VPCConnectionsHash = config.StackState.VPCConnectionsHash
+	a.VPCEndpoints = key.VPCEndpointsEnabled(config.CustomObject)
+	a.VPCFlowLogsTrafficType = key.VPCFlowLogsTrafficType(config.CustomObject)
 	a.Master.DockerVolume.ResourceName = config.StackState.DockerVolumeResourceName
 	a.Master.ImageID = config.StackState.MasterImageID
 	a.Master.Instance.ResourceName = config.StackState.MasterInstanceResourceName
 	a.Master.Instance.Type = config.StackState.MasterInstanceType
 	a.Master.CloudConfig.Version = config.StackState.MasterCloudConfigVersion
 
+	{
+		var cloudConfigVersions, dockerVolumeResourceNames, imageIDs, instanceResourceNames, instanceTypes, versionBundleVersions []string
+		for _, m := range masters(config) {
+			cloudConfigVersions = append(cloudConfigVersions, m.CloudConfigVersion)
+			dockerVolumeResourceNames = append(dockerVolumeResourceNames, m.DockerVolumeResourceName)
+			imageIDs = append(imageIDs, m.ImageID)
+			instanceResourceNames = append(instanceResourceNames, m.InstanceResourceName)
+			instanceTypes = append(instanceTypes, m.InstanceType)
+			versionBundleVersions = append(versionBundleVersions, m.VersionBundleVersion)
+		}
+
+		a.Masters.CloudConfigVersions = strings.Join(cloudConfigVersions, ",")
+		a.Masters.DockerVolumeResourceNames = strings.Join(dockerVolumeResourceNames, ",")
+		a.Masters.ImageIDs = strings.Join(imageIDs, ",")
+		a.Masters.InstanceResourceNames = strings.Join(instanceResourceNames, ",")
+		a.Masters.InstanceTypes = strings.Join(instanceTypes, ",")
+		a.Masters.VersionBundleVersions = strings.Join(versionBundleVersions, ",")
+	}
+
 	a.Worker.ASG.Key = key.WorkerASGKey
 	a.Worker.ASG.Ref = key.WorkerASGRef
 	a.Worker.Count = config.StackState.WorkerCount
@@ -29,11 +107,51 @@ func (a *GuestOutputsAdapter) Adapt(config Config) error {
 	a.Worker.InstanceType = config.StackState.WorkerInstanceType
 	a.Worker.CloudConfig.Version = config.StackState.WorkerCloudConfigVersion
 
+	{
+		var counts, dockerVolumeSizesGB, instanceTypes, launchTemplateHashes, names, scalingMaxes, scalingMins, spotEnabled, spotInstanceTypes, spotMaxPrices, spotOnDemandBases, spotOnDemandPercents []string
+		for _, p := range workerPools(config) {
+			a.WorkerPools.ASGRefs = append(a.WorkerPools.ASGRefs, nodePoolASGType(config, p.Name)+"AutoScalingGroup")
+			counts = append(counts, strconv.Itoa(p.Count))
+			dockerVolumeSizesGB = append(dockerVolumeSizesGB, strconv.Itoa(p.DockerVolumeSizeGB))
+			instanceTypes = append(instanceTypes, p.InstanceType)
+			launchTemplateHashes = append(launchTemplateHashes, p.LaunchTemplateHash)
+			names = append(names, p.Name)
+			scalingMaxes = append(scalingMaxes, strconv.Itoa(p.Scaling.Max))
+			scalingMins = append(scalingMins, strconv.Itoa(p.Scaling.Min))
+			spotEnabled = append(spotEnabled, strconv.FormatBool(p.Spot.Enabled))
+			// The instance types of a single node pool are separated by spaces,
+			// since commas separate the node pools.
+			spotInstanceTypes = append(spotInstanceTypes, strings.Join(p.Spot.InstanceTypes, " "))
+			spotMaxPrices = append(spotMaxPrices, p.Spot.MaxPrice)
+			spotOnDemandBases = append(spotOnDemandBases, strconv.Itoa(p.Spot.OnDemandBaseCapacity))
+			spotOnDemandPercents = append(spotOnDemandPercents, strconv.Itoa(p.Spot.OnDemandPercentageAboveBaseCapacity))
+		}
+
+		a.WorkerPools.ASGNamesKey = key.WorkerASGNamesKey
+		a.WorkerPools.Counts = strings.Join(counts, ",")
+		a.WorkerPools.DockerVolumeSizesGB = strings.Join(dockerVolumeSizesGB, ",")
+		a.WorkerPools.InstanceTypes = strings.Join(instanceTypes, ",")
+		a.WorkerPools.LaunchTemplateHashes = strings.Join(launchTemplateHashes, ",")
+		a.WorkerPools.Names = strings.Join(names, ",")
+		a.WorkerPools.ScalingMaxes = strings.Join(scalingMaxes, ",")
+		a.WorkerPools.ScalingMins = strings.Join(scalingMins, ",")
+		a.WorkerPools.SpotEnabled = strings.Join(spotEnabled, ",")
+		a.WorkerPools.SpotInstanceTypes = strings.Join(spotInstanceTypes, ",")
+		a.WorkerPools.SpotMaxPrices = strings.Join(spotMaxPrices, ",")
+		a.WorkerPools.SpotOnDemandBaseCapacities = strings.Join(spotOnDemandBases, ",")
+		a.WorkerPools.SpotOnDemandPercentagesAboveBaseCapacity = strings.Join(spotOnDemandPercents, ",")
+	}
+
 	a.VersionBundle.Version = config.StackState.VersionBundleVersion
 
 	return nil
 }
 
+type GuestOutputsAdapterImageCatalog struct {
+	DefaultImageID string
+	Source         string
+}
+
 type GuestOutputsAdapterMaster struct {
 	ImageID      string
 	Instance     GuestOutputsAdapterMasterInstance
@@ -41,6 +159,17 @@ type GuestOutputsAdapterMaster struct {
 	DockerVolume GuestOutputsAdapterMasterDockerVolume
 }
 
+// GuestOutputsAdapterMasters holds the comma separated state of every single
+// master instance, which is necessary to replace masters one at a time.
+type GuestOutputsAdapterMasters struct {
+	CloudConfigVersions       string
+	DockerVolumeResourceNames string
+	ImageIDs                  string
+	InstanceResourceNames     string
+	InstanceTypes             string
+	VersionBundleVersions     string
+}
+
 type GuestOutputsAdapterMasterInstance struct {
 	ResourceName string
 	Type         string
@@ -72,6 +201,26 @@ type GuestOutputsAdapterWorkerCloudConfig struct {
 	Version string
 }
 
+// GuestOutputsAdapterWorkerPools holds the comma separated state of every
+// single node pool. The ASG names of all node pools are used by the drainer.
+// SpotMaxPrices is empty in case no node pool defines a spot max price.
+type GuestOutputsAdapterWorkerPools struct {
+	ASGNamesKey                              string
+	ASGRefs                                  []string
+	Counts                                   string
+	DockerVolumeSizesGB                      string
+	InstanceTypes                            string
+	LaunchTemplateHashes                     string
+	Names                                    string
+	ScalingMaxes                             string
+	ScalingMins                              string
+	SpotEnabled                              string
+	SpotInstanceTypes                        string
+	SpotMaxPrices                            string
+	SpotOnDemandBaseCapacities               string
+	SpotOnDemandPercentagesAboveBaseCapacity string
+}
+
 type GuestOutputsAdapterVersionBundle struct {
 	Version string
 }
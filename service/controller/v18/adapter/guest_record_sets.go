@@ -1,17 +1,92 @@
 package adapter
 
+import (
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
 type GuestRecordSetsAdapter struct {
-	BaseDomain                 string
-	ClusterID                  string
+	// APILoadBalancer and IngressLoadBalancer are the load balancers the DNS
+	// records of the Kubernetes API and the ingress controller point to.
+	APILoadBalancer             GuestRecordSetsAdapterLoadBalancer
+	BaseDomain                  string
+	ClusterID                   string
+	EtcdMembers                 []GuestRecordSetsAdapterEtcdMember
+	IngressLoadBalancer         GuestRecordSetsAdapterLoadBalancer
+	MasterInstanceResourceNames []string
+	// PrivateHostedZone is true when the records of the guest cluster domain
+	// are also managed in a private hosted zone associated with the guest
+	// cluster VPC. The private hosted zone shadows the public one within the
+	// VPC, so it contains all records, while the public hosted zone only keeps
+	// the records which must be resolvable publicly.
+	PrivateHostedZone bool
+	// PublicAPIRecordSet is false when the Kubernetes API is private and its
+	// record is only published in the private hosted zone.
+	PublicAPIRecordSet bool
+	// PublicEtcdRecordSets is false when the etcd records are only published in
+	// the private hosted zone.
+	PublicEtcdRecordSets bool
+	Route53Enabled       bool
+}
+
+// GuestRecordSetsAdapterLoadBalancer is the target of an alias record. Classic
+// ELBs and NLBs expose their hosted zone ID by means of different attributes.
+type GuestRecordSetsAdapterLoadBalancer struct {
+	HostedZoneIDAttribute string
+	ResourceName          string
+}
+
+// GuestRecordSetsAdapterEtcdMember is the DNS record of a single etcd member,
+// which the etcd members of guest clusters with multiple masters use to find
+// their peers.
+type GuestRecordSetsAdapterEtcdMember struct {
 	MasterInstanceResourceName string
-	Route53Enabled             bool
+	Name                       string
+	ResourceName               string
 }
 
 func (a *GuestRecordSetsAdapter) Adapt(config Config) error {
+	// The DNS records point to the load balancers of the first load balancer
+	// type, which is the desired one while migrating between load balancer
+	// types.
+	if loadBalancerTypes(config)[0] == key.LoadBalancerTypeNetwork {
+		a.APILoadBalancer = GuestRecordSetsAdapterLoadBalancer{
+			HostedZoneIDAttribute: "CanonicalHostedZoneID",
+			ResourceName:          "ApiNetworkLoadBalancer",
+		}
+		a.IngressLoadBalancer = GuestRecordSetsAdapterLoadBalancer{
+			HostedZoneIDAttribute: "CanonicalHostedZoneID",
+			ResourceName:          "IngressNetworkLoadBalancer",
+		}
+	} else {
+		a.APILoadBalancer = GuestRecordSetsAdapterLoadBalancer{
+			HostedZoneIDAttribute: "CanonicalHostedZoneNameID",
+			ResourceName:          "ApiLoadBalancer",
+		}
+		a.IngressLoadBalancer = GuestRecordSetsAdapterLoadBalancer{
+			HostedZoneIDAttribute: "CanonicalHostedZoneNameID",
+			ResourceName:          "IngressLoadBalancer",
+		}
+	}
 	a.BaseDomain = baseDomain(config)
 	a.ClusterID = clusterID(config)
-	a.MasterInstanceResourceName = masterInstanceResourceName(config)
+	a.MasterInstanceResourceNames = masterInstanceResourceNames(config)
 	a.Route53Enabled = route53Enabled(config)
 
+	a.PrivateHostedZone = a.Route53Enabled && key.PrivateHostedZoneEnabled(config.CustomObject)
+	a.PublicAPIRecordSet = !a.PrivateHostedZone || !key.IsAPIPrivate(config.CustomObject)
+	a.PublicEtcdRecordSets = !a.PrivateHostedZone
+
+	if len(a.MasterInstanceResourceNames) > 1 {
+		for i, n := range a.MasterInstanceResourceNames {
+			m := GuestRecordSetsAdapterEtcdMember{
+				MasterInstanceResourceName: n,
+				Name:                       key.EtcdMemberName(i),
+				ResourceName:               key.IndexedName("EtcdMemberRecordSet", i),
+			}
+
+			a.EtcdMembers = append(a.EtcdMembers, m)
+		}
+	}
+
 	return nil
 }
@@ -77,3 +77,97 @@ func TestAdapterRecordSetsRegularFields(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterRecordSetsPrivateHostedZone(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description                  string
+		apiPrivate                   bool
+		privateHostedZone            v1alpha1.AWSConfigSpecAWSHostedZonesPrivate
+		route53Enabled               bool
+		expectedPrivateHostedZone    bool
+		expectedPublicAPIRecordSet   bool
+		expectedPublicEtcdRecordSets bool
+	}{
+		{
+			description:                  "private hosted zone disabled",
+			apiPrivate:                   true,
+			privateHostedZone:            v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{},
+			route53Enabled:               true,
+			expectedPrivateHostedZone:    false,
+			expectedPublicAPIRecordSet:   true,
+			expectedPublicEtcdRecordSets: true,
+		},
+		{
+			description:                  "private hosted zone enabled with public API",
+			apiPrivate:                   false,
+			privateHostedZone:            v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true},
+			route53Enabled:               true,
+			expectedPrivateHostedZone:    true,
+			expectedPublicAPIRecordSet:   true,
+			expectedPublicEtcdRecordSets: false,
+		},
+		{
+			description:                  "private hosted zone enabled with private API",
+			apiPrivate:                   true,
+			privateHostedZone:            v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true, HostVPC: true},
+			route53Enabled:               true,
+			expectedPrivateHostedZone:    true,
+			expectedPublicAPIRecordSet:   false,
+			expectedPublicEtcdRecordSets: false,
+		},
+		{
+			description:                  "private hosted zone enabled without route53",
+			apiPrivate:                   true,
+			privateHostedZone:            v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true},
+			route53Enabled:               false,
+			expectedPrivateHostedZone:    false,
+			expectedPublicAPIRecordSet:   true,
+			expectedPublicEtcdRecordSets: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Private: tc.apiPrivate,
+						},
+						HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+							API: v1alpha1.AWSConfigSpecAWSHostedZonesZone{
+								Name: "installation.aws.eu-central-1.gigantic.io",
+							},
+							Private: tc.privateHostedZone,
+						},
+					},
+				},
+			}
+
+			cfg := Config{
+				CustomObject:   customObject,
+				Clients:        Clients{},
+				Route53Enabled: tc.route53Enabled,
+			}
+			err := a.Guest.RecordSets.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if a.Guest.RecordSets.PrivateHostedZone != tc.expectedPrivateHostedZone {
+				t.Fatalf("PrivateHostedZone == %v, want %v", a.Guest.RecordSets.PrivateHostedZone, tc.expectedPrivateHostedZone)
+			}
+			if a.Guest.RecordSets.PublicAPIRecordSet != tc.expectedPublicAPIRecordSet {
+				t.Fatalf("PublicAPIRecordSet == %v, want %v", a.Guest.RecordSets.PublicAPIRecordSet, tc.expectedPublicAPIRecordSet)
+			}
+			if a.Guest.RecordSets.PublicEtcdRecordSets != tc.expectedPublicEtcdRecordSets {
+				t.Fatalf("PublicEtcdRecordSets == %v, want %v", a.Guest.RecordSets.PublicEtcdRecordSets, tc.expectedPublicEtcdRecordSets)
+			}
+		})
+	}
+}
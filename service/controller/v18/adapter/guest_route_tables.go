@@ -1,15 +1,32 @@
 package adapter
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
 type GuestRouteTablesAdapter struct {
-	HostClusterCIDR       string
-	PublicRouteTableName  string
-	PrivateRouteTableName string
+	// ExistingVPC is true when the guest cluster is deployed into an existing
+	// VPC, whose route tables are not managed by the guest cluster stack.
+	ExistingVPC          bool
+	HostClusterCIDR      string
+	PublicRouteTableName string
+	PrivateRouteTables   []GuestRouteTablesAdapterRouteTable
+	// TransitGatewayID is set when the routes to the host cluster go through the
+	// Transit Gateway instead of the VPC peering connection.
+	TransitGatewayID string
+}
+
+type GuestRouteTablesAdapterRouteTable struct {
+	Name         string
+	ResourceName string
+	// RouteTableID is the ID of an existing route table of an existing VPC. The
+	// route table is not rendered when it is set, only the peering route.
+	RouteTableID        string
+	VPCPeeringRouteName string
 }
 
 func (r *GuestRouteTablesAdapter) Adapt(cfg Config) error {
@@ -19,8 +36,109 @@ func (r *GuestRouteTablesAdapter) Adapt(cfg Config) error {
 	}
 
 	r.HostClusterCIDR = hostClusterCIDR
+	r.TransitGatewayID = transitGatewayID(cfg)
+
+	if key.IsExistingVPC(cfg.CustomObject) {
+		r.ExistingVPC = true
+
+		routeTableIDs, err := existingPrivateRouteTableIDs(cfg)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, id := range routeTableIDs {
+			rt := GuestRouteTablesAdapterRouteTable{
+				RouteTableID:        id,
+				VPCPeeringRouteName: key.IndexedName("VPCPeeringRoute", len(r.PrivateRouteTables)),
+			}
+			r.PrivateRouteTables = append(r.PrivateRouteTables, rt)
+		}
+
+		return nil
+	}
+
 	r.PublicRouteTableName = key.RouteTableName(cfg.CustomObject, suffixPublic)
-	r.PrivateRouteTableName = key.RouteTableName(cfg.CustomObject, suffixPrivate)
+
+	for i := range key.AvailabilityZones(cfg.CustomObject) {
+		rt := GuestRouteTablesAdapterRouteTable{
+			Name:                key.RouteTableName(cfg.CustomObject, key.IndexedName(suffixPrivate, i)),
+			ResourceName:        key.IndexedName("PrivateRouteTable", i),
+			VPCPeeringRouteName: key.IndexedName("VPCPeeringRoute", i),
+		}
+		r.PrivateRouteTables = append(r.PrivateRouteTables, rt)
+	}
 
 	return nil
 }
+
+// existingPrivateRouteTableIDs returns the IDs of the route tables of the
+// private subnets of an existing VPC. Private subnets of existing VPCs may share
+// route tables, which is why every route table ID is only returned once.
+func existingPrivateRouteTableIDs(cfg Config) ([]string, error) {
+	var ids []string
+	seen := map[string]bool{}
+	for _, subnetID := range key.PrivateSubnetIDs(cfg.CustomObject) {
+		routeTable, err := SubnetRouteTable(cfg.Clients, key.VPCID(cfg.CustomObject), subnetID)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		id := *routeTable.RouteTableId
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// SubnetRouteTable returns the route table of the given subnet of the given
+// VPC. Subnets not explicitly associated with any route table use the main
+// route table of their VPC.
+func SubnetRouteTable(clients Clients, vpcID, subnetID string) (*ec2.RouteTable, error) {
+	filters := [][]*ec2.Filter{
+		{
+			{
+				Name: aws.String("association.subnet-id"),
+				Values: []*string{
+					aws.String(subnetID),
+				},
+			},
+		},
+		{
+			{
+				Name: aws.String("association.main"),
+				Values: []*string{
+					aws.String("true"),
+				},
+			},
+			{
+				Name: aws.String("vpc-id"),
+				Values: []*string{
+					aws.String(vpcID),
+				},
+			},
+		},
+	}
+
+	for _, f := range filters {
+		input := &ec2.DescribeRouteTablesInput{
+			Filters: f,
+		}
+		output, err := clients.EC2.DescribeRouteTables(input)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if len(output.RouteTables) > 1 {
+			return nil, microerror.Maskf(tooManyResultsError, "route tables of subnet: %s", subnetID)
+		}
+		if len(output.RouteTables) == 1 {
+			return output.RouteTables[0], nil
+		}
+	}
+
+	return nil, microerror.Maskf(notFoundError, "route table of subnet: %s", subnetID)
+}
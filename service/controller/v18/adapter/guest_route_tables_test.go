@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
@@ -9,12 +10,13 @@ import (
 func TestAdapterRouteTablesRegularFields(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
-		description                   string
-		customObject                  v1alpha1.AWSConfig
-		expectedError                 bool
-		expectedHostClusterCIDR       string
-		expectedPublicRouteTableName  string
-		expectedPrivateRouteTableName string
+		description                  string
+		customObject                 v1alpha1.AWSConfig
+		expectedError                bool
+		expectedExistingVPC          bool
+		expectedHostClusterCIDR      string
+		expectedPublicRouteTableName string
+		expectedPrivateRouteTables   []GuestRouteTablesAdapterRouteTable
 	}{
 		{
 			description: "basic matching, all fields present",
@@ -25,10 +27,83 @@ func TestAdapterRouteTablesRegularFields(t *testing.T) {
 					},
 				},
 			},
-			expectedError:                 false,
-			expectedHostClusterCIDR:       "10.0.0.0/16",
-			expectedPublicRouteTableName:  "test-cluster-public",
-			expectedPrivateRouteTableName: "test-cluster-private",
+			expectedError:                false,
+			expectedHostClusterCIDR:      "10.0.0.0/16",
+			expectedPublicRouteTableName: "test-cluster-public",
+			expectedPrivateRouteTables: []GuestRouteTablesAdapterRouteTable{
+				{
+					Name:                "test-cluster-private",
+					ResourceName:        "PrivateRouteTable",
+					VPCPeeringRouteName: "VPCPeeringRoute",
+				},
+			},
+		},
+		{
+			description: "multiple availability zones, one private route table per availability zone",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+							"eu-central-1c",
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+				},
+			},
+			expectedError:                false,
+			expectedHostClusterCIDR:      "10.0.0.0/16",
+			expectedPublicRouteTableName: "test-cluster-public",
+			expectedPrivateRouteTables: []GuestRouteTablesAdapterRouteTable{
+				{
+					Name:                "test-cluster-private",
+					ResourceName:        "PrivateRouteTable",
+					VPCPeeringRouteName: "VPCPeeringRoute",
+				},
+				{
+					Name:                "test-cluster-private01",
+					ResourceName:        "PrivateRouteTable01",
+					VPCPeeringRouteName: "VPCPeeringRoute01",
+				},
+				{
+					Name:                "test-cluster-private02",
+					ResourceName:        "PrivateRouteTable02",
+					VPCPeeringRouteName: "VPCPeeringRoute02",
+				},
+			},
+		},
+		{
+			description: "existing VPC, one peering route per distinct existing route table",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							ID:               "vpc-1234",
+							PrivateSubnetIDs: []string{"subnet-1", "subnet-2"},
+							PublicSubnetIDs:  []string{"subnet-3", "subnet-4"},
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+				},
+			},
+			expectedError:           false,
+			expectedExistingVPC:     true,
+			expectedHostClusterCIDR: "10.0.0.0/16",
+			expectedPrivateRouteTables: []GuestRouteTablesAdapterRouteTable{
+				{
+					RouteTableID:        "rtb-1234_0",
+					VPCPeeringRouteName: "VPCPeeringRoute",
+				},
+			},
 		},
 	}
 
@@ -40,12 +115,20 @@ func TestAdapterRouteTablesRegularFields(t *testing.T) {
 			STS: &STSClientMock{},
 		}
 
+		// Existing private subnets all share the same existing route table.
+		clients := Clients{
+			EC2: &EC2ClientMock{
+				matchingRouteTables: 1,
+				routeTableID:        "rtb-1234",
+			},
+		}
+
 		a := Adapter{}
 
 		t.Run(tc.description, func(t *testing.T) {
 			cfg := Config{
 				CustomObject: tc.customObject,
-				Clients:      Clients{},
+				Clients:      clients,
 				HostClients:  hostClients,
 			}
 			err := a.Guest.RouteTables.Adapt(cfg)
@@ -57,16 +140,20 @@ func TestAdapterRouteTablesRegularFields(t *testing.T) {
 				t.Errorf("unexpected error %v", err)
 			}
 
+			if a.Guest.RouteTables.ExistingVPC != tc.expectedExistingVPC {
+				t.Errorf("unexpected ExistingVPC, got %t, want %t", a.Guest.RouteTables.ExistingVPC, tc.expectedExistingVPC)
+			}
+
 			if a.Guest.RouteTables.HostClusterCIDR != tc.expectedHostClusterCIDR {
 				t.Errorf("unexpected HostClusterCIDR, got %q, want %q", a.Guest.RouteTables.HostClusterCIDR, tc.expectedHostClusterCIDR)
 			}
 
 			if a.Guest.RouteTables.PublicRouteTableName != tc.expectedPublicRouteTableName {
-				t.Errorf("unexpected PublicRouteTableName, got %q, want %q", a.Guest.RouteTables.PublicRouteTableName, tc.expectedPrivateRouteTableName)
+				t.Errorf("unexpected PublicRouteTableName, got %q, want %q", a.Guest.RouteTables.PublicRouteTableName, tc.expectedPublicRouteTableName)
 			}
 
-			if a.Guest.RouteTables.PrivateRouteTableName != tc.expectedPrivateRouteTableName {
-				t.Errorf("unexpected PrivateRouteTableName, got %q, want %q", a.Guest.RouteTables.PrivateRouteTableName, tc.expectedPrivateRouteTableName)
+			if !reflect.DeepEqual(a.Guest.RouteTables.PrivateRouteTables, tc.expectedPrivateRouteTables) {
+				t.Errorf("unexpected PrivateRouteTables, got %#v, want %#v", a.Guest.RouteTables.PrivateRouteTables, tc.expectedPrivateRouteTables)
 			}
 		})
 	}
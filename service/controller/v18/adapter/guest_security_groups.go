@@ -33,6 +33,7 @@ type GuestSecurityGroupsAdapter struct {
 	APIWhitelistEnabled       bool
 	MasterSecurityGroupName   string
 	MasterSecurityGroupRules  []securityGroupRule
+	NATGatewayEIPNames        []string
 	WorkerSecurityGroupName   string
 	WorkerSecurityGroupRules  []securityGroupRule
 	IngressSecurityGroupName  string
@@ -50,13 +51,21 @@ func (s *GuestSecurityGroupsAdapter) Adapt(cfg Config) error {
 		return microerror.Mask(err)
 	}
 
-	s.APIWhitelistEnabled = cfg.APIWhitelist.Enabled
+	s.APIWhitelistEnabled = apiWhitelistEnabled(cfg)
 
 	s.MasterSecurityGroupName = key.SecurityGroupName(cfg.CustomObject, prefixMaster)
 	s.MasterSecurityGroupRules = masterRules
 
+	// Existing VPCs do not get any NAT gateway of the guest cluster stack. The
+	// egress IPs of existing VPCs have to be whitelisted explicitly.
+	if !key.IsExistingVPC(cfg.CustomObject) {
+		for i := range key.AvailabilityZones(cfg.CustomObject) {
+			s.NATGatewayEIPNames = append(s.NATGatewayEIPNames, natEIPName(i))
+		}
+	}
+
 	s.WorkerSecurityGroupName = key.SecurityGroupName(cfg.CustomObject, prefixWorker)
-	s.WorkerSecurityGroupRules = s.getWorkerRules(cfg.CustomObject, hostClusterCIDR)
+	s.WorkerSecurityGroupRules = s.getWorkerRules(cfg, hostClusterCIDR)
 
 	s.IngressSecurityGroupName = key.SecurityGroupName(cfg.CustomObject, prefixIngress)
 	s.IngressSecurityGroupRules = s.getIngressRules(cfg.CustomObject)
@@ -111,11 +120,17 @@ func (s *GuestSecurityGroupsAdapter) getMasterRules(cfg Config, hostClusterCIDR
 			SourceCIDR:  hostClusterCIDR,
 		},
 	}
-	return append(apiRules, otherRules...), nil
+
+	rules := append(apiRules, otherRules...)
+	rules = append(rules, customSecurityGroupRules(key.MasterSecurityGroupRules(cfg.CustomObject))...)
+
+	return rules, nil
 }
 
-func (s *GuestSecurityGroupsAdapter) getWorkerRules(customObject v1alpha1.AWSConfig, hostClusterCIDR string) []securityGroupRule {
-	return []securityGroupRule{
+func (s *GuestSecurityGroupsAdapter) getWorkerRules(cfg Config, hostClusterCIDR string) []securityGroupRule {
+	customObject := cfg.CustomObject
+
+	rules := []securityGroupRule{
 		{
 			Description:         "Allow traffic from the ingress security group to the ingress controller port 443.",
 			Port:                key.IngressControllerSecurePort(customObject),
@@ -165,10 +180,34 @@ func (s *GuestSecurityGroupsAdapter) getWorkerRules(customObject v1alpha1.AWSCon
 			SourceCIDR:  hostClusterCIDR,
 		},
 	}
+
+	// NLBs do not have security groups and preserve the source IPs of their
+	// clients, so the ingress controller ports have to be open to all traffic.
+	if hasLoadBalancerType(cfg, key.LoadBalancerTypeNetwork) {
+		nlbRules := []securityGroupRule{
+			{
+				Description: "Allow all traffic from the ingress network load balancer to the ingress controller port 443.",
+				Port:        key.IngressControllerSecurePort(customObject),
+				Protocol:    tcpProtocol,
+				SourceCIDR:  defaultCIDR,
+			},
+			{
+				Description: "Allow all traffic from the ingress network load balancer to the ingress controller port 80.",
+				Port:        key.IngressControllerInsecurePort(customObject),
+				Protocol:    tcpProtocol,
+				SourceCIDR:  defaultCIDR,
+			},
+		}
+		rules = append(rules, nlbRules...)
+	}
+
+	rules = append(rules, customSecurityGroupRules(key.WorkerSecurityGroupRules(customObject))...)
+
+	return rules
 }
 
 func (s *GuestSecurityGroupsAdapter) getIngressRules(customObject v1alpha1.AWSConfig) []securityGroupRule {
-	return []securityGroupRule{
+	rules := []securityGroupRule{
 		{
 			Description: "Allow all http traffic to the ingress load balancer.",
 			Port:        httpPort,
@@ -182,19 +221,54 @@ func (s *GuestSecurityGroupsAdapter) getIngressRules(customObject v1alpha1.AWSCo
 			SourceCIDR:  defaultCIDR,
 		},
 	}
+
+	rules = append(rules, customSecurityGroupRules(key.IngressSecurityGroupRules(customObject))...)
+
+	return rules
 }
 
 type securityGroupRule struct {
-	Description         string
-	Port                int
+	Description string
+	Port        int
+	// ToPort is the last port of rules allowing a range of ports starting at
+	// Port. It is empty for rules allowing a single port.
+	ToPort              int
 	Protocol            string
 	SourceCIDR          string
 	SourceSecurityGroup string
 }
 
+// customSecurityGroupRules converts the additional security group rules
+// configured in the custom object. They are validated by the cloudformation
+// resource.
+func customSecurityGroupRules(customRules []v1alpha1.AWSConfigSpecAWSSecurityGroupRule) []securityGroupRule {
+	var rules []securityGroupRule
+
+	for _, c := range customRules {
+		r := securityGroupRule{
+			Description: c.Description,
+			Port:        c.FromPort,
+			Protocol:    c.Protocol,
+			SourceCIDR:  c.SourceCIDR,
+		}
+		if c.ToPort != c.FromPort {
+			r.ToPort = c.ToPort
+		}
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
 func getKubernetesAPIRules(cfg Config, hostClusterCIDR string) ([]securityGroupRule, error) {
-	// When API whitelisting is enabled, add separate security group rule per each subnet.
-	if cfg.APIWhitelist.Enabled {
+	// When API whitelisting is enabled or the API is private, add separate
+	// security group rule per each subnet.
+	if apiWhitelistEnabled(cfg) || key.IsAPIPrivate(cfg.CustomObject) {
+		guestClusterCIDR, err := vpcCIDR(cfg)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
 		rules := []securityGroupRule{
 			{
 				Description: "Allow traffic from control plane CIDR.",
@@ -206,24 +280,41 @@ func getKubernetesAPIRules(cfg Config, hostClusterCIDR string) ([]securityGroupR
 				Description: "Allow traffic from tenant cluster CIDR.",
 				Port:        key.KubernetesAPISecurePort(cfg.CustomObject),
 				Protocol:    tcpProtocol,
-				SourceCIDR:  cfg.CustomObject.Spec.AWS.VPC.CIDR,
+				SourceCIDR:  guestClusterCIDR,
 			},
 		}
 
-		// Whitelist all configured subnets.
-		whitelistSubnets := strings.Split(cfg.APIWhitelist.SubnetList, ",")
-		for _, subnet := range whitelistSubnets {
-			if subnet != "" {
-				subnetRule := securityGroupRule{
-					Description: "Custom Whitelist CIDR.",
-					Port:        key.KubernetesAPISecurePort(cfg.CustomObject),
-					Protocol:    tcpProtocol,
-					SourceCIDR:  subnet,
+		// Whitelist all configured subnets of the installation and the guest
+		// cluster. Subnets whitelisted by both are only whitelisted once.
+		{
+			var whitelistSubnets []string
+			if cfg.APIWhitelist.Enabled {
+				whitelistSubnets = strings.Split(cfg.APIWhitelist.SubnetList, ",")
+			}
+			whitelistSubnets = append(whitelistSubnets, key.APIWhitelist(cfg.CustomObject)...)
+
+			seen := map[string]bool{}
+			for _, subnet := range whitelistSubnets {
+				if subnet != "" && !seen[subnet] {
+					seen[subnet] = true
+					subnetRule := securityGroupRule{
+						Description: "Custom Whitelist CIDR.",
+						Port:        key.KubernetesAPISecurePort(cfg.CustomObject),
+						Protocol:    tcpProtocol,
+						SourceCIDR:  subnet,
+					}
+					rules = append(rules, subnetRule)
 				}
-				rules = append(rules, subnetRule)
 			}
 		}
 
+		// The internal API ELB of private APIs is reached through the VPC
+		// peering, so that the public EIPs of the host cluster do not have to be
+		// whitelisted.
+		if key.IsAPIPrivate(cfg.CustomObject) {
+			return rules, nil
+		}
+
 		// Whitelist public EIPs of the host cluster NAT gateways.
 		hostClusterNATGatewayRules, err := getHostClusterNATGatewayRules(cfg)
 		if err != nil {
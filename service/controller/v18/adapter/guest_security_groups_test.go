@@ -489,6 +489,207 @@ func TestAdapterSecurityGroupsKubernetesAPIRules(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "case 6: private API with API whitelisting disabled",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Private: true,
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.1.0/24",
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								SecurePort: 443,
+							},
+						},
+					},
+				},
+			},
+			apiWhitelistingEnabled: false,
+			hostClusterCIDR:        "10.0.0.0/16",
+			expectedError:          false,
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from control plane CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.0.0.0/16",
+				},
+				{
+					Description: "Allow traffic from tenant cluster CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.1.0/24",
+				},
+			},
+		},
+		{
+			description: "case 7: private API with subnets and NAT gateway EIPs",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Private: true,
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.1.0/24",
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								SecurePort: 443,
+							},
+						},
+					},
+				},
+			},
+			apiWhitelistingEnabled: true,
+			apiWhitelistSubnets:    "192.168.1.0/24",
+			elasticIPs: []string{
+				"21.1.136.42",
+			},
+			hostClusterCIDR: "10.0.0.0/16",
+			expectedError:   false,
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from control plane CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.0.0.0/16",
+				},
+				{
+					Description: "Allow traffic from tenant cluster CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.1.0/24",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "192.168.1.0/24",
+				},
+			},
+		},
+		{
+			description: "case 8: API whitelisting enabled with subnets of the installation and the custom object",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Whitelist: []string{
+								"192.168.1.0/24",
+								"172.16.0.0/12",
+							},
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.1.0/24",
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								SecurePort: 443,
+							},
+						},
+					},
+				},
+			},
+			apiWhitelistingEnabled: true,
+			apiWhitelistSubnets:    "212.145.136.84/32,192.168.1.0/24",
+			hostClusterCIDR:        "10.0.0.0/16",
+			expectedError:          false,
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from control plane CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.0.0.0/16",
+				},
+				{
+					Description: "Allow traffic from tenant cluster CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.1.0/24",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "212.145.136.84/32",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "192.168.1.0/24",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "172.16.0.0/12",
+				},
+			},
+		},
+		{
+			description: "case 9: API whitelisting of the installation disabled with subnets of the custom object",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Whitelist: []string{
+								"172.16.0.0/12",
+							},
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.1.0/24",
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+						Kubernetes: v1alpha1.ClusterKubernetes{
+							API: v1alpha1.ClusterKubernetesAPI{
+								SecurePort: 443,
+							},
+						},
+					},
+				},
+			},
+			apiWhitelistingEnabled: false,
+			apiWhitelistSubnets:    "212.145.136.84/32",
+			hostClusterCIDR:        "10.0.0.0/16",
+			expectedError:          false,
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow traffic from control plane CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.0.0.0/16",
+				},
+				{
+					Description: "Allow traffic from tenant cluster CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.1.1.0/24",
+				},
+				{
+					Description: "Custom Whitelist CIDR.",
+					Port:        443,
+					Protocol:    "tcp",
+					SourceCIDR:  "172.16.0.0/12",
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -529,3 +730,71 @@ func TestAdapterSecurityGroupsKubernetesAPIRules(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterSecurityGroupsCustomRules(t *testing.T) {
+	testCases := []struct {
+		description   string
+		customRules   []v1alpha1.AWSConfigSpecAWSSecurityGroupRule
+		expectedRules []securityGroupRule
+	}{
+		{
+			description:   "case 0: no custom rules",
+			customRules:   nil,
+			expectedRules: nil,
+		},
+		{
+			description: "case 1: single port and port range",
+			customRules: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+				{
+					Description: "Allow node exporter scraping.",
+					FromPort:    9100,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.2.0.0/16",
+				},
+				{
+					Description: "Allow NodePort services.",
+					FromPort:    30000,
+					ToPort:      32767,
+					Protocol:    "udp",
+					SourceCIDR:  "10.3.0.0/16",
+				},
+				{
+					FromPort:   8080,
+					ToPort:     8080,
+					Protocol:   "tcp",
+					SourceCIDR: "10.4.0.0/16",
+				},
+			},
+			expectedRules: []securityGroupRule{
+				{
+					Description: "Allow node exporter scraping.",
+					Port:        9100,
+					Protocol:    "tcp",
+					SourceCIDR:  "10.2.0.0/16",
+				},
+				{
+					Description: "Allow NodePort services.",
+					Port:        30000,
+					ToPort:      32767,
+					Protocol:    "udp",
+					SourceCIDR:  "10.3.0.0/16",
+				},
+				{
+					Port:       8080,
+					Protocol:   "tcp",
+					SourceCIDR: "10.4.0.0/16",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rules := customSecurityGroupRules(tc.customRules)
+
+			if !reflect.DeepEqual(tc.expectedRules, rules) {
+				t.Fatalf("expected rules %v got %v", tc.expectedRules, rules)
+			}
+		})
+	}
+}
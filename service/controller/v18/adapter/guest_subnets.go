@@ -1,29 +1,122 @@
 package adapter
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/microerror"
+
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
 type GuestSubnetsAdapter struct {
-	PublicSubnetAZ                   string
-	PublicSubnetCIDR                 string
-	PublicSubnetName                 string
-	PublicSubnetMapPublicIPOnLaunch  bool
-	PrivateSubnetAZ                  string
-	PrivateSubnetCIDR                string
-	PrivateSubnetName                string
-	PrivateSubnetMapPublicIPOnLaunch bool
+	PublicSubnets  []GuestSubnetsAdapterSubnet
+	PrivateSubnets []GuestSubnetsAdapterSubnet
+}
+
+type GuestSubnetsAdapterSubnet struct {
+	AvailabilityZone      string
+	CIDR                  string
+	MapPublicIPOnLaunch   bool
+	Name                  string
+	ResourceName          string
+	RouteTableAssociation GuestSubnetsAdapterRouteTableAssociation
+}
+
+type GuestSubnetsAdapterRouteTableAssociation struct {
+	ResourceName   string
+	RouteTableName string
 }
 
 func (s *GuestSubnetsAdapter) Adapt(cfg Config) error {
-	s.PublicSubnetAZ = key.AvailabilityZone(cfg.CustomObject)
-	s.PublicSubnetCIDR = cfg.CustomObject.Spec.AWS.VPC.PublicSubnetCIDR
-	s.PublicSubnetName = key.SubnetName(cfg.CustomObject, suffixPublic)
-	s.PublicSubnetMapPublicIPOnLaunch = false
-	s.PrivateSubnetAZ = key.AvailabilityZone(cfg.CustomObject)
-	s.PrivateSubnetCIDR = key.PrivateSubnetCIDR(cfg.CustomObject)
-	s.PrivateSubnetName = key.SubnetName(cfg.CustomObject, suffixPrivate)
-	s.PrivateSubnetMapPublicIPOnLaunch = false
+	// Existing subnets are neither created nor associated with route tables,
+	// since their routing is managed by the owner of the VPC.
+	if key.IsExistingVPC(cfg.CustomObject) {
+		return nil
+	}
+
+	publicSubnetCIDRs, err := key.PublicSubnetCIDRs(cfg.CustomObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	privateSubnetCIDRs, err := key.PrivateSubnetCIDRs(cfg.CustomObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for i, az := range key.AvailabilityZones(cfg.CustomObject) {
+		// All public subnets share the single public route table, since they
+		// all route through the same internet gateway.
+		public := GuestSubnetsAdapterSubnet{
+			AvailabilityZone:    az,
+			CIDR:                publicSubnetCIDRs[i],
+			MapPublicIPOnLaunch: false,
+			Name:                key.SubnetName(cfg.CustomObject, key.IndexedName(suffixPublic, i)),
+			ResourceName:        key.IndexedName("PublicSubnet", i),
+			RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+				ResourceName:   key.IndexedName("PublicSubnetRouteTableAssociation", i),
+				RouteTableName: "PublicRouteTable",
+			},
+		}
+		s.PublicSubnets = append(s.PublicSubnets, public)
+
+		// Every private subnet gets its own route table, since it routes through
+		// the NAT gateway of its own availability zone.
+		private := GuestSubnetsAdapterSubnet{
+			AvailabilityZone:    az,
+			CIDR:                privateSubnetCIDRs[i],
+			MapPublicIPOnLaunch: false,
+			Name:                key.SubnetName(cfg.CustomObject, key.IndexedName(suffixPrivate, i)),
+			ResourceName:        key.IndexedName("PrivateSubnet", i),
+			RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+				ResourceName:   key.IndexedName("PrivateSubnetRouteTableAssociation", i),
+				RouteTableName: key.IndexedName("PrivateRouteTable", i),
+			},
+		}
+		s.PrivateSubnets = append(s.PrivateSubnets, private)
+	}
 
 	return nil
 }
+
+// privateSubnetCIDRs returns the CIDRs of the private subnets of the guest
+// cluster, one per availability zone. The CIDRs of existing subnets are looked
+// up, since they are not part of the custom object.
+func privateSubnetCIDRs(cfg Config) ([]string, error) {
+	if !key.IsExistingVPC(cfg.CustomObject) {
+		cidrs, err := key.PrivateSubnetCIDRs(cfg.CustomObject)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return cidrs, nil
+	}
+
+	var cidrs []string
+	for _, id := range key.PrivateSubnetIDs(cfg.CustomObject) {
+		subnet, err := existingSubnet(cfg.Clients, id)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		cidrs = append(cidrs, *subnet.CidrBlock)
+	}
+
+	return cidrs, nil
+}
+
+func existingSubnet(clients Clients, subnetID string) (*ec2.Subnet, error) {
+	input := &ec2.DescribeSubnetsInput{
+		SubnetIds: []*string{
+			aws.String(subnetID),
+		},
+	}
+	output, err := clients.EC2.DescribeSubnets(input)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	} else if len(output.Subnets) == 0 {
+		return nil, microerror.Maskf(notFoundError, "subnet: %s", subnetID)
+	} else if len(output.Subnets) > 1 {
+		return nil, microerror.Maskf(tooManyResultsError, "subnet: %s found %d subnets", subnetID, len(output.Subnets))
+	}
+
+	return output.Subnets[0], nil
+}
@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
@@ -9,17 +10,11 @@ import (
 func TestAdapterSubnetsRegularFields(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
-		description                              string
-		customObject                             v1alpha1.AWSConfig
-		expectedError                            bool
-		expectedPublicSubnetAZ                   string
-		expectedPublicSubnetCIDR                 string
-		expectedPublicSubnetName                 string
-		expectedPublicSubnetMapPublicIPOnLaunch  bool
-		expectedPrivateSubnetAZ                  string
-		expectedPrivateSubnetCIDR                string
-		expectedPrivateSubnetName                string
-		expectedPrivateSubnetMapPublicIPOnLaunch bool
+		description            string
+		customObject           v1alpha1.AWSConfig
+		expectedError          bool
+		expectedPublicSubnets  []GuestSubnetsAdapterSubnet
+		expectedPrivateSubnets []GuestSubnetsAdapterSubnet
 	}{
 		{
 			description: "basic matching, all fields present",
@@ -37,15 +32,146 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 					},
 				},
 			},
-			expectedError:                            false,
-			expectedPublicSubnetAZ:                   "eu-central-1a",
-			expectedPublicSubnetCIDR:                 "10.1.1.0/25",
-			expectedPublicSubnetName:                 "test-cluster-public",
-			expectedPublicSubnetMapPublicIPOnLaunch:  false,
-			expectedPrivateSubnetAZ:                  "eu-central-1a",
-			expectedPrivateSubnetCIDR:                "10.1.2.0/25",
-			expectedPrivateSubnetName:                "test-cluster-private",
-			expectedPrivateSubnetMapPublicIPOnLaunch: false,
+			expectedError: false,
+			expectedPublicSubnets: []GuestSubnetsAdapterSubnet{
+				{
+					AvailabilityZone:    "eu-central-1a",
+					CIDR:                "10.1.1.0/25",
+					MapPublicIPOnLaunch: false,
+					Name:                "test-cluster-public",
+					ResourceName:        "PublicSubnet",
+					RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+						ResourceName:   "PublicSubnetRouteTableAssociation",
+						RouteTableName: "PublicRouteTable",
+					},
+				},
+			},
+			expectedPrivateSubnets: []GuestSubnetsAdapterSubnet{
+				{
+					AvailabilityZone:    "eu-central-1a",
+					CIDR:                "10.1.2.0/25",
+					MapPublicIPOnLaunch: false,
+					Name:                "test-cluster-private",
+					ResourceName:        "PrivateSubnet",
+					RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+						ResourceName:   "PrivateSubnetRouteTableAssociation",
+						RouteTableName: "PrivateRouteTable",
+					},
+				},
+			},
+		},
+		{
+			description: "multiple availability zones, first availability zone keeps the configured subnets",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AZ: "eu-central-1a",
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR:              "10.1.0.0/16",
+							PublicSubnetCIDR:  "10.1.1.0/25",
+							PrivateSubnetCIDR: "10.1.2.0/25",
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+				},
+			},
+			expectedError: false,
+			expectedPublicSubnets: []GuestSubnetsAdapterSubnet{
+				{
+					AvailabilityZone:    "eu-central-1a",
+					CIDR:                "10.1.1.0/25",
+					MapPublicIPOnLaunch: false,
+					Name:                "test-cluster-public",
+					ResourceName:        "PublicSubnet",
+					RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+						ResourceName:   "PublicSubnetRouteTableAssociation",
+						RouteTableName: "PublicRouteTable",
+					},
+				},
+				{
+					AvailabilityZone:    "eu-central-1b",
+					CIDR:                "10.1.0.0/25",
+					MapPublicIPOnLaunch: false,
+					Name:                "test-cluster-public01",
+					ResourceName:        "PublicSubnet01",
+					RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+						ResourceName:   "PublicSubnetRouteTableAssociation01",
+						RouteTableName: "PublicRouteTable",
+					},
+				},
+			},
+			expectedPrivateSubnets: []GuestSubnetsAdapterSubnet{
+				{
+					AvailabilityZone:    "eu-central-1a",
+					CIDR:                "10.1.2.0/25",
+					MapPublicIPOnLaunch: false,
+					Name:                "test-cluster-private",
+					ResourceName:        "PrivateSubnet",
+					RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+						ResourceName:   "PrivateSubnetRouteTableAssociation",
+						RouteTableName: "PrivateRouteTable",
+					},
+				},
+				{
+					AvailabilityZone:    "eu-central-1b",
+					CIDR:                "10.1.0.128/25",
+					MapPublicIPOnLaunch: false,
+					Name:                "test-cluster-private01",
+					ResourceName:        "PrivateSubnet01",
+					RouteTableAssociation: GuestSubnetsAdapterRouteTableAssociation{
+						ResourceName:   "PrivateSubnetRouteTableAssociation01",
+						RouteTableName: "PrivateRouteTable01",
+					},
+				},
+			},
+		},
+		{
+			description: "multiple availability zones, invalid VPC CIDR",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "invalid",
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "existing VPC, no subnets",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							ID:               "vpc-1234",
+							PrivateSubnetIDs: []string{"subnet-1", "subnet-2"},
+							PublicSubnetIDs:  []string{"subnet-3", "subnet-4"},
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+				},
+			},
+			expectedError: false,
 		},
 	}
 
@@ -67,36 +193,12 @@ func TestAdapterSubnetsRegularFields(t *testing.T) {
 				t.Errorf("unexpected error %v", err)
 			}
 
-			if a.Guest.Subnets.PublicSubnetAZ != tc.expectedPublicSubnetAZ {
-				t.Errorf("unexpected PublicSubnetAZ, got %q, want %q", a.Guest.Subnets.PublicSubnetAZ, tc.expectedPublicSubnetAZ)
-			}
-
-			if a.Guest.Subnets.PublicSubnetCIDR != tc.expectedPublicSubnetCIDR {
-				t.Errorf("unexpected PublicSubnetCIDR, got %q, want %q", a.Guest.Subnets.PublicSubnetCIDR, tc.expectedPublicSubnetCIDR)
-			}
-
-			if a.Guest.Subnets.PublicSubnetName != tc.expectedPublicSubnetName {
-				t.Errorf("unexpected PublicSubnetName, got %q, want %q", a.Guest.Subnets.PublicSubnetName, tc.expectedPublicSubnetName)
-			}
-
-			if a.Guest.Subnets.PublicSubnetMapPublicIPOnLaunch != tc.expectedPublicSubnetMapPublicIPOnLaunch {
-				t.Errorf("unexpected PublicSubnetMapPublicIPOnLaunch, got %t, want %t", a.Guest.Subnets.PublicSubnetMapPublicIPOnLaunch, tc.expectedPublicSubnetMapPublicIPOnLaunch)
-			}
-
-			if a.Guest.Subnets.PrivateSubnetAZ != tc.expectedPrivateSubnetAZ {
-				t.Errorf("unexpected PrivateSubnetAZ, got %q, want %q", a.Guest.Subnets.PrivateSubnetAZ, tc.expectedPrivateSubnetAZ)
-			}
-
-			if a.Guest.Subnets.PrivateSubnetCIDR != tc.expectedPrivateSubnetCIDR {
-				t.Errorf("unexpected PrivateSubnetCIDR, got %q, want %q", a.Guest.Subnets.PrivateSubnetCIDR, tc.expectedPrivateSubnetCIDR)
-			}
-
-			if a.Guest.Subnets.PrivateSubnetName != tc.expectedPrivateSubnetName {
-				t.Errorf("unexpected PrivateSubnetName, got %q, want %q", a.Guest.Subnets.PrivateSubnetName, tc.expectedPrivateSubnetName)
+			if !reflect.DeepEqual(a.Guest.Subnets.PublicSubnets, tc.expectedPublicSubnets) {
+				t.Errorf("unexpected PublicSubnets, got %#v, want %#v", a.Guest.Subnets.PublicSubnets, tc.expectedPublicSubnets)
 			}
 
-			if a.Guest.Subnets.PrivateSubnetMapPublicIPOnLaunch != tc.expectedPrivateSubnetMapPublicIPOnLaunch {
-				t.Errorf("unexpected PrivateSubnetMapPublicIPOnLaunch, got %t, want %t", a.Guest.Subnets.PrivateSubnetMapPublicIPOnLaunch, tc.expectedPrivateSubnetMapPublicIPOnLaunch)
+			if !reflect.DeepEqual(a.Guest.Subnets.PrivateSubnets, tc.expectedPrivateSubnets) {
+				t.Errorf("unexpected PrivateSubnets, got %#v, want %#v", a.Guest.Subnets.PrivateSubnets, tc.expectedPrivateSubnets)
 			}
 		})
 	}
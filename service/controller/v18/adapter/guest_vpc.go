@@ -16,6 +16,27 @@ type GuestVPCAdapter struct {
 	HostAccountID    string
 	PeerVPCID        string
 	PeerRoleArn      string
+	// ExistingVPCID and ExistingSubnets are set when the guest cluster is
+	// deployed into an existing VPC. They are rendered as stack parameters
+	// named like the resources they replace, so that references to the VPC and
+	// the subnets resolve to the existing ones.
+	ExistingVPCID   string
+	ExistingSubnets []GuestVPCAdapterSubnet
+	// FlowLogsDestination is the location in the logging bucket the VPC flow
+	// logs are delivered to. FlowLogsTrafficType is the type of traffic they
+	// capture.
+	FlowLogsDestination string
+	FlowLogsTrafficType string
+	// TransitGatewayID is set when the guest cluster VPC is attached to the
+	// Transit Gateway instead of being peered with the host cluster VPC. The
+	// attachment spans the private subnets of all availability zones.
+	TransitGatewayID      string
+	TransitGatewaySubnets []string
+}
+
+type GuestVPCAdapterSubnet struct {
+	ID           string
+	ResourceName string
 }
 
 func (v *GuestVPCAdapter) Adapt(cfg Config) error {
@@ -24,6 +45,34 @@ func (v *GuestVPCAdapter) Adapt(cfg Config) error {
 	v.InstallationName = cfg.InstallationName
 	v.HostAccountID = cfg.HostAccountID
 	v.PeerVPCID = key.PeerID(cfg.CustomObject)
+	v.FlowLogsDestination = key.VPCFlowLogsDestination(cfg.CustomObject)
+	v.FlowLogsTrafficType = key.VPCFlowLogsTrafficType(cfg.CustomObject)
+
+	v.TransitGatewayID = transitGatewayID(cfg)
+	if v.TransitGatewayID != "" {
+		for i := range key.AvailabilityZones(cfg.CustomObject) {
+			v.TransitGatewaySubnets = append(v.TransitGatewaySubnets, key.IndexedName("PrivateSubnet", i))
+		}
+	}
+
+	if key.IsExistingVPC(cfg.CustomObject) {
+		v.ExistingVPCID = key.VPCID(cfg.CustomObject)
+
+		for i, id := range key.PublicSubnetIDs(cfg.CustomObject) {
+			s := GuestVPCAdapterSubnet{
+				ID:           id,
+				ResourceName: key.IndexedName("PublicSubnet", i),
+			}
+			v.ExistingSubnets = append(v.ExistingSubnets, s)
+		}
+		for i, id := range key.PrivateSubnetIDs(cfg.CustomObject) {
+			s := GuestVPCAdapterSubnet{
+				ID:           id,
+				ResourceName: key.IndexedName("PrivateSubnet", i),
+			}
+			v.ExistingSubnets = append(v.ExistingSubnets, s)
+		}
+	}
 
 	// PeerRoleArn.
 	roleName := key.PeerAccessRoleName(cfg.CustomObject)
@@ -60,3 +109,18 @@ func VpcCIDR(clients Clients, vpcID string) (string, error) {
 	}
 	return *output.Vpcs[0].CidrBlock, nil
 }
+
+// vpcCIDR returns the CIDR of the VPC of the guest cluster. The CIDR of existing
+// VPCs is looked up, since it is not part of the custom object.
+func vpcCIDR(cfg Config) (string, error) {
+	if !key.IsExistingVPC(cfg.CustomObject) {
+		return key.CIDR(cfg.CustomObject), nil
+	}
+
+	cidr, err := VpcCIDR(cfg.Clients, key.VPCID(cfg.CustomObject))
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return cidr, nil
+}
@@ -0,0 +1,112 @@
+package adapter
+
+import (
+	"regexp"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// resourceNameInvalidChars matches the characters not allowed in the logical
+// IDs of CloudFormation resources.
+var resourceNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+type GuestVPCConnectionsAdapter struct {
+	// Peerings are the additional VPC peering connections of the guest cluster
+	// VPC. Their resource names are derived from the IDs of the peer VPCs, so
+	// that adding or removing peerings does not replace the other ones.
+	Peerings []GuestVPCConnectionsAdapterPeering
+	// PrivateRouteTables are the route tables of the private subnets routing
+	// the peer VPCs and propagating the routes of the virtual private gateway.
+	// They are either referenced by their resource name or, in existing VPCs,
+	// given by their ID.
+	PrivateRouteTables []GuestVPCConnectionsAdapterRouteTable
+	VPNGateway         GuestVPCConnectionsAdapterVPNGateway
+}
+
+type GuestVPCConnectionsAdapterPeering struct {
+	AccountID           string
+	CIDR                string
+	IngressResourceName string
+	ResourceName        string
+	Region              string
+	RoleARN             string
+	RouteResourceNames  []string
+	VPCID               string
+}
+
+type GuestVPCConnectionsAdapterRouteTable struct {
+	ID           string
+	ResourceName string
+}
+
+type GuestVPCConnectionsAdapterVPNGateway struct {
+	ID      string
+	Ingress []GuestVPCConnectionsAdapterIngress
+}
+
+type GuestVPCConnectionsAdapterIngress struct {
+	CIDR         string
+	ResourceName string
+}
+
+func (a *GuestVPCConnectionsAdapter) Adapt(cfg Config) error {
+	peerings := key.VPCPeerings(cfg.CustomObject)
+	vpnGatewayID := key.VPNGatewayID(cfg.CustomObject)
+
+	if len(peerings) == 0 && vpnGatewayID == "" {
+		return nil
+	}
+
+	if key.IsExistingVPC(cfg.CustomObject) {
+		routeTableIDs, err := existingPrivateRouteTableIDs(cfg)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, id := range routeTableIDs {
+			a.PrivateRouteTables = append(a.PrivateRouteTables, GuestVPCConnectionsAdapterRouteTable{ID: id})
+		}
+	} else {
+		for i := range key.AvailabilityZones(cfg.CustomObject) {
+			rt := GuestVPCConnectionsAdapterRouteTable{
+				ResourceName: key.IndexedName("PrivateRouteTable", i),
+			}
+			a.PrivateRouteTables = append(a.PrivateRouteTables, rt)
+		}
+	}
+
+	for _, p := range peerings {
+		suffix := resourceNameInvalidChars.ReplaceAllString(p.ID, "")
+
+		peering := GuestVPCConnectionsAdapterPeering{
+			AccountID:           p.AccountID,
+			CIDR:                p.CIDR,
+			IngressResourceName: "VPCPeeringIngress" + suffix,
+			ResourceName:        "VPCPeeringConnection" + suffix,
+			Region:              p.Region,
+			RoleARN:             p.RoleARN,
+			VPCID:               p.ID,
+		}
+		for i := range a.PrivateRouteTables {
+			peering.RouteResourceNames = append(peering.RouteResourceNames, key.IndexedName("VPCPeeringRoute"+suffix, i))
+		}
+
+		a.Peerings = append(a.Peerings, peering)
+	}
+
+	if vpnGatewayID != "" {
+		a.VPNGateway.ID = vpnGatewayID
+
+		for i, cidr := range key.VPNGatewayCIDRs(cfg.CustomObject) {
+			ingress := GuestVPCConnectionsAdapterIngress{
+				CIDR:         cidr,
+				ResourceName: key.IndexedName("VPNGatewayIngress", i),
+			}
+			a.VPNGateway.Ingress = append(a.VPNGateway.Ingress, ingress)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package adapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func TestAdapterVPCConnectionsRegularFields(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description                string
+		customObject               v1alpha1.AWSConfig
+		expectedPeerings           []GuestVPCConnectionsAdapterPeering
+		expectedPrivateRouteTables []GuestVPCConnectionsAdapterRouteTable
+		expectedVPNGateway         GuestVPCConnectionsAdapterVPNGateway
+	}{
+		{
+			description: "no peerings and no virtual private gateway",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AZ:     "eu-central-1a",
+						Region: "eu-central-1",
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.0.0/16",
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "peering and virtual private gateway",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						Region: "eu-central-1",
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.0.0/16",
+							Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+								{
+									AccountID: "123456789012",
+									CIDR:      "10.100.0.0/16",
+									ID:        "vpc-0a1b2c3d",
+									Region:    "eu-west-1",
+									RoleARN:   "arn:aws:iam::123456789012:role/peering",
+								},
+							},
+							VPNGateway: v1alpha1.AWSConfigSpecAWSVPCVPNGateway{
+								ID:    "vgw-0a1b2c3d",
+								CIDRs: []string{"192.168.0.0/16", "172.16.0.0/12"},
+							},
+						},
+					},
+				},
+			},
+			expectedPeerings: []GuestVPCConnectionsAdapterPeering{
+				{
+					AccountID:           "123456789012",
+					CIDR:                "10.100.0.0/16",
+					IngressResourceName: "VPCPeeringIngressvpc0a1b2c3d",
+					ResourceName:        "VPCPeeringConnectionvpc0a1b2c3d",
+					Region:              "eu-west-1",
+					RoleARN:             "arn:aws:iam::123456789012:role/peering",
+					RouteResourceNames:  []string{"VPCPeeringRoutevpc0a1b2c3d", "VPCPeeringRoutevpc0a1b2c3d01"},
+					VPCID:               "vpc-0a1b2c3d",
+				},
+			},
+			expectedPrivateRouteTables: []GuestVPCConnectionsAdapterRouteTable{
+				{ResourceName: "PrivateRouteTable"},
+				{ResourceName: "PrivateRouteTable01"},
+			},
+			expectedVPNGateway: GuestVPCConnectionsAdapterVPNGateway{
+				ID: "vgw-0a1b2c3d",
+				Ingress: []GuestVPCConnectionsAdapterIngress{
+					{CIDR: "192.168.0.0/16", ResourceName: "VPNGatewayIngress"},
+					{CIDR: "172.16.0.0/12", ResourceName: "VPNGatewayIngress01"},
+				},
+			},
+		},
+		{
+			description: "peering in existing VPC with shared route table",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						Region: "eu-central-1",
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							ID:               "vpc-1234",
+							PrivateSubnetIDs: []string{"subnet-private-a", "subnet-private-b"},
+							PublicSubnetIDs:  []string{"subnet-public-a", "subnet-public-b"},
+							Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+								{
+									CIDR: "10.100.0.0/16",
+									ID:   "vpc-0a1b2c3d",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedPeerings: []GuestVPCConnectionsAdapterPeering{
+				{
+					CIDR:                "10.100.0.0/16",
+					IngressResourceName: "VPCPeeringIngressvpc0a1b2c3d",
+					ResourceName:        "VPCPeeringConnectionvpc0a1b2c3d",
+					RouteResourceNames:  []string{"VPCPeeringRoutevpc0a1b2c3d"},
+					VPCID:               "vpc-0a1b2c3d",
+				},
+			},
+			expectedPrivateRouteTables: []GuestVPCConnectionsAdapterRouteTable{
+				{ID: "rtb-1234_0"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		ec2Mock := &EC2ClientMock{
+			routeTableID: "rtb-1234",
+			vpcCIDR:      "172.31.0.0/16",
+		}
+		ec2Mock.SetMatchingRouteTables(1)
+		clients := Clients{
+			EC2: ec2Mock,
+			IAM: &IAMClientMock{},
+			STS: &STSClientMock{},
+		}
+		a := Adapter{}
+
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject: tc.customObject,
+				Clients:      clients,
+			}
+			err := a.Guest.VPCConnections.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if !reflect.DeepEqual(a.Guest.VPCConnections.Peerings, tc.expectedPeerings) {
+				t.Errorf("unexpected Peerings, got %#v, want %#v", a.Guest.VPCConnections.Peerings, tc.expectedPeerings)
+			}
+			if !reflect.DeepEqual(a.Guest.VPCConnections.PrivateRouteTables, tc.expectedPrivateRouteTables) {
+				t.Errorf("unexpected PrivateRouteTables, got %#v, want %#v", a.Guest.VPCConnections.PrivateRouteTables, tc.expectedPrivateRouteTables)
+			}
+			if !reflect.DeepEqual(a.Guest.VPCConnections.VPNGateway, tc.expectedVPNGateway) {
+				t.Errorf("unexpected VPNGateway, got %#v, want %#v", a.Guest.VPCConnections.VPNGateway, tc.expectedVPNGateway)
+			}
+		})
+	}
+}
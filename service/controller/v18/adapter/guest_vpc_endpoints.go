@@ -0,0 +1,100 @@
+package adapter
+
+import (
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// vpcEndpointInterfaceServices are the AWS services the guest cluster nodes
+// reach through interface endpoints. ECR requires endpoints for both its API
+// and the Docker registry, whose image layers are served from S3.
+var vpcEndpointInterfaceServices = []GuestVPCEndpointsAdapterService{
+	{ResourceName: "VPCEndpointEC2", Service: "ec2"},
+	{ResourceName: "VPCEndpointECRAPI", Service: "ecr.api"},
+	{ResourceName: "VPCEndpointECRDKR", Service: "ecr.dkr"},
+	{ResourceName: "VPCEndpointKMS", Service: "kms"},
+	{ResourceName: "VPCEndpointLogs", Service: "logs"},
+	{ResourceName: "VPCEndpointSTS", Service: "sts"},
+}
+
+type GuestVPCEndpointsAdapter struct {
+	// Enabled is true when the guest cluster VPC gets a gateway endpoint for S3
+	// and interface endpoints for the other AWS APIs used by the guest cluster
+	// nodes, so that their traffic does not go through the NAT gateways.
+	Enabled bool
+	// GatewayEndpoint is associated with the private route tables of the guest
+	// cluster.
+	GatewayEndpoint    GuestVPCEndpointsAdapterService
+	InterfaceEndpoints []GuestVPCEndpointsAdapterService
+	// PrivateRouteTables are the route tables of the private subnets. They are
+	// either referenced by their resource name or, in existing VPCs, given by
+	// their ID.
+	PrivateRouteTables []GuestVPCEndpointsAdapterRouteTable
+	// PrivateSubnets are the resource names of the private subnets the network
+	// interfaces of the interface endpoints are placed in.
+	PrivateSubnets    []string
+	SecurityGroupName string
+	VPCCIDR           string
+}
+
+type GuestVPCEndpointsAdapterRouteTable struct {
+	ID           string
+	ResourceName string
+}
+
+type GuestVPCEndpointsAdapterService struct {
+	ResourceName string
+	Service      string
+	ServiceName  string
+}
+
+func (a *GuestVPCEndpointsAdapter) Adapt(cfg Config) error {
+	if !key.VPCEndpointsEnabled(cfg.CustomObject) {
+		return nil
+	}
+
+	vpcCIDR, err := vpcCIDR(cfg)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	a.Enabled = true
+	a.SecurityGroupName = key.SecurityGroupName(cfg.CustomObject, "vpc-endpoints")
+	a.VPCCIDR = vpcCIDR
+
+	a.GatewayEndpoint = GuestVPCEndpointsAdapterService{
+		ResourceName: "VPCEndpointS3",
+		Service:      key.VPCEndpointServiceS3,
+		ServiceName:  key.VPCEndpointServiceName(cfg.CustomObject, key.VPCEndpointServiceS3),
+	}
+
+	for _, s := range vpcEndpointInterfaceServices {
+		s.ServiceName = key.VPCEndpointServiceName(cfg.CustomObject, s.Service)
+		a.InterfaceEndpoints = append(a.InterfaceEndpoints, s)
+	}
+
+	for i := range key.AvailabilityZones(cfg.CustomObject) {
+		a.PrivateSubnets = append(a.PrivateSubnets, key.IndexedName("PrivateSubnet", i))
+	}
+
+	if key.IsExistingVPC(cfg.CustomObject) {
+		routeTableIDs, err := existingPrivateRouteTableIDs(cfg)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, id := range routeTableIDs {
+			a.PrivateRouteTables = append(a.PrivateRouteTables, GuestVPCEndpointsAdapterRouteTable{ID: id})
+		}
+	} else {
+		for i := range key.AvailabilityZones(cfg.CustomObject) {
+			rt := GuestVPCEndpointsAdapterRouteTable{
+				ResourceName: key.IndexedName("PrivateRouteTable", i),
+			}
+			a.PrivateRouteTables = append(a.PrivateRouteTables, rt)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package adapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func TestAdapterVPCEndpointsRegularFields(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description                string
+		customObject               v1alpha1.AWSConfig
+		expectedEnabled            bool
+		expectedPrivateRouteTables []GuestVPCEndpointsAdapterRouteTable
+		expectedPrivateSubnets     []string
+		expectedVPCCIDR            string
+	}{
+		{
+			description: "VPC endpoints disabled",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AZ:     "eu-central-1a",
+						Region: "eu-central-1",
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.0.0/16",
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "VPC endpoints enabled",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						Region: "eu-central-1",
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: "10.1.0.0/16",
+							Endpoints: v1alpha1.AWSConfigSpecAWSVPCEndpoints{
+								Enabled: true,
+							},
+						},
+					},
+				},
+			},
+			expectedEnabled: true,
+			expectedPrivateRouteTables: []GuestVPCEndpointsAdapterRouteTable{
+				{ResourceName: "PrivateRouteTable"},
+				{ResourceName: "PrivateRouteTable01"},
+			},
+			expectedPrivateSubnets: []string{"PrivateSubnet", "PrivateSubnet01"},
+			expectedVPCCIDR:        "10.1.0.0/16",
+		},
+		{
+			description: "VPC endpoints enabled in existing VPC with shared route table",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "test-cluster",
+					},
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: []string{
+							"eu-central-1a",
+							"eu-central-1b",
+						},
+						Region: "eu-central-1",
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							ID:               "vpc-1234",
+							PrivateSubnetIDs: []string{"subnet-private-a", "subnet-private-b"},
+							PublicSubnetIDs:  []string{"subnet-public-a", "subnet-public-b"},
+							Endpoints: v1alpha1.AWSConfigSpecAWSVPCEndpoints{
+								Enabled: true,
+							},
+						},
+					},
+				},
+			},
+			expectedEnabled: true,
+			expectedPrivateRouteTables: []GuestVPCEndpointsAdapterRouteTable{
+				{ID: "rtb-1234_0"},
+			},
+			expectedPrivateSubnets: []string{"PrivateSubnet", "PrivateSubnet01"},
+			expectedVPCCIDR:        "172.31.0.0/16",
+		},
+	}
+
+	for _, tc := range testCases {
+		ec2Mock := &EC2ClientMock{
+			routeTableID: "rtb-1234",
+			vpcCIDR:      "172.31.0.0/16",
+		}
+		ec2Mock.SetMatchingRouteTables(1)
+		clients := Clients{
+			EC2: ec2Mock,
+			IAM: &IAMClientMock{},
+			STS: &STSClientMock{},
+		}
+		a := Adapter{}
+
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject: tc.customObject,
+				Clients:      clients,
+			}
+			err := a.Guest.VPCEndpoints.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if a.Guest.VPCEndpoints.Enabled != tc.expectedEnabled {
+				t.Errorf("unexpected Enabled, got %t, want %t", a.Guest.VPCEndpoints.Enabled, tc.expectedEnabled)
+			}
+			if !reflect.DeepEqual(a.Guest.VPCEndpoints.PrivateRouteTables, tc.expectedPrivateRouteTables) {
+				t.Errorf("unexpected PrivateRouteTables, got %#v, want %#v", a.Guest.VPCEndpoints.PrivateRouteTables, tc.expectedPrivateRouteTables)
+			}
+			if !reflect.DeepEqual(a.Guest.VPCEndpoints.PrivateSubnets, tc.expectedPrivateSubnets) {
+				t.Errorf("unexpected PrivateSubnets, got %#v, want %#v", a.Guest.VPCEndpoints.PrivateSubnets, tc.expectedPrivateSubnets)
+			}
+			if a.Guest.VPCEndpoints.VPCCIDR != tc.expectedVPCCIDR {
+				t.Errorf("unexpected VPCCIDR, got %q, want %q", a.Guest.VPCEndpoints.VPCCIDR, tc.expectedVPCCIDR)
+			}
+		})
+	}
+}
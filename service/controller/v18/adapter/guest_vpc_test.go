@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
@@ -120,3 +121,71 @@ func TestAdapterVPCPeerRoleField(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapterVPCExistingFields(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description             string
+		vpc                     v1alpha1.AWSConfigSpecAWSVPC
+		expectedExistingVPCID   string
+		expectedExistingSubnets []GuestVPCAdapterSubnet
+	}{
+		{
+			description: "new VPC",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.0.0.0/24",
+			},
+			expectedExistingVPCID:   "",
+			expectedExistingSubnets: nil,
+		},
+		{
+			description: "existing VPC spanning two availability zones",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				ID:               "vpc-1234",
+				PrivateSubnetIDs: []string{"subnet-1", "subnet-2"},
+				PublicSubnetIDs:  []string{"subnet-3", "subnet-4"},
+			},
+			expectedExistingVPCID: "vpc-1234",
+			expectedExistingSubnets: []GuestVPCAdapterSubnet{
+				{ID: "subnet-3", ResourceName: "PublicSubnet"},
+				{ID: "subnet-4", ResourceName: "PublicSubnet01"},
+				{ID: "subnet-1", ResourceName: "PrivateSubnet"},
+				{ID: "subnet-2", ResourceName: "PrivateSubnet01"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			a := Adapter{}
+			cfg := Config{
+				CustomObject: v1alpha1.AWSConfig{
+					Spec: v1alpha1.AWSConfigSpec{
+						Cluster: v1alpha1.Cluster{
+							ID: "test-cluster",
+						},
+						AWS: v1alpha1.AWSConfigSpecAWS{
+							VPC: tc.vpc,
+						},
+					},
+				},
+				HostClients: Clients{
+					IAM: &IAMClientMock{},
+					STS: &STSClientMock{},
+				},
+			}
+			err := a.Guest.VPC.Adapt(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if a.Guest.VPC.ExistingVPCID != tc.expectedExistingVPCID {
+				t.Errorf("unexpected ExistingVPCID, got %q, want %q", a.Guest.VPC.ExistingVPCID, tc.expectedExistingVPCID)
+			}
+
+			if !reflect.DeepEqual(a.Guest.VPC.ExistingSubnets, tc.expectedExistingSubnets) {
+				t.Errorf("unexpected ExistingSubnets, got %#v, want %#v", a.Guest.VPC.ExistingSubnets, tc.expectedExistingSubnets)
+			}
+		})
+	}
+}
@@ -1,5 +1,12 @@
 package adapter
 
+import (
+	"github.com/giantswarm/aws-operator/service/controller/v18/dns"
+)
+
+// HostPostRecordSetsAdapter delegates the guest cluster zone from the host
+// cluster zone in case the latter is managed in Route53. Other DNS providers
+// are managed by the delegation resource.
 type HostPostRecordSetsAdapter struct {
 	BaseDomain                 string
 	ClusterID                  string
@@ -10,7 +17,7 @@ type HostPostRecordSetsAdapter struct {
 func (r *HostPostRecordSetsAdapter) Adapt(config Config) error {
 	r.BaseDomain = baseDomain(config)
 	r.ClusterID = clusterID(config)
-	r.Route53Enabled = route53Enabled(config)
+	r.Route53Enabled = route53Enabled(config) && config.DNSProvider == dns.Route53Provider
 	r.GuestHostedZoneNameServers = hostedZoneNameServers(config)
 
 	return nil
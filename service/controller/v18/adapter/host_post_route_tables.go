@@ -22,31 +22,59 @@ type HostPostRouteTablesAdapter struct {
 }
 
 func (i *HostPostRouteTablesAdapter) Adapt(cfg Config) error {
+	// Guest cluster VPCs attached to the Transit Gateway do not have a peering
+	// connection and do not need any routes in the host cluster route tables.
+	// The installation routes the whole IPAM network to the Transit Gateway
+	// once and the guest cluster VPC attachments propagate their CIDRs to the
+	// Transit Gateway route table, see HostPostTransitGatewayAdapter.
+	if cfg.StackState.TransitGatewayAttachmentID != "" {
+		return nil
+	}
+
 	peerConnectionID, err := waitForPeeringConnectionID(cfg)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
+	privateSubnetCIDRs, err := privateSubnetCIDRs(cfg)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
 	// private routes.
 	for _, routeTableName := range cfg.CustomObject.Spec.AWS.VPC.RouteTableNames {
 		routeTableID, err := routeTableID(routeTableName, cfg)
 		if err != nil {
 			return microerror.Mask(err)
 		}
-		rt := HostPostRouteTablesAdapterRouteTable{
-			Name:         routeTableName,
-			RouteTableID: routeTableID,
-			// Requester CIDR block, we create the peering connection from the guest's private subnet.
-			CidrBlock:        key.PrivateSubnetCIDR(cfg.CustomObject),
-			PeerConnectionID: peerConnectionID,
+		// Requester CIDR blocks, we create the peering connection from the
+		// guest's private subnets, one per availability zone.
+		for _, cidrBlock := range privateSubnetCIDRs {
+			rt := HostPostRouteTablesAdapterRouteTable{
+				Name:             routeTableName,
+				RouteTableID:     routeTableID,
+				CidrBlock:        cidrBlock,
+				PeerConnectionID: peerConnectionID,
+			}
+			i.PrivateRouteTables = append(i.PrivateRouteTables, rt)
 		}
-		i.PrivateRouteTables = append(i.PrivateRouteTables, rt)
 	}
 
-	// public routes for vault.
-	if cfg.EncrypterBackend == encrypter.VaultBackend {
+	// public routes for vault and private Kubernetes APIs. Guest nodes access
+	// Vault's ELB and the host cluster control plane accesses the internal API
+	// ELB of the guest cluster from the public subnets of the host cluster.
+	if cfg.EncrypterBackend == encrypter.VaultBackend || key.IsAPIPrivate(cfg.CustomObject) {
+		guestClusterCIDR, err := vpcCIDR(cfg)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
 		publicRouteTables := strings.Split(cfg.PublicRouteTables, ",")
 		for _, routeTableName := range publicRouteTables {
+			if routeTableName == "" {
+				continue
+			}
+
 			routeTableID, err := routeTableID(routeTableName, cfg)
 			if err != nil {
 				return microerror.Mask(err)
@@ -55,8 +83,9 @@ func (i *HostPostRouteTablesAdapter) Adapt(cfg Config) error {
 				Name:         routeTableName,
 				RouteTableID: routeTableID,
 				// Requester CIDR block, we create the peering connection from the
-				// guest's CIDR for being able to access Vault's ELB.
-				CidrBlock:        key.CIDR(cfg.CustomObject),
+				// guest's CIDR for being able to access Vault's ELB and the
+				// internal API ELB.
+				CidrBlock:        guestClusterCIDR,
 				PeerConnectionID: peerConnectionID,
 			}
 			i.PublicRouteTables = append(i.PublicRouteTables, rt)
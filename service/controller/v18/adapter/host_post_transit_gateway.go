@@ -0,0 +1,22 @@
+package adapter
+
+// HostPostTransitGatewayAdapter associates the Transit Gateway attachment of
+// the guest cluster VPC with the Transit Gateway route table of the
+// installation and propagates the routes to the guest cluster VPC to it. The
+// host cluster route tables route the whole IPAM network of the installation to
+// the Transit Gateway, so no per guest cluster routes are necessary there.
+type HostPostTransitGatewayAdapter struct {
+	AttachmentID string
+	RouteTableID string
+}
+
+func (h *HostPostTransitGatewayAdapter) Adapt(cfg Config) error {
+	if cfg.StackState.TransitGatewayAttachmentID == "" {
+		return nil
+	}
+
+	h.AttachmentID = cfg.StackState.TransitGatewayAttachmentID
+	h.RouteTableID = cfg.TransitGateway.RouteTableID
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package adapter
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// HostPreResourceSharesAdapter shares the Transit Gateway of the host cluster
+// account with the guest cluster account, so that the guest cluster main stack
+// can attach its VPC to it. Guest clusters running in the host cluster account
+// do not need a resource share. Sharing is restricted to the AWS Organization
+// of the host cluster account. With resource sharing enabled for the
+// Organization, shares are accepted without invitation, so that the guest
+// cluster main stack can attach its VPC right away.
+type HostPreResourceSharesAdapter struct {
+	GuestAccountID    string
+	Name              string
+	TransitGatewayARN string
+}
+
+func (h *HostPreResourceSharesAdapter) Adapt(cfg Config) error {
+	if cfg.TransitGateway.ID == "" || cfg.GuestAccountID == cfg.HostAccountID {
+		return nil
+	}
+
+	h.GuestAccountID = cfg.GuestAccountID
+	h.Name = fmt.Sprintf("%s-transit-gateway", key.ClusterID(cfg.CustomObject))
+	h.TransitGatewayARN = fmt.Sprintf("arn:%s:ec2:%s:%s:transit-gateway/%s", key.RegionARN(cfg.CustomObject), key.Region(cfg.CustomObject), cfg.HostAccountID, cfg.TransitGateway.ID)
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+func TestAdapterHostPreResourceSharesRegularFields(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+			},
+		},
+	}
+
+	testCases := []struct {
+		description               string
+		guestAccountID            string
+		hostAccountID             string
+		transitGateway            TransitGateway
+		expectedGuestAccountID    string
+		expectedName              string
+		expectedTransitGatewayARN string
+	}{
+		{
+			description:    "no transit gateway, no resource share",
+			guestAccountID: "111111111111",
+			hostAccountID:  "222222222222",
+		},
+		{
+			description:    "guest cluster in host cluster account, no resource share",
+			guestAccountID: "222222222222",
+			hostAccountID:  "222222222222",
+			transitGateway: TransitGateway{
+				ID:           "tgw-0123456789abcdef0",
+				RouteTableID: "tgw-rtb-0123456789abcdef0",
+			},
+		},
+		{
+			description:    "guest cluster in separate account, resource share",
+			guestAccountID: "111111111111",
+			hostAccountID:  "222222222222",
+			transitGateway: TransitGateway{
+				ID:           "tgw-0123456789abcdef0",
+				RouteTableID: "tgw-rtb-0123456789abcdef0",
+			},
+			expectedGuestAccountID:    "111111111111",
+			expectedName:              "test-cluster-transit-gateway",
+			expectedTransitGatewayARN: "arn:aws:ec2:eu-central-1:222222222222:transit-gateway/tgw-0123456789abcdef0",
+		},
+	}
+
+	for _, tc := range testCases {
+		a := Adapter{}
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := Config{
+				CustomObject:   customObject,
+				GuestAccountID: tc.guestAccountID,
+				HostAccountID:  tc.hostAccountID,
+				TransitGateway: tc.transitGateway,
+			}
+			err := a.HostPre.ResourceShares.Adapt(cfg)
+			if err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+
+			if a.HostPre.ResourceShares.GuestAccountID != tc.expectedGuestAccountID {
+				t.Errorf("unexpected GuestAccountID, got %q, want %q", a.HostPre.ResourceShares.GuestAccountID, tc.expectedGuestAccountID)
+			}
+			if a.HostPre.ResourceShares.Name != tc.expectedName {
+				t.Errorf("unexpected Name, got %q, want %q", a.HostPre.ResourceShares.Name, tc.expectedName)
+			}
+			if a.HostPre.ResourceShares.TransitGatewayARN != tc.expectedTransitGatewayARN {
+				t.Errorf("unexpected TransitGatewayARN, got %q, want %q", a.HostPre.ResourceShares.TransitGatewayARN, tc.expectedTransitGatewayARN)
+			}
+		})
+	}
+}
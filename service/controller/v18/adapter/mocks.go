@@ -110,6 +110,10 @@ func (e *EC2ClientMock) DescribeRouteTables(input *ec2.DescribeRouteTablesInput)
 	return output, nil
 }
 
+func (e *EC2ClientMock) SetVPCCIDR(value string) {
+	e.vpcCIDR = value
+}
+
 func (e *EC2ClientMock) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
 	if e.unexistingVPC {
 		return nil, fmt.Errorf("vpc not found")
@@ -225,6 +229,9 @@ func (c *CloudFormationMock) CreateStack(*awscloudformation.CreateStackInput) (*
 func (c *CloudFormationMock) DeleteStack(*awscloudformation.DeleteStackInput) (*awscloudformation.DeleteStackOutput, error) {
 	return nil, nil
 }
+func (c *CloudFormationMock) DescribeStackEvents(*awscloudformation.DescribeStackEventsInput) (*awscloudformation.DescribeStackEventsOutput, error) {
+	return nil, nil
+}
 func (c *CloudFormationMock) DescribeStacks(*awscloudformation.DescribeStacksInput) (*awscloudformation.DescribeStacksOutput, error) {
 	return nil, nil
 }
@@ -1,6 +1,8 @@
 package adapter
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
@@ -66,6 +68,15 @@ type APIWhitelist struct {
 	SubnetList string
 }
 
+// TransitGateway is the Transit Gateway in the host cluster account guest
+// cluster VPCs are attached to instead of being peered with the host cluster
+// VPC. Attachments are associated with the route table and propagate the
+// routes to the guest cluster VPC to it.
+type TransitGateway struct {
+	ID           string
+	RouteTableID string
+}
+
 type Clients struct {
 	CloudFormation CFClient
 	EC2            EC2Client
@@ -84,6 +95,35 @@ type StackState struct {
 
 	HostedZoneNameServers string
 
+	// LoadBalancerTypes are the types of the load balancers rendered for the
+	// Kubernetes API and the ingress controller. In case it is empty the type
+	// is taken from the custom object.
+	LoadBalancerTypes []string
+	// LoadBalancerHash identifies the settings of the classic ELBs.
+	LoadBalancerHash string
+	// LoadBalancerSwitchTime is the time the DNS records switched to the load
+	// balancers of the first type while migrating between load balancer types.
+	LoadBalancerSwitchTime time.Time
+	// SecurityGroupsHash identifies the API whitelist and the additional
+	// security group rules configured in the custom object.
+	SecurityGroupsHash string
+	// VPCConnectionsHash identifies the additional VPC peerings and the virtual
+	// private gateway configured in the custom object.
+	VPCConnectionsHash string
+
+	// TransitGateway is true when the guest cluster VPC is attached to the
+	// Transit Gateway instead of being peered with the host cluster VPC.
+	// TransitGatewayAttachmentID is the ID of the attachment the host cluster
+	// post stack associates with the Transit Gateway route table.
+	TransitGateway             bool
+	TransitGatewayAttachmentID string
+
+	// DefaultImageID is the AMI resolved from the AMI catalog and
+	// ImageCatalogSource the catalog configuration it was resolved from. Both
+	// are kept in the stack outputs in order to reuse the AMI.
+	DefaultImageID     string
+	ImageCatalogSource string
+
 	DockerVolumeResourceName   string
 	MasterImageID              string
 	MasterInstanceType         string
@@ -93,6 +133,9 @@ type StackState struct {
 	// version should be used here ever.
 	MasterCloudConfigVersion string
 	MasterInstanceMonitoring bool
+	// Masters holds the state of every single master instance. In case it is
+	// empty a single master is rendered based on the master fields above.
+	Masters []StackStateMaster
 
 	WorkerCount              string
 	WorkerDockerVolumeSizeGB int
@@ -103,15 +146,64 @@ type StackState struct {
 	// actually always only be the ones the operator has hard coded. No other
 	// version should be used here ever.
 	WorkerCloudConfigVersion string
+	// WorkerPools holds the state of every single node pool. In case it is
+	// empty the node pools are taken from the custom object.
+	WorkerPools []StackStateWorkerPool
 
 	VersionBundleVersion string
 }
 
+// StackStateMaster is the state of a single master instance. Masters are
+// replaced one at a time during updates, which is why every master carries its
+// own configuration.
+type StackStateMaster struct {
+	CloudConfigVersion       string
+	DockerVolumeResourceName string
+	ImageID                  string
+	InstanceResourceName     string
+	InstanceType             string
+	VersionBundleVersion     string
+}
+
+// StackStateWorkerPool is the state of a single node pool. Every node pool is
+// rendered as its own auto scaling group.
+type StackStateWorkerPool struct {
+	Count              int
+	DockerVolumeSizeGB int
+	ImageID            string
+	InstanceType       string
+	LaunchTemplateHash string
+	Name               string
+	Scaling            StackStateWorkerPoolScaling
+	Spot               StackStateWorkerPoolSpot
+}
+
+// StackStateWorkerPoolScaling holds the bounds of the ASG of a single node
+// pool. The desired capacity of node pools scaled by cluster-autoscaler is
+// left to cluster-autoscaler.
+type StackStateWorkerPoolScaling struct {
+	Enabled bool
+	Max     int
+	Min     int
+}
+
+// StackStateWorkerPoolSpot is the spot configuration of a single node pool.
+type StackStateWorkerPoolSpot struct {
+	Enabled bool
+	// InstanceTypes are all instance types the node pool may use, starting
+	// with the instance type of the node pool.
+	InstanceTypes                       []string
+	MaxPrice                            string
+	OnDemandBaseCapacity                int
+	OnDemandPercentageAboveBaseCapacity int
+}
+
 // CFClient describes the methods required to be implemented by a CloudFormation
 // AWS client.
 type CFClient interface {
 	CreateStack(*awscloudformation.CreateStackInput) (*awscloudformation.CreateStackOutput, error)
 	DeleteStack(*awscloudformation.DeleteStackInput) (*awscloudformation.DeleteStackOutput, error)
+	DescribeStackEvents(*awscloudformation.DescribeStackEventsInput) (*awscloudformation.DescribeStackEventsOutput, error)
 	DescribeStacks(*awscloudformation.DescribeStacksInput) (*awscloudformation.DescribeStacksOutput, error)
 	UpdateStack(*awscloudformation.UpdateStackInput) (*awscloudformation.UpdateStackOutput, error)
 	UpdateTerminationProtection(*awscloudformation.UpdateTerminationProtectionInput) (*awscloudformation.UpdateTerminationProtectionOutput, error)
@@ -146,11 +238,14 @@ type KMSClient interface {
 // SmallCloudconfigConfig represents the data structure required for executing
 // the small cloudconfig template.
 type SmallCloudconfigConfig struct {
-	Region    string
-	Registry  string
-	Role      string
-	S3HTTPURL string
-	S3URL     string
+	// EtcdMemberName is the name of the etcd member running on a master
+	// instance. It is only set for guest clusters running multiple masters.
+	EtcdMemberName string
+	Region         string
+	Registry       string
+	Role           string
+	S3HTTPURL      string
+	S3URL          string
 }
 
 // ELBClient describes the methods required to be implemented by a ELB AWS
@@ -0,0 +1,32 @@
+package ami
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// Builtin resolves AMIs from the Container Linux release built into the
+// operator.
+type Builtin struct {
+}
+
+func NewBuiltin() *Builtin {
+	return &Builtin{}
+}
+
+func (b *Builtin) ImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	imageID, err := key.ImageID(customObject)
+	if err != nil {
+		return "", microerror.Maskf(imageNotFoundError, "%s", err.Error())
+	}
+
+	return imageID, nil
+}
+
+func (b *Builtin) Source() string {
+	return ""
+}
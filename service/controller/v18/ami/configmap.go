@@ -0,0 +1,91 @@
+package ami
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+type ConfigMapConfig struct {
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	Name      string
+	Namespace string
+}
+
+// ConfigMap resolves AMIs from a config map mapping regions to AMI IDs, e.g.
+//
+//     data:
+//       eu-central-1: ami-32042fd9
+//       eu-north-1: ami-0ab45b7cd3d3fd8b3
+//
+// The config map is read whenever an AMI is resolved, so that AMIs can be
+// changed without restarting the operator.
+type ConfigMap struct {
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+
+	name      string
+	namespace string
+}
+
+func NewConfigMap(config ConfigMapConfig) (*ConfigMap, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Name == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Name must not be empty", config)
+	}
+	if config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Namespace must not be empty", config)
+	}
+
+	c := &ConfigMap{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		name:      config.Name,
+		namespace: config.Namespace,
+	}
+
+	return c, nil
+}
+
+func (c *ConfigMap) ImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	region := key.Region(customObject)
+
+	c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding the AMI for region '%s' in config map '%s/%s'", region, c.namespace, c.name))
+
+	cm, err := c.k8sClient.CoreV1().ConfigMaps(c.namespace).Get(c.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", microerror.Maskf(imageNotFoundError, "config map '%s/%s' does not exist", c.namespace, c.name)
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	imageID, ok := cm.Data[region]
+	if !ok || imageID == "" {
+		return "", microerror.Maskf(imageNotFoundError, "no image id for region '%s' in config map '%s/%s'", region, c.namespace, c.name)
+	}
+
+	c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found the AMI '%s' for region '%s' in config map '%s/%s'", imageID, region, c.namespace, c.name))
+
+	return imageID, nil
+}
+
+func (c *ConfigMap) Source() string {
+	return ""
+}
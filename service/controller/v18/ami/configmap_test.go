@@ -0,0 +1,111 @@
+package ami
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_ConfigMap_ImageID(t *testing.T) {
+	testCases := []struct {
+		description     string
+		objects         []runtime.Object
+		region          string
+		expectedImageID string
+		errorMatcher    func(error) bool
+	}{
+		{
+			description: "image of the region",
+			objects: []runtime.Object{
+				newTestConfigMap(map[string]string{
+					"eu-central-1": "ami-32042fd9",
+					"eu-north-1":   "ami-0ab45b7cd3d3fd8b3",
+				}),
+			},
+			region:          "eu-north-1",
+			expectedImageID: "ami-0ab45b7cd3d3fd8b3",
+			errorMatcher:    nil,
+		},
+		{
+			description: "no image for the region",
+			objects: []runtime.Object{
+				newTestConfigMap(map[string]string{
+					"eu-central-1": "ami-32042fd9",
+				}),
+			},
+			region:          "eu-north-1",
+			expectedImageID: "",
+			errorMatcher:    IsImageNotFound,
+		},
+		{
+			description:     "no config map",
+			objects:         nil,
+			region:          "eu-central-1",
+			expectedImageID: "",
+			errorMatcher:    IsImageNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var err error
+
+			var catalog *ConfigMap
+			{
+				c := ConfigMapConfig{
+					K8sClient: fake.NewSimpleClientset(tc.objects...),
+					Logger:    microloggertest.New(),
+
+					Name:      "aws-operator-ami-catalog",
+					Namespace: "giantswarm",
+				}
+
+				catalog, err = NewConfigMap(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Region: tc.region,
+					},
+				},
+			}
+
+			imageID, err := catalog.ImageID(context.Background(), customObject)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if imageID != tc.expectedImageID {
+				t.Fatalf("expected %q got %q", tc.expectedImageID, imageID)
+			}
+		})
+	}
+}
+
+func newTestConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "aws-operator-ami-catalog",
+			Namespace: "giantswarm",
+		},
+		Data: data,
+	}
+}
@@ -0,0 +1,136 @@
+package ami
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+type EC2Config struct {
+	Logger micrologger.Logger
+
+	// NamePattern filters the AMIs by name, e.g. CoreOS-stable-*-hvm.
+	NamePattern string
+	// Owner is the ID of the AWS account owning the AMIs, e.g. 595879546273
+	// for Container Linux.
+	Owner string
+}
+
+// EC2 resolves AMIs by querying the images of an owner in the region of the
+// guest cluster. The most recently created image matching the name pattern is
+// used. New releases are only picked up by guest clusters when they are
+// updated to another version bundle version, see Source.
+type EC2 struct {
+	logger micrologger.Logger
+
+	namePattern string
+	owner       string
+}
+
+func NewEC2(config EC2Config) (*EC2, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.NamePattern == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.NamePattern must not be empty", config)
+	}
+	if config.Owner == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Owner must not be empty", config)
+	}
+
+	e := &EC2{
+		logger: config.Logger,
+
+		namePattern: config.NamePattern,
+		owner:       config.Owner,
+	}
+
+	return e, nil
+}
+
+func (e *EC2) ImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	region := key.Region(customObject)
+
+	e.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding the AMI for region '%s' in the AWS API", region))
+
+	i := &ec2.DescribeImagesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("architecture"),
+				Values: aws.StringSlice([]string{"x86_64"}),
+			},
+			{
+				Name:   aws.String("name"),
+				Values: aws.StringSlice([]string{e.namePattern}),
+			},
+			{
+				Name:   aws.String("state"),
+				Values: aws.StringSlice([]string{ec2.ImageStateAvailable}),
+			},
+			{
+				Name:   aws.String("virtualization-type"),
+				Values: aws.StringSlice([]string{ec2.VirtualizationTypeHvm}),
+			},
+		},
+		Owners: aws.StringSlice([]string{e.owner}),
+	}
+
+	o, err := sc.AWSClient.EC2.DescribeImages(i)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	imageID, err := latestImageID(o.Images)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if imageID == "" {
+		return "", microerror.Maskf(imageNotFoundError, "no image of owner '%s' matching '%s' in region '%s'", e.owner, e.namePattern, region)
+	}
+
+	e.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found the AMI '%s' for region '%s' in the AWS API", imageID, region))
+
+	return imageID, nil
+}
+
+// Source identifies the owner and the name pattern of the images, since new
+// images are published for them over time.
+func (e *EC2) Source() string {
+	return fmt.Sprintf("ec2/%s/%s", e.owner, e.namePattern)
+}
+
+// latestImageID returns the ID of the most recently created image. The ID is
+// empty in case no image is given.
+func latestImageID(images []*ec2.Image) (string, error) {
+	var imageID string
+	var latest time.Time
+
+	for _, i := range images {
+		created, err := time.Parse(time.RFC3339, aws.StringValue(i.CreationDate))
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		if imageID == "" || created.After(latest) {
+			imageID = aws.StringValue(i.ImageId)
+			latest = created
+		}
+	}
+
+	return imageID, nil
+}
@@ -0,0 +1,53 @@
+package ami
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func Test_latestImageID(t *testing.T) {
+	testCases := []struct {
+		description     string
+		images          []*ec2.Image
+		expectedImageID string
+	}{
+		{
+			description:     "no images",
+			images:          nil,
+			expectedImageID: "",
+		},
+		{
+			description: "most recently created image",
+			images: []*ec2.Image{
+				{
+					CreationDate: aws.String("2018-06-04T21:04:54.000Z"),
+					ImageId:      aws.String("ami-32042fd9"),
+				},
+				{
+					CreationDate: aws.String("2018-11-27T23:39:18.000Z"),
+					ImageId:      aws.String("ami-0ab45b7cd3d3fd8b3"),
+				},
+				{
+					CreationDate: aws.String("2018-09-12T20:15:31.000Z"),
+					ImageId:      aws.String("ami-0fd5ca9a8ec4de4fb"),
+				},
+			},
+			expectedImageID: "ami-0ab45b7cd3d3fd8b3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := latestImageID(tc.images)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			if imageID != tc.expectedImageID {
+				t.Fatalf("expected %q got %q", tc.expectedImageID, imageID)
+			}
+		})
+	}
+}
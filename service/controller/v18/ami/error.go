@@ -0,0 +1,23 @@
+package ami
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var imageNotFoundError = &microerror.Error{
+	Kind: "imageNotFoundError",
+}
+
+// IsImageNotFound asserts imageNotFoundError.
+func IsImageNotFound(err error) bool {
+	return microerror.Cause(err) == imageNotFoundError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
@@ -0,0 +1,27 @@
+package ami
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+)
+
+const (
+	BuiltinCatalog   = "builtin"
+	ConfigMapCatalog = "configmap"
+	EC2Catalog       = "ec2"
+)
+
+// Interface resolves the AMIs guest cluster nodes are launched from when the
+// custom object does not configure any AMI for them.
+type Interface interface {
+	// ImageID returns the ID of the AMI to launch guest cluster nodes from in
+	// the region of the given guest cluster.
+	ImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error)
+	// Source identifies the configuration of catalogs whose AMIs change
+	// without their configuration changing. AMIs resolved from such catalogs
+	// are kept for a guest cluster as long as its version bundle version and
+	// the source do not change. Source is empty for catalogs which resolve
+	// AMIs from their configuration alone.
+	Source() string
+}
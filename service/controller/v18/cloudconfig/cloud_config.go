@@ -13,6 +13,14 @@ const (
 	FileOwner          = "root:root"
 	FilePermission     = 0700
 	GzipBase64Encoding = "gzip+base64"
+
+	// decrypterImageName and decrypterImageTag define the operator image
+	// providing the decrypt command guest cluster nodes decrypt their assets
+	// with. The image is pinned to the operator release of this version bundle,
+	// so that bootstrapping nodes does not depend on the build of the operator
+	// reconciling the guest cluster.
+	decrypterImageName = "giantswarm/aws-operator"
+	decrypterImageTag  = "4.3.0"
 )
 
 // Config represents the configuration used to create a cloud config service.
@@ -31,6 +39,7 @@ type CloudConfig struct {
 	encrypter encrypter.Interface
 	logger    micrologger.Logger
 
+	decrypterImage      string
 	k8sAPIExtraArgs     []string
 	k8sKubeletExtraArgs []string
 	registryDomain      string
@@ -84,6 +93,7 @@ func New(config Config) (*CloudConfig, error) {
 		encrypter: config.Encrypter,
 		logger:    config.Logger,
 
+		decrypterImage:      fmt.Sprintf("%s/%s:%s", config.RegistryDomain, decrypterImageName, decrypterImageTag),
 		k8sAPIExtraArgs:     k8sAPIExtraArgs,
 		k8sKubeletExtraArgs: k8sKubeletExtraArgs,
 		registryDomain:      config.RegistryDomain,
@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
 	"encoding/base64"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
 	"testing"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/certs"
 	"github.com/giantswarm/legacycerts/legacy"
 	"github.com/giantswarm/micrologger/microloggertest"
 	"github.com/giantswarm/randomkeys"
@@ -51,7 +56,7 @@ func Test_Service_CloudConfig_NewMasterTemplate(t *testing.T) {
 		if err != nil {
 			t.Fatalf("expected %#v got %#v", nil, err)
 		}
-		template, err := ccService.NewMasterTemplate(context.TODO(), tc.CustomObject, tc.Certs, tc.ClusterKeys)
+		template, err := ccService.NewMasterTemplate(context.TODO(), tc.CustomObject, tc.Certs, certs.TLS{}, tc.ClusterKeys)
 		if err != nil {
 			t.Fatalf("expected %#v got %#v", nil, err)
 		}
@@ -86,11 +91,113 @@ func Test_Service_CloudConfig_NewMasterTemplate(t *testing.T) {
 		})
 
 		t.Run("VerifyAPIServerEncryptionKey", func(t *testing.T) {
-			if !strings.Contains(decoded, "H4sIAAAAAAAA/1SNMQ7CMAxF957CF+jQNSviCuwldYgVYTd2aBQh7o4CVREeLL33pf8T8eLgzF7bWkj4JBzoNswrXVCNhB1s06Bo8lCP5gaAEf6wC0OvWOxDq8pGC+oRzmj+6r/UL2GzH43A8x1dt9MhYW90EDDFQFUoR6EQa8YglGv/KceKYR+hBblQaQ6er3cAAAD//9QjGEbUAAAA") {
+			plaintext, err := testDecryptFile(decoded, "/etc/kubernetes/encryption/k8s-encryption-config.yaml.enc")
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+			if !strings.Contains(plaintext, "secret: fekhfiwoiqhoifhwqefoiqwefoikqhwef") {
 				t.Fatalf("expected %#v got %#v", "cloud config to contain apiserver encryption config", "wrong config output")
 			}
 		})
+
+		t.Run("VerifyDataKey", func(t *testing.T) {
+			if !strings.Contains(decoded, encrypter.MockDataKey.Ciphertext) {
+				t.Fatalf("expected %#v got %#v", "cloud config to contain data key", "none")
+			}
+		})
+
+		t.Run("VerifyDecrypterImage", func(t *testing.T) {
+			if !strings.Contains(decoded, "docker://quay.io/giantswarm/aws-operator:4.3.0") {
+				t.Fatalf("expected %#v got %#v", "cloud config to contain decrypter image", "none")
+			}
+			if strings.Contains(decoded, "openssl enc") {
+				t.Fatalf("expected %#v got %#v", "cloud config to not decrypt assets with openssl", "openssl enc")
+			}
+		})
+	}
+}
+
+func Test_Service_CloudConfig_NewMasterTemplate_EtcdCluster(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+					API: v1alpha1.AWSConfigSpecAWSHostedZonesZone{
+						Name: "gauss.eu-central-1.aws.gigantic.io",
+					},
+				},
+				Masters: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+					{},
+					{},
+				},
+			},
+			Cluster: v1alpha1.Cluster{
+				ID: "al9qy",
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+					Port:   2379,
+				},
+			},
+		},
+	}
+	etcdPeerTLS := certs.TLS{
+		CA:  []byte("123456789-super-magic-etcd-peer-ca"),
+		Crt: []byte("123456789-super-magic-etcd-peer-crt"),
+		Key: []byte("123456789-super-magic-etcd-peer-key"),
 	}
+
+	ccService, err := testNewCloudConfigService()
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+	template, err := ccService.NewMasterTemplate(context.TODO(), customObject, legacy.CompactTLSAssets{}, etcdPeerTLS, randomkeys.Cluster{})
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	decoded, err := testDecodeTemplate(template)
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	t.Run("VerifyEtcdPeerTLS", func(t *testing.T) {
+		files := map[string][]byte{
+			"/etc/kubernetes/ssl/etcd/peer-ca.pem.enc":  etcdPeerTLS.CA,
+			"/etc/kubernetes/ssl/etcd/peer-crt.pem.enc": etcdPeerTLS.Crt,
+			"/etc/kubernetes/ssl/etcd/peer-key.pem.enc": etcdPeerTLS.Key,
+		}
+		for path, expected := range files {
+			plaintext, err := testDecryptFile(decoded, path)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+			if plaintext != string(expected) {
+				t.Fatalf("expected %#v got %#v", string(expected), plaintext)
+			}
+		}
+	})
+
+	t.Run("VerifyEtcdClusterConf", func(t *testing.T) {
+		expected := []string{
+			"--peer-cert-file /etc/etcd/peer-crt.pem",
+			"--initial-cluster etcd0=https://etcd0.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io:2380,etcd1=https://etcd1.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io:2380,etcd2=https://etcd2.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io:2380",
+			"--initial-cluster-state ${ETCD_INITIAL_CLUSTER_STATE}",
+			"ExecStartPre=/opt/bin/etcd-cluster-state",
+		}
+		for _, e := range expected {
+			if !strings.Contains(decoded, e) {
+				t.Fatalf("expected %#v got %#v", fmt.Sprintf("cloud config to contain %q", e), "none")
+			}
+		}
+	})
+
+	t.Run("VerifyEtcdClusterStateScript", func(t *testing.T) {
+		if !strings.Contains(decoded, "--endpoints https://etcd.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io:2379") {
+			t.Fatalf("expected %#v got %#v", "cloud config to contain etcd cluster state script", "none")
+		}
+	})
 }
 
 func Test_Service_CloudConfig_NewWorkerTemplate(t *testing.T) {
@@ -185,6 +292,60 @@ func testDecodeTemplate(template string) (string, error) {
 	return b.String(), nil
 }
 
+// testDecryptFile finds the gzip+base64 encoded content of the file with the
+// given path in the decoded cloud config and decrypts it using the mock data
+// key.
+func testDecryptFile(decoded string, path string) (string, error) {
+	lines := strings.Split(decoded, "\n")
+
+	var content string
+	for i, l := range lines {
+		if strings.TrimSpace(l) != "- path: "+path {
+			continue
+		}
+		// The gzip header of the content is the first line starting with H4sI.
+		for _, l := range lines[i+1:] {
+			if strings.HasPrefix(strings.TrimSpace(l), "H4sI") {
+				content = strings.TrimSpace(l)
+				break
+			}
+		}
+		break
+	}
+	if content == "" {
+		return "", fmt.Errorf("content of %q not found", path)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(encrypter.MockDataKey.Plaintext)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := ciphertext[:gcm.NonceSize()]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
 func testNewCloudConfigService() (*CloudConfig, error) {
 	var err error
 
@@ -0,0 +1,50 @@
+package cloudconfig
+
+import (
+	"github.com/giantswarm/certs"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter"
+)
+
+// EtcdPeerTLSTmplSet holds the etcd peer certificate of guest clusters with
+// multiple masters encrypted using the cluster's data key.
+type EtcdPeerTLSTmplSet struct {
+	CA  string
+	Crt string
+	Key string
+}
+
+func renderEtcdPeerTLSTmplSet(dataKey encrypter.DataKey, etcdPeerTLS certs.TLS) (EtcdPeerTLSTmplSet, error) {
+	seal := func(data []byte) (string, error) {
+		enc, err := encrypter.Seal(dataKey, data)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		com, err := compactor(enc)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		return com, nil
+	}
+
+	var err error
+	var etcdPeerTLSTmplSet EtcdPeerTLSTmplSet
+
+	etcdPeerTLSTmplSet.CA, err = seal(etcdPeerTLS.CA)
+	if err != nil {
+		return EtcdPeerTLSTmplSet{}, microerror.Mask(err)
+	}
+	etcdPeerTLSTmplSet.Crt, err = seal(etcdPeerTLS.Crt)
+	if err != nil {
+		return EtcdPeerTLSTmplSet{}, microerror.Mask(err)
+	}
+	etcdPeerTLSTmplSet.Key, err = seal(etcdPeerTLS.Key)
+	if err != nil {
+		return EtcdPeerTLSTmplSet{}, microerror.Mask(err)
+	}
+
+	return etcdPeerTLSTmplSet, nil
+}
@@ -4,35 +4,51 @@ import (
 	"context"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/certs"
 	k8scloudconfig "github.com/giantswarm/k8scloudconfig/v_3_6_1"
 	"github.com/giantswarm/legacycerts/legacy"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/randomkeys"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter/vault"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 	"github.com/giantswarm/aws-operator/service/controller/v18/templates/cloudconfig"
 )
 
 // NewMasterTemplate generates a new master cloud config template and returns it
 // as a base64 encoded string.
-func (c *CloudConfig) NewMasterTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, certs legacy.CompactTLSAssets, clusterKeys randomkeys.Cluster) (string, error) {
+func (c *CloudConfig) NewMasterTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts legacy.CompactTLSAssets, etcdPeerTLS certs.TLS, clusterKeys randomkeys.Cluster) (string, error) {
 	var err error
 
 	encryptionKey, err := c.encrypter.EncryptionKey(ctx, customObject)
 	if err != nil {
 		return "", microerror.Mask(err)
 	}
-	randomKeyTmplSet, err := renderRandomKeyTmplSet(ctx, c.encrypter, encryptionKey, clusterKeys)
+	dataKey, err := c.encrypter.DataKey(ctx, encryptionKey)
 	if err != nil {
 		return "", microerror.Mask(err)
 	}
+	randomKeyTmplSet, err := renderRandomKeyTmplSet(dataKey, clusterKeys)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var etcdPeerTLSTmplSet EtcdPeerTLSTmplSet
+	if key.MasterCount(customObject) > 1 {
+		etcdPeerTLSTmplSet, err = renderEtcdPeerTLSTmplSet(dataKey, etcdPeerTLS)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+	}
 
 	var params k8scloudconfig.Params
 	{
 		be := baseExtension{
-			customObject:  customObject,
-			encrypter:     c.encrypter,
-			encryptionKey: encryptionKey,
+			customObject:   customObject,
+			dataKey:        dataKey.Ciphertext,
+			decrypterImage: c.decrypterImage,
+			encrypter:      c.encrypter,
+			encryptionKey:  encryptionKey,
 		}
 
 		params.Cluster = customObject.Spec.Cluster
@@ -44,9 +60,10 @@ func (c *CloudConfig) NewMasterTemplate(ctx context.Context, customObject v1alph
 		params.DisableIngressControllerService = false
 		params.EtcdPort = customObject.Spec.Cluster.Etcd.Port
 		params.Extension = &MasterExtension{
-			certs:            certs,
-			baseExtension:    be,
-			RandomKeyTmplSet: randomKeyTmplSet,
+			certs:              clusterCerts,
+			baseExtension:      be,
+			EtcdPeerTLSTmplSet: etcdPeerTLSTmplSet,
+			RandomKeyTmplSet:   randomKeyTmplSet,
 		}
 		params.Hyperkube.Apiserver.Pod.CommandExtraArgs = c.k8sAPIExtraArgs
 		params.Hyperkube.Kubelet.Docker.CommandExtraArgs = c.k8sKubeletExtraArgs
@@ -75,7 +92,7 @@ func (c *CloudConfig) NewMasterTemplate(ctx context.Context, customObject v1alph
 }
 
 // RandomKeyTmplSet holds a collection of rendered templates for random key
-// encryption via KMS.
+// encryption using the cluster's data key.
 type RandomKeyTmplSet struct {
 	APIServerEncryptionKey string
 }
@@ -83,8 +100,9 @@ type RandomKeyTmplSet struct {
 type MasterExtension struct {
 	baseExtension
 
-	certs            legacy.CompactTLSAssets
-	RandomKeyTmplSet RandomKeyTmplSet
+	certs              legacy.CompactTLSAssets
+	EtcdPeerTLSTmplSet EtcdPeerTLSTmplSet
+	RandomKeyTmplSet   RandomKeyTmplSet
 }
 
 func (e *MasterExtension) Files() ([]k8scloudconfig.FileAsset, error) {
@@ -95,6 +113,12 @@ func (e *MasterExtension) Files() ([]k8scloudconfig.FileAsset, error) {
 			Owner:        FileOwner,
 			Permissions:  FilePermission,
 		},
+		{
+			AssetContent: cloudconfig.DataKey,
+			Path:         "/etc/kubernetes/data-key.enc",
+			Owner:        FileOwner,
+			Permissions:  FilePermission,
+		},
 		{
 			AssetContent: e.certs.APIServerCrt,
 			Path:         "/etc/kubernetes/ssl/apiserver-crt.pem.enc",
@@ -245,6 +269,48 @@ func (e *MasterExtension) Files() ([]k8scloudconfig.FileAsset, error) {
 		},
 	}
 
+	// Guest clusters with multiple masters run etcd as a cluster spanning all
+	// masters. The etcd members authenticate each other using the etcd peer
+	// certificate, which is issued for the domains of all etcd members.
+	if key.MasterCount(e.customObject) > 1 {
+		fms := []k8scloudconfig.FileMetadata{
+			{
+				AssetContent: e.EtcdPeerTLSTmplSet.CA,
+				Path:         "/etc/kubernetes/ssl/etcd/peer-ca.pem.enc",
+				Owner:        FileOwner,
+				Encoding:     GzipBase64Encoding,
+				Permissions:  FilePermission,
+			},
+			{
+				AssetContent: e.EtcdPeerTLSTmplSet.Crt,
+				Path:         "/etc/kubernetes/ssl/etcd/peer-crt.pem.enc",
+				Owner:        FileOwner,
+				Encoding:     GzipBase64Encoding,
+				Permissions:  FilePermission,
+			},
+			{
+				AssetContent: e.EtcdPeerTLSTmplSet.Key,
+				Path:         "/etc/kubernetes/ssl/etcd/peer-key.pem.enc",
+				Owner:        FileOwner,
+				Encoding:     GzipBase64Encoding,
+				Permissions:  FilePermission,
+			},
+			{
+				AssetContent: cloudconfig.EtcdClusterStateScript,
+				Path:         "/opt/bin/etcd-cluster-state",
+				Owner:        FileOwner,
+				Permissions:  FilePermission,
+			},
+			{
+				AssetContent: cloudconfig.EtcdClusterConf,
+				Path:         "/etc/systemd/system/etcd3.service.d/10-etcd-cluster.conf",
+				Owner:        FileOwner,
+				Permissions:  0644,
+			},
+		}
+		filesMeta = append(filesMeta, fms...)
+	}
+
 	var newFiles []k8scloudconfig.FileAsset
 
 	for _, fm := range filesMeta {
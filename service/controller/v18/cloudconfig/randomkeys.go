@@ -2,7 +2,6 @@ package cloudconfig
 
 import (
 	"bytes"
-	"context"
 	"text/template"
 
 	"github.com/giantswarm/microerror"
@@ -12,7 +11,7 @@ import (
 	"github.com/giantswarm/aws-operator/service/controller/v18/templates/cloudconfig"
 )
 
-func renderRandomKeyTmplSet(ctx context.Context, encrypter encrypter.Interface, key string, clusterKeys randomkeys.Cluster) (RandomKeyTmplSet, error) {
+func renderRandomKeyTmplSet(dataKey encrypter.DataKey, clusterKeys randomkeys.Cluster) (RandomKeyTmplSet, error) {
 	var randomKeyTmplSet RandomKeyTmplSet
 	{
 		tmpl, err := template.New("encryption-config").Parse(cloudconfig.EncryptionConfig)
@@ -29,12 +28,12 @@ func renderRandomKeyTmplSet(ctx context.Context, encrypter encrypter.Interface,
 			return RandomKeyTmplSet{}, microerror.Mask(err)
 		}
 
-		enc, err := encrypter.Encrypt(ctx, key, buf.String())
+		enc, err := encrypter.Seal(dataKey, buf.Bytes())
 		if err != nil {
 			return RandomKeyTmplSet{}, microerror.Mask(err)
 		}
 
-		com, err := compactor([]byte(enc))
+		com, err := compactor(enc)
 		if err != nil {
 			return RandomKeyTmplSet{}, microerror.Mask(err)
 		}
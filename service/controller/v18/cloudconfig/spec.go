@@ -11,32 +11,53 @@ import (
 // AWSConfigSpec.
 type TemplateData struct {
 	v1alpha1.AWSConfigSpec
-	EncrypterType string
-	VaultAddress  string
-	EncryptionKey string
+	DataKey               string
+	DecrypterImage        string
+	EncrypterType         string
+	VaultAddress          string
+	VaultAWSMountPath     string
+	VaultNamespace        string
+	VaultTransitMountPath string
+	EncryptionKey         string
 }
 
 type baseExtension struct {
-	customObject  v1alpha1.AWSConfig
-	encrypter     encrypter.Interface
-	encryptionKey string
+	customObject v1alpha1.AWSConfig
+	// dataKey is the ciphertext of the data key the assets are encrypted with.
+	dataKey string
+	// decrypterImage is the image providing the decrypt command the assets are
+	// decrypted with.
+	decrypterImage string
+	encrypter      encrypter.Interface
+	encryptionKey  string
 }
 
 func (e *baseExtension) templateData() TemplateData {
 	var encrypterType string
 	var vaultAddress string
+	var vaultAWSMountPath string
+	var vaultNamespace string
+	var vaultTransitMountPath string
 	v, ok := e.encrypter.(*vault.Encrypter)
 	if ok {
 		encrypterType = encrypter.VaultBackend
 		vaultAddress = v.Address()
+		vaultAWSMountPath = v.AWSMountPath()
+		vaultNamespace = v.Namespace()
+		vaultTransitMountPath = v.TransitMountPath()
 	} else {
 		encrypterType = encrypter.KMSBackend
 	}
 	data := TemplateData{
-		AWSConfigSpec: e.customObject.Spec,
-		EncrypterType: encrypterType,
-		VaultAddress:  vaultAddress,
-		EncryptionKey: e.encryptionKey,
+		AWSConfigSpec:         e.customObject.Spec,
+		DataKey:               e.dataKey,
+		DecrypterImage:        e.decrypterImage,
+		EncrypterType:         encrypterType,
+		VaultAddress:          vaultAddress,
+		VaultAWSMountPath:     vaultAWSMountPath,
+		VaultNamespace:        vaultNamespace,
+		VaultTransitMountPath: vaultTransitMountPath,
+		EncryptionKey:         e.encryptionKey,
 	}
 
 	return data
@@ -20,13 +20,19 @@ func (c *CloudConfig) NewWorkerTemplate(ctx context.Context, customObject v1alph
 	if err != nil {
 		return "", microerror.Mask(err)
 	}
+	dataKey, err := c.encrypter.DataKey(ctx, encryptionKey)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
 
 	var params k8scloudconfig.Params
 	{
 		be := baseExtension{
-			customObject:  customObject,
-			encrypter:     c.encrypter,
-			encryptionKey: encryptionKey,
+			customObject:   customObject,
+			dataKey:        dataKey.Ciphertext,
+			decrypterImage: c.decrypterImage,
+			encrypter:      c.encrypter,
+			encryptionKey:  encryptionKey,
 		}
 
 		params.Cluster = customObject.Spec.Cluster
@@ -72,6 +78,12 @@ func (e *WorkerExtension) Files() ([]k8scloudconfig.FileAsset, error) {
 			Owner:        "root:root",
 			Permissions:  0700,
 		},
+		{
+			AssetContent: cloudconfig.DataKey,
+			Path:         "/etc/kubernetes/data-key.enc",
+			Owner:        "root:root",
+			Permissions:  0700,
+		},
 		{
 			AssetContent: e.certs.WorkerCrt,
 			Path:         "/etc/kubernetes/ssl/worker-crt.pem.enc",
@@ -1,11 +1,19 @@
 package cloudformation
 
 import (
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/microerror"
 )
 
+const (
+	// failedStatusSuffix is the suffix of the statuses of failed resources, e.g.
+	// CREATE_FAILED or UPDATE_FAILED.
+	failedStatusSuffix = "_FAILED"
+)
+
 type Config struct {
 	Client CloudFormationInterface
 }
@@ -83,6 +91,75 @@ func (c *CloudFormation) DescribeOutputsAndStatus(stackName string) ([]*cloudfor
 	return stackOutputs, stackStatus, nil
 }
 
+// DescribeFailure returns the first failure of the latest operation of the
+// given stack. Stack events are listed newest first, so we walk the events
+// back to the event starting the latest operation and keep the oldest failed
+// resource we come across. Resources failing later on are usually only
+// canceled because of the first failure. In case no resource failed, the
+// reason of the stack rolling back is returned. The error is matched by
+// IsFailureNotFound in case the latest operation did not fail at all.
+func (c *CloudFormation) DescribeFailure(stackName string) (StackFailure, error) {
+	var resourceFailure *cloudformation.StackEvent
+	var stackFailure *cloudformation.StackEvent
+	{
+		i := &cloudformation.DescribeStackEventsInput{
+			StackName: aws.String(stackName),
+		}
+
+		var done bool
+		for !done {
+			o, err := c.client.DescribeStackEvents(i)
+			if IsStackNotFound(err) {
+				return StackFailure{}, microerror.Maskf(stackNotFoundError, "stack name '%s'", stackName)
+			} else if err != nil {
+				return StackFailure{}, microerror.Mask(err)
+			}
+
+			for _, e := range o.StackEvents {
+				status := aws.StringValue(e.ResourceStatus)
+
+				if isStackEvent(e) {
+					if isOperationStartStatus(status) {
+						done = true
+						break
+					}
+					if isRollbackStartStatus(status) {
+						stackFailure = e
+					}
+					continue
+				}
+
+				if strings.HasSuffix(status, failedStatusSuffix) {
+					resourceFailure = e
+				}
+			}
+
+			if o.NextToken == nil {
+				break
+			}
+			i.NextToken = o.NextToken
+		}
+	}
+
+	var f *cloudformation.StackEvent
+	if resourceFailure != nil {
+		f = resourceFailure
+	} else if stackFailure != nil {
+		f = stackFailure
+	} else {
+		return StackFailure{}, microerror.Maskf(failureNotFoundError, "stack name '%s'", stackName)
+	}
+
+	failure := StackFailure{
+		LogicalResourceID:    aws.StringValue(f.LogicalResourceId),
+		ResourceStatus:       aws.StringValue(f.ResourceStatus),
+		ResourceStatusReason: aws.StringValue(f.ResourceStatusReason),
+		ResourceType:         aws.StringValue(f.ResourceType),
+	}
+
+	return failure, nil
+}
+
 func (c *CloudFormation) GetOutputValue(outputs []*cloudformation.Output, key string) (string, error) {
 	for _, o := range outputs {
 		if *o.OutputKey == key {
@@ -92,3 +169,42 @@ func (c *CloudFormation) GetOutputValue(outputs []*cloudformation.Output, key st
 
 	return "", microerror.Maskf(outputNotFoundError, "stack output value for key '%s'", key)
 }
+
+// IsFailedStatus returns true for stack statuses in which the latest
+// operation of a stack failed and which the stack does not leave on its own.
+func IsFailedStatus(status string) bool {
+	failedStatuses := []string{
+		cloudformation.StackStatusCreateFailed,
+		cloudformation.StackStatusDeleteFailed,
+		cloudformation.StackStatusRollbackComplete,
+		cloudformation.StackStatusRollbackFailed,
+		cloudformation.StackStatusUpdateRollbackComplete,
+		cloudformation.StackStatusUpdateRollbackFailed,
+	}
+
+	for _, s := range failedStatuses {
+		if status == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isOperationStartStatus(status string) bool {
+	return status == cloudformation.ResourceStatusCreateInProgress ||
+		status == cloudformation.ResourceStatusDeleteInProgress ||
+		status == cloudformation.ResourceStatusUpdateInProgress
+}
+
+// isStackEvent returns true for events describing the stack itself rather than
+// one of its resources. Events of nested stacks have the same resource type as
+// the stack, so we compare the physical ID of the resource with the stack ID.
+func isStackEvent(e *cloudformation.StackEvent) bool {
+	return aws.StringValue(e.PhysicalResourceId) == aws.StringValue(e.StackId)
+}
+
+func isRollbackStartStatus(status string) bool {
+	return status == cloudformation.StackStatusRollbackInProgress ||
+		status == cloudformation.StackStatusUpdateRollbackInProgress
+}
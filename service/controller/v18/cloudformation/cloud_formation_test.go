@@ -0,0 +1,177 @@
+package cloudformation
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+type cloudFormationClientMock struct {
+	stackEvents [][]*cloudformation.StackEvent
+}
+
+func (c *cloudFormationClientMock) DescribeStackEvents(input *cloudformation.DescribeStackEventsInput) (*cloudformation.DescribeStackEventsOutput, error) {
+	var page int
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(aws.StringValue(input.NextToken))
+	}
+
+	o := &cloudformation.DescribeStackEventsOutput{
+		StackEvents: c.stackEvents[page],
+	}
+	if page+1 < len(c.stackEvents) {
+		o.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+
+	return o, nil
+}
+
+func (c *cloudFormationClientMock) DescribeStacks(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+	return nil, nil
+}
+
+func Test_CloudFormation_DescribeFailure(t *testing.T) {
+	testCases := []struct {
+		description     string
+		stackEvents     [][]*cloudformation.StackEvent
+		expectedFailure StackFailure
+		errorMatcher    func(error) bool
+	}{
+		{
+			description: "first failed resource of a rolled back creation",
+			stackEvents: [][]*cloudformation.StackEvent{
+				{
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "ROLLBACK_COMPLETE", ""),
+					newTestStackEvent("MasterInstance", "AWS::EC2::Instance", "DELETE_COMPLETE", ""),
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "ROLLBACK_IN_PROGRESS", "The following resource(s) failed to create: [MasterInstance, EtcdVolume]."),
+					newTestStackEvent("EtcdVolume", "AWS::EC2::Volume", "CREATE_FAILED", "Resource creation cancelled"),
+					newTestStackEvent("MasterInstance", "AWS::EC2::Instance", "CREATE_FAILED", "Instance limit exceeded"),
+				},
+				{
+					newTestStackEvent("MasterInstance", "AWS::EC2::Instance", "CREATE_IN_PROGRESS", ""),
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "CREATE_IN_PROGRESS", "User Initiated"),
+				},
+			},
+			expectedFailure: StackFailure{
+				LogicalResourceID:    "MasterInstance",
+				ResourceStatus:       "CREATE_FAILED",
+				ResourceStatusReason: "Instance limit exceeded",
+				ResourceType:         "AWS::EC2::Instance",
+			},
+			errorMatcher: nil,
+		},
+		{
+			description: "failures of earlier operations are ignored",
+			stackEvents: [][]*cloudformation.StackEvent{
+				{
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "UPDATE_ROLLBACK_COMPLETE", ""),
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "UPDATE_ROLLBACK_IN_PROGRESS", "Parameter validation failed"),
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "UPDATE_IN_PROGRESS", "User Initiated"),
+					newTestStackEvent("workerAutoScalingGroup", "AWS::AutoScaling::AutoScalingGroup", "UPDATE_FAILED", "Received 0 SUCCESS signal(s)"),
+				},
+			},
+			expectedFailure: StackFailure{
+				LogicalResourceID:    "cluster-al9qy-guest-main",
+				ResourceStatus:       "UPDATE_ROLLBACK_IN_PROGRESS",
+				ResourceStatusReason: "Parameter validation failed",
+				ResourceType:         "AWS::CloudFormation::Stack",
+			},
+			errorMatcher: nil,
+		},
+		{
+			description: "no failure of a successful operation",
+			stackEvents: [][]*cloudformation.StackEvent{
+				{
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "UPDATE_COMPLETE", ""),
+					newTestStackEvent("workerAutoScalingGroup", "AWS::AutoScaling::AutoScalingGroup", "UPDATE_COMPLETE", ""),
+					newTestStackEvent("cluster-al9qy-guest-main", "AWS::CloudFormation::Stack", "UPDATE_IN_PROGRESS", "User Initiated"),
+				},
+			},
+			expectedFailure: StackFailure{},
+			errorMatcher:    IsFailureNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var err error
+
+			var cf *CloudFormation
+			{
+				c := Config{
+					Client: &cloudFormationClientMock{
+						stackEvents: tc.stackEvents,
+					},
+				}
+
+				cf, err = New(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			failure, err := cf.DescribeFailure("cluster-al9qy-guest-main")
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if failure != tc.expectedFailure {
+				t.Fatalf("expected %#v got %#v", tc.expectedFailure, failure)
+			}
+		})
+	}
+}
+
+func Test_CloudFormation_IsFailedStatus(t *testing.T) {
+	testCases := []struct {
+		status   string
+		expected bool
+	}{
+		{status: cloudformation.StackStatusCreateComplete, expected: false},
+		{status: cloudformation.StackStatusCreateInProgress, expected: false},
+		{status: cloudformation.StackStatusUpdateRollbackInProgress, expected: false},
+		{status: cloudformation.StackStatusRollbackComplete, expected: true},
+		{status: cloudformation.StackStatusUpdateRollbackComplete, expected: true},
+		{status: cloudformation.StackStatusDeleteFailed, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.status, func(t *testing.T) {
+			if IsFailedStatus(tc.status) != tc.expected {
+				t.Fatalf("expected %t got %t", tc.expected, IsFailedStatus(tc.status))
+			}
+		})
+	}
+}
+
+func newTestStackEvent(logicalID, resourceType, status, reason string) *cloudformation.StackEvent {
+	stackID := "arn:aws:cloudformation:eu-central-1:123456789012:stack/cluster-al9qy-guest-main/1"
+
+	physicalID := logicalID
+	if resourceType == "AWS::CloudFormation::Stack" {
+		physicalID = stackID
+	}
+
+	e := &cloudformation.StackEvent{
+		LogicalResourceId:  aws.String(logicalID),
+		PhysicalResourceId: aws.String(physicalID),
+		ResourceStatus:     aws.String(status),
+		ResourceType:       aws.String(resourceType),
+		StackId:            aws.String(stackID),
+	}
+	if reason != "" {
+		e.ResourceStatusReason = aws.String(reason)
+	}
+
+	return e
+}
@@ -6,6 +6,15 @@ import (
 	"github.com/giantswarm/microerror"
 )
 
+var failureNotFoundError = &microerror.Error{
+	Kind: "failureNotFoundError",
+}
+
+// IsFailureNotFound asserts failureNotFoundError.
+func IsFailureNotFound(err error) bool {
+	return microerror.Cause(err) == failureNotFoundError
+}
+
 var invalidConfigError = &microerror.Error{
 	Kind: "invalidConfigError",
 }
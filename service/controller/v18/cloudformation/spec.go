@@ -0,0 +1,15 @@
+package cloudformation
+
+// StackFailure describes the first failure of the latest operation of a
+// stack.
+type StackFailure struct {
+	// LogicalResourceID is the logical ID of the failed resource as defined in
+	// the template of the stack.
+	LogicalResourceID string
+	// ResourceStatus is the status of the failed resource, e.g. CREATE_FAILED.
+	ResourceStatus string
+	// ResourceStatusReason is the reason CloudFormation gives for the failure.
+	ResourceStatusReason string
+	// ResourceType is the type of the failed resource, e.g. AWS::EC2::Instance.
+	ResourceType string
+}
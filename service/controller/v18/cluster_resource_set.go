@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/scheme"
 	"github.com/giantswarm/certs"
 	"github.com/giantswarm/guestcluster"
 	"github.com/giantswarm/legacycerts/legacy"
@@ -16,32 +17,42 @@ import (
 	"github.com/giantswarm/operatorkit/controller/resource/retryresource"
 	"github.com/giantswarm/randomkeys"
 	"github.com/giantswarm/statusresource"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/giantswarm/aws-operator/client/aws"
 	awsservice "github.com/giantswarm/aws-operator/service/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
 	"github.com/giantswarm/aws-operator/service/controller/v18/cloudconfig"
 	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v18/cloudformation"
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v18/credential"
+	"github.com/giantswarm/aws-operator/service/controller/v18/dns"
 	"github.com/giantswarm/aws-operator/service/controller/v18/ebs"
 	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter"
 	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter/kms"
 	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter/vault"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/bridgezone"
+	"github.com/giantswarm/aws-operator/service/controller/v18/resource/certconfig"
 	cloudformationresource "github.com/giantswarm/aws-operator/service/controller/v18/resource/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v18/resource/delegation"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/ebsvolume"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/encryptionkey"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/endpoints"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/hostedzone"
+	"github.com/giantswarm/aws-operator/service/controller/v18/resource/ipam"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/loadbalancer"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/migration"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/namespace"
+	"github.com/giantswarm/aws-operator/service/controller/v18/resource/privatehostedzone"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/s3bucket"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/s3object"
 	"github.com/giantswarm/aws-operator/service/controller/v18/resource/service"
+	"github.com/giantswarm/aws-operator/service/controller/v18/resource/subnettag"
 )
 
 type ClusterResourceSetConfig struct {
@@ -53,22 +64,81 @@ type ClusterResourceSetConfig struct {
 	Logger             micrologger.Logger
 	RandomkeysSearcher randomkeys.Interface
 
-	AccessLogsExpiration   int
-	AdvancedMonitoringEC2  bool
-	APIWhitelist           adapter.APIWhitelist
-	EncrypterBackend       string
-	GuestUpdateEnabled     bool
-	IncludeTags            bool
-	InstallationName       string
-	DeleteLoggingBucket    bool
-	OIDC                   cloudconfig.OIDCConfig
-	ProjectName            string
-	PublicRouteTables      string
-	Route53Enabled         bool
-	PodInfraContainerImage string
-	RegistryDomain         string
-	SSOPublicKey           string
-	VaultAddress           string
+	AccessLogsExpiration           int
+	AdvancedMonitoringEC2          bool
+	AMI                            AMIConfig
+	APIWhitelist                   adapter.APIWhitelist
+	BridgeZoneRetirement           BridgeZoneRetirementConfig
+	DNS                            DNSConfig
+	EncrypterBackend               string
+	GuestUpdateEnabled             bool
+	GuestUpdateReplacementApproval bool
+	IncludeTags                    bool
+	InstallationName               string
+	IPAM                           IPAMConfig
+	DeleteLoggingBucket            bool
+	OIDC                           cloudconfig.OIDCConfig
+	ProjectName                    string
+	PublicRouteTables              string
+	Route53Enabled                 bool
+	PodInfraContainerImage         string
+	RegistryDomain                 string
+	SSOPublicKey                   string
+	TransitGateway                 adapter.TransitGateway
+	Vault                          VaultConfig
+	VaultAddress                   string
+}
+
+// AMIConfig represents the configuration of the catalog resolving the AMIs of
+// guest cluster nodes which do not configure their AMI in the custom object.
+type AMIConfig struct {
+	Catalog            string
+	ConfigMapName      string
+	ConfigMapNamespace string
+	EC2NamePattern     string
+	EC2Owner           string
+}
+
+// BridgeZoneRetirementConfig represents the configuration of the retirement of
+// the intermediate zone guest cluster domains were delegated through before
+// they got delegated from the host cluster zone directly.
+type BridgeZoneRetirementConfig struct {
+	Enabled     bool
+	GracePeriod time.Duration
+}
+
+// DNSConfig represents the configuration of the DNS provider managing the zone
+// of the installation base domain guest cluster zones are delegated from.
+type DNSConfig struct {
+	Provider             string
+	RFC2136Server        string
+	RFC2136TSIGAlgorithm string
+	RFC2136TSIGKeyName   string
+	RFC2136TSIGSecret    string
+}
+
+// IPAMConfig represents the configuration of the allocation of the VPC CIDRs of
+// guest clusters not configuring them in the custom object.
+type IPAMConfig struct {
+	Network   string
+	VPCPrefix int
+}
+
+// VaultConfig represents the configuration of the Vault encrypter backend in
+// addition to the Vault address.
+type VaultConfig struct {
+	AppRoleMountPath    string
+	AppRoleID           string
+	AppRoleSecretID     string
+	AuthMethod          string
+	AWSMountPath        string
+	AWSRole             string
+	CAFile              string
+	KubernetesMountPath string
+	KubernetesRole      string
+	KubernetesTokenFile string
+	Namespace           string
+	TransitMountPath    string
 }
 
 func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.ResourceSet, error) {
@@ -121,6 +191,12 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 	if config.APIWhitelist.Enabled && config.APIWhitelist.SubnetList == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.APIWhitelist.SubnetList must not be empty when %T.APIWhitelist is enabled", config)
 	}
+	if config.TransitGateway.ID != "" && config.TransitGateway.RouteTableID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TransitGateway.RouteTableID must not be empty when %T.TransitGateway.ID is set", config, config)
+	}
+	if config.TransitGateway.ID != "" && config.IPAM.Network == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IPAM.Network must not be empty when %T.TransitGateway.ID is set", config, config)
+	}
 	if config.SSOPublicKey == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.SSOPublicKey must not be empty", config)
 	}
@@ -132,7 +208,19 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		c := &vault.EncrypterConfig{
 			Logger: config.Logger,
 
-			Address: config.VaultAddress,
+			Address:             config.VaultAddress,
+			AppRoleMountPath:    config.Vault.AppRoleMountPath,
+			AppRoleID:           config.Vault.AppRoleID,
+			AppRoleSecretID:     config.Vault.AppRoleSecretID,
+			AuthMethod:          config.Vault.AuthMethod,
+			AWSMountPath:        config.Vault.AWSMountPath,
+			AWSRole:             config.Vault.AWSRole,
+			CAFile:              config.Vault.CAFile,
+			KubernetesMountPath: config.Vault.KubernetesMountPath,
+			KubernetesRole:      config.Vault.KubernetesRole,
+			KubernetesTokenFile: config.Vault.KubernetesTokenFile,
+			Namespace:           config.Vault.Namespace,
+			TransitMountPath:    config.Vault.TransitMountPath,
 		}
 
 		encrypterObject, err = vault.NewEncrypter(c)
@@ -155,6 +243,69 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		return nil, microerror.Maskf(invalidConfigError, "unknown encrypter backend %q", config.EncrypterBackend)
 	}
 
+	var imageCatalog ami.Interface
+	switch config.AMI.Catalog {
+	case ami.BuiltinCatalog:
+		imageCatalog = ami.NewBuiltin()
+	case ami.ConfigMapCatalog:
+		c := ami.ConfigMapConfig{
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+
+			Name:      config.AMI.ConfigMapName,
+			Namespace: config.AMI.ConfigMapNamespace,
+		}
+
+		imageCatalog, err = ami.NewConfigMap(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	case ami.EC2Catalog:
+		c := ami.EC2Config{
+			Logger: config.Logger,
+
+			NamePattern: config.AMI.EC2NamePattern,
+			Owner:       config.AMI.EC2Owner,
+		}
+
+		imageCatalog, err = ami.NewEC2(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	default:
+		return nil, microerror.Maskf(invalidConfigError, "unknown AMI catalog %q", config.AMI.Catalog)
+	}
+
+	var dnsProvider dns.Interface
+	switch config.DNS.Provider {
+	case dns.RFC2136Provider:
+		c := dns.RFC2136Config{
+			Logger: config.Logger,
+
+			Server:        config.DNS.RFC2136Server,
+			TSIGAlgorithm: config.DNS.RFC2136TSIGAlgorithm,
+			TSIGKeyName:   config.DNS.RFC2136TSIGKeyName,
+			TSIGSecret:    config.DNS.RFC2136TSIGSecret,
+		}
+
+		dnsProvider, err = dns.NewRFC2136(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	case dns.Route53Provider:
+		c := dns.Route53Config{
+			HostRoute53: config.HostAWSClients.Route53,
+			Logger:      config.Logger,
+		}
+
+		dnsProvider, err = dns.NewRoute53(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	default:
+		return nil, microerror.Maskf(invalidConfigError, "unknown DNS provider %q", config.DNS.Provider)
+	}
+
 	var cloudConfig *cloudconfig.CloudConfig
 	{
 		c := cloudconfig.Config{
@@ -199,12 +350,31 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var ipamResource controller.Resource
+	{
+		c := ipam.Config{
+			G8sClient: config.G8sClient,
+			HostEC2:   config.HostAWSClients.EC2,
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+
+			Network:   config.IPAM.Network,
+			VPCPrefix: config.IPAM.VPCPrefix,
+		}
+
+		ipamResource, err = ipam.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var hostedZoneResource controller.Resource
 	{
 		c := hostedzone.Config{
 			HostRoute53: config.HostAWSClients.Route53,
 			Logger:      config.Logger,
 
+			DNSProvider:    config.DNS.Provider,
 			Route53Enabled: config.Route53Enabled,
 		}
 
@@ -214,15 +384,33 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var privateHostedZoneResource controller.Resource
+	{
+		c := privatehostedzone.Config{
+			HostRoute53: config.HostAWSClients.Route53,
+			HostSTS:     config.HostAWSClients.STS,
+			Logger:      config.Logger,
+
+			Route53Enabled: config.Route53Enabled,
+		}
+
+		privateHostedZoneResource, err = privatehostedzone.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var bridgeZoneResource controller.Resource
 	{
 		c := bridgezone.Config{
+			DNS:           dnsProvider,
 			HostAWSConfig: config.HostAWSConfig,
-			HostRoute53:   config.HostAWSClients.Route53,
 			K8sClient:     config.K8sClient,
 			Logger:        config.Logger,
 
-			Route53Enabled: config.Route53Enabled,
+			RetirementEnabled:     config.BridgeZoneRetirement.Enabled,
+			RetirementGracePeriod: config.BridgeZoneRetirement.GracePeriod,
+			Route53Enabled:        config.Route53Enabled,
 		}
 
 		bridgeZoneResource, err = bridgezone.New(c)
@@ -253,10 +441,44 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var certsSearcher certs.Interface
+	{
+		c := certs.Config{
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+
+			WatchTimeout: 5 * time.Second,
+		}
+
+		certsSearcher, err = certs.NewSearcher(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var certConfigResource controller.Resource
+	{
+		c := certconfig.Config{
+			G8sClient: config.G8sClient,
+			Logger:    config.Logger,
+		}
+
+		ops, err := certconfig.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		certConfigResource, err = toCRUDResource(config.Logger, ops)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var s3BucketObjectResource controller.Resource
 	{
 		c := s3object.Config{
 			CertWatcher:       config.CertsSearcher,
+			CertsSearcher:     certsSearcher,
 			CloudConfig:       cloudConfig,
 			Encrypter:         encrypterObject,
 			Logger:            config.Logger,
@@ -298,27 +520,55 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var subnetTagResource controller.Resource
+	{
+		c := subnettag.Config{
+			Logger: config.Logger,
+		}
+
+		subnetTagResource, err = subnettag.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// eventRecorder emits Kubernetes events about the guest cluster custom
+	// objects, e.g. in order to preview the changes of guest cluster updates.
+	var eventRecorder record.EventRecorder
+	{
+		b := record.NewBroadcaster()
+		b.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: config.K8sClient.CoreV1().Events("")})
+
+		eventRecorder = b.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: config.ProjectName})
+	}
+
 	var cloudformationResource controller.Resource
 	{
 		c := cloudformationresource.Config{
+			EventRecorder: eventRecorder,
 			HostClients: &adapter.Clients{
 				EC2:            config.HostAWSClients.EC2,
 				IAM:            config.HostAWSClients.IAM,
 				STS:            config.HostAWSClients.STS,
 				CloudFormation: config.HostAWSClients.CloudFormation,
 			},
-			Logger: config.Logger,
+			ImageCatalog: imageCatalog,
+			Logger:       config.Logger,
 			APIWhitelist: adapter.APIWhitelist{
 				Enabled:    config.APIWhitelist.Enabled,
 				SubnetList: config.APIWhitelist.SubnetList,
 			},
 
 			AdvancedMonitoringEC2: config.AdvancedMonitoringEC2,
+			DNSProvider:           config.DNS.Provider,
 			EncrypterBackend:      config.EncrypterBackend,
 			EncrypterRoleManager:  encrypterRoleManager,
 			InstallationName:      config.InstallationName,
+			IPAMNetwork:           config.IPAM.Network,
 			PublicRouteTables:     config.PublicRouteTables,
+			ReplacementApproval:   config.GuestUpdateReplacementApproval,
 			Route53Enabled:        config.Route53Enabled,
+			TransitGateway:        config.TransitGateway,
 		}
 
 		ops, err := cloudformationresource.New(c)
@@ -332,6 +582,22 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
+	var delegationResource controller.Resource
+	{
+		c := delegation.Config{
+			DNS:    dnsProvider,
+			Logger: config.Logger,
+
+			DNSProvider:    config.DNS.Provider,
+			Route53Enabled: config.Route53Enabled,
+		}
+
+		delegationResource, err = delegation.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var namespaceResource controller.Resource
 	{
 		c := namespace.Config{
@@ -386,21 +652,6 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 		}
 	}
 
-	var certsSearcher certs.Interface
-	{
-		c := certs.Config{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
-
-			WatchTimeout: 5 * time.Second,
-		}
-
-		certsSearcher, err = certs.NewSearcher(c)
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
-	}
-
 	var guestCluster guestcluster.Interface
 	{
 		c := guestcluster.Config{
@@ -438,14 +689,19 @@ func NewClusterResourceSet(config ClusterResourceSetConfig) (*controller.Resourc
 	resources := []controller.Resource{
 		statusResource,
 		migrationResource,
+		ipamResource,
 		hostedZoneResource,
 		bridgeZoneResource,
 		encryptionKeyResource,
 		s3BucketResource,
+		certConfigResource,
 		s3BucketObjectResource,
 		loadBalancerResource,
 		ebsVolumeResource,
+		subnetTagResource,
+		privateHostedZoneResource,
 		cloudformationResource,
+		delegationResource,
 		namespaceResource,
 		serviceResource,
 		endpointsResource,
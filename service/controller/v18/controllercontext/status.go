@@ -24,6 +24,7 @@ type ClusterHostedZonesZone struct {
 }
 
 type Drainer struct {
-	// WorkerASGName is filled by the workerasgname resource.
-	WorkerASGName string
+	// WorkerASGNames is filled by the workerasgname resource. It holds the ASG
+	// names of all node pools.
+	WorkerASGNames []string
 }
@@ -0,0 +1,50 @@
+package dns
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var delegationNotFoundError = &microerror.Error{
+	Kind: "delegationNotFoundError",
+}
+
+// IsDelegationNotFound asserts delegationNotFoundError.
+func IsDelegationNotFound(err error) bool {
+	return microerror.Cause(err) == delegationNotFoundError
+}
+
+var executionError = &microerror.Error{
+	Kind: "executionError",
+}
+
+// IsExecution asserts executionError.
+func IsExecution(err error) bool {
+	return microerror.Cause(err) == executionError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var updateRefusedError = &microerror.Error{
+	Kind: "updateRefusedError",
+}
+
+// IsUpdateRefused asserts updateRefusedError.
+func IsUpdateRefused(err error) bool {
+	return microerror.Cause(err) == updateRefusedError
+}
+
+var zoneNotFoundError = &microerror.Error{
+	Kind: "zoneNotFoundError",
+}
+
+// IsZoneNotFound asserts zoneNotFoundError.
+func IsZoneNotFound(err error) bool {
+	return microerror.Cause(err) == zoneNotFoundError
+}
@@ -0,0 +1,295 @@
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	miekgdns "github.com/miekg/dns"
+)
+
+const (
+	TSIGAlgorithmHMACSHA1   = "hmac-sha1"
+	TSIGAlgorithmHMACSHA256 = "hmac-sha256"
+	TSIGAlgorithmHMACSHA512 = "hmac-sha512"
+)
+
+const (
+	// rfc2136Timeout is the time to wait for the DNS server in case the
+	// context does not define a deadline.
+	rfc2136Timeout = 10 * time.Second
+	// tsigFudge is the number of seconds the clocks of the operator and the DNS
+	// server may differ.
+	tsigFudge = 300
+)
+
+var tsigAlgorithms = map[string]string{
+	TSIGAlgorithmHMACSHA1:   miekgdns.HmacSHA1,
+	TSIGAlgorithmHMACSHA256: miekgdns.HmacSHA256,
+	TSIGAlgorithmHMACSHA512: miekgdns.HmacSHA512,
+}
+
+type RFC2136Config struct {
+	Logger micrologger.Logger
+
+	// Server is the address of the DNS server the zone of the installation
+	// base domain is updated on, e.g. 10.0.0.2:53. The port defaults to 53.
+	Server string
+	// TSIGAlgorithm is the algorithm of the TSIG key, e.g. hmac-sha256.
+	TSIGAlgorithm string
+	// TSIGKeyName is the name of the TSIG key updates are signed with. Updates
+	// are not signed in case it is empty, which requires the DNS server to
+	// authorize updates by the address of the operator.
+	TSIGKeyName string
+	// TSIGSecret is the base64 encoded secret of the TSIG key.
+	TSIGSecret string
+}
+
+// RFC2136 manages delegations on a DNS server accepting dynamic updates as
+// specified in RFC2136, e.g. an on-prem BIND server. Messages are exchanged
+// via TCP and signed with TSIG when a key is configured.
+type RFC2136 struct {
+	logger micrologger.Logger
+
+	server        string
+	tsigAlgorithm string
+	tsigKeyName   string
+	tsigSecret    string
+}
+
+func NewRFC2136(config RFC2136Config) (*RFC2136, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Server == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Server must not be empty", config)
+	}
+
+	server := config.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	var tsigAlgorithm, tsigKeyName string
+	if config.TSIGKeyName != "" {
+		algorithm, ok := tsigAlgorithms[config.TSIGAlgorithm]
+		if !ok {
+			return nil, microerror.Maskf(invalidConfigError, "%T.TSIGAlgorithm must be one of %s, %s or %s", config, TSIGAlgorithmHMACSHA1, TSIGAlgorithmHMACSHA256, TSIGAlgorithmHMACSHA512)
+		}
+		secret, err := base64.StdEncoding.DecodeString(config.TSIGSecret)
+		if err != nil || len(secret) == 0 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.TSIGSecret must be a base64 encoded secret when %T.TSIGKeyName is set", config, config)
+		}
+
+		tsigAlgorithm = algorithm
+		tsigKeyName = miekgdns.Fqdn(config.TSIGKeyName)
+	}
+
+	r := &RFC2136{
+		logger: config.Logger,
+
+		server:        server,
+		tsigAlgorithm: tsigAlgorithm,
+		tsigKeyName:   tsigKeyName,
+		tsigSecret:    config.TSIGSecret,
+	}
+
+	return r, nil
+}
+
+// FindDelegation queries the DNS server for the NS records of zone. A DNS
+// server authoritative for parentZone answers with a referral, which lists the
+// delegation in the authority section. The DNS server is ensured to be
+// authoritative for parentZone first, since the NS records of any other server
+// would not be the delegation from parentZone.
+func (r *RFC2136) FindDelegation(ctx context.Context, parentZone, zone string) (Delegation, error) {
+	if !isSubdomain(zone, parentZone) {
+		return Delegation{}, microerror.Maskf(invalidConfigError, "zone %#q must be a subdomain of zone %#q", zone, parentZone)
+	}
+
+	err := r.findZone(ctx, parentZone)
+	if err != nil {
+		return Delegation{}, microerror.Mask(err)
+	}
+
+	m := new(miekgdns.Msg)
+	m.SetQuestion(miekgdns.Fqdn(zone), miekgdns.TypeNS)
+
+	resp, err := r.exchange(ctx, m)
+	if err != nil {
+		return Delegation{}, microerror.Mask(err)
+	}
+
+	if resp.Rcode == miekgdns.RcodeNameError {
+		return Delegation{}, microerror.Maskf(delegationNotFoundError, "NS records %#q on DNS server %#q", zone, r.server)
+	} else if resp.Rcode != miekgdns.RcodeSuccess {
+		return Delegation{}, microerror.Maskf(executionError, "querying NS records %#q on DNS server %#q failed with %s", zone, r.server, miekgdns.RcodeToString[resp.Rcode])
+	}
+
+	var d Delegation
+	for _, rr := range append(resp.Answer, resp.Ns...) {
+		ns, ok := rr.(*miekgdns.NS)
+		if !ok || canonicalName(ns.Hdr.Name) != canonicalName(zone) {
+			continue
+		}
+
+		d.NameServers = append(d.NameServers, ns.Ns)
+		d.TTL = int64(ns.Hdr.Ttl)
+	}
+
+	if len(d.NameServers) == 0 {
+		return Delegation{}, microerror.Maskf(delegationNotFoundError, "NS records %#q on DNS server %#q", zone, r.server)
+	}
+
+	return d, nil
+}
+
+// findZone ensures the DNS server is authoritative for zone by querying the
+// SOA record of zone. Only the apex of a zone has a SOA record.
+func (r *RFC2136) findZone(ctx context.Context, zone string) error {
+	m := new(miekgdns.Msg)
+	m.SetQuestion(miekgdns.Fqdn(zone), miekgdns.TypeSOA)
+
+	resp, err := r.exchange(ctx, m)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if resp.Rcode != miekgdns.RcodeSuccess || !resp.Authoritative {
+		return microerror.Maskf(zoneNotFoundError, "zone %#q on DNS server %#q", zone, r.server)
+	}
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == miekgdns.TypeSOA && canonicalName(rr.Header().Name) == canonicalName(zone) {
+			return nil
+		}
+	}
+
+	return microerror.Maskf(zoneNotFoundError, "zone %#q on DNS server %#q", zone, r.server)
+}
+
+// EnsureCreatedDelegation replaces the NS records of zone within a single
+// update, so that the delegation never resolves partially.
+func (r *RFC2136) EnsureCreatedDelegation(ctx context.Context, parentZone, zone string, delegation Delegation) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensuring delegation of zone %#q from zone %#q on DNS server %#q", zone, parentZone, r.server))
+
+	m := new(miekgdns.Msg)
+	m.SetUpdate(miekgdns.Fqdn(parentZone))
+	m.RemoveRRset([]miekgdns.RR{newNSRecord(zone, 0, "")})
+	for _, ns := range delegation.NameServers {
+		m.Insert([]miekgdns.RR{newNSRecord(zone, uint32(delegation.TTL), ns)})
+	}
+
+	err := r.update(ctx, m)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured delegation of zone %#q from zone %#q on DNS server %#q", zone, parentZone, r.server))
+
+	return nil
+}
+
+// EnsureDeletedDelegation deletes the NS records of zone. Deleting records
+// which do not exist is no error in RFC2136, so no lookup is needed.
+func (r *RFC2136) EnsureDeletedDelegation(ctx context.Context, parentZone, zone string) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensuring deletion of delegation of zone %#q from zone %#q on DNS server %#q", zone, parentZone, r.server))
+
+	m := new(miekgdns.Msg)
+	m.SetUpdate(miekgdns.Fqdn(parentZone))
+	m.RemoveRRset([]miekgdns.RR{newNSRecord(zone, 0, "")})
+
+	err := r.update(ctx, m)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured deletion of delegation of zone %#q from zone %#q on DNS server %#q", zone, parentZone, r.server))
+
+	return nil
+}
+
+func (r *RFC2136) update(ctx context.Context, m *miekgdns.Msg) error {
+	zone := m.Question[0].Name
+
+	resp, err := r.exchange(ctx, m)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	rcode := miekgdns.RcodeToString[resp.Rcode]
+	switch resp.Rcode {
+	case miekgdns.RcodeSuccess:
+		return nil
+	case miekgdns.RcodeNotAuth, miekgdns.RcodeNotZone, miekgdns.RcodeRefused:
+		return microerror.Maskf(updateRefusedError, "updating zone %#q on DNS server %#q failed with %s", zone, r.server, rcode)
+	default:
+		return microerror.Maskf(executionError, "updating zone %#q on DNS server %#q failed with %s", zone, r.server, rcode)
+	}
+}
+
+// exchange sends the given message to the DNS server and returns its response.
+// The message is signed and the signature of the response is verified in case
+// a TSIG key is configured.
+func (r *RFC2136) exchange(ctx context.Context, m *miekgdns.Msg) (*miekgdns.Msg, error) {
+	timeout := rfc2136Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	c := miekgdns.Client{
+		Net:     "tcp",
+		Timeout: timeout,
+	}
+	if r.tsigKeyName != "" {
+		c.TsigSecret = map[string]string{r.tsigKeyName: r.tsigSecret}
+		m.SetTsig(r.tsigKeyName, r.tsigAlgorithm, tsigFudge, time.Now().Unix())
+	}
+
+	// Signed responses with rcode NOTAUTH and responses with invalid signatures
+	// are reported as TSIG errors.
+	resp, _, err := c.Exchange(m, r.server)
+	if err == miekgdns.ErrAuth || err == miekgdns.ErrSig || err == miekgdns.ErrTime || err == miekgdns.ErrSecret {
+		return nil, microerror.Maskf(updateRefusedError, "authenticating with DNS server %#q failed with %s", r.server, err)
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// DNS servers do not sign responses to requests they could not parse, so
+	// only successful responses must be signed.
+	if r.tsigKeyName != "" && resp.Rcode == miekgdns.RcodeSuccess && resp.IsTsig() == nil {
+		return nil, microerror.Maskf(updateRefusedError, "DNS server %#q sent unsigned response", r.server)
+	}
+
+	return resp, nil
+}
+
+// newNSRecord returns the NS record of zone pointing to the given name server.
+// Deleting the NS records of zone only needs the name and type of the record,
+// so the name server is empty in this case.
+func newNSRecord(zone string, ttl uint32, nameServer string) *miekgdns.NS {
+	return &miekgdns.NS{
+		Hdr: miekgdns.RR_Header{
+			Name:   miekgdns.Fqdn(zone),
+			Rrtype: miekgdns.TypeNS,
+			Class:  miekgdns.ClassINET,
+			Ttl:    ttl,
+		},
+		Ns: miekgdns.Fqdn(nameServer),
+	}
+}
+
+func canonicalName(name string) string {
+	return strings.ToLower(miekgdns.Fqdn(name))
+}
+
+// isSubdomain returns true when name is a subdomain of zone, but not zone
+// itself.
+func isSubdomain(name, zone string) bool {
+	return strings.HasSuffix(canonicalName(name), "."+canonicalName(zone))
+}
@@ -0,0 +1,395 @@
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	miekgdns "github.com/miekg/dns"
+)
+
+// testDNSServer is a DNS server authoritative for a single zone accepting
+// dynamic updates signed with a TSIG key.
+type testDNSServer struct {
+	listener net.Listener
+	server   *miekgdns.Server
+	zone     string
+
+	mutex   sync.Mutex
+	records map[string][]miekgdns.RR
+}
+
+func newTestDNSServer(t *testing.T, zone, keyName string, secret []byte) *testDNSServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected nil, got %#v", err)
+	}
+
+	s := &testDNSServer{
+		listener: l,
+		zone:     zone,
+
+		records: map[string][]miekgdns.RR{},
+	}
+
+	s.server = &miekgdns.Server{
+		Listener: l,
+		Handler:  miekgdns.HandlerFunc(s.handle),
+		TsigSecret: map[string]string{
+			miekgdns.Fqdn(keyName): base64.StdEncoding.EncodeToString(secret),
+		},
+	}
+
+	go s.server.ActivateAndServe()
+
+	return s
+}
+
+func (s *testDNSServer) Close() {
+	s.server.Shutdown()
+}
+
+func (s *testDNSServer) handle(w miekgdns.ResponseWriter, req *miekgdns.Msg) {
+	resp := new(miekgdns.Msg)
+	resp.SetReply(req)
+	resp.Authoritative = true
+
+	// Unsigned requests and requests with invalid signatures are refused
+	// without signing the response.
+	t := req.IsTsig()
+	if t == nil || w.TsigStatus() != nil {
+		resp.Rcode = miekgdns.RcodeNotAuth
+		w.WriteMsg(resp)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch req.Opcode {
+	case miekgdns.OpcodeQuery:
+		name := canonicalName(req.Question[0].Name)
+		// Only the zone of the server has a SOA record. Queries for zones the
+		// server is not authoritative for are refused.
+		if req.Question[0].Qtype == miekgdns.TypeSOA {
+			if name != canonicalName(s.zone) {
+				resp.Authoritative = false
+				resp.Rcode = miekgdns.RcodeRefused
+				break
+			}
+			resp.Answer = []miekgdns.RR{
+				&miekgdns.SOA{
+					Hdr:  miekgdns.RR_Header{Name: name, Rrtype: miekgdns.TypeSOA, Class: miekgdns.ClassINET, Ttl: 300},
+					Ns:   "ns." + name,
+					Mbox: "hostmaster." + name,
+				},
+			}
+			break
+		}
+		// Delegations are served as referrals in the authority section.
+		if len(s.records[name]) == 0 {
+			resp.Rcode = miekgdns.RcodeNameError
+		}
+		resp.Ns = s.records[name]
+	case miekgdns.OpcodeUpdate:
+		if canonicalName(req.Question[0].Name) != canonicalName(s.zone) {
+			resp.Rcode = miekgdns.RcodeNotAuth
+			break
+		}
+		for _, rr := range req.Ns {
+			name := canonicalName(rr.Header().Name)
+			if rr.Header().Class == miekgdns.ClassANY {
+				delete(s.records, name)
+			} else {
+				s.records[name] = append(s.records[name], rr)
+			}
+		}
+	}
+
+	// Responses are signed with the key of the request they respond to.
+	resp.SetTsig(t.Hdr.Name, t.Algorithm, tsigFudge, time.Now().Unix())
+	w.WriteMsg(resp)
+}
+
+func Test_RFC2136_Delegation(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	testCases := []struct {
+		name         string
+		algorithm    string
+		keyName      string
+		secret       []byte
+		parentZone   string
+		errorMatcher func(error) bool
+	}{
+		{
+			name:       "case 0: hmac-sha256",
+			algorithm:  TSIGAlgorithmHMACSHA256,
+			keyName:    "aws-operator",
+			secret:     secret,
+			parentZone: "installation.eu-central-1.aws.gigantic.io",
+		},
+		{
+			name:       "case 1: hmac-sha512",
+			algorithm:  TSIGAlgorithmHMACSHA512,
+			keyName:    "aws-operator",
+			secret:     secret,
+			parentZone: "installation.eu-central-1.aws.gigantic.io",
+		},
+		{
+			name:       "case 2: hmac-sha1",
+			algorithm:  TSIGAlgorithmHMACSHA1,
+			keyName:    "aws-operator",
+			secret:     secret,
+			parentZone: "installation.eu-central-1.aws.gigantic.io",
+		},
+		{
+			name:         "case 3: wrong secret",
+			algorithm:    TSIGAlgorithmHMACSHA256,
+			keyName:      "aws-operator",
+			secret:       []byte("wrong"),
+			parentZone:   "installation.eu-central-1.aws.gigantic.io",
+			errorMatcher: IsUpdateRefused,
+		},
+		{
+			name:         "case 4: unsigned",
+			parentZone:   "installation.eu-central-1.aws.gigantic.io",
+			errorMatcher: IsUpdateRefused,
+		},
+		{
+			name:         "case 5: wrong parent zone",
+			algorithm:    TSIGAlgorithmHMACSHA256,
+			keyName:      "aws-operator",
+			secret:       secret,
+			parentZone:   "other.eu-central-1.aws.gigantic.io",
+			errorMatcher: IsUpdateRefused,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestDNSServer(t, "installation.eu-central-1.aws.gigantic.io", "aws-operator", secret)
+			defer s.Close()
+
+			c := RFC2136Config{
+				Logger: microloggertest.New(),
+
+				Server:        s.listener.Addr().String(),
+				TSIGAlgorithm: tc.algorithm,
+				TSIGKeyName:   tc.keyName,
+				TSIGSecret:    base64.StdEncoding.EncodeToString(tc.secret),
+			}
+			p, err := NewRFC2136(c)
+			if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+
+			ctx := context.Background()
+			zone := "al9qy.k8s.installation.eu-central-1.aws.gigantic.io"
+
+			delegation := Delegation{
+				NameServers: []string{
+					"ns-1.awsdns-1.org.",
+					"ns-2.awsdns-2.com.",
+				},
+				TTL: 900,
+			}
+
+			err = p.EnsureCreatedDelegation(ctx, tc.parentZone, zone, delegation)
+			if tc.errorMatcher != nil {
+				if !tc.errorMatcher(err) {
+					t.Fatalf("expected matching error, got %#v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+
+			d, err := p.FindDelegation(ctx, tc.parentZone, zone)
+			if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+			if !reflect.DeepEqual(d, delegation) {
+				t.Fatalf("expected %#v, got %#v", delegation, d)
+			}
+
+			// Creating the delegation again replaces the name servers instead
+			// of adding them.
+			delegation.NameServers = []string{"ns-3.awsdns-3.net."}
+
+			err = p.EnsureCreatedDelegation(ctx, tc.parentZone, zone, delegation)
+			if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+
+			d, err = p.FindDelegation(ctx, tc.parentZone, zone)
+			if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+			if !reflect.DeepEqual(d, delegation) {
+				t.Fatalf("expected %#v, got %#v", delegation, d)
+			}
+
+			err = p.EnsureDeletedDelegation(ctx, tc.parentZone, zone)
+			if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+
+			_, err = p.FindDelegation(ctx, tc.parentZone, zone)
+			if !IsDelegationNotFound(err) {
+				t.Fatalf("expected delegation not found error, got %#v", err)
+			}
+
+			// Deleting the delegation again is no error.
+			err = p.EnsureDeletedDelegation(ctx, tc.parentZone, zone)
+			if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+		})
+	}
+}
+
+func Test_RFC2136_FindDelegation(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	testCases := []struct {
+		name         string
+		parentZone   string
+		zone         string
+		errorMatcher func(error) bool
+	}{
+		{
+			name:         "case 0: delegation not found",
+			parentZone:   "installation.eu-central-1.aws.gigantic.io",
+			zone:         "al9qy.k8s.installation.eu-central-1.aws.gigantic.io",
+			errorMatcher: IsDelegationNotFound,
+		},
+		{
+			name:         "case 1: parent zone not served by DNS server",
+			parentZone:   "other.eu-central-1.aws.gigantic.io",
+			zone:         "al9qy.k8s.other.eu-central-1.aws.gigantic.io",
+			errorMatcher: IsZoneNotFound,
+		},
+		{
+			name:         "case 2: parent zone not being the apex of a zone",
+			parentZone:   "k8s.installation.eu-central-1.aws.gigantic.io",
+			zone:         "al9qy.k8s.installation.eu-central-1.aws.gigantic.io",
+			errorMatcher: IsZoneNotFound,
+		},
+		{
+			name:         "case 3: zone not within parent zone",
+			parentZone:   "installation.eu-central-1.aws.gigantic.io",
+			zone:         "al9qy.k8s.other.eu-central-1.aws.gigantic.io",
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name:         "case 4: zone equal to parent zone",
+			parentZone:   "installation.eu-central-1.aws.gigantic.io",
+			zone:         "installation.eu-central-1.aws.gigantic.io.",
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestDNSServer(t, "installation.eu-central-1.aws.gigantic.io", "aws-operator", secret)
+			defer s.Close()
+
+			c := RFC2136Config{
+				Logger: microloggertest.New(),
+
+				Server:        s.listener.Addr().String(),
+				TSIGAlgorithm: TSIGAlgorithmHMACSHA256,
+				TSIGKeyName:   "aws-operator",
+				TSIGSecret:    base64.StdEncoding.EncodeToString(secret),
+			}
+			p, err := NewRFC2136(c)
+			if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+
+			_, err = p.FindDelegation(context.Background(), tc.parentZone, tc.zone)
+			if !tc.errorMatcher(err) {
+				t.Fatalf("expected matching error, got %#v", err)
+			}
+		})
+	}
+}
+
+func Test_RFC2136_Config(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         RFC2136Config
+		expectedServer string
+		errorMatcher   func(error) bool
+	}{
+		{
+			name: "case 0: server with port",
+			config: RFC2136Config{
+				Logger: microloggertest.New(),
+				Server: "10.0.0.2:5353",
+			},
+			expectedServer: "10.0.0.2:5353",
+		},
+		{
+			name: "case 1: server without port",
+			config: RFC2136Config{
+				Logger: microloggertest.New(),
+				Server: "10.0.0.2",
+			},
+			expectedServer: "10.0.0.2:53",
+		},
+		{
+			name: "case 2: missing server",
+			config: RFC2136Config{
+				Logger: microloggertest.New(),
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 3: unsupported algorithm",
+			config: RFC2136Config{
+				Logger:        microloggertest.New(),
+				Server:        "10.0.0.2",
+				TSIGAlgorithm: "hmac-md5.sig-alg.reg.int",
+				TSIGKeyName:   "aws-operator",
+				TSIGSecret:    "c2VjcmV0",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 4: invalid secret",
+			config: RFC2136Config{
+				Logger:        microloggertest.New(),
+				Server:        "10.0.0.2",
+				TSIGAlgorithm: TSIGAlgorithmHMACSHA256,
+				TSIGKeyName:   "aws-operator",
+				TSIGSecret:    "not base64",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewRFC2136(tc.config)
+			if tc.errorMatcher != nil {
+				if !tc.errorMatcher(err) {
+					t.Fatalf("expected matching error, got %#v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("expected nil, got %#v", err)
+			}
+
+			if p.server != tc.expectedServer {
+				t.Fatalf("expected %q, got %q", tc.expectedServer, p.server)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+type Route53Config struct {
+	// HostRoute53 is the Route53 client of the host cluster account the zone
+	// of the installation base domain is managed in.
+	HostRoute53 *route53.Route53
+	Logger      micrologger.Logger
+}
+
+// Route53 manages delegations in public hosted zones of the host cluster
+// account.
+type Route53 struct {
+	hostRoute53 *route53.Route53
+	logger      micrologger.Logger
+}
+
+func NewRoute53(config Route53Config) (*Route53, error) {
+	if config.HostRoute53 == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HostRoute53 must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Route53{
+		hostRoute53: config.HostRoute53,
+		logger:      config.Logger,
+	}
+
+	return r, nil
+}
+
+func (r *Route53) FindDelegation(ctx context.Context, parentZone, zone string) (Delegation, error) {
+	zoneID, err := r.findHostedZoneID(ctx, parentZone)
+	if err != nil {
+		return Delegation{}, microerror.Mask(err)
+	}
+
+	rs, err := r.findRecordSet(ctx, zoneID, zone)
+	if err != nil {
+		return Delegation{}, microerror.Mask(err)
+	}
+
+	var d Delegation
+	for _, rr := range rs.ResourceRecords {
+		d.NameServers = append(d.NameServers, aws.StringValue(rr.Value))
+	}
+	d.TTL = aws.Int64Value(rs.TTL)
+
+	return d, nil
+}
+
+func (r *Route53) EnsureCreatedDelegation(ctx context.Context, parentZone, zone string, delegation Delegation) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensuring delegation of zone %#q from route53 hosted zone %#q", zone, parentZone))
+
+	zoneID, err := r.findHostedZoneID(ctx, parentZone)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var records []*route53.ResourceRecord
+	for _, ns := range delegation.NameServers {
+		records = append(records, &route53.ResourceRecord{
+			Value: aws.String(ns),
+		})
+	}
+
+	in := &route53.ChangeResourceRecordSetsInput{
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{
+				{
+					Action: aws.String(route53.ChangeActionUpsert),
+					ResourceRecordSet: &route53.ResourceRecordSet{
+						Name:            aws.String(zone),
+						Type:            aws.String(route53.RRTypeNs),
+						TTL:             aws.Int64(delegation.TTL),
+						ResourceRecords: records,
+					},
+				},
+			},
+		},
+		HostedZoneId: aws.String(zoneID),
+	}
+
+	_, err = r.hostRoute53.ChangeResourceRecordSetsWithContext(ctx, in)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured delegation of zone %#q from route53 hosted zone %#q", zone, parentZone))
+
+	return nil
+}
+
+func (r *Route53) EnsureDeletedDelegation(ctx context.Context, parentZone, zone string) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensuring deletion of delegation of zone %#q from route53 hosted zone %#q", zone, parentZone))
+
+	zoneID, err := r.findHostedZoneID(ctx, parentZone)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Route53 only deletes record sets matching the given values exactly, so
+	// the current record set is looked up first.
+	rs, err := r.findRecordSet(ctx, zoneID, zone)
+	if IsDelegationNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("delegation of zone %#q from route53 hosted zone %#q already deleted", zone, parentZone))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	in := &route53.ChangeResourceRecordSetsInput{
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{
+				{
+					Action:            aws.String(route53.ChangeActionDelete),
+					ResourceRecordSet: rs,
+				},
+			},
+		},
+		HostedZoneId: aws.String(zoneID),
+	}
+
+	_, err = r.hostRoute53.ChangeResourceRecordSetsWithContext(ctx, in)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured deletion of delegation of zone %#q from route53 hosted zone %#q", zone, parentZone))
+
+	return nil
+}
+
+// findHostedZoneID returns the ID of the public hosted zone with the given
+// name. Private hosted zones may have the same name and are skipped.
+func (r *Route53) findHostedZoneID(ctx context.Context, name string) (string, error) {
+	name = strings.TrimSuffix(name, ".")
+
+	var marker *string
+	for {
+		in := &route53.ListHostedZonesInput{
+			Marker: marker,
+		}
+
+		out, err := r.hostRoute53.ListHostedZonesWithContext(ctx, in)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		for _, hz := range out.HostedZones {
+			if hz.Name == nil || hz.Id == nil {
+				continue
+			}
+			if hz.Config != nil && aws.BoolValue(hz.Config.PrivateZone) {
+				continue
+			}
+
+			if strings.TrimSuffix(*hz.Name, ".") == name {
+				return *hz.Id, nil
+			}
+		}
+
+		if !aws.BoolValue(out.IsTruncated) {
+			return "", microerror.Maskf(zoneNotFoundError, "hosted zone %#q", name)
+		}
+		marker = out.NextMarker
+	}
+}
+
+func (r *Route53) findRecordSet(ctx context.Context, zoneID, zone string) (*route53.ResourceRecordSet, error) {
+	zone = strings.TrimSuffix(zone, ".")
+
+	in := &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zoneID),
+		MaxItems:        aws.String("1"),
+		StartRecordName: aws.String(zone),
+		StartRecordType: aws.String(route53.RRTypeNs),
+	}
+
+	out, err := r.hostRoute53.ListResourceRecordSetsWithContext(ctx, in)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Record sets are listed starting at the given name and type, so the first
+	// record set only matches in case the delegation exists.
+	if len(out.ResourceRecordSets) == 0 {
+		return nil, microerror.Maskf(delegationNotFoundError, "NS record set %#q in hosted zone %#q", zone, zoneID)
+	}
+	rs := out.ResourceRecordSets[0]
+	if strings.TrimSuffix(aws.StringValue(rs.Name), ".") != zone || aws.StringValue(rs.Type) != route53.RRTypeNs {
+		return nil, microerror.Maskf(delegationNotFoundError, "NS record set %#q in hosted zone %#q", zone, zoneID)
+	}
+
+	return rs, nil
+}
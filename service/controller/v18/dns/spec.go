@@ -0,0 +1,35 @@
+package dns
+
+import (
+	"context"
+)
+
+const (
+	RFC2136Provider = "rfc2136"
+	Route53Provider = "route53"
+)
+
+// Delegation is the NS record set delegating a zone from its parent zone.
+type Delegation struct {
+	// NameServers are the fully qualified names of the name servers of the
+	// delegated zone, e.g. ns-1.awsdns-1.org.
+	NameServers []string
+	// TTL is the time to live of the NS record set in seconds.
+	TTL int64
+}
+
+// Interface manages the NS records delegating guest cluster zones from the
+// zone of the installation base domain. The zone of the base domain is not
+// necessarily managed in Route53 of the host cluster account, e.g. when it is
+// served by an on-prem DNS server.
+type Interface interface {
+	// FindDelegation returns the delegation of zone from parentZone. The error
+	// is asserted by IsDelegationNotFound in case parentZone does not delegate
+	// zone.
+	FindDelegation(ctx context.Context, parentZone, zone string) (Delegation, error)
+	// EnsureCreatedDelegation ensures parentZone delegates zone exactly to the
+	// name servers of the given delegation.
+	EnsureCreatedDelegation(ctx context.Context, parentZone, zone string, delegation Delegation) error
+	// EnsureDeletedDelegation ensures parentZone does not delegate zone.
+	EnsureDeletedDelegation(ctx context.Context, parentZone, zone string) error
+}
@@ -25,27 +25,40 @@ func IsFiltered(vol *ec2.Volume, filterFuncs []func(t *ec2.Tag) bool) bool {
 }
 
 func NewDockerVolumeFilter(customObject v1alpha1.AWSConfig) func(t *ec2.Tag) bool {
-	return func(t *ec2.Tag) bool {
-		if *t.Key == nameTagKey && *t.Value == key.DockerVolumeName(customObject) {
-			return true
-		}
-		return false
-	}
+	return newNameTagFilter(key.DockerVolumeNames(customObject)...)
 }
 
 func NewEtcdVolumeFilter(customObject v1alpha1.AWSConfig) func(t *ec2.Tag) bool {
+	return newNameTagFilter(key.EtcdVolumeNames(customObject)...)
+}
+
+// NewMasterVolumeFilter matches the docker and etcd volumes of the master
+// instance with the given index.
+func NewMasterVolumeFilter(customObject v1alpha1.AWSConfig, index int) func(t *ec2.Tag) bool {
+	return newNameTagFilter(
+		key.IndexedName(key.DockerVolumeName(customObject), index),
+		key.IndexedName(key.EtcdVolumeName(customObject), index),
+	)
+}
+
+func NewPersistentVolumeFilter(customObject v1alpha1.AWSConfig) func(t *ec2.Tag) bool {
 	return func(t *ec2.Tag) bool {
-		if *t.Key == nameTagKey && *t.Value == key.EtcdVolumeName(customObject) {
+		if *t.Key == cloudProviderPersistentVolumeTagKey {
 			return true
 		}
 		return false
 	}
 }
 
-func NewPersistentVolumeFilter(customObject v1alpha1.AWSConfig) func(t *ec2.Tag) bool {
+func newNameTagFilter(names ...string) func(t *ec2.Tag) bool {
 	return func(t *ec2.Tag) bool {
-		if *t.Key == cloudProviderPersistentVolumeTagKey {
-			return true
+		if *t.Key != nameTagKey {
+			return false
+		}
+		for _, n := range names {
+			if *t.Value == n {
+				return true
+			}
 		}
 		return false
 	}
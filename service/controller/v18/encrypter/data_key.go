@@ -0,0 +1,127 @@
+package encrypter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"sync"
+
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// DataKeySize is the size of the data keys in bytes, which makes assets
+	// being encrypted using AES-256.
+	DataKeySize = 32
+)
+
+// DataKeyCache holds the data keys of the encryption keys an encrypter manages.
+// It is safe for concurrent use.
+type DataKeyCache struct {
+	mutex sync.Mutex
+	keys  map[string]DataKey
+}
+
+func NewDataKeyCache() *DataKeyCache {
+	c := &DataKeyCache{
+		keys: map[string]DataKey{},
+	}
+
+	return c
+}
+
+// Delete removes the data key of the given encryption key from the cache. It
+// has to be called when the encryption key is deleted, so that a recreated
+// encryption key does not reuse a data key encrypted with its predecessor.
+func (c *DataKeyCache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.keys, key)
+}
+
+// Get returns the data key of the given encryption key. In case there is no
+// data key cached yet, generate is called once to create it. Concurrent calls
+// for the same encryption key wait for the data key being generated instead of
+// generating their own.
+func (c *DataKeyCache) Get(key string, generate func() (DataKey, error)) (DataKey, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	dataKey, ok := c.keys[key]
+	if ok {
+		return dataKey, nil
+	}
+
+	dataKey, err := generate()
+	if err != nil {
+		return DataKey{}, microerror.Mask(err)
+	}
+	if len(dataKey.Plaintext) != DataKeySize {
+		return DataKey{}, microerror.Maskf(invalidDataKeyError, "expected %d bytes, got %d", DataKeySize, len(dataKey.Plaintext))
+	}
+
+	c.keys[key] = dataKey
+
+	return dataKey, nil
+}
+
+// Seal encrypts the given plaintext locally using AES-GCM with the plaintext
+// data key. The random nonce is prepended to the returned ciphertext, which is
+// followed by the authentication tag. The layout is relied upon by Open, which
+// the decrypt scripts running on the guest cluster nodes use.
+func Seal(dataKey DataKey, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Open decrypts the given ciphertext created by Seal with the plaintext data
+// key. The authentication tag is verified, so that ciphertexts which were
+// modified or encrypted with another data key are rejected.
+func Open(dataKey DataKey, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if len(ciphertext) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, microerror.Maskf(invalidCiphertextError, "expected at least %d bytes, got %d", gcm.NonceSize()+gcm.Overhead(), len(ciphertext))
+	}
+
+	nonce := ciphertext[:gcm.NonceSize()]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, microerror.Maskf(invalidCiphertextError, "%s", err.Error())
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(dataKey DataKey) (cipher.AEAD, error) {
+	if len(dataKey.Plaintext) != DataKeySize {
+		return nil, microerror.Maskf(invalidDataKeyError, "expected %d bytes, got %d", DataKeySize, len(dataKey.Plaintext))
+	}
+
+	block, err := aes.NewCipher(dataKey.Plaintext)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return gcm, nil
+}
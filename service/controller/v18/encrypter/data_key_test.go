@@ -0,0 +1,200 @@
+package encrypter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+func Test_DataKeyCache_Get(t *testing.T) {
+	testCases := []struct {
+		name              string
+		keys              []string
+		generateDataKey   DataKey
+		generateErr       error
+		expectedGenerated int
+		errorMatcher      func(error) bool
+	}{
+		{
+			name:              "case 0: data key is generated once per encryption key",
+			keys:              []string{"key-a", "key-a", "key-a"},
+			generateDataKey:   MockDataKey,
+			expectedGenerated: 1,
+			errorMatcher:      nil,
+		},
+		{
+			name:              "case 1: data keys are generated for each encryption key",
+			keys:              []string{"key-a", "key-b", "key-a", "key-b"},
+			generateDataKey:   MockDataKey,
+			expectedGenerated: 2,
+			errorMatcher:      nil,
+		},
+		{
+			name:              "case 2: data key of invalid size is not cached",
+			keys:              []string{"key-a", "key-a"},
+			generateDataKey:   DataKey{Ciphertext: "ciphertext", Plaintext: []byte("too-short")},
+			expectedGenerated: 2,
+			errorMatcher:      IsInvalidDataKey,
+		},
+		{
+			name:              "case 3: failed generation is not cached",
+			keys:              []string{"key-a", "key-a"},
+			generateErr:       errors.New("generation failed"),
+			expectedGenerated: 2,
+			errorMatcher:      func(err error) bool { return err != nil },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := NewDataKeyCache()
+
+			var generated int
+			generate := func() (DataKey, error) {
+				generated++
+				return tc.generateDataKey, tc.generateErr
+			}
+
+			for _, k := range tc.keys {
+				dataKey, err := cache.Get(k, generate)
+
+				switch {
+				case err == nil && tc.errorMatcher == nil:
+					// correct; carry on
+				case err != nil && tc.errorMatcher == nil:
+					t.Fatalf("error == %#v, want nil", err)
+				case err == nil && tc.errorMatcher != nil:
+					t.Fatalf("error == nil, want non-nil")
+				case !tc.errorMatcher(err):
+					t.Fatalf("error == %#v, want matching", err)
+				}
+
+				if err == nil && dataKey.Ciphertext != tc.generateDataKey.Ciphertext {
+					t.Fatalf("data key ciphertext == %q, want %q", dataKey.Ciphertext, tc.generateDataKey.Ciphertext)
+				}
+			}
+
+			if generated != tc.expectedGenerated {
+				t.Fatalf("generated == %d, want %d", generated, tc.expectedGenerated)
+			}
+		})
+	}
+}
+
+func Test_DataKeyCache_Delete(t *testing.T) {
+	cache := NewDataKeyCache()
+
+	var generated int
+	generate := func() (DataKey, error) {
+		generated++
+		return MockDataKey, nil
+	}
+
+	_, err := cache.Get("key-a", generate)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	cache.Delete("key-a")
+
+	_, err = cache.Get("key-a", generate)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	if generated != 2 {
+		t.Fatalf("generated == %d, want %d", generated, 2)
+	}
+}
+
+func Test_Seal(t *testing.T) {
+	plaintext := []byte("-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n")
+
+	ciphertext, err := Seal(MockDataKey, plaintext)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	var gcm cipher.AEAD
+	{
+		block, err := aes.NewCipher(MockDataKey.Plaintext)
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+		gcm, err = cipher.NewGCM(block)
+		if err != nil {
+			t.Fatalf("error == %#v, want nil", err)
+		}
+	}
+
+	// The decrypt scripts on the guest cluster nodes rely on the nonce being
+	// prepended and the authentication tag being appended to the ciphertext.
+	if len(ciphertext) != gcm.NonceSize()+len(plaintext)+gcm.Overhead() {
+		t.Fatalf("len(ciphertext) == %d, want %d", len(ciphertext), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	}
+
+	nonce := ciphertext[:gcm.NonceSize()]
+	opened, err := gcm.Open(nil, nonce, ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if string(opened) != string(plaintext) {
+		t.Fatalf("plaintext == %q, want %q", opened, plaintext)
+	}
+
+	other, err := Seal(MockDataKey, plaintext)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if string(other[:gcm.NonceSize()]) == string(nonce) {
+		t.Fatalf("nonce reused across encryptions")
+	}
+}
+
+func Test_Open(t *testing.T) {
+	plaintext := []byte("-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n")
+
+	ciphertext, err := Seal(MockDataKey, plaintext)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	opened, err := Open(MockDataKey, ciphertext)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	if string(opened) != string(plaintext) {
+		t.Fatalf("plaintext == %q, want %q", opened, plaintext)
+	}
+
+	// Modifying any byte of the nonce, the ciphertext or the authentication tag
+	// makes the authentication fail.
+	for _, i := range []int{0, 12, len(ciphertext) - 1} {
+		modified := append([]byte{}, ciphertext...)
+		modified[i] ^= 0x01
+
+		_, err := Open(MockDataKey, modified)
+		if !IsInvalidCiphertext(err) {
+			t.Fatalf("byte %d modified: error == %#v, want invalid ciphertext error", i, err)
+		}
+	}
+
+	otherKey := DataKey{
+		Plaintext: []byte("fedcba9876543210fedcba9876543210"),
+	}
+	_, err = Open(otherKey, ciphertext)
+	if !IsInvalidCiphertext(err) {
+		t.Fatalf("error == %#v, want invalid ciphertext error", err)
+	}
+
+	_, err = Open(MockDataKey, ciphertext[:27])
+	if !IsInvalidCiphertext(err) {
+		t.Fatalf("error == %#v, want invalid ciphertext error", err)
+	}
+
+	_, err = Open(DataKey{Plaintext: []byte("short")}, ciphertext)
+	if !IsInvalidDataKey(err) {
+		t.Fatalf("error == %#v, want invalid data key error", err)
+	}
+}
@@ -0,0 +1,21 @@
+package encrypter
+
+import "github.com/giantswarm/microerror"
+
+var invalidDataKeyError = &microerror.Error{
+	Kind: "invalidDataKeyError",
+}
+
+// IsInvalidDataKey asserts invalidDataKeyError.
+func IsInvalidDataKey(err error) bool {
+	return microerror.Cause(err) == invalidDataKeyError
+}
+
+var invalidCiphertextError = &microerror.Error{
+	Kind: "invalidCiphertextError",
+}
+
+// IsInvalidCiphertext asserts invalidCiphertextError.
+func IsInvalidCiphertext(err error) bool {
+	return microerror.Cause(err) == invalidCiphertextError
+}
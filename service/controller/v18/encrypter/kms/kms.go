@@ -2,6 +2,7 @@ package kms
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -16,7 +17,8 @@ import (
 )
 
 type Encrypter struct {
-	logger micrologger.Logger
+	dataKeys *encrypter.DataKeyCache
+	logger   micrologger.Logger
 
 	installationName string
 }
@@ -37,7 +39,8 @@ func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
 	}
 
 	kms := &Encrypter{
-		logger: c.Logger,
+		dataKeys: encrypter.NewDataKeyCache(),
+		logger:   c.Logger,
 
 		installationName: c.InstallationName,
 	}
@@ -138,6 +141,7 @@ func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject
 	}
 
 	var keyID *string
+	var keyARN string
 	{
 		e.logger.LogCtx(ctx, "level", "debug", "message", "finding out encryption key")
 
@@ -160,6 +164,7 @@ func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject
 
 			e.logger.LogCtx(ctx, "level", "debug", "message", "found encryption key")
 			keyID = out.KeyMetadata.KeyId
+			keyARN = *out.KeyMetadata.Arn
 		}
 	}
 
@@ -184,6 +189,8 @@ func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject
 			return microerror.Mask(err)
 		}
 
+		e.dataKeys.Delete(keyARN)
+
 		e.logger.LogCtx(ctx, "level", "info", "message", "scheduled deletion of encryption key")
 	}
 
@@ -234,23 +241,43 @@ func (k *Encrypter) EncryptionKey(ctx context.Context, customObject v1alpha1.AWS
 	return *out.KeyMetadata.Arn, nil
 }
 
-func (k *Encrypter) Encrypt(ctx context.Context, key, plaintext string) (string, error) {
-	sc, err := controllercontext.FromContext(ctx)
-	if err != nil {
-		return "", microerror.Mask(err)
-	}
+func (k *Encrypter) DataKey(ctx context.Context, key string) (encrypter.DataKey, error) {
+	generate := func() (encrypter.DataKey, error) {
+		sc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return encrypter.DataKey{}, microerror.Mask(err)
+		}
 
-	encryptInput := &kms.EncryptInput{
-		KeyId:     aws.String(key),
-		Plaintext: []byte(plaintext),
+		k.logger.LogCtx(ctx, "level", "debug", "message", "generating data key")
+
+		in := &kms.GenerateDataKeyInput{
+			KeyId:   aws.String(key),
+			KeySpec: aws.String(kms.DataKeySpecAes256),
+		}
+
+		out, err := sc.AWSClient.KMS.GenerateDataKey(in)
+		if err != nil {
+			return encrypter.DataKey{}, microerror.Mask(err)
+		}
+
+		k.logger.LogCtx(ctx, "level", "debug", "message", "generated data key")
+
+		dataKey := encrypter.DataKey{
+			// The node side decrypt scripts expect the ciphertext blob base64
+			// encoded.
+			Ciphertext: base64.StdEncoding.EncodeToString(out.CiphertextBlob),
+			Plaintext:  out.Plaintext,
+		}
+
+		return dataKey, nil
 	}
 
-	encryptOutput, err := sc.AWSClient.KMS.Encrypt(encryptInput)
+	dataKey, err := k.dataKeys.Get(key, generate)
 	if err != nil {
-		return "", microerror.Mask(err)
+		return encrypter.DataKey{}, microerror.Mask(err)
 	}
 
-	return string(encryptOutput.CiphertextBlob), nil
+	return dataKey, nil
 }
 
 func (e *Encrypter) IsKeyNotFound(err error) bool {
@@ -7,14 +7,20 @@ import (
 	"github.com/giantswarm/legacycerts/legacy"
 )
 
+// MockDataKey is the data key returned by EncrypterMock.DataKey.
+var MockDataKey = DataKey{
+	Ciphertext: "mock-data-key",
+	Plaintext:  []byte("0123456789abcdef0123456789abcdef"),
+}
+
 type EncrypterMock struct {
 	IsError bool
 	KeyID   string
 	KeyName string
 }
 
-func (e *EncrypterMock) Encrypt(ctx context.Context, key, plaintext string) (string, error) {
-	return plaintext, nil
+func (e *EncrypterMock) DataKey(ctx context.Context, key string) (DataKey, error) {
+	return MockDataKey, nil
 }
 
 func (e *EncrypterMock) EncryptTLSAssets(ctx context.Context, customObject v1alpha1.AWSConfig, assets legacy.AssetsBundle) (*legacy.CompactTLSAssets, error) {
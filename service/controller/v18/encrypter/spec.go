@@ -12,6 +12,16 @@ const (
 	VaultBackend = "vault"
 )
 
+// DataKey is a per-cluster data encryption key. Assets are encrypted locally
+// using the plaintext key. The ciphertext is the data key encrypted with the
+// cluster's encryption key and is shipped to the guest cluster nodes along
+// with the encrypted assets, so that only one call to the encryption backend
+// is needed for decrypting all of them.
+type DataKey struct {
+	Ciphertext string
+	Plaintext  []byte
+}
+
 type EncryptionKeyState struct {
 	KeyID   string
 	KeyName string
@@ -24,8 +34,10 @@ type Interface interface {
 }
 
 type Encrypter interface {
+	// DataKey returns the data key of the given encryption key. The data key is
+	// generated once and cached by the encrypter.
+	DataKey(ctx context.Context, key string) (DataKey, error)
 	EncryptionKey(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error)
-	Encrypt(ctx context.Context, key, plaintext string) (string, error)
 	IsKeyNotFound(error) bool
 }
 
@@ -55,19 +55,26 @@ func CreateRawTLSAssets(assets legacy.AssetsBundle) *RawTLSAssets {
 	}
 }
 
+// Encrypt encrypts the TLS assets locally using the data key of the given
+// encryption key, so that the encryption backend is only called once for
+// fetching the data key.
 func (r *RawTLSAssets) Encrypt(ctx context.Context, e Encrypter, key string) (*EncryptedTLSAssets, error) {
-	var err error
+	dataKey, err := e.DataKey(ctx, key)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	encrypt := func(data []byte) (b []byte) {
 		if err != nil {
 			return []byte{}
 		}
 
-		bs, err := e.Encrypt(ctx, key, string(data))
-
+		b, err = Seal(dataKey, data)
 		if err != nil {
 			return []byte{}
 		}
-		return []byte(bs)
+
+		return b
 	}
 	encryptedAssets := EncryptedTLSAssets{
 		APIServerCA:       encrypt(r.APIServerCA),
@@ -1,5 +1,11 @@
 package vault
 
+const (
+	AppRoleAuthMethod    = "approle"
+	AWSAuthMethod        = "aws"
+	KubernetesAuthMethod = "kubernetes"
+)
+
 const (
 	httpClientTimeout = 5
 
@@ -7,9 +13,12 @@ const (
 	// instance identity PKCS7 signature.
 	instanceIdentityPKCS7Endpoint = "http://169.254.169.254/latest/dynamic/instance-identity/pkcs7"
 
-	defaultRole = "encrypter"
 	// fixed nonce, see https://www.vaultproject.io/api/auth/aws/index.html#nonce.
 	defaultNonce = "aws-operator"
+
+	// tokenRenewRatio is the share of the lease duration of a token after which
+	// it is renewed.
+	tokenRenewRatio = 2.0 / 3.0
 )
 
 type LoginPayload struct {
@@ -18,13 +27,25 @@ type LoginPayload struct {
 	Nonce string `json:"nonce,omitempty"`
 }
 
+type AppRoleLoginPayload struct {
+	RoleID   string `json:"role_id"`
+	SecretID string `json:"secret_id,omitempty"`
+}
+
+type KubernetesLoginPayload struct {
+	Role string `json:"role"`
+	JWT  string `json:"jwt"`
+}
+
 type LoginResponse struct {
 	Auth LoginAuthResponse `json:"auth"`
 }
 
 type LoginAuthResponse struct {
-	Metadata    LoginAuthMetadataResponse `json:"metadata"`
-	ClientToken string                    `json:"client_token"`
+	Metadata      LoginAuthMetadataResponse `json:"metadata"`
+	ClientToken   string                    `json:"client_token"`
+	LeaseDuration int                       `json:"lease_duration"`
+	Renewable     bool                      `json:"renewable"`
 }
 
 type LoginAuthMetadataResponse struct {
@@ -35,16 +56,17 @@ type ErrorResponse struct {
 	Errors []string `json:"errors"`
 }
 
-type EncryptPayload struct {
-	Plaintext string `json:"plaintext"`
+type DataKeyPayload struct {
+	Bits int `json:"bits"`
 }
 
-type EncryptResponse struct {
-	Data EncryptResponseData `json:"data"`
+type DataKeyResponse struct {
+	Data DataKeyResponseData `json:"data"`
 }
 
-type EncryptResponseData struct {
+type DataKeyResponseData struct {
 	Ciphertext string `json:"ciphertext"`
+	Plaintext  string `json:"plaintext"`
 }
 
 type DecryptPayload struct {
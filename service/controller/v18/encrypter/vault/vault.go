@@ -3,6 +3,8 @@ package vault
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -12,6 +14,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
@@ -19,6 +22,7 @@ import (
 	"github.com/giantswarm/micrologger"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
@@ -27,19 +31,64 @@ const (
 )
 
 type Encrypter struct {
+	dataKeys   *encrypter.DataKeyCache
 	httpClient *http.Client
 	logger     micrologger.Logger
 
-	address string
-	base    *url.URL
-	nonce   string
-	token   string
+	address             string
+	appRoleMountPath    string
+	appRoleID           string
+	appRoleSecretID     string
+	authMethod          string
+	awsMountPath        string
+	awsRole             string
+	base                *url.URL
+	kubernetesMountPath string
+	kubernetesRole      string
+	kubernetesTokenFile string
+	namespace           string
+	transitMountPath    string
+
+	// loginMutex serializes logins and token renewals. The fields below it are
+	// only accessed while holding it.
+	loginMutex     sync.Mutex
+	nonce          string
+	tokenRenewAt   time.Time
+	tokenRenewable bool
+
+	tokenMutex sync.Mutex
+	token      string
 }
 
 type EncrypterConfig struct {
 	Logger micrologger.Logger
 
 	Address string
+	// AppRoleMountPath, AppRoleID and AppRoleSecretID configure the AppRole
+	// auth method used when AuthMethod is approle.
+	AppRoleMountPath string
+	AppRoleID        string
+	AppRoleSecretID  string
+	// AuthMethod is the auth method the operator authenticates with. One of
+	// approle, aws or kubernetes.
+	AuthMethod string
+	// AWSMountPath is the mount path of the AWS auth method. Guest cluster nodes
+	// always authenticate using it, regardless of AuthMethod. AWSRole is only
+	// used when AuthMethod is aws.
+	AWSMountPath string
+	AWSRole      string
+	// CAFile is the path of the PEM encoded CA certificates the Vault server
+	// certificate is verified with. If empty, the system CAs are used.
+	CAFile string
+	// KubernetesMountPath, KubernetesRole and KubernetesTokenFile configure the
+	// Kubernetes auth method used when AuthMethod is kubernetes.
+	KubernetesMountPath string
+	KubernetesRole      string
+	KubernetesTokenFile string
+	// Namespace is the Vault namespace the auth methods and the transit secrets
+	// engine are mounted in. If empty, the root namespace is used.
+	Namespace        string
+	TransitMountPath string
 }
 
 func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
@@ -49,6 +98,38 @@ func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
 	if c.Address == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Address must not be empty", c)
 	}
+	if c.AWSMountPath == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.AWSMountPath must not be empty", c)
+	}
+	if c.TransitMountPath == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TransitMountPath must not be empty", c)
+	}
+
+	switch c.AuthMethod {
+	case AppRoleAuthMethod:
+		if c.AppRoleMountPath == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.AppRoleMountPath must not be empty", c)
+		}
+		if c.AppRoleID == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.AppRoleID must not be empty", c)
+		}
+	case AWSAuthMethod:
+		if c.AWSRole == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.AWSRole must not be empty", c)
+		}
+	case KubernetesAuthMethod:
+		if c.KubernetesMountPath == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.KubernetesMountPath must not be empty", c)
+		}
+		if c.KubernetesRole == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.KubernetesRole must not be empty", c)
+		}
+		if c.KubernetesTokenFile == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.KubernetesTokenFile must not be empty", c)
+		}
+	default:
+		return nil, microerror.Maskf(invalidConfigError, "unknown %T.AuthMethod %q", c, c.AuthMethod)
+	}
 
 	base, err := url.Parse(c.Address + "/v1/")
 	if err != nil {
@@ -60,12 +141,44 @@ func NewEncrypter(c *EncrypterConfig) (*Encrypter, error) {
 		Timeout: time.Second * httpClientTimeout,
 	}
 
+	if c.CAFile != "" {
+		b, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(b) {
+			return nil, microerror.Maskf(invalidConfigError, "%T.CAFile must contain PEM encoded certificates", c)
+		}
+
+		httpClient.Transport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				RootCAs: rootCAs,
+			},
+		}
+	}
+
 	e := &Encrypter{
+		dataKeys:   encrypter.NewDataKeyCache(),
 		httpClient: httpClient,
 		logger:     c.Logger,
 
-		address: c.Address,
-		base:    base,
+		address:             c.Address,
+		appRoleMountPath:    c.AppRoleMountPath,
+		appRoleID:           c.AppRoleID,
+		appRoleSecretID:     c.AppRoleSecretID,
+		authMethod:          c.AuthMethod,
+		awsMountPath:        c.AWSMountPath,
+		awsRole:             c.AWSRole,
+		base:                base,
+		kubernetesMountPath: c.KubernetesMountPath,
+		kubernetesRole:      c.KubernetesRole,
+		kubernetesTokenFile: c.KubernetesTokenFile,
+		namespace:           c.Namespace,
+		transitMountPath:    c.TransitMountPath,
+
 		// fixed nonce, so that we can reauthenticate from the same host.
 		nonce: defaultNonce,
 	}
@@ -81,7 +194,7 @@ func (e *Encrypter) EncryptionKey(ctx context.Context, customObject v1alpha1.AWS
 
 	keyName := e.keyName(customObject)
 
-	p := path.Join("transit", "keys", keyName)
+	p := transitKeysPath(e.transitMountPath, keyName)
 
 	req, err := e.newRequest("GET", p)
 	if err != nil {
@@ -104,46 +217,65 @@ func (e *Encrypter) EncryptionKey(ctx context.Context, customObject v1alpha1.AWS
 	return keyName, nil
 }
 
-func (e *Encrypter) Encrypt(ctx context.Context, key, plaintext string) (string, error) {
-	err := e.ensureToken()
-	if err != nil {
-		return "", microerror.Mask(err)
-	}
+func (e *Encrypter) DataKey(ctx context.Context, key string) (encrypter.DataKey, error) {
+	generate := func() (encrypter.DataKey, error) {
+		err := e.ensureToken()
+		if err != nil {
+			return encrypter.DataKey{}, microerror.Mask(err)
+		}
 
-	encoded := base64.StdEncoding.EncodeToString([]byte(plaintext))
+		e.logger.LogCtx(ctx, "level", "debug", "message", "generating data key")
 
-	payload := &EncryptPayload{
-		Plaintext: encoded,
-	}
+		payload := &DataKeyPayload{
+			Bits: encrypter.DataKeySize * 8,
+		}
 
-	p := path.Join("transit", "encrypt", key)
+		p := path.Join(e.transitMountPath, "datakey", "plaintext", key)
 
-	req, err := e.newPayloadRequest(p, payload)
-	if err != nil {
-		return "", microerror.Mask(err)
-	}
+		req, err := e.newPayloadRequest(p, payload)
+		if err != nil {
+			return encrypter.DataKey{}, microerror.Mask(err)
+		}
 
-	resp, err := e.httpClient.Do(req)
-	if err != nil {
-		return "", microerror.Mask(err)
-	}
+		resp, err := e.httpClient.Do(req)
+		if err != nil {
+			return encrypter.DataKey{}, microerror.Mask(err)
+		}
 
-	defer resp.Body.Close()
+		defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return "", microerror.Maskf(invalidHTTPStatusCodeError, "want 200, got %d, response body: %q", resp.StatusCode, body)
+		if resp.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(resp.Body)
+			return encrypter.DataKey{}, microerror.Maskf(invalidHTTPStatusCodeError, "want 200, got %d, response body: %q", resp.StatusCode, body)
+		}
+
+		dataKeyResp := &DataKeyResponse{}
+		err = json.NewDecoder(resp.Body).Decode(dataKeyResp)
+		if err != nil {
+			return encrypter.DataKey{}, microerror.Mask(err)
+		}
+
+		plaintext, err := base64.StdEncoding.DecodeString(dataKeyResp.Data.Plaintext)
+		if err != nil {
+			return encrypter.DataKey{}, microerror.Mask(err)
+		}
+
+		e.logger.LogCtx(ctx, "level", "debug", "message", "generated data key")
+
+		dataKey := encrypter.DataKey{
+			Ciphertext: dataKeyResp.Data.Ciphertext,
+			Plaintext:  plaintext,
+		}
+
+		return dataKey, nil
 	}
 
-	encryptResp := &EncryptResponse{}
-	err = json.NewDecoder(resp.Body).Decode(encryptResp)
+	dataKey, err := e.dataKeys.Get(key, generate)
 	if err != nil {
-		return "", microerror.Mask(err)
+		return encrypter.DataKey{}, microerror.Mask(err)
 	}
 
-	ciphertext := encryptResp.Data.Ciphertext
-
-	return ciphertext, nil
+	return dataKey, nil
 }
 
 func (e *Encrypter) EnsureCreatedAuthorizedIAMRoles(ctx context.Context, customObject v1alpha1.AWSConfig) error {
@@ -233,7 +365,7 @@ func (e *Encrypter) EnsureCreatedEncryptionKey(ctx context.Context, customObject
 		e.logger.LogCtx(ctx, "level", "debug", "message", "creating encryption key")
 
 		key := e.keyName(customObject)
-		path := transitKeysPath(key)
+		path := transitKeysPath(e.transitMountPath, key)
 		payload := &struct{}{}
 
 		req, err := e.newPayloadRequest(path, payload)
@@ -340,7 +472,7 @@ func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject
 		e.logger.LogCtx(ctx, "level", "debug", "message", "ensuring encryption key is deletable")
 
 		key := e.keyName(customObject)
-		path := transitKeysConfigPath(key)
+		path := transitKeysConfigPath(e.transitMountPath, key)
 		payload := &KeyConfigPayload{
 			DeletionAllowed: true,
 		}
@@ -368,7 +500,7 @@ func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject
 		e.logger.LogCtx(ctx, "level", "debug", "message", "deleting encryption key")
 
 		key := e.keyName(customObject)
-		path := transitKeysPath(key)
+		path := transitKeysPath(e.transitMountPath, key)
 
 		req, err := e.newRequest("DELETE", path)
 		if err != nil {
@@ -386,6 +518,8 @@ func (e *Encrypter) EnsureDeletedEncryptionKey(ctx context.Context, customObject
 			return microerror.Maskf(invalidHTTPStatusCodeError, "want 204, got %d, response body: %q", resp.StatusCode, body)
 		}
 
+		e.dataKeys.Delete(key)
+
 		e.logger.LogCtx(ctx, "level", "debug", "message", "deleted encryption key")
 	}
 
@@ -406,7 +540,7 @@ func (e *Encrypter) Decrypt(key, ciphertext string) (string, error) {
 		Ciphertext: ciphertext,
 	}
 
-	p := path.Join("transit", "decrypt", key)
+	p := path.Join(e.transitMountPath, "decrypt", key)
 
 	req, err := e.newPayloadRequest(p, payload)
 	if err != nil {
@@ -445,7 +579,55 @@ func (e *Encrypter) Address() string {
 	return e.address
 }
 
+// AWSMountPath returns the mount path of the AWS auth method guest cluster
+// nodes authenticate with.
+func (e *Encrypter) AWSMountPath() string {
+	return e.awsMountPath
+}
+
+func (e *Encrypter) Namespace() string {
+	return e.namespace
+}
+
+func (e *Encrypter) TransitMountPath() string {
+	return e.transitMountPath
+}
+
+// ensureToken makes sure the encrypter holds a valid token. Renewable tokens
+// are renewed once tokenRenewRatio of their lease duration passed, so that they
+// do not expire in between reconciliations. In case the renewal fails, e.g.
+// because the token reached its max TTL, the encrypter logs in again.
 func (e *Encrypter) ensureToken() error {
+	e.loginMutex.Lock()
+	defer e.loginMutex.Unlock()
+
+	if e.getToken() == "" {
+		err := e.login()
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	if !e.tokenRenewAt.IsZero() && !time.Now().Before(e.tokenRenewAt) {
+		if e.tokenRenewable {
+			err := e.renewToken()
+			if err == nil {
+				return nil
+			}
+
+			e.logger.Log("level", "warning", "message", "could not renew token, logging in again", "stack", fmt.Sprintf("%#v", err))
+		}
+
+		err := e.login()
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
 	if e.isTokenValid() {
 		return nil
 	}
@@ -484,17 +666,80 @@ func (e *Encrypter) isTokenValid() bool {
 }
 
 func (e *Encrypter) login() error {
-	pkcs7, err := e.getPKCS7()
+	var p string
+	var payload interface{}
+	switch e.authMethod {
+	case AppRoleAuthMethod:
+		p = path.Join("auth", e.appRoleMountPath, "login")
+		payload = &AppRoleLoginPayload{
+			RoleID:   e.appRoleID,
+			SecretID: e.appRoleSecretID,
+		}
+	case AWSAuthMethod:
+		pkcs7, err := e.getPKCS7()
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		p = path.Join("auth", e.awsMountPath, "login")
+		payload = &LoginPayload{
+			Role:  e.awsRole,
+			PKCS7: pkcs7,
+			Nonce: e.nonce,
+		}
+	case KubernetesAuthMethod:
+		// The service account token is read on every login since it may be
+		// rotated.
+		jwt, err := ioutil.ReadFile(e.kubernetesTokenFile)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		p = path.Join("auth", e.kubernetesMountPath, "login")
+		payload = &KubernetesLoginPayload{
+			Role: e.kubernetesRole,
+			JWT:  strings.TrimSpace(string(jwt)),
+		}
+	}
+
+	req, err := e.newPayloadRequest(p, payload)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Logging in does not require a token, so a possibly expired one is not
+	// sent along.
+	req.Header.Del("X-Vault-Token")
+
+	resp, err := e.httpClient.Do(req)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	payload := &LoginPayload{
-		Role:  defaultRole,
-		PKCS7: pkcs7,
-		Nonce: e.nonce,
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return microerror.Maskf(invalidHTTPStatusCodeError, "want 200, got %d, response body: %q", resp.StatusCode, body)
+	}
+
+	loginResp := &LoginResponse{}
+	err = json.NewDecoder(resp.Body).Decode(loginResp)
+	if err != nil {
+		return microerror.Mask(err)
 	}
-	p := path.Join("auth", "aws", "login")
+
+	e.setToken(loginResp.Auth)
+	if e.authMethod == AWSAuthMethod {
+		e.nonce = loginResp.Auth.Metadata.Nonce
+	}
+
+	return nil
+}
+
+func (e *Encrypter) renewToken() error {
+	p := path.Join("auth", "token", "renew-self")
+	payload := &struct{}{}
 
 	req, err := e.newPayloadRequest(p, payload)
 	if err != nil {
@@ -513,18 +758,40 @@ func (e *Encrypter) login() error {
 		return microerror.Maskf(invalidHTTPStatusCodeError, "want 200, got %d, response body: %q", resp.StatusCode, body)
 	}
 
-	loginResp := &LoginResponse{}
-	err = json.NewDecoder(resp.Body).Decode(loginResp)
+	renewResp := &LoginResponse{}
+	err = json.NewDecoder(resp.Body).Decode(renewResp)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	e.token = loginResp.Auth.ClientToken
-	e.nonce = loginResp.Auth.Metadata.Nonce
+	e.setToken(renewResp.Auth)
 
 	return nil
 }
 
+func (e *Encrypter) getToken() string {
+	e.tokenMutex.Lock()
+	defer e.tokenMutex.Unlock()
+
+	return e.token
+}
+
+// setToken stores the token of the given auth response and schedules its
+// renewal. Tokens without lease duration do not expire and are never renewed.
+func (e *Encrypter) setToken(auth LoginAuthResponse) {
+	e.tokenMutex.Lock()
+	e.token = auth.ClientToken
+	e.tokenMutex.Unlock()
+
+	e.tokenRenewable = auth.Renewable
+	if auth.LeaseDuration > 0 {
+		lease := time.Duration(auth.LeaseDuration) * time.Second
+		e.tokenRenewAt = time.Now().Add(time.Duration(float64(lease) * tokenRenewRatio))
+	} else {
+		e.tokenRenewAt = time.Time{}
+	}
+}
+
 func (e *Encrypter) newRequest(method, path string) (*http.Request, error) {
 	u := &url.URL{Path: path}
 	dest := e.base.ResolveReference(u)
@@ -536,8 +803,12 @@ func (e *Encrypter) newRequest(method, path string) (*http.Request, error) {
 		return nil, microerror.Mask(err)
 	}
 
-	if e.token != "" {
-		req.Header.Set("X-Vault-Token", e.token)
+	if e.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", e.namespace)
+	}
+	token := e.getToken()
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
 	}
 
 	return req, nil
@@ -558,8 +829,12 @@ func (e *Encrypter) newPayloadRequest(path string, payload interface{}) (*http.R
 		return nil, microerror.Mask(err)
 	}
 
-	if e.token != "" {
-		req.Header.Set("X-Vault-Token", e.token)
+	if e.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", e.namespace)
+	}
+	token := e.getToken()
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
 	}
 
 	return req, nil
@@ -581,7 +856,7 @@ func (e *Encrypter) getPKCS7() (string, error) {
 }
 
 func (e *Encrypter) getAuthAWSRole(name string) (*AWSAuthRole, error) {
-	path := authAWSRolePath(name)
+	path := authAWSRolePath(e.awsMountPath, name)
 
 	req, err := e.newRequest("GET", path)
 	if err != nil {
@@ -609,7 +884,7 @@ func (e *Encrypter) getAuthAWSRole(name string) (*AWSAuthRole, error) {
 }
 
 func (e *Encrypter) postAuthAWSRole(name string, data *AWSAuthRole) error {
-	path := authAWSRolePath(name)
+	path := authAWSRolePath(e.awsMountPath, name)
 
 	req, err := e.newPayloadRequest(path, data)
 	if err != nil {
@@ -635,14 +910,14 @@ func (e *Encrypter) keyName(customObject v1alpha1.AWSConfig) string {
 	return key.ClusterID(customObject)
 }
 
-func authAWSRolePath(role string) string {
-	return path.Join("auth", "aws", "role", role)
+func authAWSRolePath(mountPath, role string) string {
+	return path.Join("auth", mountPath, "role", role)
 }
 
-func transitKeysConfigPath(key string) string {
-	return path.Join("transit", "keys", key, "config")
+func transitKeysConfigPath(mountPath, key string) string {
+	return path.Join(mountPath, "keys", key, "config")
 }
 
-func transitKeysPath(key string) string {
-	return path.Join("transit", "keys", key)
+func transitKeysPath(mountPath, key string) string {
+	return path.Join(mountPath, "keys", key)
 }
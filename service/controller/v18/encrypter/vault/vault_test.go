@@ -0,0 +1,386 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+func Test_NewEncrypter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	defer os.RemoveAll(dir)
+
+	invalidCAFile := filepath.Join(dir, "invalid-ca.pem")
+	err = ioutil.WriteFile(invalidCAFile, []byte("not a certificate"), 0600)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	testCases := []struct {
+		name         string
+		config       func(c *EncrypterConfig)
+		errorMatcher func(error) bool
+	}{
+		{
+			name:         "case 0: aws auth method is valid",
+			config:       func(c *EncrypterConfig) {},
+			errorMatcher: nil,
+		},
+		{
+			name: "case 1: approle auth method is valid",
+			config: func(c *EncrypterConfig) {
+				c.AuthMethod = AppRoleAuthMethod
+				c.AppRoleMountPath = "approle"
+				c.AppRoleID = "role-id"
+			},
+			errorMatcher: nil,
+		},
+		{
+			name: "case 2: approle auth method without role ID is invalid",
+			config: func(c *EncrypterConfig) {
+				c.AuthMethod = AppRoleAuthMethod
+				c.AppRoleMountPath = "approle"
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 3: kubernetes auth method is valid",
+			config: func(c *EncrypterConfig) {
+				c.AuthMethod = KubernetesAuthMethod
+				c.KubernetesMountPath = "kubernetes"
+				c.KubernetesRole = "aws-operator"
+				c.KubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+			},
+			errorMatcher: nil,
+		},
+		{
+			name: "case 4: kubernetes auth method without role is invalid",
+			config: func(c *EncrypterConfig) {
+				c.AuthMethod = KubernetesAuthMethod
+				c.KubernetesMountPath = "kubernetes"
+				c.KubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 5: unknown auth method is invalid",
+			config: func(c *EncrypterConfig) {
+				c.AuthMethod = "userpass"
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 6: empty transit mount path is invalid",
+			config: func(c *EncrypterConfig) {
+				c.TransitMountPath = ""
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 7: CA file without certificates is invalid",
+			config: func(c *EncrypterConfig) {
+				c.CAFile = invalidCAFile
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &EncrypterConfig{
+				Logger: microloggertest.New(),
+
+				Address:          "https://vault",
+				AuthMethod:       AWSAuthMethod,
+				AWSMountPath:     "aws",
+				AWSRole:          "encrypter",
+				TransitMountPath: "transit",
+			}
+			tc.config(c)
+
+			_, err := NewEncrypter(c)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
+
+func Test_Encrypter_DataKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tokenFile := filepath.Join(dir, "token")
+	err = ioutil.WriteFile(tokenFile, []byte("service-account-jwt\n"), 0600)
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	testCases := []struct {
+		name              string
+		config            func(c *EncrypterConfig)
+		expectedLoginPath string
+		expectedLogin     map[string]string
+	}{
+		{
+			name: "case 0: approle auth method",
+			config: func(c *EncrypterConfig) {
+				c.AuthMethod = AppRoleAuthMethod
+				c.AppRoleMountPath = "approle"
+				c.AppRoleID = "role-id"
+				c.AppRoleSecretID = "secret-id"
+			},
+			expectedLoginPath: "/v1/auth/approle/login",
+			expectedLogin: map[string]string{
+				"role_id":   "role-id",
+				"secret_id": "secret-id",
+			},
+		},
+		{
+			name: "case 1: kubernetes auth method with custom mount path and namespace",
+			config: func(c *EncrypterConfig) {
+				c.AuthMethod = KubernetesAuthMethod
+				c.KubernetesMountPath = "installation/kubernetes"
+				c.KubernetesRole = "aws-operator"
+				c.KubernetesTokenFile = tokenFile
+				c.Namespace = "giantswarm"
+				c.TransitMountPath = "installation/transit"
+			},
+			expectedLoginPath: "/v1/auth/installation/kubernetes/login",
+			expectedLogin: map[string]string{
+				"role": "aws-operator",
+				"jwt":  "service-account-jwt",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &EncrypterConfig{
+				Logger: microloggertest.New(),
+
+				AWSMountPath:     "aws",
+				TransitMountPath: "transit",
+			}
+			tc.config(c)
+
+			vault := newTestVault(t, c.Namespace, c.TransitMountPath, 3600)
+			defer vault.Close()
+			c.Address = vault.URL
+
+			e, err := NewEncrypter(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			dataKey, err := e.DataKey(context.Background(), "al9qy")
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			if dataKey.Ciphertext != "vault:v1:al9qy" {
+				t.Fatalf("data key ciphertext == %q, want %q", dataKey.Ciphertext, "vault:v1:al9qy")
+			}
+
+			if len(vault.logins) != 1 {
+				t.Fatalf("logins == %d, want %d", len(vault.logins), 1)
+			}
+			if vault.logins[0].path != tc.expectedLoginPath {
+				t.Fatalf("login path == %q, want %q", vault.logins[0].path, tc.expectedLoginPath)
+			}
+			for k, v := range tc.expectedLogin {
+				if vault.logins[0].payload[k] != v {
+					t.Fatalf("login payload %q == %q, want %q", k, vault.logins[0].payload[k], v)
+				}
+			}
+		})
+	}
+}
+
+func Test_Encrypter_ensureToken(t *testing.T) {
+	testCases := []struct {
+		name           string
+		leaseDuration  int
+		renewFails     bool
+		expectedLogins int
+		expectedRenews int
+	}{
+		{
+			name:           "case 0: token is renewed before it expires",
+			leaseDuration:  3600,
+			expectedLogins: 1,
+			expectedRenews: 1,
+		},
+		{
+			name:           "case 1: encrypter logs in again when renewal fails",
+			leaseDuration:  3600,
+			renewFails:     true,
+			expectedLogins: 2,
+			expectedRenews: 1,
+		},
+		{
+			name:           "case 2: token without lease duration is not renewed",
+			leaseDuration:  0,
+			expectedLogins: 1,
+			expectedRenews: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vault := newTestVault(t, "", "transit", tc.leaseDuration)
+			defer vault.Close()
+			vault.renewFails = tc.renewFails
+
+			c := &EncrypterConfig{
+				Logger: microloggertest.New(),
+
+				Address:          vault.URL,
+				AppRoleMountPath: "approle",
+				AppRoleID:        "role-id",
+				AuthMethod:       AppRoleAuthMethod,
+				AWSMountPath:     "aws",
+				TransitMountPath: "transit",
+			}
+
+			e, err := NewEncrypter(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			err = e.ensureToken()
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			// Pretend the renewal of the token is due.
+			if !e.tokenRenewAt.IsZero() {
+				e.tokenRenewAt = time.Now().Add(-time.Second)
+			}
+
+			err = e.ensureToken()
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if len(vault.logins) != tc.expectedLogins {
+				t.Fatalf("logins == %d, want %d", len(vault.logins), tc.expectedLogins)
+			}
+			if vault.renews != tc.expectedRenews {
+				t.Fatalf("renews == %d, want %d", vault.renews, tc.expectedRenews)
+			}
+			if tc.leaseDuration > 0 && !e.tokenRenewAt.After(time.Now()) {
+				t.Fatalf("token renewal at %s, want future", e.tokenRenewAt)
+			}
+		})
+	}
+}
+
+type testLogin struct {
+	path    string
+	payload map[string]string
+}
+
+type testVault struct {
+	*httptest.Server
+
+	mutex      sync.Mutex
+	logins     []testLogin
+	renews     int
+	renewFails bool
+}
+
+// newTestVault returns a fake Vault server implementing the endpoints the
+// encrypter uses for logging in, renewing its token and generating data keys.
+// Requests are rejected in case they are not scoped to the given namespace.
+func newTestVault(t *testing.T, namespace string, transitMountPath string, leaseDuration int) *testVault {
+	v := &testVault{}
+
+	auth := func(w http.ResponseWriter) {
+		resp := map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token":   "token",
+				"lease_duration": leaseDuration,
+				"renewable":      true,
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+
+	v.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+
+		if r.Header.Get("X-Vault-Namespace") != namespace {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/v1/auth/token/renew-self":
+			v.renews++
+			if v.renewFails {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			auth(w)
+
+		case r.URL.Path == "/v1/auth/token/lookup-self":
+			if r.Header.Get("X-Vault-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+
+		case filepath.Base(r.URL.Path) == "login":
+			payload := map[string]string{}
+			err := json.NewDecoder(r.Body).Decode(&payload)
+			if err != nil {
+				t.Errorf("error == %#v, want nil", err)
+			}
+			v.logins = append(v.logins, testLogin{path: r.URL.Path, payload: payload})
+			auth(w)
+
+		case filepath.Dir(r.URL.Path) == "/v1/"+transitMountPath+"/datakey/plaintext":
+			if r.Header.Get("X-Vault-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			resp := map[string]interface{}{
+				"data": map[string]interface{}{
+					"ciphertext": "vault:v1:" + filepath.Base(r.URL.Path),
+					"plaintext":  base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")),
+				},
+			}
+			json.NewEncoder(w).Encode(resp)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return v
+}
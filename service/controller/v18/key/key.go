@@ -3,6 +3,7 @@ package key
 import (
 	"crypto/sha1"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 
+	"github.com/giantswarm/aws-operator/pkg/ipam"
 	"github.com/giantswarm/aws-operator/service/controller/v18/templates/cloudconfig"
 	"github.com/giantswarm/aws-operator/service/controller/v18/templates/cloudformation/guest"
 	"github.com/giantswarm/aws-operator/service/controller/v18/templates/cloudformation/hostpost"
@@ -31,6 +33,20 @@ const (
 	// and managed by a cluster.
 	CloudProviderTagOwnedValue = "owned"
 
+	// CloudProviderTagSharedValue is used to indicate an AWS resource is used
+	// but not owned by a cluster, e.g. the subnets of existing VPCs.
+	CloudProviderTagSharedValue = "shared"
+
+	// ClusterAutoscalerEnabledTagName and ClusterAutoscalerTagName are used by
+	// cluster-autoscaler to discover the ASGs it may scale.
+	ClusterAutoscalerEnabledTagName = "k8s.io/cluster-autoscaler/enabled"
+	ClusterAutoscalerTagName        = "k8s.io/cluster-autoscaler/%s"
+
+	// EtcdPeerCert is the certificate the etcd members of guest clusters with
+	// multiple masters present to their peers. Other than the etcd server
+	// certificate it is issued for the domains of all etcd members.
+	EtcdPeerCert = "etcd-peer"
+
 	// EnableTerminationProtection is used to protect the CF stacks from deletion.
 	EnableTerminationProtection = true
 
@@ -50,28 +66,105 @@ const (
 	LogDeliveryURI = "uri=http://acs.amazonaws.com/groups/s3/LogDelivery"
 
 	InstanceIDAnnotation = "aws-operator.giantswarm.io/instance"
+	// ASGNameAnnotation transports the name of the ASG a drained instance
+	// belongs to, since guest clusters might run multiple worker ASGs.
+	ASGNameAnnotation = "aws-operator.giantswarm.io/asg"
+	// ApprovedChangeSetAnnotation approves the execution of the change set of
+	// the guest cluster main stack named by its value, in case the change set
+	// has to be approved since it replaces or removes resources.
+	ApprovedChangeSetAnnotation = "aws-operator.giantswarm.io/approved-change-set"
+	// StackRecoveryAnnotation opts guest clusters into the automatic recovery
+	// of guest cluster main stacks stuck in ROLLBACK_COMPLETE or
+	// UPDATE_ROLLBACK_FAILED when set to "true".
+	StackRecoveryAnnotation = "aws-operator.giantswarm.io/stack-recovery"
+
+	// DefaultNodePoolName is the name of the single node pool guest clusters
+	// run in case no node pools are configured.
+	DefaultNodePoolName = "default"
+
+	// LoadBalancerTypeClassic and LoadBalancerTypeNetwork are the types of the
+	// load balancers of the Kubernetes API and the ingress controller.
+	LoadBalancerTypeClassic = "classic"
+	LoadBalancerTypeNetwork = "network"
+
+	// VPCEndpointServiceS3 is the AWS service the VPC of the guest cluster gets
+	// a gateway endpoint for. All other VPC endpoints are interface endpoints.
+	VPCEndpointServiceS3 = "s3"
+
+	// VPCFlowLogsPrefix is the prefix the VPC flow logs of the guest cluster are
+	// delivered to in the logging bucket.
+	VPCFlowLogsPrefix = "vpc-flow-logs"
+
+	// VPCFlowLogsTrafficTypeAccept, VPCFlowLogsTrafficTypeAll and
+	// VPCFlowLogsTrafficTypeReject are the types of traffic captured by the VPC
+	// flow logs.
+	VPCFlowLogsTrafficTypeAccept = "ACCEPT"
+	VPCFlowLogsTrafficTypeAll    = "ALL"
+	VPCFlowLogsTrafficTypeReject = "REJECT"
 
 	chinaAWSCliContainerRegistry   = "docker://registry-intl.cn-shanghai.aliyuncs.com/giantswarm/awscli:latest"
 	defaultAWSCliContainerRegistry = "quay.io/coreos/awscli:025a357f05242fdad6a81e8a6b520098aa65a600"
 	defaultDockerVolumeSizeGB      = 100
+
+	// Defaults of the classic ELBs of the Kubernetes API and the ingress
+	// controller in case the custom object does not configure them.
+	defaultAPIELBIdleTimeoutSeconds            = 1200
+	defaultELBAccessLogsEmitIntervalMinutes    = 60
+	defaultELBConnectionDrainingTimeoutSeconds = 300
+	defaultELBHealthCheckHealthyThreshold      = 2
+	defaultELBHealthCheckIntervalSeconds       = 5
+	defaultELBHealthCheckTimeoutSeconds        = 3
+	defaultELBHealthCheckUnhealthyThreshold    = 2
+	defaultIngressELBIdleTimeoutSeconds        = 60
 )
 
 const (
-	DockerVolumeResourceNameKey   = "DockerVolumeResourceName"
-	HostedZoneNameServers         = "HostedZoneNameServers"
-	MasterImageIDKey              = "MasterImageID"
-	MasterInstanceResourceNameKey = "MasterInstanceResourceName"
-	MasterInstanceTypeKey         = "MasterInstanceType"
-	MasterInstanceMonitoring      = "Monitoring"
-	MasterCloudConfigVersionKey   = "MasterCloudConfigVersion"
-	WorkerASGKey                  = "WorkerASGName"
-	WorkerCountKey                = "WorkerCount"
-	WorkerDockerVolumeSizeKey     = "WorkerDockerVolumeSizeGB"
-	WorkerImageIDKey              = "WorkerImageID"
-	WorkerInstanceMonitoring      = "Monitoring"
-	WorkerInstanceTypeKey         = "WorkerInstanceType"
-	WorkerCloudConfigVersionKey   = "WorkerCloudConfigVersion"
-	VersionBundleVersionKey       = "VersionBundleVersion"
+	APIPrivateKey                     = "APIPrivate"
+	DockerVolumeResourceNameKey       = "DockerVolumeResourceName"
+	DockerVolumeResourceNamesKey      = "DockerVolumeResourceNames"
+	DefaultImageIDKey                 = "DefaultImageID"
+	HostedZoneNameServers             = "HostedZoneNameServers"
+	ImageCatalogSourceKey             = "ImageCatalogSource"
+	LoadBalancerHashKey               = "LoadBalancerHash"
+	LoadBalancerSwitchTimeKey         = "LoadBalancerSwitchTime"
+	LoadBalancerTypesKey              = "LoadBalancerTypes"
+	MasterImageIDKey                  = "MasterImageID"
+	MasterImageIDsKey                 = "MasterImageIDs"
+	MasterInstanceResourceNameKey     = "MasterInstanceResourceName"
+	MasterInstanceResourceNamesKey    = "MasterInstanceResourceNames"
+	MasterInstanceTypeKey             = "MasterInstanceType"
+	MasterInstanceTypesKey            = "MasterInstanceTypes"
+	MasterInstanceMonitoring          = "Monitoring"
+	MasterCloudConfigVersionKey       = "MasterCloudConfigVersion"
+	MasterCloudConfigVersionsKey      = "MasterCloudConfigVersions"
+	MasterVersionBundleVersionsKey    = "MasterVersionBundleVersions"
+	PrivateHostedZoneIDKey            = "PrivateHostedZoneID"
+	SecurityGroupsHashKey             = "SecurityGroupsHash"
+	TransitGatewayAttachmentIDKey     = "TransitGatewayAttachmentID"
+	WorkerASGKey                      = "WorkerASGName"
+	WorkerASGNamesKey                 = "WorkerASGNames"
+	WorkerCountKey                    = "WorkerCount"
+	WorkerDockerVolumeSizeKey         = "WorkerDockerVolumeSizeGB"
+	WorkerImageIDKey                  = "WorkerImageID"
+	WorkerInstanceMonitoring          = "Monitoring"
+	WorkerInstanceTypeKey             = "WorkerInstanceType"
+	WorkerPoolCountsKey               = "WorkerPoolCounts"
+	WorkerPoolDockerVolumeSizesKey    = "WorkerPoolDockerVolumeSizesGB"
+	WorkerPoolInstanceTypesKey        = "WorkerPoolInstanceTypes"
+	WorkerPoolLaunchTemplateHashesKey = "WorkerPoolLaunchTemplateHashes"
+	WorkerPoolNamesKey                = "WorkerPoolNames"
+	WorkerPoolScalingMaxesKey         = "WorkerPoolScalingMaxes"
+	WorkerPoolScalingMinsKey          = "WorkerPoolScalingMins"
+	WorkerPoolSpotEnabledKey          = "WorkerPoolSpotEnabled"
+	WorkerPoolSpotInstanceTypesKey    = "WorkerPoolSpotInstanceTypes"
+	WorkerPoolSpotMaxPricesKey        = "WorkerPoolSpotMaxPrices"
+	WorkerPoolSpotOnDemandBasesKey    = "WorkerPoolSpotOnDemandBaseCapacities"
+	WorkerPoolSpotOnDemandPercentsKey = "WorkerPoolSpotOnDemandPercentagesAboveBaseCapacity"
+	WorkerCloudConfigVersionKey       = "WorkerCloudConfigVersion"
+	VersionBundleVersionKey           = "VersionBundleVersion"
+	VPCConnectionsHashKey             = "VPCConnectionsHash"
+	VPCEndpointsKey                   = "VPCEndpoints"
+	VPCFlowLogsTrafficTypeKey         = "VPCFlowLogsTrafficType"
 )
 
 const (
@@ -102,8 +195,34 @@ func AutoScalingGroupName(customObject v1alpha1.AWSConfig, groupName string) str
 	return fmt.Sprintf("%s-%s", ClusterID(customObject), groupName)
 }
 
+// ApprovedChangeSet returns the name of the change set of the guest cluster
+// main stack approved by the custom object.
+func ApprovedChangeSet(customObject v1alpha1.AWSConfig) string {
+	return customObject.Annotations[ApprovedChangeSetAnnotation]
+}
+
+// APIWhitelist returns the CIDRs allowed to access the Kubernetes API of the
+// guest cluster in addition to the ones whitelisted for the installation.
+func APIWhitelist(customObject v1alpha1.AWSConfig) []string {
+	return customObject.Spec.AWS.API.Whitelist
+}
+
+// AvailabilityZone returns the availability zone the master instance is
+// placed in. This is the first of the availability zones the guest cluster is
+// spread across.
 func AvailabilityZone(customObject v1alpha1.AWSConfig) string {
-	return customObject.Spec.AWS.AZ
+	return AvailabilityZones(customObject)[0]
+}
+
+// AvailabilityZones returns the availability zones the guest cluster is spread
+// across. Guest clusters not configuring multiple availability zones only use
+// the single availability zone given in the AZ field of the custom object.
+func AvailabilityZones(customObject v1alpha1.AWSConfig) []string {
+	if len(customObject.Spec.AWS.AvailabilityZones) > 0 {
+		return customObject.Spec.AWS.AvailabilityZones
+	}
+
+	return []string{customObject.Spec.AWS.AZ}
 }
 
 func AWSCliContainerRegistry(customObject v1alpha1.AWSConfig) string {
@@ -146,7 +265,7 @@ func CloudFormationGuestTemplates() []string {
 		guest.IAMPolicies,
 		guest.Instance,
 		guest.InternetGateway,
-		guest.LaunchConfiguration,
+		guest.LaunchTemplate,
 		guest.LoadBalancers,
 		guest.Main,
 		guest.NatGateway,
@@ -157,6 +276,9 @@ func CloudFormationGuestTemplates() []string {
 		guest.SecurityGroups,
 		guest.Subnets,
 		guest.VPC,
+		guest.VPCConnections,
+		guest.VPCEndpoints,
+		guest.VPCParameters,
 	}
 }
 
@@ -165,6 +287,7 @@ func CloudFormationHostPostTemplates() []string {
 		hostpost.Main,
 		hostpost.RecordSets,
 		hostpost.RouteTables,
+		hostpost.TransitGateway,
 	}
 }
 
@@ -172,6 +295,7 @@ func CloudFormationHostPreTemplates() []string {
 	return []string{
 		hostpre.IAMRoles,
 		hostpre.Main,
+		hostpre.ResourceShares,
 	}
 }
 
@@ -179,6 +303,10 @@ func ClusterCloudProviderTag(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf(CloudProviderTagName, ClusterID(customObject))
 }
 
+func ClusterAutoscalerTag(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf(ClusterAutoscalerTagName, ClusterID(customObject))
+}
+
 func ClusterCustomer(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.Cluster.Customer.ID
 }
@@ -229,10 +357,45 @@ func DockerVolumeName(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf("%s-docker", ClusterID(customObject))
 }
 
+// DockerVolumeNames returns the Name tags of the docker volumes of all master
+// instances.
+func DockerVolumeNames(customObject v1alpha1.AWSConfig) []string {
+	return masterIndexedNames(customObject, DockerVolumeName(customObject))
+}
+
 func EtcdVolumeName(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf("%s-etcd", ClusterID(customObject))
 }
 
+// EtcdVolumeNames returns the Name tags of the etcd volumes of all master
+// instances.
+func EtcdVolumeNames(customObject v1alpha1.AWSConfig) []string {
+	return masterIndexedNames(customObject, EtcdVolumeName(customObject))
+}
+
+// EtcdMemberName returns the name of the etcd member running on the master
+// instance with the given index.
+func EtcdMemberName(index int) string {
+	return fmt.Sprintf("etcd%d", index)
+}
+
+// EtcdMemberDomain returns the domain the etcd member running on the master
+// instance with the given index advertises to its peers.
+func EtcdMemberDomain(customObject v1alpha1.AWSConfig, index int) string {
+	return fmt.Sprintf("%s.%s.k8s.%s", EtcdMemberName(index), ClusterID(customObject), BaseDomain(customObject))
+}
+
+// EtcdMemberDomains returns the domains of the etcd members of all master
+// instances.
+func EtcdMemberDomains(customObject v1alpha1.AWSConfig) []string {
+	var domains []string
+	for i := 0; i < MasterCount(customObject); i++ {
+		domains = append(domains, EtcdMemberDomain(customObject, i))
+	}
+
+	return domains
+}
+
 func EC2ServiceDomain(customObject v1alpha1.AWSConfig) string {
 	domain := "ec2.amazonaws.com"
 
@@ -263,6 +426,19 @@ func HostedZoneNameIngress(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.AWS.HostedZones.Ingress.Name
 }
 
+// PrivateHostedZoneEnabled returns true when the records of the guest cluster
+// domain are managed in a private hosted zone associated with the guest
+// cluster VPC, in addition to the public hosted zone.
+func PrivateHostedZoneEnabled(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.HostedZones.Private.Enabled
+}
+
+// PrivateHostedZoneHostVPC returns true when the private hosted zone of the
+// guest cluster domain is also associated with the host cluster VPC.
+func PrivateHostedZoneHostVPC(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.HostedZones.Private.HostVPC
+}
+
 func IngressControllerInsecurePort(customObject v1alpha1.AWSConfig) int {
 	return customObject.Spec.Cluster.Kubernetes.IngressController.InsecurePort
 }
@@ -283,6 +459,105 @@ func IsDeleted(customObject v1alpha1.AWSConfig) bool {
 	return customObject.GetDeletionTimestamp() != nil
 }
 
+// IsStackRecoveryEnabled returns true when the custom object opts into the
+// automatic recovery of its guest cluster main stack.
+func IsStackRecoveryEnabled(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Annotations[StackRecoveryAnnotation] == "true"
+}
+
+// IsAPIPrivate returns true when the Kubernetes API of the guest cluster is
+// only reachable from within its VPC and the networks peered with it.
+func IsAPIPrivate(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.API.Private
+}
+
+// LoadBalancerType returns the type of the load balancers of the Kubernetes API
+// and the ingress controller. Classic ELBs are used unless configured
+// otherwise.
+func LoadBalancerType(customObject v1alpha1.AWSConfig) string {
+	if customObject.Spec.AWS.LoadBalancerType == "" {
+		return LoadBalancerTypeClassic
+	}
+
+	return customObject.Spec.AWS.LoadBalancerType
+}
+
+// APIELBIdleTimeoutSeconds returns the idle timeout of the Kubernetes API
+// ELB.
+func APIELBIdleTimeoutSeconds(customObject v1alpha1.AWSConfig) int {
+	if customObject.Spec.AWS.API.ELB.IdleTimeoutSeconds == 0 {
+		return defaultAPIELBIdleTimeoutSeconds
+	}
+
+	return customObject.Spec.AWS.API.ELB.IdleTimeoutSeconds
+}
+
+// IngressELBIdleTimeoutSeconds returns the idle timeout of the ingress
+// controller ELB. Long lived connections like websockets need higher values
+// than the default.
+func IngressELBIdleTimeoutSeconds(customObject v1alpha1.AWSConfig) int {
+	if customObject.Spec.AWS.Ingress.ELB.IdleTimeoutSeconds == 0 {
+		return defaultIngressELBIdleTimeoutSeconds
+	}
+
+	return customObject.Spec.AWS.Ingress.ELB.IdleTimeoutSeconds
+}
+
+func ELBAccessLogsEnabled(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.ELB.AccessLogs.Enabled
+}
+
+func ELBAccessLogsEmitIntervalMinutes(customObject v1alpha1.AWSConfig) int {
+	if customObject.Spec.AWS.ELB.AccessLogs.EmitIntervalMinutes == 0 {
+		return defaultELBAccessLogsEmitIntervalMinutes
+	}
+
+	return customObject.Spec.AWS.ELB.AccessLogs.EmitIntervalMinutes
+}
+
+func ELBConnectionDrainingEnabled(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.ELB.ConnectionDraining.Enabled
+}
+
+func ELBConnectionDrainingTimeoutSeconds(customObject v1alpha1.AWSConfig) int {
+	if customObject.Spec.AWS.ELB.ConnectionDraining.TimeoutSeconds == 0 {
+		return defaultELBConnectionDrainingTimeoutSeconds
+	}
+
+	return customObject.Spec.AWS.ELB.ConnectionDraining.TimeoutSeconds
+}
+
+func ELBCrossZone(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.ELB.CrossZone
+}
+
+// ELBHealthCheck returns the health check settings of the ELBs with defaults
+// applied to all settings the custom object does not configure.
+func ELBHealthCheck(customObject v1alpha1.AWSConfig) v1alpha1.AWSConfigSpecAWSELBHealthCheck {
+	healthCheck := customObject.Spec.AWS.ELB.HealthCheck
+
+	if healthCheck.HealthyThreshold == 0 {
+		healthCheck.HealthyThreshold = defaultELBHealthCheckHealthyThreshold
+	}
+	if healthCheck.IntervalSeconds == 0 {
+		healthCheck.IntervalSeconds = defaultELBHealthCheckIntervalSeconds
+	}
+	if healthCheck.TimeoutSeconds == 0 {
+		healthCheck.TimeoutSeconds = defaultELBHealthCheckTimeoutSeconds
+	}
+	if healthCheck.UnhealthyThreshold == 0 {
+		healthCheck.UnhealthyThreshold = defaultELBHealthCheckUnhealthyThreshold
+	}
+
+	return healthCheck
+}
+
+// IsExistingVPC returns true when the guest cluster is deployed into an existing
+// VPC and existing subnets instead of creating its own.
+func IsExistingVPC(customObject v1alpha1.AWSConfig) bool {
+	return VPCID(customObject) != ""
+}
+
 func KubernetesAPISecurePort(customObject v1alpha1.AWSConfig) int {
 	return customObject.Spec.Cluster.Kubernetes.API.SecurePort
 }
@@ -336,6 +611,22 @@ func MasterImageID(customObject v1alpha1.AWSConfig) string {
 	return imageID
 }
 
+// MasterImageIDs returns the AMIs configured in the custom object for every
+// master instance. The AMI of a master is empty in case it is not configured,
+// in which case the AMI is resolved by the AMI catalog.
+func MasterImageIDs(customObject v1alpha1.AWSConfig) []string {
+	var imageIDs []string
+	for i := range MasterInstanceNames(customObject) {
+		var imageID string
+		if i < len(customObject.Spec.AWS.Masters) {
+			imageID = customObject.Spec.AWS.Masters[i].ImageID
+		}
+		imageIDs = append(imageIDs, imageID)
+	}
+
+	return imageIDs
+}
+
 func MasterInstanceResourceName(customObject v1alpha1.AWSConfig) string {
 	return getResourcenameWithTimeHash("MasterInstance", customObject)
 }
@@ -346,6 +637,11 @@ func MasterInstanceName(customObject v1alpha1.AWSConfig) string {
 	return fmt.Sprintf("%s-master", clusterID)
 }
 
+// MasterInstanceNames returns the Name tags of all master instances.
+func MasterInstanceNames(customObject v1alpha1.AWSConfig) []string {
+	return masterIndexedNames(customObject, MasterInstanceName(customObject))
+}
+
 func MasterInstanceType(customObject v1alpha1.AWSConfig) string {
 	var instanceType string
 
@@ -376,6 +672,52 @@ func PrivateSubnetCIDR(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.AWS.VPC.PrivateSubnetCIDR
 }
 
+// PrivateSubnetCIDRs returns the CIDRs of the private subnets of the guest
+// cluster, one per availability zone, in the order of AvailabilityZones.
+func PrivateSubnetCIDRs(customObject v1alpha1.AWSConfig) ([]string, error) {
+	if len(AvailabilityZones(customObject)) == 1 {
+		return []string{PrivateSubnetCIDR(customObject)}, nil
+	}
+
+	_, private, err := subnetCIDRs(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return private, nil
+}
+
+// PrivateSubnetIDs returns the IDs of the existing private subnets the guest
+// cluster is deployed into, one per availability zone.
+func PrivateSubnetIDs(customObject v1alpha1.AWSConfig) []string {
+	return customObject.Spec.AWS.VPC.PrivateSubnetIDs
+}
+
+func PublicSubnetCIDR(customObject v1alpha1.AWSConfig) string {
+	return customObject.Spec.AWS.VPC.PublicSubnetCIDR
+}
+
+// PublicSubnetCIDRs returns the CIDRs of the public subnets of the guest
+// cluster, one per availability zone, in the order of AvailabilityZones.
+func PublicSubnetCIDRs(customObject v1alpha1.AWSConfig) ([]string, error) {
+	if len(AvailabilityZones(customObject)) == 1 {
+		return []string{PublicSubnetCIDR(customObject)}, nil
+	}
+
+	public, _, err := subnetCIDRs(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return public, nil
+}
+
+// PublicSubnetIDs returns the IDs of the existing public subnets the guest
+// cluster is deployed into, one per availability zone.
+func PublicSubnetIDs(customObject v1alpha1.AWSConfig) []string {
+	return customObject.Spec.AWS.VPC.PublicSubnetIDs
+}
+
 func CIDR(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.AWS.VPC.CIDR
 }
@@ -416,6 +758,24 @@ func SecurityGroupName(customObject v1alpha1.AWSConfig, groupName string) string
 	return fmt.Sprintf("%s-%s", ClusterID(customObject), groupName)
 }
 
+// IngressSecurityGroupRules returns the additional ingress rules of the
+// security group of the ingress ELB configured in the custom object.
+func IngressSecurityGroupRules(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigSpecAWSSecurityGroupRule {
+	return customObject.Spec.AWS.SecurityGroups.Ingress
+}
+
+// MasterSecurityGroupRules returns the additional ingress rules of the
+// security group of the masters configured in the custom object.
+func MasterSecurityGroupRules(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigSpecAWSSecurityGroupRule {
+	return customObject.Spec.AWS.SecurityGroups.Master
+}
+
+// WorkerSecurityGroupRules returns the additional ingress rules of the
+// security group of the workers configured in the custom object.
+func WorkerSecurityGroupRules(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigSpecAWSSecurityGroupRule {
+	return customObject.Spec.AWS.SecurityGroups.Worker
+}
+
 func SmallCloudConfigPath(customObject v1alpha1.AWSConfig, accountID string, role string) string {
 	return fmt.Sprintf("%s/%s", BucketName(customObject, accountID), BucketObjectName(customObject, role))
 }
@@ -499,51 +859,249 @@ func ToVersionBundleVersion(v interface{}) (string, error) {
 	return VersionBundleVersion(customObject), nil
 }
 
+// UsesImageCatalog returns true when any master instance or node pool of the
+// guest cluster does not configure its AMI in the custom object.
+func UsesImageCatalog(customObject v1alpha1.AWSConfig) bool {
+	for _, imageID := range MasterImageIDs(customObject) {
+		if imageID == "" {
+			return true
+		}
+	}
+	for _, p := range NodePools(customObject) {
+		if NodePoolImageID(p) == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
 // VersionBundleVersion returns the version contained in the Version Bundle.
 func VersionBundleVersion(customObject v1alpha1.AWSConfig) string {
 	return customObject.Spec.VersionBundle.Version
 }
 
-func WorkerCount(customObject v1alpha1.AWSConfig) int {
-	return len(customObject.Spec.AWS.Workers)
+// VPCID returns the ID of the existing VPC the guest cluster is deployed into.
+func VPCID(customObject v1alpha1.AWSConfig) string {
+	return customObject.Spec.AWS.VPC.ID
 }
 
-// WorkerDockerVolumeSizeGB returns size of a docker volume configured for
-// worker nodes. If there are no workers in custom object, 0 is returned as
-// size.
-func WorkerDockerVolumeSizeGB(customObject v1alpha1.AWSConfig) int {
-	if len(customObject.Spec.AWS.Workers) <= 0 {
+// VPCPeerings returns the additional VPCs the guest cluster VPC is peered
+// with.
+func VPCPeerings(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigSpecAWSVPCPeering {
+	return customObject.Spec.AWS.VPC.Peerings
+}
+
+// VPNGatewayCIDRs returns the CIDRs of the networks reached through the virtual
+// private gateway attached to the guest cluster VPC.
+func VPNGatewayCIDRs(customObject v1alpha1.AWSConfig) []string {
+	return customObject.Spec.AWS.VPC.VPNGateway.CIDRs
+}
+
+// VPNGatewayID returns the ID of the virtual private gateway attached to the
+// guest cluster VPC. It is empty when no virtual private gateway is attached.
+func VPNGatewayID(customObject v1alpha1.AWSConfig) string {
+	return customObject.Spec.AWS.VPC.VPNGateway.ID
+}
+
+// VPCEndpointsEnabled returns true when the VPC of the guest cluster gets
+// endpoints for the AWS APIs used by the guest cluster nodes.
+func VPCEndpointsEnabled(customObject v1alpha1.AWSConfig) bool {
+	return customObject.Spec.AWS.VPC.Endpoints.Enabled
+}
+
+// VPCFlowLogsDestination returns the ARN of the location in the logging bucket
+// the VPC flow logs of the guest cluster are delivered to.
+func VPCFlowLogsDestination(customObject v1alpha1.AWSConfig) string {
+	return fmt.Sprintf("arn:%s:s3:::%s/%s", RegionARN(customObject), TargetLogBucketName(customObject), VPCFlowLogsPrefix)
+}
+
+// VPCFlowLogsTrafficType returns the type of traffic captured by the VPC flow
+// logs of the guest cluster, which defaults to all traffic.
+func VPCFlowLogsTrafficType(customObject v1alpha1.AWSConfig) string {
+	if customObject.Spec.AWS.VPC.FlowLogs.TrafficType != "" {
+		return customObject.Spec.AWS.VPC.FlowLogs.TrafficType
+	}
+
+	return VPCFlowLogsTrafficTypeAll
+}
+
+// VPCEndpointServiceName returns the name of the VPC endpoint service of the
+// given AWS service in the region of the guest cluster, e.g. ecr.api. The
+// names of interface endpoint services in China regions are prefixed with cn,
+// the names of gateway endpoint services are not.
+func VPCEndpointServiceName(customObject v1alpha1.AWSConfig, service string) string {
+	name := fmt.Sprintf("com.amazonaws.%s.%s", Region(customObject), service)
+
+	if IsChinaRegion(customObject) && service != VPCEndpointServiceS3 {
+		name = "cn." + name
+	}
+
+	return name
+}
+
+// NodePools returns the worker node pools of the guest cluster. Guest clusters
+// not configuring any node pools run a single node pool made of all workers.
+func NodePools(customObject v1alpha1.AWSConfig) []v1alpha1.AWSConfigSpecAWSNodePool {
+	if len(customObject.Spec.AWS.NodePools) > 0 {
+		return customObject.Spec.AWS.NodePools
+	}
+
+	p := v1alpha1.AWSConfigSpecAWSNodePool{
+		Name:    DefaultNodePoolName,
+		Scaling: customObject.Spec.Cluster.Scaling,
+		Workers: customObject.Spec.AWS.Workers,
+	}
+
+	return []v1alpha1.AWSConfigSpecAWSNodePool{p}
+}
+
+func NodePoolCount(nodePool v1alpha1.AWSConfigSpecAWSNodePool) int {
+	return len(nodePool.Workers)
+}
+
+// NodePoolDockerVolumeSizeGB returns size of a docker volume configured for
+// the worker nodes of the given node pool. If there are no workers in the node
+// pool, the default size is returned.
+func NodePoolDockerVolumeSizeGB(nodePool v1alpha1.AWSConfigSpecAWSNodePool) int {
+	if len(nodePool.Workers) <= 0 {
 		return defaultDockerVolumeSizeGB
 	}
 
-	if customObject.Spec.AWS.Workers[0].DockerVolumeSizeGB <= 0 {
+	if nodePool.Workers[0].DockerVolumeSizeGB <= 0 {
 		return defaultDockerVolumeSizeGB
 	}
 
-	return customObject.Spec.AWS.Workers[0].DockerVolumeSizeGB
+	return nodePool.Workers[0].DockerVolumeSizeGB
 }
 
-func WorkerImageID(customObject v1alpha1.AWSConfig) string {
+// NodePoolImageID returns the AMI configured for the given node pool, which is
+// taken from its first worker. The AMI is empty in case it is not configured,
+// in which case the AMI is resolved by the AMI catalog.
+func NodePoolImageID(nodePool v1alpha1.AWSConfigSpecAWSNodePool) string {
 	var imageID string
 
-	if len(customObject.Spec.AWS.Workers) > 0 {
-		imageID = customObject.Spec.AWS.Workers[0].ImageID
+	if len(nodePool.Workers) > 0 {
+		imageID = nodePool.Workers[0].ImageID
 	}
 
 	return imageID
 }
 
-func WorkerInstanceType(customObject v1alpha1.AWSConfig) string {
+func NodePoolInstanceType(nodePool v1alpha1.AWSConfigSpecAWSNodePool) string {
 	var instanceType string
 
-	if len(customObject.Spec.AWS.Workers) > 0 {
-		instanceType = customObject.Spec.AWS.Workers[0].InstanceType
-
+	if len(nodePool.Workers) > 0 {
+		instanceType = nodePool.Workers[0].InstanceType
 	}
 
 	return instanceType
 }
 
+// NodePoolScalingEnabled returns true when cluster-autoscaler may scale the
+// given node pool.
+func NodePoolScalingEnabled(nodePool v1alpha1.AWSConfigSpecAWSNodePool) bool {
+	return nodePool.Scaling.Max > 0
+}
+
+// NodePoolScalingMax returns the maximum size of the ASG of the given node
+// pool. Node pools not scaled by cluster-autoscaler may exceed their number of
+// workers by one instance during rolling updates.
+func NodePoolScalingMax(nodePool v1alpha1.AWSConfigSpecAWSNodePool) int {
+	if NodePoolScalingEnabled(nodePool) {
+		return nodePool.Scaling.Max
+	}
+
+	return NodePoolCount(nodePool) + 1
+}
+
+// NodePoolScalingMin returns the minimum size of the ASG of the given node
+// pool. Node pools not scaled by cluster-autoscaler run exactly their number
+// of workers.
+func NodePoolScalingMin(nodePool v1alpha1.AWSConfigSpecAWSNodePool) int {
+	if NodePoolScalingEnabled(nodePool) {
+		return nodePool.Scaling.Min
+	}
+
+	return NodePoolCount(nodePool)
+}
+
+// HasScalingNodePools returns true when cluster-autoscaler may scale any node
+// pool of the guest cluster.
+func HasScalingNodePools(customObject v1alpha1.AWSConfig) bool {
+	for _, p := range NodePools(customObject) {
+		if NodePoolScalingEnabled(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NodePoolInstanceTypes returns all instance types the given node pool may use.
+// The instance type of the node pool comes first, followed by the additional
+// instance types configured for spot instances.
+func NodePoolInstanceTypes(nodePool v1alpha1.AWSConfigSpecAWSNodePool) []string {
+	var instanceTypes []string
+
+	seen := map[string]bool{}
+	for _, t := range append([]string{NodePoolInstanceType(nodePool)}, nodePool.Spot.InstanceTypes...) {
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+
+		instanceTypes = append(instanceTypes, t)
+	}
+
+	return instanceTypes
+}
+
+// HasSpotNodePools returns whether any node pool of the guest cluster runs on
+// spot instances.
+func HasSpotNodePools(customObject v1alpha1.AWSConfig) bool {
+	for _, p := range NodePools(customObject) {
+		if p.Spot.Enabled {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WorkerCount returns the number of workers of all node pools.
+func WorkerCount(customObject v1alpha1.AWSConfig) int {
+	var count int
+
+	for _, p := range NodePools(customObject) {
+		count += NodePoolCount(p)
+	}
+
+	return count
+}
+
+// WorkerDockerVolumeSizeGB returns size of a docker volume configured for
+// worker nodes of the first node pool. If there are no workers in custom
+// object, the default size is returned.
+func WorkerDockerVolumeSizeGB(customObject v1alpha1.AWSConfig) int {
+	return NodePoolDockerVolumeSizeGB(NodePools(customObject)[0])
+}
+
+func WorkerImageID(customObject v1alpha1.AWSConfig) string {
+	var imageID string
+
+	workers := NodePools(customObject)[0].Workers
+	if len(workers) > 0 {
+		imageID = workers[0].ImageID
+	}
+
+	return imageID
+}
+
+func WorkerInstanceType(customObject v1alpha1.AWSConfig) string {
+	return NodePoolInstanceType(NodePools(customObject)[0])
+}
+
 func WorkerRoleARN(customObject v1alpha1.AWSConfig, accountID string) string {
 	return baseRoleARN(customObject, accountID, "worker")
 }
@@ -567,6 +1125,41 @@ func componentName(domainName string) (string, error) {
 	return splits[0], nil
 }
 
+// IndexedName returns the name of a resource rendered multiple times, e.g. once
+// per availability zone or once per master instance. The first resource keeps
+// the plain name so that stacks of existing guest clusters are not changed.
+//
+//     IndexedName("PrivateSubnet", 0) => PrivateSubnet
+//     IndexedName("PrivateSubnet", 1) => PrivateSubnet01
+//
+func IndexedName(name string, index int) string {
+	if index == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s%02d", name, index)
+}
+
+// NodePoolResourceName returns the name of the resource of the node pool with
+// the given node pool name, e.g. workerGpuLarge for the worker resource of the
+// node pool gpu-large. The default node pool keeps the name of the resource of
+// guest clusters created before node pools were supported, so that their
+// workers are not replaced.
+func NodePoolResourceName(name string, nodePoolName string) string {
+	if nodePoolName == DefaultNodePoolName {
+		return name
+	}
+
+	for _, s := range strings.Split(nodePoolName, "-") {
+		if s == "" {
+			continue
+		}
+		name += strings.ToUpper(s[:1]) + s[1:]
+	}
+
+	return name
+}
+
 // ImageID returns the EC2 AMI for the configured region.
 func ImageID(customObject v1alpha1.AWSConfig) (string, error) {
 	region := Region(customObject)
@@ -612,6 +1205,67 @@ func ImageID(customObject v1alpha1.AWSConfig) (string, error) {
 	return imageID, nil
 }
 
+// subnetCIDRs returns the CIDRs of the public and private subnets of a guest
+// cluster spread across multiple availability zones. The first availability
+// zone keeps the subnet CIDRs configured in the custom object, so that
+// spreading an existing guest cluster across more availability zones does not
+// replace its subnets and the master placed in them. The subnets of the other
+// availability zones have the same sizes and are allocated from the free space
+// of the VPC CIDR in the order of the availability zones.
+func subnetCIDRs(customObject v1alpha1.AWSConfig) ([]string, []string, error) {
+	_, vpcCIDR, err := net.ParseCIDR(CIDR(customObject))
+	if err != nil {
+		return nil, nil, microerror.Maskf(invalidConfigError, "invalid VPC CIDR '%s'", CIDR(customObject))
+	}
+	_, publicCIDR, err := net.ParseCIDR(PublicSubnetCIDR(customObject))
+	if err != nil {
+		return nil, nil, microerror.Maskf(invalidConfigError, "invalid public subnet CIDR '%s'", PublicSubnetCIDR(customObject))
+	}
+	_, privateCIDR, err := net.ParseCIDR(PrivateSubnetCIDR(customObject))
+	if err != nil {
+		return nil, nil, microerror.Maskf(invalidConfigError, "invalid private subnet CIDR '%s'", PrivateSubnetCIDR(customObject))
+	}
+
+	public := []string{publicCIDR.String()}
+	private := []string{privateCIDR.String()}
+	allocated := []net.IPNet{*publicCIDR, *privateCIDR}
+
+	for _, az := range AvailabilityZones(customObject)[1:] {
+		publicSubnet, err := ipam.Free(*vpcCIDR, publicCIDR.Mask, allocated)
+		if err != nil {
+			return nil, nil, microerror.Maskf(invalidConfigError, "cannot allocate public subnet for availability zone '%s' in VPC CIDR %s", az, vpcCIDR.String())
+		}
+		allocated = append(allocated, publicSubnet)
+		public = append(public, publicSubnet.String())
+
+		privateSubnet, err := ipam.Free(*vpcCIDR, privateCIDR.Mask, allocated)
+		if err != nil {
+			return nil, nil, microerror.Maskf(invalidConfigError, "cannot allocate private subnet for availability zone '%s' in VPC CIDR %s", az, vpcCIDR.String())
+		}
+		allocated = append(allocated, privateSubnet)
+		private = append(private, privateSubnet.String())
+	}
+
+	return public, private, nil
+}
+
+// masterIndexedNames returns the indexed names for all master instances. There
+// is always at least one master so that clusters without any master
+// configuration still resolve the legacy names.
+func masterIndexedNames(customObject v1alpha1.AWSConfig, name string) []string {
+	count := MasterCount(customObject)
+	if count < 1 {
+		count = 1
+	}
+
+	var names []string
+	for i := 0; i < count; i++ {
+		names = append(names, IndexedName(name, i))
+	}
+
+	return names
+}
+
 // getResourcenameWithTimeHash returns the string compared from specific prefix,
 // time hash and cluster ID.
 func getResourcenameWithTimeHash(prefix string, customObject v1alpha1.AWSConfig) string {
@@ -508,6 +508,45 @@ func Test_MasterImageID(t *testing.T) {
 	}
 }
 
+func Test_MasterImageIDs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		customObject     v1alpha1.AWSConfig
+		expectedImageIDs []string
+	}{
+		{
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Masters: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								ImageID: "ami-d60ad6b9",
+							},
+							{},
+							{
+								ImageID: "ami-32042fd9",
+							},
+						},
+					},
+				},
+			},
+			expectedImageIDs: []string{"ami-d60ad6b9", "", "ami-32042fd9"},
+		},
+		{
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{},
+			},
+			expectedImageIDs: []string{""},
+		},
+	}
+
+	for _, tc := range tests {
+		if !reflect.DeepEqual(MasterImageIDs(tc.customObject), tc.expectedImageIDs) {
+			t.Fatalf("Expected master image IDs %#v but was %#v", tc.expectedImageIDs, MasterImageIDs(tc.customObject))
+		}
+	}
+}
+
 func Test_MasterInstanceName(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -774,6 +813,181 @@ func Test_WorkerCount(t *testing.T) {
 	}
 }
 
+func Test_NodePools(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		customObject      v1alpha1.AWSConfig
+		expectedNames     []string
+		expectedCounts    []int
+		expectedWorkerSum int
+	}{
+		{
+			name: "case 0: no node pools, workers form the default node pool",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								InstanceType: "m3.medium",
+							},
+							{
+								InstanceType: "m3.medium",
+							},
+						},
+					},
+				},
+			},
+			expectedNames:     []string{DefaultNodePoolName},
+			expectedCounts:    []int{2},
+			expectedWorkerSum: 2,
+		},
+		{
+			name: "case 1: node pools take precedence over workers",
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						NodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+							{
+								Name: "general",
+								Workers: []v1alpha1.AWSConfigSpecAWSNode{
+									{
+										InstanceType: "m4.xlarge",
+									},
+								},
+							},
+							{
+								Name: "memory",
+								Workers: []v1alpha1.AWSConfigSpecAWSNode{
+									{
+										InstanceType: "r4.xlarge",
+									},
+									{
+										InstanceType: "r4.xlarge",
+									},
+									{
+										InstanceType: "r4.xlarge",
+									},
+								},
+							},
+						},
+						Workers: []v1alpha1.AWSConfigSpecAWSNode{
+							{
+								InstanceType: "m3.medium",
+							},
+						},
+					},
+				},
+			},
+			expectedNames:     []string{"general", "memory"},
+			expectedCounts:    []int{1, 3},
+			expectedWorkerSum: 4,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			var counts []int
+			for _, p := range NodePools(tc.customObject) {
+				names = append(names, p.Name)
+				counts = append(counts, NodePoolCount(p))
+			}
+
+			if !reflect.DeepEqual(names, tc.expectedNames) {
+				t.Fatalf("expected node pool names %v but was %v", tc.expectedNames, names)
+			}
+			if !reflect.DeepEqual(counts, tc.expectedCounts) {
+				t.Fatalf("expected node pool counts %v but was %v", tc.expectedCounts, counts)
+			}
+			if WorkerCount(tc.customObject) != tc.expectedWorkerSum {
+				t.Fatalf("expected worker count %d but was %d", tc.expectedWorkerSum, WorkerCount(tc.customObject))
+			}
+		})
+	}
+}
+
+func Test_NodePoolInstanceTypes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name                  string
+		nodePool              v1alpha1.AWSConfigSpecAWSNodePool
+		expectedInstanceTypes []string
+	}{
+		{
+			name: "case 0: on-demand node pool uses its instance type",
+			nodePool: v1alpha1.AWSConfigSpecAWSNodePool{
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						InstanceType: "m4.xlarge",
+					},
+				},
+			},
+			expectedInstanceTypes: []string{"m4.xlarge"},
+		},
+		{
+			name: "case 1: spot node pool diversifies instance types without duplicates",
+			nodePool: v1alpha1.AWSConfigSpecAWSNodePool{
+				Spot: v1alpha1.AWSConfigSpecAWSNodePoolSpot{
+					Enabled:       true,
+					InstanceTypes: []string{"m5.xlarge", "m4.xlarge", "m5a.xlarge"},
+				},
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						InstanceType: "m4.xlarge",
+					},
+				},
+			},
+			expectedInstanceTypes: []string{"m4.xlarge", "m5.xlarge", "m5a.xlarge"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			instanceTypes := NodePoolInstanceTypes(tc.nodePool)
+
+			if !reflect.DeepEqual(instanceTypes, tc.expectedInstanceTypes) {
+				t.Fatalf("expected instance types %v but was %v", tc.expectedInstanceTypes, instanceTypes)
+			}
+		})
+	}
+}
+
+func Test_NodePoolResourceName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name                 string
+		nodePoolName         string
+		expectedResourceName string
+	}{
+		{
+			name:                 "case 0: default node pool keeps the legacy resource name",
+			nodePoolName:         DefaultNodePoolName,
+			expectedResourceName: "worker",
+		},
+		{
+			name:                 "case 1: node pool name is appended",
+			nodePoolName:         "spot",
+			expectedResourceName: "workerSpot",
+		},
+		{
+			name:                 "case 2: dashes are removed",
+			nodePoolName:         "gpu-large-2",
+			expectedResourceName: "workerGpuLarge2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resourceName := NodePoolResourceName("worker", tc.nodePoolName)
+
+			if resourceName != tc.expectedResourceName {
+				t.Fatalf("expected resource name %s but was %s", tc.expectedResourceName, resourceName)
+			}
+		})
+	}
+}
+
 func Test_WorkerDockerVolumeSizeGB(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1256,6 +1470,80 @@ func Test_PrivateSubnetCIDR(t *testing.T) {
 	}
 }
 
+func Test_SubnetCIDRs(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description     string
+		azs             []string
+		expectedPublic  []string
+		expectedPrivate []string
+		errorMatcher    func(error) bool
+	}{
+		{
+			description:     "single availability zone, configured subnets",
+			azs:             []string{"eu-central-1a"},
+			expectedPublic:  []string{"10.1.1.0/25"},
+			expectedPrivate: []string{"10.1.2.0/25"},
+		},
+		{
+			description:     "two availability zones, first availability zone keeps the configured subnets",
+			azs:             []string{"eu-central-1a", "eu-central-1b"},
+			expectedPublic:  []string{"10.1.1.0/25", "10.1.0.0/25"},
+			expectedPrivate: []string{"10.1.2.0/25", "10.1.0.128/25"},
+		},
+		{
+			description:     "three availability zones, earlier availability zones keep their subnets",
+			azs:             []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			expectedPublic:  []string{"10.1.1.0/25", "10.1.0.0/25", "10.1.1.128/25"},
+			expectedPrivate: []string{"10.1.2.0/25", "10.1.0.128/25", "10.1.2.128/25"},
+		},
+		{
+			description:  "VPC CIDR exhausted",
+			azs:          []string{"eu-central-1a", "eu-central-1b", "eu-central-1c", "eu-central-1d", "eu-central-1e"},
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						AvailabilityZones: tc.azs,
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR:              "10.1.0.0/22",
+							PublicSubnetCIDR:  "10.1.1.0/25",
+							PrivateSubnetCIDR: "10.1.2.0/25",
+						},
+					},
+				},
+			}
+
+			public, err := PublicSubnetCIDRs(customObject)
+			if err != nil {
+				if tc.errorMatcher == nil || !tc.errorMatcher(err) {
+					t.Fatalf("unexpected error %#v", err)
+				}
+				return
+			}
+			if tc.errorMatcher != nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !reflect.DeepEqual(public, tc.expectedPublic) {
+				t.Fatalf("expected public subnet CIDRs %v got %v", tc.expectedPublic, public)
+			}
+
+			private, err := PrivateSubnetCIDRs(customObject)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if !reflect.DeepEqual(private, tc.expectedPrivate) {
+				t.Fatalf("expected private subnet CIDRs %v got %v", tc.expectedPrivate, private)
+			}
+		})
+	}
+}
+
 func Test_CIDR(t *testing.T) {
 	t.Parallel()
 	customObject := v1alpha1.AWSConfig{
@@ -1543,3 +1831,125 @@ func Test_getResourcenameWithTimeHash_Format(t *testing.T) {
 		t.Fatalf("expected %s to not contain dashes", n)
 	}
 }
+
+func Test_NodePoolScaling(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		nodePool        v1alpha1.AWSConfigSpecAWSNodePool
+		expectedEnabled bool
+		expectedMin     int
+		expectedMax     int
+	}{
+		{
+			name: "case 0: autoscaling disabled, bounds follow the number of workers",
+			nodePool: v1alpha1.AWSConfigSpecAWSNodePool{
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						InstanceType: "m4.xlarge",
+					},
+					{
+						InstanceType: "m4.xlarge",
+					},
+				},
+			},
+			expectedEnabled: false,
+			expectedMin:     2,
+			expectedMax:     3,
+		},
+		{
+			name: "case 1: autoscaling enabled, bounds come from the node pool",
+			nodePool: v1alpha1.AWSConfigSpecAWSNodePool{
+				Scaling: v1alpha1.ClusterScaling{
+					Max: 10,
+					Min: 3,
+				},
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						InstanceType: "m4.xlarge",
+					},
+				},
+			},
+			expectedEnabled: true,
+			expectedMin:     3,
+			expectedMax:     10,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if NodePoolScalingEnabled(tc.nodePool) != tc.expectedEnabled {
+				t.Fatalf("expected enabled %t got %t", tc.expectedEnabled, NodePoolScalingEnabled(tc.nodePool))
+			}
+			if NodePoolScalingMin(tc.nodePool) != tc.expectedMin {
+				t.Fatalf("expected min %d got %d", tc.expectedMin, NodePoolScalingMin(tc.nodePool))
+			}
+			if NodePoolScalingMax(tc.nodePool) != tc.expectedMax {
+				t.Fatalf("expected max %d got %d", tc.expectedMax, NodePoolScalingMax(tc.nodePool))
+			}
+		})
+	}
+}
+
+func Test_UsesImageCatalog(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		description string
+		masters     []v1alpha1.AWSConfigSpecAWSNode
+		nodePools   []v1alpha1.AWSConfigSpecAWSNodePool
+		workers     []v1alpha1.AWSConfigSpecAWSNode
+		expected    bool
+	}{
+		{
+			description: "all images configured",
+			masters:     []v1alpha1.AWSConfigSpecAWSNode{{ImageID: "ami-1"}},
+			workers:     []v1alpha1.AWSConfigSpecAWSNode{{ImageID: "ami-2"}},
+			expected:    false,
+		},
+		{
+			description: "master image not configured",
+			masters:     []v1alpha1.AWSConfigSpecAWSNode{{}},
+			workers:     []v1alpha1.AWSConfigSpecAWSNode{{ImageID: "ami-2"}},
+			expected:    true,
+		},
+		{
+			description: "worker image not configured",
+			masters:     []v1alpha1.AWSConfigSpecAWSNode{{ImageID: "ami-1"}},
+			workers:     []v1alpha1.AWSConfigSpecAWSNode{{}},
+			expected:    true,
+		},
+		{
+			description: "node pool image not configured",
+			masters:     []v1alpha1.AWSConfigSpecAWSNode{{ImageID: "ami-1"}},
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name:    "pool01",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{ImageID: "ami-2"}},
+				},
+				{
+					Name:    "pool02",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Masters:   tc.masters,
+						NodePools: tc.nodePools,
+						Workers:   tc.workers,
+					},
+				},
+			}
+
+			if UsesImageCatalog(customObject) != tc.expected {
+				t.Fatalf("expected %t got %t", tc.expected, UsesImageCatalog(customObject))
+			}
+		})
+	}
+}
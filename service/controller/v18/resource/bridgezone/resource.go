@@ -14,6 +14,7 @@ import (
 	"github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v18/credential"
+	"github.com/giantswarm/aws-operator/service/controller/v18/dns"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
@@ -22,12 +23,21 @@ const (
 )
 
 type Config struct {
+	// DNS manages the delegation of the intermediate zone from the host
+	// cluster zone.
+	DNS           dns.Interface
 	HostAWSConfig aws.Config
-	HostRoute53   *route53.Route53
 	K8sClient     kubernetes.Interface
 	Logger        micrologger.Logger
 
 	Route53Enabled bool
+	// RetirementEnabled enables the automated retirement of the intermediate
+	// zone once no guest cluster relies on it anymore.
+	RetirementEnabled bool
+	// RetirementGracePeriod is the time to wait in addition to the TTL of the
+	// removed intermediate zone delegation before the intermediate zone is
+	// deleted.
+	RetirementGracePeriod time.Duration
 }
 
 // Resource is bridgezone resource making sure we have fallback delegation in
@@ -83,21 +93,31 @@ type Config struct {
 // when delegation propagates and DNS caches are refreshed we can delete
 // k8s.installation.eu-central-1.aws.gigantic.io zone from the default guest
 // account.
+//
+// When the retirement is enabled the resource does that on its own. It removes
+// the k8s.installation.eu-central-1.aws.gigantic.io NS record from the host
+// cluster zone once no guest cluster relies on the intermediate zone anymore,
+// waits for the TTL of the removed record and the configured grace period and
+// deletes the intermediate zone afterwards. The progress is recorded in a
+// config map so the retirement survives operator restarts.
 type Resource struct {
+	dns           dns.Interface
 	hostAWSConfig aws.Config
 	k8sClient     kubernetes.Interface
 	logger        micrologger.Logger
 
-	route53Enabled bool
+	route53Enabled        bool
+	retirementEnabled     bool
+	retirementGracePeriod time.Duration
 }
 
 func New(config Config) (*Resource, error) {
+	if config.DNS == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DNS must not be empty", config)
+	}
 	if reflect.DeepEqual(aws.Config{}, config.HostAWSConfig) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.HostAWSConfig must not be empty", config)
 	}
-	if config.HostRoute53 == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.HostRoute53 must not be empty", config)
-	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
 	}
@@ -106,11 +126,14 @@ func New(config Config) (*Resource, error) {
 	}
 
 	r := &Resource{
+		dns:           config.DNS,
 		hostAWSConfig: config.HostAWSConfig,
 		k8sClient:     config.K8sClient,
 		logger:        config.Logger,
 
-		route53Enabled: config.Route53Enabled,
+		route53Enabled:        config.Route53Enabled,
+		retirementEnabled:     config.RetirementEnabled,
+		retirementGracePeriod: config.RetirementGracePeriod,
 	}
 
 	return r, nil
@@ -159,6 +182,22 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "got intermediate zone ID")
 	}
 
+	if r.retirementEnabled {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensuring retirement of intermediate zone")
+
+		deleted, err := r.ensureRetirement(ctx, defaultGuest, baseDomain, intermediateZone, intermediateZoneID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "ensured retirement of intermediate zone")
+
+		if deleted {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource reconciliation for custom object")
+			return nil
+		}
+	}
+
 	var finalZoneID string
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", "getting final zone ID")
@@ -0,0 +1,414 @@
+package bridgezone
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/dns"
+)
+
+const (
+	// retirementConfigMapName is the name of the config map recording the phase
+	// of the retirement of the intermediate zone, so that the retirement resumes
+	// where it stopped after restarts of the operator.
+	retirementConfigMapName      = "aws-operator-bridgezone-retirement"
+	retirementConfigMapNamespace = "giantswarm"
+
+	retirementDelegationRemovedAtKey = "delegationRemovedAt"
+	retirementDelegationTTLKey       = "delegationTTL"
+	retirementPhaseKey               = "phase"
+	retirementReliantClustersKey     = "reliantClusters"
+)
+
+const (
+	// retirementPhaseDelegating is the phase in which the host cluster zone
+	// still delegates to the intermediate zone.
+	retirementPhaseDelegating = ""
+	// retirementPhaseDelegationRemoved is the phase in which the delegation of
+	// the intermediate zone was removed from the host cluster zone and DNS
+	// caches are waited for to expire.
+	retirementPhaseDelegationRemoved = "DelegationRemoved"
+	// retirementPhaseZoneDeleted is the phase in which the intermediate zone is
+	// deleted.
+	retirementPhaseZoneDeleted = "ZoneDeleted"
+)
+
+// retirementState is the state of the retirement of the intermediate zone as
+// recorded in the retirement config map.
+type retirementState struct {
+	DelegationRemovedAt time.Time
+	DelegationTTL       time.Duration
+	Phase               string
+	ReliantClusters     []string
+}
+
+func retirementStateFromData(data map[string]string) (retirementState, error) {
+	var s retirementState
+
+	s.Phase = data[retirementPhaseKey]
+
+	if v := data[retirementDelegationRemovedAtKey]; v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return retirementState{}, microerror.Maskf(executionError, "%s '%s' must be a RFC3339 timestamp", retirementDelegationRemovedAtKey, v)
+		}
+		s.DelegationRemovedAt = t
+	}
+
+	if v := data[retirementDelegationTTLKey]; v != "" {
+		ttl, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return retirementState{}, microerror.Maskf(executionError, "%s '%s' must be a number of seconds", retirementDelegationTTLKey, v)
+		}
+		s.DelegationTTL = time.Duration(ttl) * time.Second
+	}
+
+	if v := data[retirementReliantClustersKey]; v != "" {
+		s.ReliantClusters = strings.Split(v, ",")
+	}
+
+	return s, nil
+}
+
+func (s retirementState) Data() map[string]string {
+	data := map[string]string{
+		retirementPhaseKey: s.Phase,
+	}
+
+	if !s.DelegationRemovedAt.IsZero() {
+		data[retirementDelegationRemovedAtKey] = s.DelegationRemovedAt.UTC().Format(time.RFC3339)
+		data[retirementDelegationTTLKey] = strconv.FormatInt(int64(s.DelegationTTL/time.Second), 10)
+	}
+	if len(s.ReliantClusters) > 0 {
+		data[retirementReliantClustersKey] = strings.Join(s.ReliantClusters, ",")
+	}
+
+	return data
+}
+
+// deletableAt returns the time after which the intermediate zone can be
+// deleted. Resolvers may cache the removed delegation for its TTL, so the grace
+// period only starts once the TTL expired.
+func (s retirementState) deletableAt(gracePeriod time.Duration) time.Time {
+	return s.DelegationRemovedAt.Add(s.DelegationTTL).Add(gracePeriod)
+}
+
+// ensureRetirement drives the retirement of the intermediate zone one phase
+// further per reconciliation loop. The delegation of the intermediate zone is
+// only removed from the host cluster zone once no guest cluster relies on the
+// intermediate zone anymore. The intermediate zone is deleted once the TTL of
+// the removed delegation and the configured grace period passed. It returns
+// true when the intermediate zone got deleted.
+func (r *Resource) ensureRetirement(ctx context.Context, defaultGuest *route53.Route53, baseDomain, intermediateZone, intermediateZoneID string) (bool, error) {
+	configMap, state, err := r.getRetirementState()
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	switch state.Phase {
+	case retirementPhaseDelegating:
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding guest clusters relying on intermediate zone")
+
+		reliantClusters, err := r.findReliantClusters(ctx, defaultGuest, baseDomain, intermediateZone, intermediateZoneID)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		if len(reliantClusters) > 0 {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found guest clusters relying on intermediate zone: %s", strings.Join(reliantClusters, ", ")))
+
+			if !equalStrings(state.ReliantClusters, reliantClusters) {
+				state.ReliantClusters = reliantClusters
+
+				err := r.setRetirementState(configMap, state)
+				if err != nil {
+					return false, microerror.Mask(err)
+				}
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", "not retiring intermediate zone")
+			return false, nil
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "did not find guest clusters relying on intermediate zone")
+
+		ttl, err := r.removeIntermediateZoneDelegation(ctx, baseDomain, intermediateZone)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		state = retirementState{
+			DelegationRemovedAt: time.Now(),
+			DelegationTTL:       ttl,
+			Phase:               retirementPhaseDelegationRemoved,
+		}
+
+		err = r.setRetirementState(configMap, state)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		return false, nil
+
+	case retirementPhaseDelegationRemoved:
+		deletableAt := state.deletableAt(r.retirementGracePeriod)
+		if time.Now().Before(deletableAt) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not deleting intermediate zone before %s", deletableAt.UTC().Format(time.RFC3339)))
+			return false, nil
+		}
+
+		err := r.deleteIntermediateZone(ctx, defaultGuest, intermediateZone, intermediateZoneID)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		state.Phase = retirementPhaseZoneDeleted
+
+		err = r.setRetirementState(configMap, state)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		return true, nil
+
+	case retirementPhaseZoneDeleted:
+		// The intermediate zone was found although its retirement completed. It
+		// has been created again by someone else, so it is not touched.
+		r.logger.LogCtx(ctx, "level", "warning", "message", "intermediate zone exists although its retirement completed")
+		return false, nil
+
+	default:
+		return false, microerror.Maskf(executionError, "unknown retirement phase '%s'", state.Phase)
+	}
+}
+
+// findReliantClusters returns the IDs of the guest clusters whose domains are
+// only resolvable through the intermediate zone. These are the guest clusters
+// having records in the intermediate zone directly, like guest clusters created
+// before the final zones were introduced, and the guest clusters whose final
+// zone is delegated from the intermediate zone but not from the host cluster
+// zone.
+func (r *Resource) findReliantClusters(ctx context.Context, defaultGuest *route53.Route53, baseDomain, intermediateZone, intermediateZoneID string) ([]string, error) {
+	records, err := r.listRecordSets(ctx, defaultGuest, intermediateZoneID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	reliant := map[string]bool{}
+	for _, rs := range records {
+		name := strings.TrimSuffix(*rs.Name, ".")
+		if name == intermediateZone {
+			continue
+		}
+
+		clusterID := clusterIDFromRecordName(name, intermediateZone)
+
+		if *rs.Type == route53.RRTypeNs && name == clusterID+"."+intermediateZone {
+			_, err := r.dns.FindDelegation(ctx, baseDomain, name)
+			if dns.IsDelegationNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return nil, microerror.Mask(err)
+			} else {
+				continue
+			}
+		}
+
+		reliant[clusterID] = true
+	}
+
+	var clusterIDs []string
+	for id := range reliant {
+		clusterIDs = append(clusterIDs, id)
+	}
+	sort.Strings(clusterIDs)
+
+	return clusterIDs, nil
+}
+
+// removeIntermediateZoneDelegation removes the NS record delegating to the
+// intermediate zone from the host cluster zone and returns its TTL. The TTL is
+// zero in case the delegation was already removed.
+func (r *Resource) removeIntermediateZoneDelegation(ctx context.Context, baseDomain, intermediateZone string) (time.Duration, error) {
+	r.logger.LogCtx(ctx, "level", "debug", "message", "ensuring deletion of intermediate zone delegation from host cluster zone")
+
+	delegation, err := r.dns.FindDelegation(ctx, baseDomain, intermediateZone)
+	if dns.IsDelegationNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "intermediate zone delegation not found in host cluster zone")
+		return 0, nil
+	} else if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	err = r.dns.EnsureDeletedDelegation(ctx, baseDomain, intermediateZone)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "ensured deletion of intermediate zone delegation from host cluster zone")
+
+	return time.Duration(delegation.TTL) * time.Second, nil
+}
+
+// deleteIntermediateZone deletes the records of the intermediate zone and the
+// intermediate zone itself. Route53 only deletes hosted zones which do not
+// contain other records than their own NS and SOA records.
+func (r *Resource) deleteIntermediateZone(ctx context.Context, defaultGuest *route53.Route53, intermediateZone, intermediateZoneID string) error {
+	r.logger.LogCtx(ctx, "level", "debug", "message", "deleting intermediate zone")
+
+	records, err := r.listRecordSets(ctx, defaultGuest, intermediateZoneID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var changes []*route53.Change
+	for _, rs := range records {
+		if strings.TrimSuffix(*rs.Name, ".") == intermediateZone && (*rs.Type == route53.RRTypeNs || *rs.Type == route53.RRTypeSoa) {
+			continue
+		}
+
+		delete := route53.ChangeActionDelete
+		changes = append(changes, &route53.Change{
+			Action:            &delete,
+			ResourceRecordSet: rs,
+		})
+	}
+
+	if len(changes) > 0 {
+		in := &route53.ChangeResourceRecordSetsInput{
+			ChangeBatch: &route53.ChangeBatch{
+				Changes: changes,
+			},
+			HostedZoneId: &intermediateZoneID,
+		}
+		time.Sleep(1 * time.Second)
+		_, err := defaultGuest.ChangeResourceRecordSetsWithContext(ctx, in)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	{
+		in := &route53.DeleteHostedZoneInput{
+			Id: &intermediateZoneID,
+		}
+		time.Sleep(1 * time.Second)
+		_, err := defaultGuest.DeleteHostedZoneWithContext(ctx, in)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "deleted intermediate zone")
+
+	return nil
+}
+
+func (r *Resource) listRecordSets(ctx context.Context, client *route53.Route53, zoneID string) ([]*route53.ResourceRecordSet, error) {
+	var records []*route53.ResourceRecordSet
+
+	in := &route53.ListResourceRecordSetsInput{
+		HostedZoneId: &zoneID,
+	}
+	for {
+		time.Sleep(1 * time.Second)
+		out, err := client.ListResourceRecordSetsWithContext(ctx, in)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		time.Sleep(1 * time.Second)
+
+		records = append(records, out.ResourceRecordSets...)
+
+		if out.IsTruncated == nil || !*out.IsTruncated {
+			break
+		}
+		in.StartRecordIdentifier = out.NextRecordIdentifier
+		in.StartRecordName = out.NextRecordName
+		in.StartRecordType = out.NextRecordType
+	}
+
+	return records, nil
+}
+
+// getRetirementState returns the retirement config map and the state recorded
+// in it. The config map is nil in case the retirement did not start yet.
+func (r *Resource) getRetirementState() (*apiv1.ConfigMap, retirementState, error) {
+	configMap, err := r.k8sClient.CoreV1().ConfigMaps(retirementConfigMapNamespace).Get(retirementConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, retirementState{}, nil
+	} else if err != nil {
+		return nil, retirementState{}, microerror.Mask(err)
+	}
+
+	state, err := retirementStateFromData(configMap.Data)
+	if err != nil {
+		return nil, retirementState{}, microerror.Mask(err)
+	}
+
+	return configMap, state, nil
+}
+
+// setRetirementState records the given state in the retirement config map and
+// creates it in case it does not exist yet. The update fails in case the
+// config map was modified concurrently.
+func (r *Resource) setRetirementState(configMap *apiv1.ConfigMap, state retirementState) error {
+	if configMap == nil {
+		newConfigMap := &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      retirementConfigMapName,
+				Namespace: retirementConfigMapNamespace,
+			},
+			Data: state.Data(),
+		}
+
+		_, err := r.k8sClient.CoreV1().ConfigMaps(retirementConfigMapNamespace).Create(newConfigMap)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	configMap.Data = state.Data()
+
+	_, err := r.k8sClient.CoreV1().ConfigMaps(configMap.Namespace).Update(configMap)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// clusterIDFromRecordName returns the ID of the guest cluster a record of the
+// intermediate zone belongs to, e.g. "old_cluster" for
+// "api.old_cluster.k8s.installation.eu-central-1.aws.gigantic.io".
+func clusterIDFromRecordName(name, intermediateZone string) string {
+	labels := strings.Split(strings.TrimSuffix(name, "."+intermediateZone), ".")
+	return labels[len(labels)-1]
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
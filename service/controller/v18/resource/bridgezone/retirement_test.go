@@ -0,0 +1,201 @@
+package bridgezone
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_retirementState(t *testing.T) {
+	removedAt := time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                string
+		state               retirementState
+		gracePeriod         time.Duration
+		expectedData        map[string]string
+		expectedDeletableAt time.Time
+	}{
+		{
+			name:  "case 0: delegating without reliant clusters",
+			state: retirementState{},
+			expectedData: map[string]string{
+				retirementPhaseKey: "",
+			},
+			expectedDeletableAt: time.Time{},
+		},
+		{
+			name: "case 1: delegating with reliant clusters",
+			state: retirementState{
+				ReliantClusters: []string{"al9qy", "p1l6x"},
+			},
+			expectedData: map[string]string{
+				retirementPhaseKey:           "",
+				retirementReliantClustersKey: "al9qy,p1l6x",
+			},
+			expectedDeletableAt: time.Time{},
+		},
+		{
+			name: "case 2: delegation removed",
+			state: retirementState{
+				DelegationRemovedAt: removedAt,
+				DelegationTTL:       900 * time.Second,
+				Phase:               retirementPhaseDelegationRemoved,
+			},
+			gracePeriod: 48 * time.Hour,
+			expectedData: map[string]string{
+				retirementPhaseKey:               retirementPhaseDelegationRemoved,
+				retirementDelegationRemovedAtKey: "2018-09-01T12:00:00Z",
+				retirementDelegationTTLKey:       "900",
+			},
+			expectedDeletableAt: time.Date(2018, 9, 3, 12, 15, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := tc.state.Data()
+			if !reflect.DeepEqual(data, tc.expectedData) {
+				t.Fatalf("data == %#v, want %#v", data, tc.expectedData)
+			}
+
+			state, err := retirementStateFromData(data)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if !reflect.DeepEqual(state, tc.state) {
+				t.Fatalf("state == %#v, want %#v", state, tc.state)
+			}
+
+			if tc.state.Phase == retirementPhaseDelegationRemoved {
+				deletableAt := state.deletableAt(tc.gracePeriod)
+				if !deletableAt.Equal(tc.expectedDeletableAt) {
+					t.Fatalf("deletableAt == %s, want %s", deletableAt, tc.expectedDeletableAt)
+				}
+			}
+		})
+	}
+}
+
+func Test_retirementStateFromData_Invalid(t *testing.T) {
+	testCases := []map[string]string{
+		{
+			retirementPhaseKey:               retirementPhaseDelegationRemoved,
+			retirementDelegationRemovedAtKey: "yesterday",
+		},
+		{
+			retirementPhaseKey:         retirementPhaseDelegationRemoved,
+			retirementDelegationTTLKey: "15m",
+		},
+	}
+
+	for i, data := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := retirementStateFromData(data)
+			if !IsExecution(err) {
+				t.Fatalf("expected execution error, got %#v", err)
+			}
+		})
+	}
+}
+
+func Test_setRetirementState(t *testing.T) {
+	testCases := []struct {
+		name       string
+		configMaps []runtime.Object
+	}{
+		{
+			name: "case 0: create config map",
+		},
+		{
+			name: "case 1: update config map",
+			configMaps: []runtime.Object{
+				&apiv1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      retirementConfigMapName,
+						Namespace: retirementConfigMapNamespace,
+					},
+					Data: map[string]string{
+						retirementPhaseKey:           "",
+						retirementReliantClustersKey: "al9qy",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Resource{
+				k8sClient: k8sfake.NewSimpleClientset(tc.configMaps...),
+				logger:    microloggertest.New(),
+			}
+
+			configMap, _, err := r.getRetirementState()
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			expected := retirementState{
+				DelegationRemovedAt: time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC),
+				DelegationTTL:       900 * time.Second,
+				Phase:               retirementPhaseDelegationRemoved,
+			}
+
+			err = r.setRetirementState(configMap, expected)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			_, state, err := r.getRetirementState()
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if !reflect.DeepEqual(state, expected) {
+				t.Fatalf("state == %#v, want %#v", state, expected)
+			}
+		})
+	}
+}
+
+func Test_clusterIDFromRecordName(t *testing.T) {
+	intermediateZone := "k8s.installation.eu-central-1.aws.gigantic.io"
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "api.al9qy.k8s.installation.eu-central-1.aws.gigantic.io",
+			expected: "al9qy",
+		},
+		{
+			name:     "\\052.al9qy.k8s.installation.eu-central-1.aws.gigantic.io",
+			expected: "al9qy",
+		},
+		{
+			name:     "etcd0.p1l6x.k8s.installation.eu-central-1.aws.gigantic.io",
+			expected: "p1l6x",
+		},
+		{
+			name:     "p1l6x.k8s.installation.eu-central-1.aws.gigantic.io",
+			expected: "p1l6x",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clusterID := clusterIDFromRecordName(tc.name, intermediateZone)
+			if clusterID != tc.expected {
+				t.Fatalf("clusterID == %q, want %q", clusterID, tc.expected)
+			}
+		})
+	}
+}
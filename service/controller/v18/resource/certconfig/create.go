@@ -0,0 +1,51 @@
+package certconfig
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange interface{}) error {
+	certConfigToCreate, err := toCertConfig(createChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if certConfigToCreate != nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "creating the etcd peer cert config in the Kubernetes API")
+
+		_, err = r.g8sClient.CoreV1alpha1().CertConfigs(certConfigToCreate.Namespace).Create(certConfigToCreate)
+		if apierrors.IsAlreadyExists(err) {
+			// fall through
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "created the etcd peer cert config in the Kubernetes API")
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the etcd peer cert config does not need to be created in the Kubernetes API")
+	}
+
+	return nil
+}
+
+func (r *Resource) newCreateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	currentCertConfig, err := toCertConfig(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredCertConfig, err := toCertConfig(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var certConfigToCreate *v1alpha1.CertConfig
+	if currentCertConfig == nil {
+		certConfigToCreate = desiredCertConfig
+	}
+
+	return certConfigToCreate, nil
+}
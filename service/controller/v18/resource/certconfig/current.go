@@ -0,0 +1,41 @@
+package certconfig
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/certs"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "finding the etcd peer cert config in the Kubernetes API")
+
+	name := certs.K8sName(key.ClusterID(customObject), key.EtcdPeerCert)
+
+	var certConfig *v1alpha1.CertConfig
+	{
+		manifest, err := r.g8sClient.CoreV1alpha1().CertConfigs(certConfigNamespace).Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the etcd peer cert config in the Kubernetes API")
+			return nil, nil
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		certConfig = manifest
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "found the etcd peer cert config in the Kubernetes API")
+
+	return certConfig, nil
+}
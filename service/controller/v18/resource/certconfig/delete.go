@@ -0,0 +1,67 @@
+package certconfig
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
+	certConfigToDelete, err := toCertConfig(deleteChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if certConfigToDelete != nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the etcd peer cert config in the Kubernetes API")
+
+		err = r.g8sClient.CoreV1alpha1().CertConfigs(certConfigToDelete.Namespace).Delete(certConfigToDelete.Name, &metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted the etcd peer cert config in the Kubernetes API")
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the etcd peer cert config does not need to be deleted in the Kubernetes API")
+	}
+
+	return nil
+}
+
+func (r *Resource) NewDeletePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*controller.Patch, error) {
+	delete, err := r.newDeleteChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := controller.NewPatch()
+	patch.SetDeleteChange(delete)
+
+	return patch, nil
+}
+
+// newDeleteChange deletes the etcd peer cert config when the guest cluster is
+// deleted or does not run multiple masters anymore.
+func (r *Resource) newDeleteChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	currentCertConfig, err := toCertConfig(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredCertConfig, err := toCertConfig(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var certConfigToDelete *v1alpha1.CertConfig
+	if currentCertConfig != nil && desiredCertConfig == nil {
+		certConfigToDelete = currentCertConfig
+	}
+
+	return certConfigToDelete, nil
+}
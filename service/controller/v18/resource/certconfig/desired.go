@@ -0,0 +1,68 @@
+package certconfig
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/certs"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Guest clusters with a single master run etcd as a single member, which
+	// does not talk to any peers.
+	if key.IsDeleted(customObject) || key.MasterCount(customObject) <= 1 {
+		return nil, nil
+	}
+
+	// The etcd peer certificate is issued by the same version of cert-operator
+	// and with the same properties as the etcd server certificate, which is
+	// managed by cluster-operator.
+	var etcdCertConfig *v1alpha1.CertConfig
+	{
+		name := certs.K8sName(key.ClusterID(customObject), certs.EtcdCert)
+
+		etcdCertConfig, err = r.g8sClient.CoreV1alpha1().CertConfigs(certConfigNamespace).Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, microerror.Maskf(notFoundError, "etcd cert config %#q", name)
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	certConfig := &v1alpha1.CertConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      certs.K8sName(key.ClusterID(customObject), key.EtcdPeerCert),
+			Namespace: certConfigNamespace,
+			Labels: map[string]string{
+				"giantswarm.io/certificate": key.EtcdPeerCert,
+				"giantswarm.io/cluster":     key.ClusterID(customObject),
+				"giantswarm.io/managed-by":  "aws-operator",
+			},
+		},
+		Spec: v1alpha1.CertConfigSpec{
+			Cert: v1alpha1.CertConfigSpecCert{
+				AllowBareDomains: true,
+				AltNames:         key.EtcdMemberDomains(customObject),
+				ClusterComponent: key.EtcdPeerCert,
+				ClusterID:        key.ClusterID(customObject),
+				CommonName:       customObject.Spec.Cluster.Etcd.Domain,
+				IPSANs:           etcdCertConfig.Spec.Cert.IPSANs,
+				Organizations:    etcdCertConfig.Spec.Cert.Organizations,
+				TTL:              etcdCertConfig.Spec.Cert.TTL,
+			},
+			VersionBundle: etcdCertConfig.Spec.VersionBundle,
+		},
+	}
+
+	return certConfig, nil
+}
@@ -0,0 +1,69 @@
+// Package certconfig provides an operatorkit resource that manages the
+// CertConfig of the etcd peer certificate of guest clusters with multiple
+// masters. The etcd server certificate is only issued for the etcd domain of
+// the guest cluster, but etcd members verify the domains their peers advertise.
+// cert-operator issues the etcd peer certificate for the domains of all etcd
+// members based on the CertConfig.
+package certconfig
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "certconfigv18"
+
+	// certConfigNamespace is the namespace cert-operator watches CertConfigs in.
+	certConfigNamespace = "default"
+)
+
+// Config represents the configuration used to create a new certconfig
+// resource.
+type Config struct {
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+}
+
+// Resource implements the certconfig resource.
+type Resource struct {
+	g8sClient versioned.Interface
+	logger    micrologger.Logger
+}
+
+// New creates a new configured certconfig resource.
+func New(config Config) (*Resource, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+func toCertConfig(v interface{}) (*v1alpha1.CertConfig, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	certConfig, ok := v.(*v1alpha1.CertConfig)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.CertConfig{}, v)
+	}
+
+	return certConfig, nil
+}
@@ -0,0 +1,256 @@
+package certconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newCustomObject(masters int) providerv1alpha1.AWSConfig {
+	customObject := providerv1alpha1.AWSConfig{
+		Spec: providerv1alpha1.AWSConfigSpec{
+			AWS: providerv1alpha1.AWSConfigSpecAWS{
+				HostedZones: providerv1alpha1.AWSConfigSpecAWSHostedZones{
+					API: providerv1alpha1.AWSConfigSpecAWSHostedZonesZone{
+						Name: "gauss.eu-central-1.aws.gigantic.io",
+					},
+				},
+			},
+			Cluster: providerv1alpha1.Cluster{
+				ID: "al9qy",
+				Etcd: providerv1alpha1.ClusterEtcd{
+					Domain: "etcd.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+				},
+			},
+		},
+	}
+
+	for i := 0; i < masters; i++ {
+		customObject.Spec.AWS.Masters = append(customObject.Spec.AWS.Masters, providerv1alpha1.AWSConfigSpecAWSNode{})
+	}
+
+	return customObject
+}
+
+func newEtcdCertConfig() *corev1alpha1.CertConfig {
+	return &corev1alpha1.CertConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy-etcd",
+			Namespace: "default",
+		},
+		Spec: corev1alpha1.CertConfigSpec{
+			Cert: corev1alpha1.CertConfigSpecCert{
+				AllowBareDomains: true,
+				AltNames:         []string{"etcd.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io"},
+				ClusterComponent: "etcd",
+				ClusterID:        "al9qy",
+				CommonName:       "etcd.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+				IPSANs:           []string{"127.0.0.1"},
+				Organizations:    []string{"giantswarm"},
+				TTL:              "4320h",
+			},
+			VersionBundle: corev1alpha1.CertConfigSpecVersionBundle{
+				Version: "0.1.0",
+			},
+		},
+	}
+}
+
+func Test_Resource_CertConfig_GetDesiredState(t *testing.T) {
+	testCases := []struct {
+		name             string
+		customObject     providerv1alpha1.AWSConfig
+		g8sObjects       []runtime.Object
+		expectedAltNames []string
+		errorMatcher     func(error) bool
+	}{
+		{
+			name:             "case 0: single master does not need an etcd peer cert",
+			customObject:     newCustomObject(1),
+			g8sObjects:       []runtime.Object{newEtcdCertConfig()},
+			expectedAltNames: nil,
+			errorMatcher:     nil,
+		},
+		{
+			name:         "case 1: multiple masters need an etcd peer cert for all etcd members",
+			customObject: newCustomObject(3),
+			g8sObjects:   []runtime.Object{newEtcdCertConfig()},
+			expectedAltNames: []string{
+				"etcd0.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+				"etcd1.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+				"etcd2.al9qy.k8s.gauss.eu-central-1.aws.gigantic.io",
+			},
+			errorMatcher: nil,
+		},
+		{
+			name:             "case 2: missing etcd cert config",
+			customObject:     newCustomObject(3),
+			g8sObjects:       nil,
+			expectedAltNames: nil,
+			errorMatcher:     IsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{
+				G8sClient: fake.NewSimpleClientset(tc.g8sObjects...),
+				Logger:    microloggertest.New(),
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			desiredState, err := r.GetDesiredState(context.Background(), &tc.customObject)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			certConfig, err := toCertConfig(desiredState)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			if tc.expectedAltNames == nil {
+				if certConfig != nil {
+					t.Fatalf("cert config == %#v, want nil", certConfig)
+				}
+				return
+			}
+
+			if certConfig.Name != "al9qy-etcd-peer" {
+				t.Fatalf("name == %q, want %q", certConfig.Name, "al9qy-etcd-peer")
+			}
+			if certConfig.Spec.Cert.ClusterComponent != "etcd-peer" {
+				t.Fatalf("cluster component == %q, want %q", certConfig.Spec.Cert.ClusterComponent, "etcd-peer")
+			}
+			if !reflect.DeepEqual(certConfig.Spec.Cert.AltNames, tc.expectedAltNames) {
+				t.Fatalf("alt names == %v, want %v", certConfig.Spec.Cert.AltNames, tc.expectedAltNames)
+			}
+			if certConfig.Spec.VersionBundle.Version != "0.1.0" {
+				t.Fatalf("version bundle version == %q, want %q", certConfig.Spec.VersionBundle.Version, "0.1.0")
+			}
+		})
+	}
+}
+
+func Test_Resource_CertConfig_NewUpdatePatch(t *testing.T) {
+	newCertConfig := func(altNames ...string) *corev1alpha1.CertConfig {
+		return &corev1alpha1.CertConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "al9qy-etcd-peer",
+				Namespace: "default",
+			},
+			Spec: corev1alpha1.CertConfigSpec{
+				Cert: corev1alpha1.CertConfigSpecCert{
+					AltNames: altNames,
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		currentState   *corev1alpha1.CertConfig
+		desiredState   *corev1alpha1.CertConfig
+		expectedCreate *corev1alpha1.CertConfig
+		expectedDelete *corev1alpha1.CertConfig
+		expectedUpdate *corev1alpha1.CertConfig
+	}{
+		{
+			name:           "case 0: missing cert config is created",
+			currentState:   nil,
+			desiredState:   newCertConfig("etcd0", "etcd1", "etcd2"),
+			expectedCreate: newCertConfig("etcd0", "etcd1", "etcd2"),
+		},
+		{
+			name:         "case 1: equal cert config is kept",
+			currentState: newCertConfig("etcd0", "etcd1", "etcd2"),
+			desiredState: newCertConfig("etcd0", "etcd1", "etcd2"),
+		},
+		{
+			name:           "case 2: cert config is updated when masters are added",
+			currentState:   newCertConfig("etcd0", "etcd1", "etcd2"),
+			desiredState:   newCertConfig("etcd0", "etcd1", "etcd2", "etcd3", "etcd4"),
+			expectedUpdate: newCertConfig("etcd0", "etcd1", "etcd2", "etcd3", "etcd4"),
+		},
+		{
+			name:           "case 3: cert config is deleted when running a single master",
+			currentState:   newCertConfig("etcd0", "etcd1", "etcd2"),
+			desiredState:   nil,
+			expectedDelete: newCertConfig("etcd0", "etcd1", "etcd2"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{
+				G8sClient: fake.NewSimpleClientset(),
+				Logger:    microloggertest.New(),
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			var currentState, desiredState interface{}
+			if tc.currentState != nil {
+				currentState = tc.currentState
+			}
+			if tc.desiredState != nil {
+				desiredState = tc.desiredState
+			}
+
+			create, err := r.newCreateChange(context.Background(), nil, currentState, desiredState)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			delete, err := r.newDeleteChange(context.Background(), nil, currentState, desiredState)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+			update, err := r.newUpdateChange(context.Background(), nil, currentState, desiredState)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			changes := []struct {
+				name     string
+				change   interface{}
+				expected *corev1alpha1.CertConfig
+			}{
+				{name: "create", change: create, expected: tc.expectedCreate},
+				{name: "delete", change: delete, expected: tc.expectedDelete},
+				{name: "update", change: update, expected: tc.expectedUpdate},
+			}
+
+			for _, c := range changes {
+				certConfig, err := toCertConfig(c.change)
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+				if !reflect.DeepEqual(certConfig, c.expected) {
+					t.Fatalf("%s change == %#v, want %#v", c.name, certConfig, c.expected)
+				}
+			}
+		})
+	}
+}
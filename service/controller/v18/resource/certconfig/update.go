@@ -0,0 +1,81 @@
+package certconfig
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller"
+)
+
+func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	certConfigToUpdate, err := toCertConfig(updateChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if certConfigToUpdate != nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating the etcd peer cert config in the Kubernetes API")
+
+		_, err = r.g8sClient.CoreV1alpha1().CertConfigs(certConfigToUpdate.Namespace).Update(certConfigToUpdate)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated the etcd peer cert config in the Kubernetes API")
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the etcd peer cert config does not need to be updated in the Kubernetes API")
+	}
+
+	return nil
+}
+
+func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*controller.Patch, error) {
+	create, err := r.newCreateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	delete, err := r.newDeleteChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	update, err := r.newUpdateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patch := controller.NewPatch()
+	patch.SetCreateChange(create)
+	patch.SetDeleteChange(delete)
+	patch.SetUpdateChange(update)
+
+	return patch, nil
+}
+
+// newUpdateChange updates the etcd peer cert config when the number of masters
+// and with it the domains of the etcd members change. cert-operator then issues
+// a new etcd peer certificate.
+func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	currentCertConfig, err := toCertConfig(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredCertConfig, err := toCertConfig(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if currentCertConfig == nil || desiredCertConfig == nil {
+		return nil, nil
+	}
+	if reflect.DeepEqual(currentCertConfig.Spec, desiredCertConfig.Spec) {
+		return nil, nil
+	}
+
+	certConfigToUpdate := currentCertConfig.DeepCopy()
+	certConfigToUpdate.Spec = desiredCertConfig.Spec
+
+	return certConfigToUpdate, nil
+}
@@ -0,0 +1,242 @@
+package cloudformation
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// changeSetApprovalRequiredReason, changeSetCreatedReason and
+	// changeSetExecutedReason are the reasons of the Kubernetes events emitted
+	// about the change sets of the guest cluster main stack.
+	changeSetApprovalRequiredReason = "ChangeSetApprovalRequired"
+	changeSetCreatedReason          = "ChangeSetCreated"
+	changeSetExecutedReason         = "ChangeSetExecuted"
+
+	// changeSetNamePrefix is the prefix of the names of the change sets created
+	// for updates of the guest cluster main stack.
+	changeSetNamePrefix = "update"
+	// changeSetHashLength is the number of hex characters of the hash of the
+	// update used in change set names.
+	changeSetHashLength = 16
+	// changeSetWaitAttempts and changeSetWaitDelay bound the time we wait for
+	// CloudFormation to compute a change set within a single reconciliation.
+	changeSetWaitAttempts = 24
+	changeSetWaitDelay    = 5 * time.Second
+)
+
+// changeSetSummary classifies the changes of a change set by the logical IDs
+// of the affected resources. Modifications requiring the replacement of a
+// resource, including conditional replacements, are listed as replaced.
+type changeSetSummary struct {
+	Added    []string
+	Modified []string
+	Removed  []string
+	Replaced []string
+}
+
+// newChangeSetSummary classifies the given changes of a change set.
+func newChangeSetSummary(changes []*cloudformation.Change) changeSetSummary {
+	var s changeSetSummary
+
+	for _, c := range changes {
+		rc := c.ResourceChange
+		if rc == nil {
+			continue
+		}
+
+		id := aws.StringValue(rc.LogicalResourceId)
+
+		switch aws.StringValue(rc.Action) {
+		case cloudformation.ChangeActionAdd:
+			s.Added = append(s.Added, id)
+		case cloudformation.ChangeActionRemove:
+			s.Removed = append(s.Removed, id)
+		case cloudformation.ChangeActionModify:
+			replacement := aws.StringValue(rc.Replacement)
+			if replacement == cloudformation.ReplacementTrue || replacement == cloudformation.ReplacementConditional {
+				s.Replaced = append(s.Replaced, id)
+			} else {
+				s.Modified = append(s.Modified, id)
+			}
+		}
+	}
+
+	sort.Strings(s.Added)
+	sort.Strings(s.Modified)
+	sort.Strings(s.Removed)
+	sort.Strings(s.Replaced)
+
+	return s
+}
+
+// NeedsApproval returns true when executing the change set replaces or removes
+// any resource of the guest cluster main stack.
+func (s changeSetSummary) NeedsApproval() bool {
+	return len(s.Removed) > 0 || len(s.Replaced) > 0
+}
+
+func (s changeSetSummary) String() string {
+	var parts []string
+
+	if len(s.Added) > 0 {
+		parts = append(parts, fmt.Sprintf("add %s", strings.Join(s.Added, ", ")))
+	}
+	if len(s.Modified) > 0 {
+		parts = append(parts, fmt.Sprintf("modify in place %s", strings.Join(s.Modified, ", ")))
+	}
+	if len(s.Replaced) > 0 {
+		parts = append(parts, fmt.Sprintf("replace %s", strings.Join(s.Replaced, ", ")))
+	}
+	if len(s.Removed) > 0 {
+		parts = append(parts, fmt.Sprintf("remove %s", strings.Join(s.Removed, ", ")))
+	}
+
+	if len(parts) == 0 {
+		return "no resource changes"
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// changeSetName returns the name of the change set for the given update of the
+// guest cluster main stack. The name is derived from the template and the
+// parameters of the update, so that the same update always results in the
+// same change set name, which is what approvals refer to.
+func changeSetName(updateStackInput cloudformation.UpdateStackInput) string {
+	h := sha256.New()
+
+	h.Write([]byte(aws.StringValue(updateStackInput.TemplateBody)))
+	for _, p := range updateStackInput.Parameters {
+		h.Write([]byte(aws.StringValue(p.ParameterKey)))
+		h.Write([]byte(aws.StringValue(p.ParameterValue)))
+	}
+
+	return fmt.Sprintf("%s-%s", changeSetNamePrefix, fmt.Sprintf("%x", h.Sum(nil))[:changeSetHashLength])
+}
+
+// ensureChangeSet returns the summary of the change set with the given name for
+// the given update of the guest cluster main stack. The change set is created
+// in case it does not exist yet. Change sets are kept until they are executed,
+// so that change sets held back for approval can be inspected. The returned
+// boolean is false in case the change set is not computed yet or does not
+// contain any changes, in which case there is nothing to execute.
+func (r *Resource) ensureChangeSet(ctx context.Context, cf *cloudformation.CloudFormation, updateStackInput cloudformation.UpdateStackInput, name string) (changeSetSummary, bool, error) {
+	describeInput := &cloudformation.DescribeChangeSetInput{
+		ChangeSetName: aws.String(name),
+		StackName:     updateStackInput.StackName,
+	}
+
+	_, err := cf.DescribeChangeSet(describeInput)
+	if IsChangeSetNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating change set %#q for the guest cluster main stack", name))
+
+		i := &cloudformation.CreateChangeSetInput{
+			Capabilities:  updateStackInput.Capabilities,
+			ChangeSetName: aws.String(name),
+			ChangeSetType: aws.String(cloudformation.ChangeSetTypeUpdate),
+			Parameters:    updateStackInput.Parameters,
+			StackName:     updateStackInput.StackName,
+			TemplateBody:  updateStackInput.TemplateBody,
+		}
+
+		_, err := cf.CreateChangeSet(i)
+		if err != nil {
+			return changeSetSummary{}, false, microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created change set %#q for the guest cluster main stack", name))
+
+		// The waiter fails when computing the change set fails or takes too long.
+		// Both cases are handled based on the status of the change set below.
+		err = cf.WaitUntilChangeSetCreateCompleteWithContext(ctx, describeInput, request.WithWaiterMaxAttempts(changeSetWaitAttempts), request.WithWaiterDelay(request.ConstantWaiterDelay(changeSetWaitDelay)))
+		if err != nil && !IsResourceNotReady(err) {
+			return changeSetSummary{}, false, microerror.Mask(err)
+		}
+	} else if err != nil {
+		return changeSetSummary{}, false, microerror.Mask(err)
+	}
+
+	var changes []*cloudformation.Change
+	for {
+		o, err := cf.DescribeChangeSet(describeInput)
+		if err != nil {
+			return changeSetSummary{}, false, microerror.Mask(err)
+		}
+
+		switch aws.StringValue(o.Status) {
+		case cloudformation.ChangeSetStatusCreateComplete:
+			// Change sets become obsolete when the stack is updated otherwise in
+			// the meantime. They are recreated with the next reconciliation.
+			if aws.StringValue(o.ExecutionStatus) == cloudformation.ExecutionStatusObsolete {
+				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("change set %#q for the guest cluster main stack is obsolete", name))
+
+				err := deleteChangeSet(cf, describeInput)
+				if err != nil {
+					return changeSetSummary{}, false, microerror.Mask(err)
+				}
+
+				return changeSetSummary{}, false, nil
+			}
+		case cloudformation.ChangeSetStatusFailed:
+			// Failed change sets are deleted so that they are recreated with the
+			// next reconciliation.
+			err := deleteChangeSet(cf, describeInput)
+			if err != nil {
+				return changeSetSummary{}, false, microerror.Mask(err)
+			}
+
+			if !isNoChangesStatusReason(aws.StringValue(o.StatusReason)) {
+				return changeSetSummary{}, false, microerror.Maskf(executionFailedError, "change set %#q failed: %s", name, aws.StringValue(o.StatusReason))
+			}
+
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("change set %#q for the guest cluster main stack does not contain any changes", name))
+
+			return changeSetSummary{}, false, nil
+		default:
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("change set %#q for the guest cluster main stack is in status %#q", name, aws.StringValue(o.Status)))
+			return changeSetSummary{}, false, nil
+		}
+
+		changes = append(changes, o.Changes...)
+
+		if o.NextToken == nil {
+			break
+		}
+		describeInput.NextToken = o.NextToken
+	}
+
+	return newChangeSetSummary(changes), true, nil
+}
+
+func deleteChangeSet(cf *cloudformation.CloudFormation, describeInput *cloudformation.DescribeChangeSetInput) error {
+	i := &cloudformation.DeleteChangeSetInput{
+		ChangeSetName: describeInput.ChangeSetName,
+		StackName:     describeInput.StackName,
+	}
+
+	_, err := cf.DeleteChangeSet(i)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// isNoChangesStatusReason returns true when the given status reason of a failed
+// change set states that the update does not contain any changes.
+//
+// FIXME: CloudFormation does not provide any other means to find out why
+// computing a change set failed, so we have to check the status reason.
+func isNoChangesStatusReason(reason string) bool {
+	return strings.Contains(reason, "didn't contain changes") || strings.Contains(reason, "No updates are to be performed")
+}
@@ -0,0 +1,125 @@
+package cloudformation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+func Test_Resource_Cloudformation_newChangeSetSummary(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description           string
+		changes               []*cloudformation.Change
+		expectedSummary       changeSetSummary
+		expectedNeedsApproval bool
+		expectedString        string
+	}{
+		{
+			description:           "no changes",
+			changes:               nil,
+			expectedSummary:       changeSetSummary{},
+			expectedNeedsApproval: false,
+			expectedString:        "no resource changes",
+		},
+		{
+			description: "in place changes do not need approval",
+			changes: []*cloudformation.Change{
+				newTestChange(cloudformation.ChangeActionModify, "workerAutoScalingGroup", cloudformation.ReplacementFalse),
+				newTestChange(cloudformation.ChangeActionAdd, "workerSpotAutoScalingGroup", ""),
+				newTestChange(cloudformation.ChangeActionModify, "workerLaunchTemplate", cloudformation.ReplacementFalse),
+			},
+			expectedSummary: changeSetSummary{
+				Added:    []string{"workerSpotAutoScalingGroup"},
+				Modified: []string{"workerAutoScalingGroup", "workerLaunchTemplate"},
+			},
+			expectedNeedsApproval: false,
+			expectedString:        "add workerSpotAutoScalingGroup; modify in place workerAutoScalingGroup, workerLaunchTemplate",
+		},
+		{
+			description: "replacements need approval",
+			changes: []*cloudformation.Change{
+				newTestChange(cloudformation.ChangeActionModify, "MasterInstance", cloudformation.ReplacementTrue),
+				newTestChange(cloudformation.ChangeActionModify, "EtcdVolume", cloudformation.ReplacementConditional),
+			},
+			expectedSummary: changeSetSummary{
+				Replaced: []string{"EtcdVolume", "MasterInstance"},
+			},
+			expectedNeedsApproval: true,
+			expectedString:        "replace EtcdVolume, MasterInstance",
+		},
+		{
+			description: "removals need approval",
+			changes: []*cloudformation.Change{
+				newTestChange(cloudformation.ChangeActionRemove, "workerSpotAutoScalingGroup", ""),
+			},
+			expectedSummary: changeSetSummary{
+				Removed: []string{"workerSpotAutoScalingGroup"},
+			},
+			expectedNeedsApproval: true,
+			expectedString:        "remove workerSpotAutoScalingGroup",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			summary := newChangeSetSummary(tc.changes)
+
+			if !reflect.DeepEqual(summary, tc.expectedSummary) {
+				t.Fatalf("expected %#v got %#v", tc.expectedSummary, summary)
+			}
+			if summary.NeedsApproval() != tc.expectedNeedsApproval {
+				t.Fatalf("expected %t got %t", tc.expectedNeedsApproval, summary.NeedsApproval())
+			}
+			if summary.String() != tc.expectedString {
+				t.Fatalf("expected %q got %q", tc.expectedString, summary.String())
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_changeSetName(t *testing.T) {
+	t.Parallel()
+	newInput := func(templateBody, version string) cloudformation.UpdateStackInput {
+		return cloudformation.UpdateStackInput{
+			Parameters: []*cloudformation.Parameter{
+				{
+					ParameterKey:   aws.String(versionBundleVersionParameterKey),
+					ParameterValue: aws.String(version),
+				},
+			},
+			TemplateBody: aws.String(templateBody),
+		}
+	}
+
+	name := changeSetName(newInput("template", "1.0.0"))
+	if len(name) != len(changeSetNamePrefix)+1+changeSetHashLength {
+		t.Fatalf("expected change set name of length %d got %q", len(changeSetNamePrefix)+1+changeSetHashLength, name)
+	}
+	if changeSetName(newInput("template", "1.0.0")) != name {
+		t.Fatalf("expected the same update to result in the same change set name")
+	}
+	if changeSetName(newInput("changed template", "1.0.0")) == name {
+		t.Fatalf("expected a changed template to result in a different change set name")
+	}
+	if changeSetName(newInput("template", "2.0.0")) == name {
+		t.Fatalf("expected changed parameters to result in a different change set name")
+	}
+}
+
+func newTestChange(action, logicalID, replacement string) *cloudformation.Change {
+	c := &cloudformation.Change{
+		ResourceChange: &cloudformation.ResourceChange{
+			Action:            aws.String(action),
+			LogicalResourceId: aws.String(logicalID),
+		},
+		Type: aws.String(cloudformation.ChangeTypeResource),
+	}
+	if replacement != "" {
+		c.ResourceChange.Replacement = aws.String(replacement)
+	}
+
+	return c
+}
@@ -118,7 +118,7 @@ func (r *Resource) newCreateChange(ctx context.Context, obj, currentState, desir
 	if currentStackState.Name == "" || desiredStackState.Name != currentStackState.Name {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack has to be created")
 
-		if err := r.validateCluster(customObject); err != nil {
+		if err := r.validateNewCluster(ctx, customObject); err != nil {
 			return cloudformation.CreateStackInput{}, microerror.Mask(err)
 		}
 
@@ -7,9 +7,11 @@ import (
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 )
 
@@ -36,6 +38,11 @@ func Test_Resource_Cloudformation_newCreate(t *testing.T) {
 					},
 				},
 				Region: "eu-central-1",
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.0.0/16",
+					PrivateSubnetCIDR: "10.1.0.0/17",
+					PublicSubnetCIDR:  "10.1.128.0/17",
+				},
 				Workers: []v1alpha1.AWSConfigSpecAWSNode{
 					{
 						ImageID: "myimageid",
@@ -84,10 +91,15 @@ func Test_Resource_Cloudformation_newCreate(t *testing.T) {
 	var err error
 	var newResource *Resource
 	{
+		ec2Mock := &adapter.EC2ClientMock{}
+		ec2Mock.SetVPCCIDR("10.0.0.0/16")
+
 		c := Config{}
 
+		c.EventRecorder = &record.FakeRecorder{}
+		c.ImageCatalog = ami.NewBuiltin()
 		c.HostClients = &adapter.Clients{
-			EC2:            &adapter.EC2ClientMock{},
+			EC2:            ec2Mock,
 			CloudFormation: &adapter.CloudFormationMock{},
 			IAM:            &adapter.IAMClientMock{},
 			STS:            &adapter.STSClientMock{},
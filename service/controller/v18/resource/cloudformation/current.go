@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/microerror"
@@ -28,6 +30,11 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		return StackState{}, microerror.Mask(err)
 	}
 
+	err = r.reportHostStackStatuses(ctx, customObject)
+	if err != nil {
+		return StackState{}, microerror.Mask(err)
+	}
+
 	// In order to compute the current state of the guest cluster's cloud
 	// formation stack we have to describe the CF stacks and lookup the right
 	// stack. We dispatch our custom StackState structure and enrich it with all
@@ -41,11 +48,22 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		if cloudformationservice.IsStackNotFound(err) {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster main stack outputs in the AWS API")
 			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack does not exist")
+			setStackStatus(key.ClusterID(customObject), guestMainStack, "")
 			return StackState{}, nil
 
 		} else if cloudformationservice.IsOutputsNotAccessible(err) {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster main stack outputs in the AWS API")
 			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("the guest cluster main stack has status '%s'", stackStatus))
+
+			err := r.reportStackStatus(ctx, customObject, &sc.CloudFormation, guestMainStack, stackName, stackStatus)
+			if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+			_, err = r.recoverStack(ctx, customObject, stackName, stackStatus)
+			if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+
 			if key.IsDeleted(customObject) {
 				// Keep finalizers to as long as we don't
 				// encounter IsStackNotFound.
@@ -62,6 +80,26 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		}
 
 		r.logger.LogCtx(ctx, "level", "debug", "message", "found the guest cluster main stack outputs in the AWS API")
+
+		// Stacks which rolled back still provide outputs, so we have to report
+		// their status here as well.
+		err = r.reportStackStatus(ctx, customObject, &sc.CloudFormation, guestMainStack, stackName, stackStatus)
+		if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+
+		// Stacks which failed to be created roll back and do not provide the
+		// outputs we need below. Recovering them deletes them, so we stop here.
+		recovering, err := r.recoverStack(ctx, customObject, stackName, stackStatus)
+		if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+		if recovering {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			resourcecanceledcontext.SetCanceled(ctx)
+
+			return StackState{}, nil
+		}
 	}
 
 	var currentState StackState
@@ -185,22 +223,232 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 			return StackState{}, microerror.Mask(err)
 		}
 
+		// Stacks created before load balancer types were supported do not
+		// provide the load balancer types. They run classic ELBs.
+		loadBalancerTypes := []string{key.LoadBalancerTypeClassic}
+		{
+			v, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.LoadBalancerTypesKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				loadBalancerTypes = strings.Split(v, ",")
+			}
+		}
+
+		// Stacks migrating between load balancer types provide the time the DNS
+		// records switched to the load balancers of the first type.
+		var loadBalancerSwitchTime time.Time
+		{
+			v, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.LoadBalancerSwitchTimeKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				loadBalancerSwitchTime, err = time.Parse(time.RFC3339, v)
+				if err != nil {
+					return StackState{}, microerror.Mask(err)
+				}
+			}
+		}
+
+		// Stacks created before the settings of the classic ELBs could be
+		// configured do not provide the load balancer hash. Changes are only
+		// detected once the hash is known.
+		var loadBalancerHash string
+		{
+			v, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.LoadBalancerHashKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				loadBalancerHash = v
+			}
+		}
+
+		// Stacks created before security group rules could be configured do not
+		// provide the security groups hash. Changes are only detected once the
+		// hash is known.
+		var securityGroupsHash string
+		{
+			v, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.SecurityGroupsHashKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				securityGroupsHash = v
+			}
+		}
+
+		// Stacks created before VPC peerings and virtual private gateways could be
+		// configured do not provide the VPC connections hash. Changes are only
+		// detected once the hash is known.
+		var vpcConnectionsHash string
+		{
+			v, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.VPCConnectionsHashKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				vpcConnectionsHash = v
+			}
+		}
+
+		// Guest clusters peered with the host cluster VPC do not provide the ID of
+		// a Transit Gateway attachment.
+		var transitGatewayAttachmentID string
+		{
+			v, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.TransitGatewayAttachmentIDKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				transitGatewayAttachmentID = v
+			}
+		}
+
+		// Stacks of guest clusters without private hosted zone do not provide the
+		// ID of the private hosted zone.
+		var privateHostedZone bool
+		{
+			_, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.PrivateHostedZoneIDKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				privateHostedZone = true
+			}
+		}
+
+		// Stacks of guest clusters with public Kubernetes APIs do not provide the
+		// private API output.
+		var apiPrivate bool
+		{
+			_, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.APIPrivateKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				apiPrivate = true
+			}
+		}
+
+		// Stacks of guest clusters without VPC endpoints do not provide the VPC
+		// endpoints output.
+		var vpcEndpoints bool
+		{
+			_, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.VPCEndpointsKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				vpcEndpoints = true
+			}
+		}
+
+		// Stacks created before VPC flow logs were supported do not provide the
+		// traffic type of the VPC flow logs. Changes are only detected once the
+		// traffic type is known.
+		var vpcFlowLogsTrafficType string
+		{
+			v, err := sc.CloudFormation.GetOutputValue(stackOutputs, key.VPCFlowLogsTrafficTypeKey)
+			if cloudformationservice.IsOutputNotFound(err) {
+				// Fall through.
+			} else if err != nil {
+				return StackState{}, microerror.Mask(err)
+			} else {
+				vpcFlowLogsTrafficType = v
+			}
+		}
+
+		masters, err := getCurrentMasters(&sc.CloudFormation, stackOutputs)
+		if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+		// Guest clusters created before multiple masters were supported do not
+		// provide the per master outputs. They run a single master, which is
+		// described by the general master outputs.
+		if len(masters) == 0 {
+			m := StackStateMaster{
+				CloudConfigVersion:       masterCloudConfigVersion,
+				DockerVolumeResourceName: dockerVolumeResourceName,
+				ImageID:                  masterImageID,
+				InstanceResourceName:     masterInstanceResourceName,
+				InstanceType:             masterInstanceType,
+				VersionBundleVersion:     versionBundleVersion,
+			}
+			masters = append(masters, m)
+		}
+
+		workerPools, err := getCurrentWorkerPools(&sc.CloudFormation, stackOutputs)
+		if err != nil {
+			return StackState{}, microerror.Mask(err)
+		}
+		// Guest clusters created before node pools were supported do not provide
+		// the per node pool outputs. They run a single node pool, which is
+		// described by the general worker outputs.
+		if len(workerPools) == 0 {
+			count, err := strconv.Atoi(workerCount)
+			if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+
+			w := StackStateWorkerPool{
+				Count:              count,
+				DockerVolumeSizeGB: workerDockerVolumeSizeGB,
+				InstanceType:       workerInstanceType,
+				Name:               key.DefaultNodePoolName,
+				Scaling: StackStateWorkerPoolScaling{
+					Max: count + 1,
+					Min: count,
+				},
+			}
+			workerPools = append(workerPools, w)
+		}
+
 		currentState = StackState{
 			Name: stackName,
 
+			APIPrivate: apiPrivate,
+
 			HostedZoneNameServers: hostedZoneNameServers,
+			PrivateHostedZone:     privateHostedZone,
+
+			LoadBalancerTypes:      loadBalancerTypes,
+			LoadBalancerSwitchTime: loadBalancerSwitchTime,
+			LoadBalancerHash:       loadBalancerHash,
+
+			SecurityGroupsHash: securityGroupsHash,
+			VPCConnectionsHash: vpcConnectionsHash,
+
+			TransitGateway:             transitGatewayAttachmentID != "",
+			TransitGatewayAttachmentID: transitGatewayAttachmentID,
+
+			VPCEndpoints:           vpcEndpoints,
+			VPCFlowLogsTrafficType: vpcFlowLogsTrafficType,
 
 			DockerVolumeResourceName:   dockerVolumeResourceName,
 			MasterImageID:              masterImageID,
 			MasterInstanceResourceName: masterInstanceResourceName,
 			MasterInstanceType:         masterInstanceType,
 			MasterCloudConfigVersion:   masterCloudConfigVersion,
+			Masters:                    masters,
 
 			WorkerCount:              workerCount,
 			WorkerDockerVolumeSizeGB: workerDockerVolumeSizeGB,
 			WorkerImageID:            workerImageID,
 			WorkerInstanceType:       workerInstanceType,
 			WorkerCloudConfigVersion: workerCloudConfigVersion,
+			WorkerPools:              workerPools,
 
 			VersionBundleVersion: versionBundleVersion,
 		}
@@ -208,3 +456,209 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 
 	return currentState, nil
 }
+
+// getCurrentMasters returns the state of every master instance as described by
+// the per master outputs of the guest cluster main stack. The outputs hold comma
+// separated lists, one item per master. An empty list is returned in case the
+// stack does not provide the per master outputs yet.
+func getCurrentMasters(cf *cloudformationservice.CloudFormation, stackOutputs []*cloudformation.Output) ([]StackStateMaster, error) {
+	keys := []string{
+		key.MasterCloudConfigVersionsKey,
+		key.DockerVolumeResourceNamesKey,
+		key.MasterImageIDsKey,
+		key.MasterInstanceResourceNamesKey,
+		key.MasterInstanceTypesKey,
+		key.MasterVersionBundleVersionsKey,
+	}
+
+	var lists [][]string
+	for _, k := range keys {
+		v, err := cf.GetOutputValue(stackOutputs, k)
+		if cloudformationservice.IsOutputNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		lists = append(lists, strings.Split(v, ","))
+	}
+
+	count := len(lists[0])
+	for i, l := range lists {
+		if len(l) != count {
+			return nil, microerror.Maskf(executionFailedError, "expected %d items in output %s, got %d", count, keys[i], len(l))
+		}
+	}
+
+	var masters []StackStateMaster
+	for i := 0; i < count; i++ {
+		m := StackStateMaster{
+			CloudConfigVersion:       lists[0][i],
+			DockerVolumeResourceName: lists[1][i],
+			ImageID:                  lists[2][i],
+			InstanceResourceName:     lists[3][i],
+			InstanceType:             lists[4][i],
+			VersionBundleVersion:     lists[5][i],
+		}
+		masters = append(masters, m)
+	}
+
+	return masters, nil
+}
+
+// getCurrentWorkerPools returns the state of every node pool as described by
+// the per node pool outputs of the guest cluster main stack. The outputs hold
+// comma separated lists, one item per node pool. An empty list is returned in
+// case the stack does not provide the per node pool outputs yet.
+func getCurrentWorkerPools(cf *cloudformationservice.CloudFormation, stackOutputs []*cloudformation.Output) ([]StackStateWorkerPool, error) {
+	keys := []string{
+		key.WorkerPoolCountsKey,
+		key.WorkerPoolDockerVolumeSizesKey,
+		key.WorkerPoolInstanceTypesKey,
+		key.WorkerPoolNamesKey,
+	}
+
+	var lists [][]string
+	for _, k := range keys {
+		v, err := cf.GetOutputValue(stackOutputs, k)
+		if cloudformationservice.IsOutputNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		lists = append(lists, strings.Split(v, ","))
+	}
+
+	count := len(lists[0])
+	for i, l := range lists {
+		if len(l) != count {
+			return nil, microerror.Maskf(executionFailedError, "expected %d items in output %s, got %d", count, keys[i], len(l))
+		}
+	}
+
+	// Node pools created before spot instances were supported run on-demand
+	// instances.
+	spotEnabled, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolSpotEnabledKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	// The remaining spot settings are only provided by stacks created after
+	// they were tracked. Spot instance types and max prices are only provided
+	// in case any node pool defines them, so missing outputs mean empty spot
+	// instance types and max prices.
+	spotInstanceTypes, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolSpotInstanceTypesKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	spotMaxPrices, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolSpotMaxPricesKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	spotOnDemandBases, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolSpotOnDemandBasesKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	spotOnDemandPercents, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolSpotOnDemandPercentsKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	// Node pools created before launch templates were supported do not have a
+	// launch template hash.
+	launchTemplateHashes, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolLaunchTemplateHashesKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Node pools created before cluster-autoscaler was supported do not
+	// provide their scaling bounds.
+	scalingMaxes, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolScalingMaxesKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	scalingMins, err := getOptionalOutputList(cf, stackOutputs, key.WorkerPoolScalingMinsKey, count)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var workerPools []StackStateWorkerPool
+	for i := 0; i < count; i++ {
+		workers, err := strconv.Atoi(lists[0][i])
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		dockerVolumeSizeGB, err := strconv.Atoi(lists[1][i])
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		w := StackStateWorkerPool{
+			Count:              workers,
+			DockerVolumeSizeGB: dockerVolumeSizeGB,
+			InstanceType:       lists[2][i],
+			Name:               lists[3][i],
+		}
+		if spotEnabled != nil {
+			w.Spot.Enabled, err = strconv.ParseBool(spotEnabled[i])
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+		if spotInstanceTypes != nil && spotInstanceTypes[i] != "" {
+			w.Spot.InstanceTypes = strings.Fields(spotInstanceTypes[i])
+		}
+		if spotMaxPrices != nil {
+			w.Spot.MaxPrice = spotMaxPrices[i]
+		}
+		if spotOnDemandBases != nil {
+			w.Spot.OnDemandBaseCapacity, err = strconv.Atoi(spotOnDemandBases[i])
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+		if spotOnDemandPercents != nil {
+			w.Spot.OnDemandPercentageAboveBaseCapacity, err = strconv.Atoi(spotOnDemandPercents[i])
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+		if launchTemplateHashes != nil {
+			w.LaunchTemplateHash = launchTemplateHashes[i]
+		}
+		if scalingMaxes != nil && scalingMins != nil {
+			w.Scaling.Max, err = strconv.Atoi(scalingMaxes[i])
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			w.Scaling.Min, err = strconv.Atoi(scalingMins[i])
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		} else {
+			w.Scaling.Max = workers + 1
+			w.Scaling.Min = workers
+		}
+		workerPools = append(workerPools, w)
+	}
+
+	return workerPools, nil
+}
+
+// getOptionalOutputList returns the comma separated list of the given stack
+// output, which must hold count items. Nil is returned in case the stack does
+// not provide the output yet.
+func getOptionalOutputList(cf *cloudformationservice.CloudFormation, stackOutputs []*cloudformation.Output, outputKey string, count int) ([]string, error) {
+	v, err := cf.GetOutputValue(stackOutputs, outputKey)
+	if cloudformationservice.IsOutputNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	l := strings.Split(v, ",")
+	if len(l) != count {
+		return nil, microerror.Maskf(executionFailedError, "expected %d items in output %s, got %d", count, outputKey, len(l))
+	}
+
+	return l, nil
+}
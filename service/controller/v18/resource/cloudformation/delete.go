@@ -23,6 +23,36 @@ func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange inte
 		return microerror.Mask(err)
 	}
 
+	// The host cluster post stack is deleted first, since it references
+	// resources of the guest cluster main stack. These are the VPC peering
+	// connection or the Transit Gateway attachment of the guest cluster VPC.
+	if stackStateToDelete.Name != "" {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the host cluster post stack")
+
+		stackName := aws.String(key.MainHostPostStackName(customObject))
+
+		updateTerminationProtection := &cloudformation.UpdateTerminationProtectionInput{
+			EnableTerminationProtection: aws.Bool(false),
+			StackName:                   stackName,
+		}
+		_, err = r.hostClients.CloudFormation.UpdateTerminationProtection(updateTerminationProtection)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		i := &cloudformation.DeleteStackInput{
+			StackName: stackName,
+		}
+		_, err = r.hostClients.CloudFormation.DeleteStack(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted the host cluster post stack")
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting the host cluster post stack")
+	}
+
 	if stackStateToDelete.Name != "" {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the guest cluster main stack")
 
@@ -89,33 +119,6 @@ func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange inte
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting the host cluster pre stack")
 	}
 
-	if stackStateToDelete.Name != "" {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the host cluster post stack")
-
-		stackName := aws.String(key.MainHostPostStackName(customObject))
-
-		updateTerminationProtection := &cloudformation.UpdateTerminationProtectionInput{
-			EnableTerminationProtection: aws.Bool(false),
-			StackName:                   stackName,
-		}
-		_, err = r.hostClients.CloudFormation.UpdateTerminationProtection(updateTerminationProtection)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-
-		i := &cloudformation.DeleteStackInput{
-			StackName: stackName,
-		}
-		_, err = r.hostClients.CloudFormation.DeleteStack(i)
-		if err != nil {
-			return microerror.Mask(err)
-		}
-
-		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted the host cluster post stack")
-	} else {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting the host cluster post stack")
-	}
-
 	return nil
 }
 
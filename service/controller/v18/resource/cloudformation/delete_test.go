@@ -6,8 +6,10 @@ import (
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
 )
 
 func Test_Resource_Cloudformation_newDelete(t *testing.T) {
@@ -88,6 +90,8 @@ func Test_Resource_Cloudformation_newDelete(t *testing.T) {
 	{
 		c := Config{}
 
+		c.EventRecorder = &record.FakeRecorder{}
+		c.ImageCatalog = ami.NewBuiltin()
 		c.HostClients = &adapter.Clients{}
 		c.Logger = microloggertest.New()
 		c.EncrypterBackend = "kms"
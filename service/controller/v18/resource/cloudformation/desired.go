@@ -2,10 +2,17 @@ package cloudformation
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"strconv"
 
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v18/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
@@ -19,9 +26,16 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", "computing desired state for the guest cluster main stack")
 
-		imageID, err := key.ImageID(customObject)
-		if err != nil {
-			return StackState{}, microerror.Mask(err)
+		// AMIs configured in the custom object take precedence. The AMI catalog
+		// is only consulted in case any master or node pool does not configure
+		// its AMI.
+		var defaultImageID, imageCatalogSource string
+		if key.UsesImageCatalog(customObject) {
+			defaultImageID, err = r.defaultImageID(ctx, customObject)
+			if err != nil {
+				return StackState{}, microerror.Mask(err)
+			}
+			imageCatalogSource = r.imageCatalog.Source()
 		}
 
 		// FIXME: the instance type should not depend on the number of workers.
@@ -36,22 +50,107 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 			masterInstanceType = key.MasterInstanceType(customObject)
 		}
 
+		dockerVolumeResourceName := key.DockerVolumeResourceName(customObject)
+		masterInstanceResourceName := key.MasterInstanceResourceName(customObject)
+
+		var masters []StackStateMaster
+		for i, imageID := range key.MasterImageIDs(customObject) {
+			if imageID == "" {
+				imageID = defaultImageID
+			}
+
+			m := StackStateMaster{
+				CloudConfigVersion:       key.CloudConfigVersion,
+				DockerVolumeResourceName: key.IndexedName(dockerVolumeResourceName, i),
+				ImageID:                  imageID,
+				InstanceResourceName:     key.IndexedName(masterInstanceResourceName, i),
+				InstanceType:             masterInstanceType,
+				VersionBundleVersion:     key.VersionBundleVersion(customObject),
+			}
+			masters = append(masters, m)
+		}
+
+		var workerPools []StackStateWorkerPool
+		for _, p := range key.NodePools(customObject) {
+			imageID := key.NodePoolImageID(p)
+			if imageID == "" {
+				imageID = defaultImageID
+			}
+
+			w := StackStateWorkerPool{
+				Count:              key.NodePoolCount(p),
+				DockerVolumeSizeGB: key.NodePoolDockerVolumeSizeGB(p),
+				ImageID:            imageID,
+				InstanceType:       key.NodePoolInstanceType(p),
+				Name:               p.Name,
+				Scaling: StackStateWorkerPoolScaling{
+					Enabled: key.NodePoolScalingEnabled(p),
+					Max:     key.NodePoolScalingMax(p),
+					Min:     key.NodePoolScalingMin(p),
+				},
+				Spot: StackStateWorkerPoolSpot{
+					Enabled:                             p.Spot.Enabled,
+					InstanceTypes:                       key.NodePoolInstanceTypes(p),
+					MaxPrice:                            p.Spot.MaxPrice,
+					OnDemandBaseCapacity:                p.Spot.OnDemandBaseCapacity,
+					OnDemandPercentageAboveBaseCapacity: p.Spot.OnDemandPercentageAboveBaseCapacity,
+				},
+			}
+			workerPools = append(workerPools, w)
+		}
+
+		for i, w := range workerPools {
+			workerPools[i].LaunchTemplateHash = launchTemplateHash(w.ImageID, w.InstanceType, w.DockerVolumeSizeGB, r.monitoring, key.CloudConfigVersion, key.VersionBundleVersion(customObject))
+		}
+
+		// The AMIs of the first master and the first node pool are kept as the AMIs
+		// of the guest cluster for stacks of guest clusters created before AMIs
+		// could be configured per master and node pool.
+		var masterImageID string
+		if len(masters) > 0 {
+			masterImageID = masters[0].ImageID
+		}
+		var workerImageID string
+		if len(workerPools) > 0 {
+			workerImageID = workerPools[0].ImageID
+		}
+
 		mainStack = StackState{
 			Name: key.MainGuestStackName(customObject),
 
-			DockerVolumeResourceName:   key.DockerVolumeResourceName(customObject),
-			MasterImageID:              imageID,
-			MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
+			APIPrivate: key.IsAPIPrivate(customObject),
+
+			PrivateHostedZone: r.route53Enabled && key.PrivateHostedZoneEnabled(customObject),
+
+			LoadBalancerTypes: []string{key.LoadBalancerType(customObject)},
+			LoadBalancerHash:  loadBalancerHash(customObject),
+
+			SecurityGroupsHash: securityGroupsHash(customObject),
+			VPCConnectionsHash: vpcConnectionsHash(customObject),
+
+			TransitGateway: r.transitGateway.ID != "",
+
+			DefaultImageID:     defaultImageID,
+			ImageCatalogSource: imageCatalogSource,
+
+			VPCEndpoints:           key.VPCEndpointsEnabled(customObject),
+			VPCFlowLogsTrafficType: key.VPCFlowLogsTrafficType(customObject),
+
+			DockerVolumeResourceName:   dockerVolumeResourceName,
+			MasterImageID:              masterImageID,
+			MasterInstanceResourceName: masterInstanceResourceName,
 			MasterInstanceType:         masterInstanceType,
 			MasterCloudConfigVersion:   key.CloudConfigVersion,
 			MasterInstanceMonitoring:   r.monitoring,
+			Masters:                    masters,
 
 			WorkerCount:              strconv.Itoa(key.WorkerCount(customObject)),
 			WorkerDockerVolumeSizeGB: key.WorkerDockerVolumeSizeGB(customObject),
-			WorkerImageID:            imageID,
+			WorkerImageID:            workerImageID,
 			WorkerInstanceMonitoring: r.monitoring,
 			WorkerInstanceType:       workerInstanceType,
 			WorkerCloudConfigVersion: key.CloudConfigVersion,
+			WorkerPools:              workerPools,
 
 			VersionBundleVersion: key.VersionBundleVersion(customObject),
 		}
@@ -61,3 +160,131 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 
 	return mainStack, nil
 }
+
+// defaultImageID returns the AMI of the AMI catalog for masters and node pools
+// not configuring their own AMI. Catalogs like the EC2 catalog resolve the
+// latest AMI, which changes whenever a new AMI is published. Since any new AMI
+// replaces all masters and workers, the AMI the guest cluster main stack
+// already uses is kept as long as neither the version bundle version nor the
+// catalog configuration change.
+func (r *Resource) defaultImageID(ctx context.Context, customObject v1alpha1.AWSConfig) (string, error) {
+	source := r.imageCatalog.Source()
+
+	if source != "" {
+		sc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		stackOutputs, _, err := sc.CloudFormation.DescribeOutputsAndStatus(key.MainGuestStackName(customObject))
+		if cloudformationservice.IsStackNotFound(err) || cloudformationservice.IsOutputsNotAccessible(err) {
+			// Fall through to resolve the AMI for new guest clusters.
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		} else {
+			imageID, err := pinnedImageID(&sc.CloudFormation, stackOutputs, source, key.VersionBundleVersion(customObject))
+			if err != nil {
+				return "", microerror.Mask(err)
+			}
+
+			if imageID != "" {
+				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("keeping the AMI '%s' of the guest cluster main stack", imageID))
+				return imageID, nil
+			}
+		}
+	}
+
+	imageID, err := r.imageCatalog.ImageID(ctx, customObject)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return imageID, nil
+}
+
+// pinnedImageID returns the AMI kept in the given stack outputs in case it was
+// resolved from the given catalog source for the given version bundle version.
+// The returned AMI is empty otherwise, which is also the case for stacks
+// created before AMIs were kept.
+func pinnedImageID(cf *cloudformationservice.CloudFormation, stackOutputs []*cloudformation.Output, source, versionBundleVersion string) (string, error) {
+	outputs := map[string]string{}
+	for _, k := range []string{key.DefaultImageIDKey, key.ImageCatalogSourceKey, key.VersionBundleVersionKey} {
+		v, err := cf.GetOutputValue(stackOutputs, k)
+		if cloudformationservice.IsOutputNotFound(err) {
+			return "", nil
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		outputs[k] = v
+	}
+
+	if outputs[key.ImageCatalogSourceKey] != source {
+		return "", nil
+	}
+	if outputs[key.VersionBundleVersionKey] != versionBundleVersion {
+		return "", nil
+	}
+
+	return outputs[key.DefaultImageIDKey], nil
+}
+
+// launchTemplateHash computes a short hash of everything the data of the launch
+// template of a node pool is rendered from.
+func launchTemplateHash(imageID, instanceType string, dockerVolumeSizeGB int, monitoring bool, cloudConfigVersion, versionBundleVersion string) string {
+	s := fmt.Sprintf("%s/%s/%d/%t/%s/%s", imageID, instanceType, dockerVolumeSizeGB, monitoring, cloudConfigVersion, versionBundleVersion)
+	h := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(h[:])[:launchTemplateHashLength]
+}
+
+// loadBalancerHash computes a short hash of the settings of the classic ELBs
+// configured in the custom object.
+func loadBalancerHash(customObject v1alpha1.AWSConfig) string {
+	healthCheck := key.ELBHealthCheck(customObject)
+
+	s := fmt.Sprintf(
+		"%d/%d/%d/%d/%d/%d/%t/%t/%d/%t/%d",
+		key.APIELBIdleTimeoutSeconds(customObject),
+		key.IngressELBIdleTimeoutSeconds(customObject),
+		healthCheck.HealthyThreshold,
+		healthCheck.IntervalSeconds,
+		healthCheck.TimeoutSeconds,
+		healthCheck.UnhealthyThreshold,
+		key.ELBCrossZone(customObject),
+		key.ELBConnectionDrainingEnabled(customObject),
+		key.ELBConnectionDrainingTimeoutSeconds(customObject),
+		key.ELBAccessLogsEnabled(customObject),
+		key.ELBAccessLogsEmitIntervalMinutes(customObject),
+	)
+	h := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(h[:])[:loadBalancerHashLength]
+}
+
+// securityGroupsHash computes a short hash of the API whitelist and the
+// additional security group rules configured in the custom object.
+func securityGroupsHash(customObject v1alpha1.AWSConfig) string {
+	s := fmt.Sprintf(
+		"%v/%+v/%+v/%+v",
+		key.APIWhitelist(customObject),
+		key.IngressSecurityGroupRules(customObject),
+		key.MasterSecurityGroupRules(customObject),
+		key.WorkerSecurityGroupRules(customObject),
+	)
+	h := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(h[:])[:securityGroupsHashLength]
+}
+
+func vpcConnectionsHash(customObject v1alpha1.AWSConfig) string {
+	s := fmt.Sprintf(
+		"%+v/%s/%v",
+		key.VPCPeerings(customObject),
+		key.VPNGatewayID(customObject),
+		key.VPNGatewayCIDRs(customObject),
+	)
+	h := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(h[:])[:vpcConnectionsHashLength]
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v18/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
 func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
@@ -39,6 +45,8 @@ func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
 	{
 		c := Config{}
 
+		c.EventRecorder = &record.FakeRecorder{}
+		c.ImageCatalog = ami.NewBuiltin()
 		c.HostClients = &adapter.Clients{}
 		c.Logger = microloggertest.New()
 		c.EncrypterBackend = "kms"
@@ -69,3 +77,56 @@ func Test_Resource_Cloudformation_GetDesiredState(t *testing.T) {
 		})
 	}
 }
+
+func Test_Resource_Cloudformation_pinnedImageID(t *testing.T) {
+	t.Parallel()
+	newOutputs := func(imageID, source, versionBundleVersion string) []*cloudformation.Output {
+		return []*cloudformation.Output{
+			{OutputKey: aws.String(key.DefaultImageIDKey), OutputValue: aws.String(imageID)},
+			{OutputKey: aws.String(key.ImageCatalogSourceKey), OutputValue: aws.String(source)},
+			{OutputKey: aws.String(key.VersionBundleVersionKey), OutputValue: aws.String(versionBundleVersion)},
+		}
+	}
+
+	testCases := []struct {
+		description     string
+		stackOutputs    []*cloudformation.Output
+		expectedImageID string
+	}{
+		{
+			description:     "AMI of the stack is kept",
+			stackOutputs:    newOutputs("ami-32042fd9", "ec2/595879546273/CoreOS-stable-*-hvm", "1.0.0"),
+			expectedImageID: "ami-32042fd9",
+		},
+		{
+			description:     "AMI is resolved when the version bundle version changes",
+			stackOutputs:    newOutputs("ami-32042fd9", "ec2/595879546273/CoreOS-stable-*-hvm", "0.9.0"),
+			expectedImageID: "",
+		},
+		{
+			description:     "AMI is resolved when the catalog configuration changes",
+			stackOutputs:    newOutputs("ami-32042fd9", "ec2/595879546273/CoreOS-beta-*-hvm", "1.0.0"),
+			expectedImageID: "",
+		},
+		{
+			description: "AMI is resolved for stacks created before AMIs were kept",
+			stackOutputs: []*cloudformation.Output{
+				{OutputKey: aws.String(key.VersionBundleVersionKey), OutputValue: aws.String("1.0.0")},
+			},
+			expectedImageID: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := pinnedImageID(&cloudformationservice.CloudFormation{}, tc.stackOutputs, "ec2/595879546273/CoreOS-stable-*-hvm", "1.0.0")
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if imageID != tc.expectedImageID {
+				t.Fatalf("expected image ID %q got %q", tc.expectedImageID, imageID)
+			}
+		})
+	}
+}
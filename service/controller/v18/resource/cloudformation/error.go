@@ -30,6 +30,22 @@ func IsAlreadyExists(err error) bool {
 	return false
 }
 
+// IsChangeSetNotFound asserts change set not found errors from the upstream's
+// API.
+func IsChangeSetNotFound(err error) bool {
+	c := microerror.Cause(err)
+
+	if c == nil {
+		return false
+	}
+
+	if strings.Contains(c.Error(), cloudformation.ErrCodeChangeSetNotFoundException) {
+		return true
+	}
+
+	return false
+}
+
 var deletionMustBeRetriedError = &microerror.Error{
 	Kind: "deletionMustBeRetriedError",
 }
@@ -31,6 +31,44 @@ func (r *Resource) getMainGuestTemplateBody(ctx context.Context, customObject v1
 		STS:            sc.AWSClient.STS,
 	}
 
+	var masters []adapter.StackStateMaster
+	for _, m := range stackState.Masters {
+		master := adapter.StackStateMaster{
+			CloudConfigVersion:       m.CloudConfigVersion,
+			DockerVolumeResourceName: m.DockerVolumeResourceName,
+			ImageID:                  m.ImageID,
+			InstanceResourceName:     m.InstanceResourceName,
+			InstanceType:             m.InstanceType,
+			VersionBundleVersion:     m.VersionBundleVersion,
+		}
+		masters = append(masters, master)
+	}
+
+	var workerPools []adapter.StackStateWorkerPool
+	for _, p := range stackState.WorkerPools {
+		workerPool := adapter.StackStateWorkerPool{
+			Count:              p.Count,
+			DockerVolumeSizeGB: p.DockerVolumeSizeGB,
+			ImageID:            p.ImageID,
+			InstanceType:       p.InstanceType,
+			LaunchTemplateHash: p.LaunchTemplateHash,
+			Name:               p.Name,
+			Scaling: adapter.StackStateWorkerPoolScaling{
+				Enabled: p.Scaling.Enabled,
+				Max:     p.Scaling.Max,
+				Min:     p.Scaling.Min,
+			},
+			Spot: adapter.StackStateWorkerPoolSpot{
+				Enabled:                             p.Spot.Enabled,
+				InstanceTypes:                       p.Spot.InstanceTypes,
+				MaxPrice:                            p.Spot.MaxPrice,
+				OnDemandBaseCapacity:                p.Spot.OnDemandBaseCapacity,
+				OnDemandPercentageAboveBaseCapacity: p.Spot.OnDemandPercentageAboveBaseCapacity,
+			},
+		}
+		workerPools = append(workerPools, workerPool)
+	}
+
 	cfg := adapter.Config{
 		APIWhitelist: adapter.APIWhitelist{
 			Enabled:    r.apiWhiteList.Enabled,
@@ -44,15 +82,29 @@ func (r *Resource) getMainGuestTemplateBody(ctx context.Context, customObject v1
 		HostAccountID:     hostAccountID,
 		PublicRouteTables: r.publicRouteTables,
 		Route53Enabled:    r.route53Enabled,
+		TransitGateway:    r.transitGateway,
 		StackState: adapter.StackState{
 			Name: stackState.Name,
 
+			LoadBalancerTypes:      stackState.LoadBalancerTypes,
+			LoadBalancerHash:       stackState.LoadBalancerHash,
+			LoadBalancerSwitchTime: stackState.LoadBalancerSwitchTime,
+
+			SecurityGroupsHash: stackState.SecurityGroupsHash,
+			VPCConnectionsHash: stackState.VPCConnectionsHash,
+
+			TransitGateway: stackState.TransitGateway,
+
+			DefaultImageID:     stackState.DefaultImageID,
+			ImageCatalogSource: stackState.ImageCatalogSource,
+
 			DockerVolumeResourceName:   stackState.DockerVolumeResourceName,
 			MasterImageID:              stackState.MasterImageID,
 			MasterInstanceResourceName: stackState.MasterInstanceResourceName,
 			MasterInstanceType:         stackState.MasterInstanceType,
 			MasterCloudConfigVersion:   stackState.MasterCloudConfigVersion,
 			MasterInstanceMonitoring:   stackState.MasterInstanceMonitoring,
+			Masters:                    masters,
 
 			WorkerCount:              stackState.WorkerCount,
 			WorkerDockerVolumeSizeGB: stackState.WorkerDockerVolumeSizeGB,
@@ -60,6 +112,7 @@ func (r *Resource) getMainGuestTemplateBody(ctx context.Context, customObject v1
 			WorkerInstanceMonitoring: stackState.WorkerInstanceMonitoring,
 			WorkerInstanceType:       stackState.WorkerInstanceType,
 			WorkerCloudConfigVersion: stackState.WorkerCloudConfigVersion,
+			WorkerPools:              workerPools,
 
 			VersionBundleVersion: stackState.VersionBundleVersion,
 		},
@@ -97,10 +150,16 @@ func (r *Resource) getMainHostPreTemplateBody(ctx context.Context, customObject
 	if err != nil {
 		return "", microerror.Mask(err)
 	}
+	hostAccountID, err := adapter.AccountID(*r.hostClients)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
 	cfg := adapter.Config{
 		CustomObject:   customObject,
 		GuestAccountID: guestAccountID,
+		HostAccountID:  hostAccountID,
 		Route53Enabled: r.route53Enabled,
+		TransitGateway: r.transitGateway,
 	}
 	adp, err := adapter.NewHostPre(cfg)
 	if err != nil {
@@ -134,11 +193,14 @@ func (r *Resource) getMainHostPostTemplateBody(ctx context.Context, customObject
 		CustomObject:      customObject,
 		Clients:           adapterClients,
 		HostClients:       *r.hostClients,
+		DNSProvider:       r.dnsProvider,
 		EncrypterBackend:  r.encrypterBackend,
 		PublicRouteTables: r.publicRouteTables,
 		Route53Enabled:    r.route53Enabled,
+		TransitGateway:    r.transitGateway,
 		StackState: adapter.StackState{
-			HostedZoneNameServers: guestMainStackState.HostedZoneNameServers,
+			HostedZoneNameServers:      guestMainStackState.HostedZoneNameServers,
+			TransitGatewayAttachmentID: guestMainStackState.TransitGatewayAttachmentID,
 		},
 	}
 	adp, err := adapter.NewHostPost(cfg)
@@ -6,12 +6,15 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
@@ -19,14 +22,74 @@ import (
 func testConfig() Config {
 	c := Config{}
 
+	c.EventRecorder = &record.FakeRecorder{}
+	c.ImageCatalog = ami.NewBuiltin()
 	c.HostClients = &adapter.Clients{}
 	c.Logger = microloggertest.New()
+	c.DNSProvider = "route53"
 	c.EncrypterBackend = "kms"
 	c.InstallationName = "myinstallation"
 
 	return c
 }
 
+// newTestCustomObject returns the custom object of a guest cluster with a
+// single master and worker. Region, availability zones and VPC CIDRs default to
+// a guest cluster in two availability zones of eu-central-1 in case the given
+// AWS spec does not define them.
+func newTestCustomObject(aws v1alpha1.AWSConfigSpecAWS) v1alpha1.AWSConfig {
+	if aws.Region == "" {
+		aws.Region = "eu-central-1"
+	}
+	if aws.AZ == "" && len(aws.AvailabilityZones) == 0 {
+		aws.AvailabilityZones = []string{
+			aws.Region + "a",
+			aws.Region + "b",
+		}
+	}
+	aws.Masters = []v1alpha1.AWSConfigSpecAWSNode{
+		{
+			ImageID:      "ami-1234-master",
+			InstanceType: "m3.large",
+		},
+	}
+	aws.Workers = []v1alpha1.AWSConfigSpecAWSNode{
+		{
+			ImageID:      "ami-1234-worker",
+			InstanceType: "m3.large",
+		},
+	}
+	if aws.VPC.CIDR == "" {
+		aws.VPC.CIDR = "10.1.0.0/16"
+		aws.VPC.PublicSubnetCIDR = "10.1.0.0/18"
+		aws.VPC.PrivateSubnetCIDR = "10.1.128.0/18"
+	}
+
+	return v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID:      "test-cluster",
+				Version: "myversion",
+				Kubernetes: v1alpha1.ClusterKubernetes{
+					API: v1alpha1.ClusterKubernetesAPI{
+						Domain:     "api.domain",
+						SecurePort: 443,
+					},
+					IngressController: v1alpha1.ClusterKubernetesIngressController{
+						Domain:       "ingress.domain",
+						InsecurePort: 30010,
+						SecurePort:   30011,
+					},
+				},
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.domain",
+				},
+			},
+			AWS: aws,
+		},
+	}
+}
+
 func TestMainGuestTemplateGetEmptyBody(t *testing.T) {
 	t.Parallel()
 	customObject := v1alpha1.AWSConfig{}
@@ -113,6 +176,9 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 					PrivateSubnetCIDR: "10.1.2.0/25",
 				},
 			},
+			VersionBundle: v1alpha1.AWSConfigSpecVersionBundle{
+				Version: "1.0.0",
+			},
 		},
 	}
 
@@ -129,7 +195,7 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
 		MasterInstanceType:         key.MasterInstanceType(customObject),
 		MasterCloudConfigVersion:   key.CloudConfigVersion,
-		MasterInstanceMonitoring:   false,
+		MasterInstanceMonitoring:   true,
 
 		WorkerCount:              strconv.Itoa(key.WorkerCount(customObject)),
 		WorkerDockerVolumeSizeGB: key.WorkerDockerVolumeSizeGB(customObject),
@@ -174,16 +240,38 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		t.Fatal("stack header not found")
 	}
 
-	if !strings.Contains(body, "  workerLaunchConfiguration:") {
-		t.Fatal("launch configuration header not found")
+	if !strings.Contains(body, "  workerLaunchTemplate:") {
+		t.Fatal("launch template header not found")
+	}
+
+	if !strings.Contains(body, "Version: !GetAtt workerLaunchTemplate.LatestVersionNumber") {
+		fmt.Println(body)
+		t.Fatal("asg launch template version not found")
 	}
 
 	if !strings.Contains(body, "  workerAutoScalingGroup:") {
 		t.Fatal("asg header not found")
 	}
 
+	if !strings.Contains(body, "  NodeDrainerLifecycleHook:") {
+		t.Fatal("lifecycle hook header not found")
+	}
+
+	if !strings.Contains(body, "DesiredCapacity: 1") {
+		t.Fatal("asg desired capacity not found")
+	}
+
+	if strings.Contains(body, "k8s.io/cluster-autoscaler") {
+		t.Fatal("cluster autoscaler tags found for node pool not scaled by cluster-autoscaler")
+	}
+
+	if !strings.Contains(body, "Value: !Join [ ',', [ !Ref workerAutoScalingGroup ] ]") {
+		fmt.Println(body)
+		t.Fatal("worker ASG names output not found")
+	}
+
 	if !strings.Contains(body, "InstanceType: m3.large") {
-		t.Fatal("launch configuration element not found")
+		t.Fatal("launch template element not found")
 	}
 
 	if !strings.Contains(body, "AvailabilityZones: [eu-central-1a]") {
@@ -228,7 +316,7 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		fmt.Println(body)
 		t.Fatal("worker CloudConfig version output element not found")
 	}
-	if !strings.Contains(body, key.WorkerInstanceMonitoring+": true") {
+	if !strings.Contains(body, key.WorkerInstanceMonitoring+":\n          Enabled: true") {
 		fmt.Println(body)
 		t.Fatal("WorkerInstanceMonitoring output element not found")
 	}
@@ -322,6 +410,22 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		fmt.Println(body)
 		t.Fatal("CidrBlock element not found")
 	}
+	if !strings.Contains(body, "  VPCFlowLog:") {
+		fmt.Println(body)
+		t.Fatal("VPCFlowLog element not found")
+	}
+	if !strings.Contains(body, "LogDestination: arn:aws:s3:::test-cluster-g8s-access-logs/vpc-flow-logs\n") {
+		fmt.Println(body)
+		t.Fatal("VPC flow logs destination not found")
+	}
+	if !strings.Contains(body, "TrafficType: ALL\n") {
+		fmt.Println(body)
+		t.Fatal("VPC flow logs traffic type not found")
+	}
+	if !strings.Contains(body, "VPCFlowLogsTrafficType:\n    Value: ALL\n") {
+		fmt.Println(body)
+		t.Fatal("VPCFlowLogsTrafficType output not found")
+	}
 
 	// arn depends on region
 	if !strings.Contains(body, `Resource: "arn:aws:s3:::`) {
@@ -334,6 +438,139 @@ func TestMainGuestTemplateExistingFields(t *testing.T) {
 		fmt.Println(body)
 		t.Fatal("Fixed image ID not found")
 	}
+
+	// CloudFormation rejects outputs without values
+	if strings.Contains(body, "Value: \n") {
+		fmt.Println(body)
+		t.Fatal("output without value found")
+	}
+}
+
+func TestMainGuestTemplateWorkerPoolOutputs(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description         string
+		workerPools         []StackStateWorkerPool
+		expectedContained   []string
+		expectedUncontained []string
+	}{
+		{
+			description: "case 0, on-demand node pool without spot instance types",
+			workerPools: []StackStateWorkerPool{
+				{
+					Count:        1,
+					InstanceType: "m3.large",
+					Name:         key.DefaultNodePoolName,
+				},
+			},
+			expectedContained: []string{
+				"WorkerPoolNames:\n    Value: default\n",
+			},
+			expectedUncontained: []string{
+				"Value: \n",
+				"WorkerPoolSpotInstanceTypes:",
+				"WorkerPoolSpotMaxPrices:",
+			},
+		},
+		{
+			description: "case 1, spot node pool with spot instance types",
+			workerPools: []StackStateWorkerPool{
+				{
+					Count:        1,
+					InstanceType: "m3.large",
+					Name:         key.DefaultNodePoolName,
+				},
+				{
+					Count:        2,
+					InstanceType: "m4.xlarge",
+					Name:         "spot",
+					Spot: StackStateWorkerPoolSpot{
+						Enabled:       true,
+						InstanceTypes: []string{"m4.xlarge", "m5.xlarge"},
+						MaxPrice:      "0.2",
+					},
+				},
+			},
+			expectedContained: []string{
+				"WorkerPoolSpotInstanceTypes:\n    Value: \",m4.xlarge m5.xlarge\"\n",
+				"WorkerPoolSpotMaxPrices:\n    Value: \",0.2\"\n",
+			},
+			expectedUncontained: []string{
+				"Value: \n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := newTestCustomObject(v1alpha1.AWSConfigSpecAWS{})
+			customObject.Spec.VersionBundle.Version = "1.0.0"
+
+			imageID, err := key.ImageID(customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			stackState := StackState{
+				Name: key.MainGuestStackName(customObject),
+
+				DockerVolumeResourceName:   key.DockerVolumeResourceName(customObject),
+				MasterImageID:              imageID,
+				MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
+				MasterInstanceType:         key.MasterInstanceType(customObject),
+				MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+				WorkerCount:              strconv.Itoa(key.WorkerCount(customObject)),
+				WorkerImageID:            imageID,
+				WorkerInstanceType:       key.WorkerInstanceType(customObject),
+				WorkerCloudConfigVersion: key.CloudConfigVersion,
+				WorkerPools:              tc.workerPools,
+
+				VersionBundleVersion: key.VersionBundleVersion(customObject),
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, e := range tc.expectedContained {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q not found", e)
+				}
+			}
+			for _, e := range tc.expectedUncontained {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q found", e)
+				}
+			}
+		})
+	}
 }
 
 func TestMainHostPreTemplateExistingFields(t *testing.T) {
@@ -459,6 +696,72 @@ func TestMainHostPostTemplateExistingFields(t *testing.T) {
 	}
 }
 
+func TestMainHostPostTemplatePrivateAPI(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				API: v1alpha1.AWSConfigSpecAWSAPI{
+					Private: true,
+				},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.0.0/16",
+					PrivateSubnetCIDR: "10.1.2.0/25",
+					RouteTableNames: []string{
+						"route_table_1",
+					},
+					PeerID: "mypeerid",
+				},
+			},
+		},
+	}
+
+	cfg := testConfig()
+	cfg.PublicRouteTables = "public_route_table_1,"
+	ec2Mock := &adapter.EC2ClientMock{}
+	ec2Mock.SetMatchingRouteTables(1)
+	cfg.HostClients = &adapter.Clients{
+		EC2: ec2Mock,
+		IAM: &adapter.IAMClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+	newResource, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	awsClients := aws.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+
+	ctx := context.TODO()
+	ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+	body, err := newResource.getMainHostPostTemplateBody(ctx, customObject, StackState{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The host cluster control plane reaches the internal API ELB of the guest
+	// cluster from its public subnets.
+	if !strings.Contains(body, "  PublicRoute0:") {
+		fmt.Println(body)
+		t.Fatal("public route header not found")
+	}
+	if !strings.Contains(body, "DestinationCidrBlock: 10.1.0.0/16") {
+		fmt.Println(body)
+		t.Fatal("public route destination not found")
+	}
+	if strings.Contains(body, "  PublicRoute1:") {
+		fmt.Println(body)
+		t.Fatal("unexpected public route for empty route table name found")
+	}
+}
+
 func TestMainGuestTemplateRoute53Disabled(t *testing.T) {
 	t.Parallel()
 	// customObject with example fields for both asg and launch config
@@ -703,3 +1006,1262 @@ func TestMainGuestTemplateChinaRegion(t *testing.T) {
 		t.Fatal("ARN region dependent element not found")
 	}
 }
+
+func TestMainGuestTemplateExistingVPC(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID:      "test-cluster",
+				Version: "myversion",
+				Kubernetes: v1alpha1.ClusterKubernetes{
+					API: v1alpha1.ClusterKubernetesAPI{
+						Domain:     "api.domain",
+						SecurePort: 443,
+					},
+					IngressController: v1alpha1.ClusterKubernetesIngressController{
+						Domain:       "ingress.domain",
+						InsecurePort: 30010,
+						SecurePort:   30011,
+					},
+				},
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.domain",
+				},
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+				AZ:     "eu-central-1a",
+				AvailabilityZones: []string{
+					"eu-central-1a",
+					"eu-central-1b",
+				},
+				Masters: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-master",
+						InstanceType: "m3.large",
+					},
+				},
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-worker",
+						InstanceType: "m3.large",
+					},
+				},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					ID:               "vpc-1234",
+					PrivateSubnetIDs: []string{"subnet-private-a", "subnet-private-b"},
+					PublicSubnetIDs:  []string{"subnet-public-a", "subnet-public-b"},
+				},
+			},
+		},
+	}
+
+	imageID, err := key.ImageID(customObject)
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	stackState := StackState{
+		Name: key.MainGuestStackName(customObject),
+
+		DockerVolumeResourceName:   key.DockerVolumeResourceName(customObject),
+		MasterImageID:              imageID,
+		MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
+		MasterInstanceType:         key.MasterInstanceType(customObject),
+		MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+		WorkerCount:              strconv.Itoa(key.WorkerCount(customObject)),
+		WorkerImageID:            imageID,
+		WorkerInstanceType:       key.WorkerInstanceType(customObject),
+		WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+		VersionBundleVersion: key.VersionBundleVersion(customObject),
+	}
+
+	cfg := testConfig()
+	cfg.HostClients = &adapter.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		IAM: &adapter.IAMClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+	cfg.APIWhitelist = adapter.APIWhitelist{
+		Enabled: true,
+	}
+	cfg.Route53Enabled = true
+	newResource, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Both private subnets share the same route table.
+	ec2Mock := &adapter.EC2ClientMock{}
+	ec2Mock.SetMatchingRouteTables(1)
+
+	awsClients := aws.Clients{
+		EC2: ec2Mock,
+		IAM: &adapter.IAMClientMock{},
+		KMS: &adapter.KMSClientMock{},
+		ELB: &adapter.ELBClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+
+	ctx := context.TODO()
+	ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+	body, err := newResource.getMainGuestTemplateBody(ctx, customObject, stackState)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := []string{
+		"  VPC:\n    Type: AWS::EC2::VPC::Id",
+		"Default: vpc-1234",
+		"  PrivateSubnet01:\n    Type: AWS::EC2::Subnet::Id",
+		"Default: subnet-private-b",
+		"  PublicSubnet:\n    Type: AWS::EC2::Subnet::Id",
+		"Default: subnet-public-a",
+		"  VPCPeeringConnection:",
+		"  VPCPeeringRoute:",
+		"RouteTableId: _0",
+		"  VPCFlowLog:\n    Type: AWS::EC2::FlowLog\n    Properties:\n      LogDestination: arn:aws:s3:::test-cluster-g8s-access-logs/vpc-flow-logs\n      LogDestinationType: s3\n      ResourceId: !Ref VPC\n",
+		"  IngressLoadBalancer:",
+	}
+	for _, e := range expected {
+		if !strings.Contains(body, e) {
+			fmt.Println(body)
+			t.Fatalf("%q not found", e)
+		}
+	}
+
+	unexpected := []string{
+		"Type: AWS::EC2::VPC\n",
+		"Type: AWS::EC2::Subnet\n",
+		"Type: AWS::EC2::RouteTable\n",
+		"Type: AWS::EC2::InternetGateway",
+		"Type: AWS::EC2::NatGateway",
+		"VPCGatewayAttachment",
+		"VPCPeeringRoute01:",
+		"!Ref NATEIP",
+	}
+	for _, u := range unexpected {
+		if strings.Contains(body, u) {
+			fmt.Println(body)
+			t.Fatalf("%q found", u)
+		}
+	}
+}
+
+func TestMainGuestTemplatePrivateAPI(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID:      "test-cluster",
+				Version: "myversion",
+				Kubernetes: v1alpha1.ClusterKubernetes{
+					API: v1alpha1.ClusterKubernetesAPI{
+						Domain:     "api.domain",
+						SecurePort: 443,
+					},
+					IngressController: v1alpha1.ClusterKubernetesIngressController{
+						Domain:       "ingress.domain",
+						InsecurePort: 30010,
+						SecurePort:   30011,
+					},
+				},
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.domain",
+				},
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				API: v1alpha1.AWSConfigSpecAWSAPI{
+					Private: true,
+				},
+				Region: "eu-central-1",
+				AZ:     "eu-central-1a",
+				Masters: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-master",
+						InstanceType: "m3.large",
+					},
+				},
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-worker",
+						InstanceType: "m3.large",
+					},
+				},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.1.0/24",
+					PublicSubnetCIDR:  "10.1.1.0/25",
+					PrivateSubnetCIDR: "10.1.2.0/25",
+				},
+			},
+		},
+	}
+
+	imageID, err := key.ImageID(customObject)
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	stackState := StackState{
+		Name: key.MainGuestStackName(customObject),
+
+		DockerVolumeResourceName:   key.DockerVolumeResourceName(customObject),
+		MasterImageID:              imageID,
+		MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
+		MasterInstanceType:         key.MasterInstanceType(customObject),
+		MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+		WorkerCount:              strconv.Itoa(key.WorkerCount(customObject)),
+		WorkerImageID:            imageID,
+		WorkerInstanceType:       key.WorkerInstanceType(customObject),
+		WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+		VersionBundleVersion: key.VersionBundleVersion(customObject),
+	}
+
+	cfg := testConfig()
+	cfg.HostClients = &adapter.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		IAM: &adapter.IAMClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+	cfg.Route53Enabled = true
+	newResource, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	awsClients := aws.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		IAM: &adapter.IAMClientMock{},
+		KMS: &adapter.KMSClientMock{},
+		ELB: &adapter.ELBClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+
+	ctx := context.TODO()
+	ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+	body, err := newResource.getMainGuestTemplateBody(ctx, customObject, stackState)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := []string{
+		"Scheme: internal\n      SecurityGroups:\n        - !Ref MasterSecurityGroup\n      Subnets:\n        - !Ref PrivateSubnet\n",
+		"Scheme: internet-facing\n      SecurityGroups:\n        - !Ref IngressSecurityGroup\n      Subnets:\n        - !Ref PublicSubnet\n",
+		"DNSName: !GetAtt ApiLoadBalancer.DNSName",
+	}
+	for _, e := range expected {
+		if !strings.Contains(body, e) {
+			fmt.Println(body)
+			t.Fatalf("%q not found", e)
+		}
+	}
+
+	if strings.Contains(body, "Allow all traffic to the master instance.") {
+		fmt.Println(body)
+		t.Fatal("private API must not be open to the internet")
+	}
+}
+
+func TestMainGuestTemplateLoadBalancerTypes(t *testing.T) {
+	t.Parallel()
+	classicELB := "Type: AWS::ElasticLoadBalancing::LoadBalancer\n"
+	networkLB := "Type: AWS::ElasticLoadBalancingV2::LoadBalancer\n"
+
+	testCases := []struct {
+		description            string
+		customObject           v1alpha1.AWSConfig
+		loadBalancerTypes      []string
+		loadBalancerSwitchTime time.Time
+		expectedContained      []string
+		expectedUncontained    []string
+	}{
+		{
+			description:       "case 0, classic ELBs by default",
+			customObject:      newTestCustomObject(v1alpha1.AWSConfigSpecAWS{}),
+			loadBalancerTypes: nil,
+			expectedContained: []string{
+				classicELB,
+				"LoadBalancerNames:\n        - !Ref IngressLoadBalancer\n",
+				"DNSName: !GetAtt ApiLoadBalancer.DNSName\n        HostedZoneId: !GetAtt ApiLoadBalancer.CanonicalHostedZoneNameID\n",
+				"LoadBalancerTypes:\n    Value: classic\n",
+			},
+			expectedUncontained: []string{
+				networkLB,
+				"TargetGroupARNs:",
+			},
+		},
+		{
+			description:       "case 1, NLBs only",
+			customObject:      newTestCustomObject(v1alpha1.AWSConfigSpecAWS{LoadBalancerType: "network"}),
+			loadBalancerTypes: nil,
+			expectedContained: []string{
+				networkLB,
+				"  ApiTargetGroup:\n",
+				"      - Id: !Ref MasterInstance",
+				"  IngressListener01:\n",
+				"LoadBalancerArn: !Ref IngressNetworkLoadBalancer\n      Port: 80\n",
+				"Key: proxy_protocol_v2.enabled\n",
+				"TargetGroupARNs:\n        - !Ref IngressTargetGroup\n        - !Ref IngressTargetGroup01\n",
+				"DNSName: !GetAtt ApiNetworkLoadBalancer.DNSName\n        HostedZoneId: !GetAtt ApiNetworkLoadBalancer.CanonicalHostedZoneID\n",
+				"DNSName: !GetAtt IngressNetworkLoadBalancer.DNSName\n        HostedZoneId: !GetAtt IngressNetworkLoadBalancer.CanonicalHostedZoneID\n",
+				"LoadBalancerTypes:\n    Value: network\n",
+				"FromPort: 30011\n        ToPort: 30011\n        \n        CidrIp: 0.0.0.0/0\n",
+			},
+			expectedUncontained: []string{
+				classicELB,
+				"LoadBalancerNames:",
+			},
+		},
+		{
+			description:            "case 2, migration from classic ELBs to NLBs",
+			customObject:           newTestCustomObject(v1alpha1.AWSConfigSpecAWS{LoadBalancerType: "network"}),
+			loadBalancerTypes:      []string{"network", "classic"},
+			loadBalancerSwitchTime: time.Date(2019, 1, 10, 12, 0, 0, 0, time.UTC),
+			expectedContained: []string{
+				classicELB,
+				networkLB,
+				"LoadBalancerNames:\n        - !Ref IngressLoadBalancer\n",
+				"TargetGroupARNs:\n        - !Ref IngressTargetGroup\n",
+				"DNSName: !GetAtt ApiNetworkLoadBalancer.DNSName\n",
+				"LoadBalancerTypes:\n    Value: network,classic\n",
+				"LoadBalancerSwitchTime:\n    Value: \"2019-01-10T12:00:00Z\"\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := key.ImageID(tc.customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			stackState := StackState{
+				Name: key.MainGuestStackName(tc.customObject),
+
+				LoadBalancerTypes:      tc.loadBalancerTypes,
+				LoadBalancerSwitchTime: tc.loadBalancerSwitchTime,
+
+				DockerVolumeResourceName:   key.DockerVolumeResourceName(tc.customObject),
+				MasterImageID:              imageID,
+				MasterInstanceResourceName: key.MasterInstanceResourceName(tc.customObject),
+				MasterInstanceType:         key.MasterInstanceType(tc.customObject),
+				MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+				WorkerCount:              strconv.Itoa(key.WorkerCount(tc.customObject)),
+				WorkerImageID:            imageID,
+				WorkerInstanceType:       key.WorkerInstanceType(tc.customObject),
+				WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+				VersionBundleVersion: key.VersionBundleVersion(tc.customObject),
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, tc.customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, e := range tc.expectedContained {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q not found", e)
+				}
+			}
+			for _, e := range tc.expectedUncontained {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q found", e)
+				}
+			}
+		})
+	}
+}
+
+func TestMainGuestTemplateLoadBalancerSettings(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description         string
+		customObject        v1alpha1.AWSConfig
+		expectedContained   []string
+		expectedUncontained []string
+	}{
+		{
+			description:  "case 0, default settings",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{}),
+			expectedContained: []string{
+				"ConnectionSettings:\n        IdleTimeout: 1200\n      CrossZone: false\n      HealthCheck:\n        HealthyThreshold: 2\n        Interval: 5\n",
+				"ConnectionSettings:\n        IdleTimeout: 60\n",
+				"Timeout: 3\n        UnhealthyThreshold: 2\n",
+			},
+			expectedUncontained: []string{
+				"AccessLoggingPolicy:",
+				"ConnectionDrainingPolicy:",
+			},
+		},
+		{
+			description: "case 1, custom settings",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{
+				API: v1alpha1.AWSConfigSpecAWSAPI{
+					ELB: v1alpha1.AWSConfigSpecAWSAPIELB{
+						IdleTimeoutSeconds: 600,
+					},
+				},
+				ELB: v1alpha1.AWSConfigSpecAWSELB{
+					AccessLogs: v1alpha1.AWSConfigSpecAWSELBAccessLogs{
+						Enabled:             true,
+						EmitIntervalMinutes: 5,
+					},
+					ConnectionDraining: v1alpha1.AWSConfigSpecAWSELBConnectionDraining{
+						Enabled: true,
+					},
+					CrossZone: true,
+					HealthCheck: v1alpha1.AWSConfigSpecAWSELBHealthCheck{
+						HealthyThreshold: 3,
+						IntervalSeconds:  10,
+					},
+				},
+				Ingress: v1alpha1.AWSConfigSpecAWSIngress{
+					ELB: v1alpha1.AWSConfigSpecAWSIngressELB{
+						IdleTimeoutSeconds: 3600,
+					},
+				},
+			}),
+			expectedContained: []string{
+				"AccessLoggingPolicy:\n        EmitInterval: 5\n        Enabled: true\n        S3BucketName: test-cluster-g8s-access-logs\n        S3BucketPrefix: test-cluster-api\n",
+				"S3BucketPrefix: test-cluster-ingress\n",
+				"ConnectionDrainingPolicy:\n        Enabled: true\n        Timeout: 300\n",
+				"ConnectionSettings:\n        IdleTimeout: 600\n      CrossZone: true\n      HealthCheck:\n        HealthyThreshold: 3\n        Interval: 10\n",
+				"ConnectionSettings:\n        IdleTimeout: 3600\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := key.ImageID(tc.customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			stackState := StackState{
+				Name: key.MainGuestStackName(tc.customObject),
+
+				LoadBalancerHash: loadBalancerHash(tc.customObject),
+
+				DockerVolumeResourceName:   key.DockerVolumeResourceName(tc.customObject),
+				MasterImageID:              imageID,
+				MasterInstanceResourceName: key.MasterInstanceResourceName(tc.customObject),
+				MasterInstanceType:         key.MasterInstanceType(tc.customObject),
+				MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+				WorkerCount:              strconv.Itoa(key.WorkerCount(tc.customObject)),
+				WorkerImageID:            imageID,
+				WorkerInstanceType:       key.WorkerInstanceType(tc.customObject),
+				WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+				VersionBundleVersion: key.VersionBundleVersion(tc.customObject),
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, tc.customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			expectedContained := append(tc.expectedContained, fmt.Sprintf("LoadBalancerHash:\n    Value: %s\n", loadBalancerHash(tc.customObject)))
+			for _, e := range expectedContained {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q not found", e)
+				}
+			}
+			for _, e := range tc.expectedUncontained {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q found", e)
+				}
+			}
+		})
+	}
+}
+
+func TestMainGuestTemplateTransitGateway(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID:      "test-cluster",
+				Version: "myversion",
+				Kubernetes: v1alpha1.ClusterKubernetes{
+					API: v1alpha1.ClusterKubernetesAPI{
+						Domain:     "api.domain",
+						SecurePort: 443,
+					},
+					IngressController: v1alpha1.ClusterKubernetesIngressController{
+						Domain:       "ingress.domain",
+						InsecurePort: 30010,
+						SecurePort:   30011,
+					},
+				},
+				Etcd: v1alpha1.ClusterEtcd{
+					Domain: "etcd.domain",
+				},
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+				AZ:     "eu-central-1a",
+				Masters: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-master",
+						InstanceType: "m3.large",
+					},
+				},
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{
+						ImageID:      "ami-1234-worker",
+						InstanceType: "m3.large",
+					},
+				},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.1.0/24",
+					PublicSubnetCIDR:  "10.1.1.0/25",
+					PrivateSubnetCIDR: "10.1.2.0/25",
+					RouteTableNames: []string{
+						"route_table_1",
+					},
+					PeerID: "mypeerid",
+				},
+			},
+		},
+	}
+
+	imageID, err := key.ImageID(customObject)
+	if err != nil {
+		t.Fatalf("expected %#v got %#v", nil, err)
+	}
+
+	stackState := StackState{
+		Name: key.MainGuestStackName(customObject),
+
+		DockerVolumeResourceName:   key.DockerVolumeResourceName(customObject),
+		MasterImageID:              imageID,
+		MasterInstanceResourceName: key.MasterInstanceResourceName(customObject),
+		MasterInstanceType:         key.MasterInstanceType(customObject),
+		MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+		WorkerCount:              strconv.Itoa(key.WorkerCount(customObject)),
+		WorkerImageID:            imageID,
+		WorkerInstanceType:       key.WorkerInstanceType(customObject),
+		WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+		VersionBundleVersion: key.VersionBundleVersion(customObject),
+
+		TransitGateway: true,
+	}
+
+	cfg := testConfig()
+	cfg.HostClients = &adapter.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		IAM: &adapter.IAMClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+	cfg.IPAMNetwork = "10.0.0.0/8"
+	cfg.Route53Enabled = true
+	cfg.TransitGateway = adapter.TransitGateway{
+		ID:           "tgw-1",
+		RouteTableID: "tgw-rtb-1",
+	}
+	newResource, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	awsClients := aws.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		IAM: &adapter.IAMClientMock{},
+		KMS: &adapter.KMSClientMock{},
+		ELB: &adapter.ELBClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+
+	ctx := context.TODO()
+	ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+	body, err := newResource.getMainGuestTemplateBody(ctx, customObject, stackState)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := []string{
+		"  TransitGatewayAttachment:\n    Type: AWS::EC2::TransitGatewayAttachment",
+		"TransitGatewayId: tgw-1",
+		"DependsOn: TransitGatewayAttachment",
+		"  TransitGatewayAttachmentID:",
+	}
+	for _, e := range expected {
+		if !strings.Contains(body, e) {
+			fmt.Println(body)
+			t.Fatalf("%q not found", e)
+		}
+	}
+
+	unexpected := []string{
+		"  VPCPeeringConnection:",
+		"VpcPeeringConnectionId:",
+	}
+	for _, u := range unexpected {
+		if strings.Contains(body, u) {
+			fmt.Println(body)
+			t.Fatalf("%q found", u)
+		}
+	}
+}
+
+func TestMainHostPostTemplateTransitGateway(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				Region: "eu-central-1",
+				AvailabilityZones: []string{
+					"eu-central-1a",
+				},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.0.0/16",
+					PrivateSubnetCIDR: "10.1.128.0/17",
+					RouteTableNames: []string{
+						"route_table_1",
+					},
+					PeerID: "mypeerid",
+				},
+			},
+		},
+	}
+
+	cfg := testConfig()
+	ec2Mock := &adapter.EC2ClientMock{}
+	ec2Mock.SetMatchingRouteTables(1)
+	cfg.HostClients = &adapter.Clients{
+		EC2: ec2Mock,
+		IAM: &adapter.IAMClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+	cfg.EncrypterBackend = "vault"
+	cfg.IPAMNetwork = "10.0.0.0/8"
+	cfg.PublicRouteTables = "public_route_table_1"
+	cfg.TransitGateway = adapter.TransitGateway{
+		ID:           "tgw-1",
+		RouteTableID: "tgw-rtb-1",
+	}
+	newResource, err := New(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	awsClients := aws.Clients{
+		EC2: &adapter.EC2ClientMock{},
+		STS: &adapter.STSClientMock{},
+	}
+
+	stackState := StackState{
+		TransitGatewayAttachmentID: "tgw-attach-1",
+	}
+
+	ctx := context.TODO()
+	ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+	body, err := newResource.getMainHostPostTemplateBody(ctx, customObject, stackState)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := []string{
+		"  TransitGatewayRouteTableAssociation:",
+		"  TransitGatewayRouteTablePropagation:",
+		"TransitGatewayAttachmentId: tgw-attach-1",
+		"TransitGatewayRouteTableId: tgw-rtb-1",
+	}
+	for _, e := range expected {
+		if !strings.Contains(body, e) {
+			fmt.Println(body)
+			t.Fatalf("%q not found", e)
+		}
+	}
+
+	// Guest clusters attached to the Transit Gateway are not peered with the
+	// host cluster VPC and the host cluster route tables route the whole IPAM
+	// network to the Transit Gateway already.
+	if strings.Contains(body, "Type: AWS::EC2::Route\n") {
+		fmt.Println(body)
+		t.Fatal("unexpected host cluster route found")
+	}
+}
+
+func TestMainGuestTemplateVPCEndpoints(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description         string
+		customObject        v1alpha1.AWSConfig
+		expectedContained   []string
+		expectedUncontained []string
+	}{
+		{
+			description:  "case 0, VPC endpoints disabled",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{}),
+			expectedUncontained: []string{
+				"AWS::EC2::VPCEndpoint",
+				"VPCEndpointSecurityGroup:",
+				"VPCEndpoints:",
+			},
+		},
+		{
+			description:  "case 1, VPC endpoints enabled",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{VPC: v1alpha1.AWSConfigSpecAWSVPC{Endpoints: v1alpha1.AWSConfigSpecAWSVPCEndpoints{Enabled: true}}}),
+			expectedContained: []string{
+				"  VPCEndpointSecurityGroup:\n    Type: AWS::EC2::SecurityGroup",
+				"CidrIp: 10.1.0.0/16",
+				"  VPCEndpointS3:\n    Type: AWS::EC2::VPCEndpoint\n    Properties:\n      RouteTableIds:\n        - !Ref PrivateRouteTable\n        - !Ref PrivateRouteTable01\n      ServiceName: com.amazonaws.eu-central-1.s3\n      VpcEndpointType: Gateway\n",
+				"ServiceName: com.amazonaws.eu-central-1.ec2\n      SubnetIds:\n        - !Ref PrivateSubnet\n        - !Ref PrivateSubnet01\n      VpcEndpointType: Interface\n",
+				"ServiceName: com.amazonaws.eu-central-1.ecr.api\n",
+				"ServiceName: com.amazonaws.eu-central-1.ecr.dkr\n",
+				"ServiceName: com.amazonaws.eu-central-1.kms\n",
+				"ServiceName: com.amazonaws.eu-central-1.logs\n",
+				"ServiceName: com.amazonaws.eu-central-1.sts\n",
+				"  VPCEndpoints:\n    Value: true\n",
+			},
+		},
+		{
+			description:  "case 2, VPC endpoints enabled in China region",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{Region: "cn-north-1", VPC: v1alpha1.AWSConfigSpecAWSVPC{Endpoints: v1alpha1.AWSConfigSpecAWSVPCEndpoints{Enabled: true}}}),
+			expectedContained: []string{
+				"ServiceName: com.amazonaws.cn-north-1.s3\n",
+				"ServiceName: cn.com.amazonaws.cn-north-1.ec2\n",
+				"ServiceName: cn.com.amazonaws.cn-north-1.kms\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := key.ImageID(tc.customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			stackState := StackState{
+				Name: key.MainGuestStackName(tc.customObject),
+
+				DockerVolumeResourceName:   key.DockerVolumeResourceName(tc.customObject),
+				MasterImageID:              imageID,
+				MasterInstanceResourceName: key.MasterInstanceResourceName(tc.customObject),
+				MasterInstanceType:         key.MasterInstanceType(tc.customObject),
+				MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+				WorkerCount:              strconv.Itoa(key.WorkerCount(tc.customObject)),
+				WorkerImageID:            imageID,
+				WorkerInstanceType:       key.WorkerInstanceType(tc.customObject),
+				WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+				VersionBundleVersion: key.VersionBundleVersion(tc.customObject),
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, tc.customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, e := range tc.expectedContained {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q not found", e)
+				}
+			}
+			for _, e := range tc.expectedUncontained {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q found", e)
+				}
+			}
+		})
+	}
+}
+
+func TestMainGuestTemplateSecurityGroups(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description         string
+		customObject        v1alpha1.AWSConfig
+		expectedContained   []string
+		expectedUncontained []string
+	}{
+		{
+			description:  "case 0, no whitelist and no additional rules",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{}),
+			expectedContained: []string{
+				"Description: 'Allow all traffic to the master instance.'",
+			},
+			expectedUncontained: []string{
+				"Custom Whitelist CIDR.",
+				"SecurityGroupsHash:",
+			},
+		},
+		{
+			description: "case 1, whitelist and additional rules",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{
+				API: v1alpha1.AWSConfigSpecAWSAPI{
+					Whitelist: []string{"212.145.136.84/32"},
+				},
+				SecurityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+					Ingress: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+						{
+							Description: "Allow internal load balancer.",
+							FromPort:    8443,
+							Protocol:    "tcp",
+							SourceCIDR:  "10.4.0.0/16",
+						},
+					},
+					Master: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+						{
+							Description: "Allow NodePort services.",
+							FromPort:    30000,
+							ToPort:      32767,
+							Protocol:    "udp",
+							SourceCIDR:  "10.3.0.0/16",
+						},
+					},
+					Worker: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+						{
+							Description: "Allow monitoring.",
+							FromPort:    9100,
+							ToPort:      9200,
+							Protocol:    "tcp",
+							SourceCIDR:  "10.2.0.0/16",
+						},
+					},
+				},
+			}),
+			expectedContained: []string{
+				"Description: 'Custom Whitelist CIDR.'\n        IpProtocol: tcp\n        FromPort: 443\n        ToPort: 443\n        CidrIp: 212.145.136.84/32",
+				"Description: 'Allow NodePort services.'\n        IpProtocol: udp\n        FromPort: 30000\n        ToPort: 32767\n        CidrIp: 10.3.0.0/16",
+				"Description: 'Allow monitoring.'\n        IpProtocol: tcp\n        FromPort: 9100\n        ToPort: 9200\n",
+				"Description: 'Allow internal load balancer.'\n        IpProtocol: tcp\n        FromPort: 8443\n        ToPort: 8443\n        CidrIp: 10.4.0.0/16",
+				"Description: 'Allow all https traffic to the ingress load balancer.'",
+				"Description: 'Only allow ssh traffic from the control plane.'",
+				"SecurityGroupsHash:\n    Value: 0123456789abcdef\n",
+			},
+			expectedUncontained: []string{
+				"Allow all traffic to the master instance.",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := key.ImageID(tc.customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			stackState := StackState{
+				Name: key.MainGuestStackName(tc.customObject),
+
+				DockerVolumeResourceName:   key.DockerVolumeResourceName(tc.customObject),
+				MasterImageID:              imageID,
+				MasterInstanceResourceName: key.MasterInstanceResourceName(tc.customObject),
+				MasterInstanceType:         key.MasterInstanceType(tc.customObject),
+				MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+				WorkerCount:              strconv.Itoa(key.WorkerCount(tc.customObject)),
+				WorkerImageID:            imageID,
+				WorkerInstanceType:       key.WorkerInstanceType(tc.customObject),
+				WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+				VersionBundleVersion: key.VersionBundleVersion(tc.customObject),
+			}
+			if len(key.APIWhitelist(tc.customObject)) > 0 {
+				stackState.SecurityGroupsHash = "0123456789abcdef"
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, tc.customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, e := range tc.expectedContained {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q not found", e)
+				}
+			}
+			for _, e := range tc.expectedUncontained {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q found", e)
+				}
+			}
+		})
+	}
+}
+
+func TestMainGuestTemplateVPCConnections(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description         string
+		customObject        v1alpha1.AWSConfig
+		expectedContained   []string
+		expectedUncontained []string
+	}{
+		{
+			description:  "case 0, no peerings and no virtual private gateway",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{}),
+			expectedUncontained: []string{
+				"AWS::EC2::VPCPeeringConnection\n    Properties:\n      VpcId: !Ref VPC\n      PeerVpcId: vpc-",
+				"VPNGatewayAttachment:",
+				"VPCConnectionsHash:",
+			},
+		},
+		{
+			description: "case 1, peerings and virtual private gateway",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+						{
+							AccountID: "123456789012",
+							CIDR:      "10.100.0.0/16",
+							ID:        "vpc-0a1b2c3d",
+							Region:    "eu-west-1",
+							RoleARN:   "arn:aws:iam::123456789012:role/peering",
+						},
+						{
+							CIDR: "10.101.0.0/16",
+							ID:   "vpc-1a2b3c4d",
+						},
+					},
+					VPNGateway: v1alpha1.AWSConfigSpecAWSVPCVPNGateway{
+						ID:    "vgw-0a1b2c3d",
+						CIDRs: []string{"192.168.0.0/16"},
+					},
+				},
+			}),
+			expectedContained: []string{
+				"VPCPeeringConnectionvpc0a1b2c3d:\n    Type: AWS::EC2::VPCPeeringConnection\n    Properties:\n      VpcId: !Ref VPC\n      PeerVpcId: vpc-0a1b2c3d\n      PeerOwnerId: '123456789012'\n      PeerRegion: eu-west-1\n      PeerRoleArn: arn:aws:iam::123456789012:role/peering\n",
+				"VPCPeeringConnectionvpc1a2b3c4d:\n    Type: AWS::EC2::VPCPeeringConnection\n    Properties:\n      VpcId: !Ref VPC\n      PeerVpcId: vpc-1a2b3c4d\n      Tags:\n",
+				"VPCPeeringRoutevpc0a1b2c3d:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable\n      DestinationCidrBlock: 10.100.0.0/16\n      VpcPeeringConnectionId: !Ref VPCPeeringConnectionvpc0a1b2c3d\n",
+				"VPCPeeringRoutevpc0a1b2c3d01:\n    Type: AWS::EC2::Route\n    Properties:\n      RouteTableId: !Ref PrivateRouteTable01\n",
+				"VPCPeeringIngressvpc1a2b3c4d:\n    Type: AWS::EC2::SecurityGroupIngress\n",
+				"VPNGatewayAttachment:\n    Type: AWS::EC2::VPCGatewayAttachment\n    Properties:\n      VpcId: !Ref VPC\n      VpnGatewayId: vgw-0a1b2c3d\n",
+				"RouteTableIds:\n        - !Ref PrivateRouteTable\n        - !Ref PrivateRouteTable01\n      VpnGatewayId: vgw-0a1b2c3d\n",
+				"VPNGatewayIngress:\n    Type: AWS::EC2::SecurityGroupIngress\n",
+				"CidrIp: 192.168.0.0/16",
+				"VPCConnectionsHash:\n    Value: 0123456789abcdef\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := key.ImageID(tc.customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			stackState := StackState{
+				Name: key.MainGuestStackName(tc.customObject),
+
+				DockerVolumeResourceName:   key.DockerVolumeResourceName(tc.customObject),
+				MasterImageID:              imageID,
+				MasterInstanceResourceName: key.MasterInstanceResourceName(tc.customObject),
+				MasterInstanceType:         key.MasterInstanceType(tc.customObject),
+				MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+				WorkerCount:              strconv.Itoa(key.WorkerCount(tc.customObject)),
+				WorkerImageID:            imageID,
+				WorkerInstanceType:       key.WorkerInstanceType(tc.customObject),
+				WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+				VersionBundleVersion: key.VersionBundleVersion(tc.customObject),
+			}
+			if len(key.VPCPeerings(tc.customObject)) > 0 {
+				stackState.VPCConnectionsHash = "0123456789abcdef"
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, tc.customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, e := range tc.expectedContained {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q not found", e)
+				}
+			}
+			for _, e := range tc.expectedUncontained {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q found", e)
+				}
+			}
+		})
+	}
+}
+
+func TestMainGuestTemplatePrivateHostedZone(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description         string
+		customObject        v1alpha1.AWSConfig
+		expectedContained   []string
+		expectedUncontained []string
+	}{
+		{
+			description: "case 0, private hosted zone disabled",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{
+				API: v1alpha1.AWSConfigSpecAWSAPI{Private: true},
+				HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+					API:     v1alpha1.AWSConfigSpecAWSHostedZonesZone{Name: "installation.eu-central-1.aws.gigantic.io"},
+					Private: v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{},
+				},
+			}),
+			expectedContained: []string{
+				"ApiRecordSet:\n    Type: AWS::Route53::RecordSet\n",
+				"EtcdRecordSet:\n    Type: AWS::Route53::RecordSet\n    Properties:\n      Name: 'etcd.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io.'\n      HostedZoneId: !Ref 'HostedZone'\n",
+			},
+			expectedUncontained: []string{
+				"PrivateHostedZone:",
+				"PrivateHostedZoneID:",
+			},
+		},
+		{
+			description: "case 1, private hosted zone enabled with public API",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{
+				HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+					API:     v1alpha1.AWSConfigSpecAWSHostedZonesZone{Name: "installation.eu-central-1.aws.gigantic.io"},
+					Private: v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true},
+				},
+			}),
+			expectedContained: []string{
+				"  ApiRecordSet:\n    Type: AWS::Route53::RecordSet\n",
+				"PrivateHostedZone:\n    Type: 'AWS::Route53::HostedZone'\n    Properties:\n      Name: 'test-cluster.k8s.installation.eu-central-1.aws.gigantic.io.'\n      VPCs:\n        - VPCId: !Ref VPC\n          VPCRegion: !Ref 'AWS::Region'\n",
+				"PrivateApiRecordSet:\n",
+				"PrivateEtcdRecordSet:\n    Type: AWS::Route53::RecordSet\n    Properties:\n      Name: 'etcd.test-cluster.k8s.installation.eu-central-1.aws.gigantic.io.'\n      HostedZoneId: !Ref 'PrivateHostedZone'\n",
+				"PrivateIngressRecordSet:\n",
+				"PrivateIngressWildcardRecordSet:\n",
+				"PrivateHostedZoneID:\n    Value: !Ref PrivateHostedZone\n",
+			},
+			expectedUncontained: []string{
+				"  EtcdRecordSet:",
+			},
+		},
+		{
+			description: "case 2, private hosted zone enabled with private API",
+			customObject: newTestCustomObject(v1alpha1.AWSConfigSpecAWS{
+				API: v1alpha1.AWSConfigSpecAWSAPI{Private: true},
+				HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+					API:     v1alpha1.AWSConfigSpecAWSHostedZonesZone{Name: "installation.eu-central-1.aws.gigantic.io"},
+					Private: v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true, HostVPC: true},
+				},
+			}),
+			expectedContained: []string{
+				"PrivateApiRecordSet:\n",
+				"PrivateEtcdRecordSet:\n",
+				"  IngressRecordSet:\n",
+			},
+			expectedUncontained: []string{
+				"  ApiRecordSet:",
+				"  EtcdRecordSet:",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			imageID, err := key.ImageID(tc.customObject)
+			if err != nil {
+				t.Fatalf("expected %#v got %#v", nil, err)
+			}
+
+			stackState := StackState{
+				Name: key.MainGuestStackName(tc.customObject),
+
+				DockerVolumeResourceName:   key.DockerVolumeResourceName(tc.customObject),
+				MasterImageID:              imageID,
+				MasterInstanceResourceName: key.MasterInstanceResourceName(tc.customObject),
+				MasterInstanceType:         key.MasterInstanceType(tc.customObject),
+				MasterCloudConfigVersion:   key.CloudConfigVersion,
+
+				WorkerCount:              strconv.Itoa(key.WorkerCount(tc.customObject)),
+				WorkerImageID:            imageID,
+				WorkerInstanceType:       key.WorkerInstanceType(tc.customObject),
+				WorkerCloudConfigVersion: key.CloudConfigVersion,
+
+				VersionBundleVersion: key.VersionBundleVersion(tc.customObject),
+			}
+
+			cfg := testConfig()
+			cfg.HostClients = &adapter.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+			cfg.Route53Enabled = true
+			newResource, err := New(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := aws.Clients{
+				EC2: &adapter.EC2ClientMock{},
+				IAM: &adapter.IAMClientMock{},
+				KMS: &adapter.KMSClientMock{},
+				ELB: &adapter.ELBClientMock{},
+				STS: &adapter.STSClientMock{},
+			}
+
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			body, err := newResource.getMainGuestTemplateBody(ctx, tc.customObject, stackState)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, e := range tc.expectedContained {
+				if !strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q not found", e)
+				}
+			}
+			for _, e := range tc.expectedUncontained {
+				if strings.Contains(body, e) {
+					fmt.Println(body)
+					t.Fatalf("%q found", e)
+				}
+			}
+		})
+	}
+}
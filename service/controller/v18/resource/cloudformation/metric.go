@@ -0,0 +1,60 @@
+package cloudformation
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	prometheusNamespace = "aws_operator"
+	prometheusSubsystem = "cloudformation"
+)
+
+var (
+	stackStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "stack_status",
+			Help:      "Gauge set to 1 for the current status of the CloudFormation stacks of a guest cluster.",
+		},
+		[]string{"cluster_id", "stack", "status"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(stackStatusGauge)
+}
+
+// stackStatuses tracks the status last exported for a stack of a guest
+// cluster, so that the series of the previous status can be removed when the
+// status of the stack changes.
+var stackStatuses = struct {
+	sync.Mutex
+	m map[string]string
+}{
+	m: map[string]string{},
+}
+
+// setStackStatus exports the given status of a stack of a guest cluster. An
+// empty status removes the series of the stack, e.g. once it got deleted.
+func setStackStatus(clusterID, stack, status string) {
+	stackStatuses.Lock()
+	defer stackStatuses.Unlock()
+
+	k := clusterID + "/" + stack
+
+	previous, ok := stackStatuses.m[k]
+	if ok && previous != status {
+		stackStatusGauge.DeleteLabelValues(clusterID, stack, previous)
+	}
+
+	if status == "" {
+		delete(stackStatuses.m, k)
+		return
+	}
+
+	stackStatuses.m[k] = status
+	stackStatusGauge.WithLabelValues(clusterID, stack, status).Set(1)
+}
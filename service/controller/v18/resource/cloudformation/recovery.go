@@ -0,0 +1,177 @@
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ebs"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+const (
+	// stackRecoveryReason is the reason of the Kubernetes events emitted about
+	// the recovery of the guest cluster main stack.
+	stackRecoveryReason = "StackRecovery"
+)
+
+// recoverStack recovers the guest cluster main stack in case it is stuck in
+// the given status and the custom object opts into the recovery. Stacks which
+// failed to be created are deleted, so that they are created again with the
+// next reconciliation. Stacks which failed to roll back an update continue
+// rolling back while skipping the resources which failed to roll back. The
+// returned boolean is true in case the stack is being recovered.
+func (r *Resource) recoverStack(ctx context.Context, customObject v1alpha1.AWSConfig, stackName, status string) (bool, error) {
+	if !isRecoverableStatus(status) || key.IsDeleted(customObject) {
+		return false, nil
+	}
+
+	if !key.IsStackRecoveryEnabled(customObject) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not recovering the guest cluster main stack in status '%s' since the custom object does not set %s=true", status, key.StackRecoveryAnnotation))
+		return false, nil
+	}
+
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	var resources []*cloudformation.StackResourceSummary
+	{
+		i := &cloudformation.ListStackResourcesInput{
+			StackName: aws.String(stackName),
+		}
+
+		err := sc.AWSClient.CloudFormation.ListStackResourcesPages(i, func(o *cloudformation.ListStackResourcesOutput, lastPage bool) bool {
+			resources = append(resources, o.StackResourceSummaries...)
+			return true
+		})
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	switch status {
+	case cloudformation.StackStatusRollbackComplete:
+		r.logger.LogCtx(ctx, "level", "debug", "message", "recovering the guest cluster main stack which failed to be created")
+
+		// Deleting the stack must never delete any etcd volume of the guest
+		// cluster. Resources of stacks which rolled back are deleted already, so
+		// we only double check that none of the etcd volumes is still managed by
+		// the stack.
+		volumes, err := sc.EBSService.ListVolumes(customObject, ebs.NewEtcdVolumeFilter(customObject))
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		ids := stackVolumeIDs(resources, volumes)
+		if len(ids) > 0 {
+			message := fmt.Sprintf("not recovering the guest cluster main stack since deleting it would delete the etcd volumes %s", strings.Join(ids, ", "))
+			r.logger.LogCtx(ctx, "level", "warning", "message", message)
+			r.eventRecorder.Event(&customObject, apiv1.EventTypeWarning, stackRecoveryReason, message)
+
+			return false, nil
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("retaining %d etcd volumes of the guest cluster", len(volumes)))
+
+		updateTerminationProtection := &cloudformation.UpdateTerminationProtectionInput{
+			EnableTerminationProtection: aws.Bool(false),
+			StackName:                   aws.String(stackName),
+		}
+		_, err = sc.AWSClient.CloudFormation.UpdateTerminationProtection(updateTerminationProtection)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		i := &cloudformation.DeleteStackInput{
+			StackName: aws.String(stackName),
+		}
+		_, err = sc.AWSClient.CloudFormation.DeleteStack(i)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		message := "deleting the guest cluster main stack which failed to be created in order to create it again"
+		r.logger.LogCtx(ctx, "level", "debug", "message", message)
+		r.eventRecorder.Event(&customObject, apiv1.EventTypeNormal, stackRecoveryReason, message)
+
+	case cloudformation.StackStatusUpdateRollbackFailed:
+		r.logger.LogCtx(ctx, "level", "debug", "message", "recovering the guest cluster main stack which failed to roll back an update")
+
+		skip := resourcesToSkip(resources)
+
+		i := &cloudformation.ContinueUpdateRollbackInput{
+			ResourcesToSkip: aws.StringSlice(skip),
+			StackName:       aws.String(stackName),
+		}
+		_, err := sc.AWSClient.CloudFormation.ContinueUpdateRollback(i)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+
+		message := fmt.Sprintf("continuing to roll back the update of the guest cluster main stack while skipping %d resources", len(skip))
+		if len(skip) > 0 {
+			message = fmt.Sprintf("%s: %s", message, strings.Join(skip, ", "))
+		}
+		r.logger.LogCtx(ctx, "level", "debug", "message", message)
+		r.eventRecorder.Event(&customObject, apiv1.EventTypeNormal, stackRecoveryReason, message)
+	}
+
+	return true, nil
+}
+
+// isRecoverableStatus returns true for the stack statuses the guest cluster
+// main stack can be recovered from.
+func isRecoverableStatus(status string) bool {
+	return status == cloudformation.StackStatusRollbackComplete || status == cloudformation.StackStatusUpdateRollbackFailed
+}
+
+// resourcesToSkip returns the logical IDs of the resources which failed to
+// roll back an update. CloudFormation only allows to skip resources in status
+// UPDATE_FAILED when continuing to roll back an update. Skipped resources are
+// considered to be rolled back, so they keep their current state.
+func resourcesToSkip(resources []*cloudformation.StackResourceSummary) []string {
+	var skip []string
+
+	for _, r := range resources {
+		if aws.StringValue(r.ResourceStatus) == cloudformation.ResourceStatusUpdateFailed {
+			skip = append(skip, aws.StringValue(r.LogicalResourceId))
+		}
+	}
+
+	sort.Strings(skip)
+
+	return skip
+}
+
+// stackVolumeIDs returns the IDs of the given volumes which are still managed
+// by the stack having the given resources.
+func stackVolumeIDs(resources []*cloudformation.StackResourceSummary, volumes []ebs.Volume) []string {
+	var ids []string
+
+	for _, v := range volumes {
+		for _, r := range resources {
+			if aws.StringValue(r.PhysicalResourceId) != v.VolumeID {
+				continue
+			}
+			if aws.StringValue(r.ResourceStatus) == cloudformation.ResourceStatusDeleteComplete {
+				continue
+			}
+
+			ids = append(ids, v.VolumeID)
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
@@ -0,0 +1,115 @@
+package cloudformation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/ebs"
+)
+
+func Test_Resource_Cloudformation_resourcesToSkip(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description  string
+		resources    []*cloudformation.StackResourceSummary
+		expectedSkip []string
+	}{
+		{
+			description:  "no resources",
+			resources:    nil,
+			expectedSkip: nil,
+		},
+		{
+			description: "resources which failed to roll back are skipped",
+			resources: []*cloudformation.StackResourceSummary{
+				newTestStackResource("workerAutoScalingGroup", "", cloudformation.ResourceStatusUpdateFailed),
+				newTestStackResource("MasterInstance", "", cloudformation.ResourceStatusUpdateComplete),
+				newTestStackResource("workerLaunchTemplate", "", cloudformation.ResourceStatusUpdateFailed),
+				newTestStackResource("EtcdVolume", "", cloudformation.ResourceStatusCreateComplete),
+			},
+			expectedSkip: []string{"workerAutoScalingGroup", "workerLaunchTemplate"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			skip := resourcesToSkip(tc.resources)
+
+			if !reflect.DeepEqual(skip, tc.expectedSkip) {
+				t.Fatalf("expected %#v got %#v", tc.expectedSkip, skip)
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_stackVolumeIDs(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description string
+		resources   []*cloudformation.StackResourceSummary
+		volumes     []ebs.Volume
+		expectedIDs []string
+	}{
+		{
+			description: "no volumes",
+			resources: []*cloudformation.StackResourceSummary{
+				newTestStackResource("EtcdVolume", "vol-1", cloudformation.ResourceStatusCreateComplete),
+			},
+			volumes:     nil,
+			expectedIDs: nil,
+		},
+		{
+			description: "deleted volumes are not managed by the stack",
+			resources: []*cloudformation.StackResourceSummary{
+				newTestStackResource("EtcdVolume", "vol-1", cloudformation.ResourceStatusDeleteComplete),
+			},
+			volumes: []ebs.Volume{
+				{VolumeID: "vol-1"},
+			},
+			expectedIDs: nil,
+		},
+		{
+			description: "volumes outside of the stack are not managed by the stack",
+			resources: []*cloudformation.StackResourceSummary{
+				newTestStackResource("EtcdVolume", "vol-1", cloudformation.ResourceStatusCreateComplete),
+			},
+			volumes: []ebs.Volume{
+				{VolumeID: "vol-2"},
+			},
+			expectedIDs: nil,
+		},
+		{
+			description: "existing volumes are managed by the stack",
+			resources: []*cloudformation.StackResourceSummary{
+				newTestStackResource("EtcdVolume", "vol-1", cloudformation.ResourceStatusCreateComplete),
+				newTestStackResource("EtcdVolume1", "vol-3", cloudformation.ResourceStatusDeleteFailed),
+			},
+			volumes: []ebs.Volume{
+				{VolumeID: "vol-3"},
+				{VolumeID: "vol-1"},
+			},
+			expectedIDs: []string{"vol-1", "vol-3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ids := stackVolumeIDs(tc.resources, tc.volumes)
+
+			if !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Fatalf("expected %#v got %#v", tc.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func newTestStackResource(logicalID, physicalID, status string) *cloudformation.StackResourceSummary {
+	return &cloudformation.StackResourceSummary{
+		LogicalResourceId:  aws.String(logicalID),
+		PhysicalResourceId: aws.String(physicalID),
+		ResourceStatus:     aws.String(status),
+	}
+}
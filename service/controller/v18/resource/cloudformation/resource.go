@@ -5,9 +5,11 @@ import (
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/giantswarm/aws-operator/pkg/awstags"
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
 	"github.com/giantswarm/aws-operator/service/controller/v18/encrypter"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
@@ -29,54 +31,95 @@ type AWSConfig struct {
 // resource.
 type Config struct {
 	APIWhitelist         adapter.APIWhitelist
+	EventRecorder        record.EventRecorder
 	HostClients          *adapter.Clients
+	ImageCatalog         ami.Interface
 	Logger               micrologger.Logger
 	EncrypterRoleManager encrypter.RoleManager
 
 	AdvancedMonitoringEC2 bool
-	EncrypterBackend      string
-	InstallationName      string
-	PublicRouteTables     string
-	Route53Enabled        bool
+	// DNSProvider is the DNS provider managing the zone of the installation
+	// base domain. The delegation of the guest cluster zone is only part of the
+	// host cluster post stack in case it is Route53.
+	DNSProvider      string
+	EncrypterBackend string
+	InstallationName string
+	// IPAMNetwork is the network the VPC CIDRs of guest clusters are allocated
+	// from. The host cluster route tables route it to the Transit Gateway, which
+	// is why VPCs of new guest clusters attached to the Transit Gateway must be
+	// part of it.
+	IPAMNetwork       string
+	PublicRouteTables string
+	// ReplacementApproval requires change sets replacing or removing resources
+	// of the guest cluster main stack to be approved before they are executed.
+	ReplacementApproval bool
+	Route53Enabled      bool
+	// TransitGateway attaches the VPCs of new guest clusters to a Transit
+	// Gateway instead of peering them with the host cluster VPC.
+	TransitGateway adapter.TransitGateway
 }
 
 // Resource implements the cloudformation resource.
 type Resource struct {
 	apiWhiteList         adapter.APIWhitelist
 	encrypterRoleManager encrypter.RoleManager
+	eventRecorder        record.EventRecorder
 	hostClients          *adapter.Clients
+	imageCatalog         ami.Interface
 	logger               micrologger.Logger
 
-	encrypterBackend  string
-	installationName  string
-	monitoring        bool
-	publicRouteTables string
-	route53Enabled    bool
+	dnsProvider         string
+	encrypterBackend    string
+	installationName    string
+	ipamNetwork         string
+	monitoring          bool
+	publicRouteTables   string
+	replacementApproval bool
+	route53Enabled      bool
+	transitGateway      adapter.TransitGateway
 }
 
 // New creates a new configured cloudformation resource.
 func New(config Config) (*Resource, error) {
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
 	if config.HostClients == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.HostClients must not be empty", config)
 	}
+	if config.ImageCatalog == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ImageCatalog must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
 	}
 	if config.EncrypterBackend == "" {
 		return nil, microerror.Maskf(invalidConfigError, "config.EncrypterBackend must not be empty")
 	}
+	if config.TransitGateway.ID != "" && config.TransitGateway.RouteTableID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TransitGateway.RouteTableID must not be empty when %T.TransitGateway.ID is set", config, config)
+	}
+	if config.TransitGateway.ID != "" && config.IPAMNetwork == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IPAMNetwork must not be empty when %T.TransitGateway.ID is set", config, config)
+	}
 
 	newService := &Resource{
 		apiWhiteList:         config.APIWhitelist,
+		eventRecorder:        config.EventRecorder,
 		hostClients:          config.HostClients,
+		imageCatalog:         config.ImageCatalog,
 		logger:               config.Logger,
 		encrypterRoleManager: config.EncrypterRoleManager,
 
-		encrypterBackend:  config.EncrypterBackend,
-		installationName:  config.InstallationName,
-		monitoring:        config.AdvancedMonitoringEC2,
-		publicRouteTables: config.PublicRouteTables,
-		route53Enabled:    config.Route53Enabled,
+		dnsProvider:         config.DNSProvider,
+		encrypterBackend:    config.EncrypterBackend,
+		installationName:    config.InstallationName,
+		ipamNetwork:         config.IPAMNetwork,
+		monitoring:          config.AdvancedMonitoringEC2,
+		publicRouteTables:   config.PublicRouteTables,
+		replacementApproval: config.ReplacementApproval,
+		route53Enabled:      config.Route53Enabled,
+		transitGateway:      config.TransitGateway,
 	}
 
 	return newService, nil
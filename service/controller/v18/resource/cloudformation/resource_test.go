@@ -8,8 +8,10 @@ import (
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
 )
 
 func Test_Resource_Cloudformation_GetCloudFormationTags(t *testing.T) {
@@ -56,6 +58,8 @@ func Test_Resource_Cloudformation_GetCloudFormationTags(t *testing.T) {
 
 	c := Config{}
 
+	c.EventRecorder = &record.FakeRecorder{}
+	c.ImageCatalog = ami.NewBuiltin()
 	c.HostClients = &adapter.Clients{
 		EC2:            &adapter.EC2ClientMock{},
 		CloudFormation: &adapter.CloudFormationMock{},
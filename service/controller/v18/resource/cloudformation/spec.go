@@ -1,12 +1,38 @@
 package cloudformation
 
-import "github.com/aws/aws-sdk-go/service/cloudformation"
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
 
 const (
 	// defaultCreationTimeout is the timeout in minutes for the creation of the
 	// stack.
 	defaultCreationTimeout = 10
 
+	// launchTemplateHashLength is the number of hex characters of the launch
+	// template hashes of node pools.
+	launchTemplateHashLength = 16
+
+	// loadBalancerSwitchDelay is the time the load balancers of the previous
+	// type are kept after the DNS records switched to the load balancers of
+	// another type. It matches the highest TTL of the DNS records of the guest
+	// cluster main stack.
+	loadBalancerSwitchDelay = 15 * time.Minute
+
+	// loadBalancerHashLength is the number of hex characters of the hash of the
+	// settings of the classic ELBs.
+	loadBalancerHashLength = 16
+
+	// securityGroupsHashLength is the number of hex characters of the hash of
+	// the API whitelist and the additional security group rules.
+	securityGroupsHashLength = 16
+
+	// vpcConnectionsHashLength is the number of hex characters of the hash of
+	// the VPC peerings and the virtual private gateway.
+	vpcConnectionsHashLength = 16
+
 	workerRoleKey = "WorkerRole"
 
 	namedIAMCapability = "CAPABILITY_NAMED_IAM"
@@ -20,7 +46,57 @@ const (
 type StackState struct {
 	Name string
 
+	// APIPrivate is true when the Kubernetes API of the guest cluster is only
+	// reachable from within the guest cluster VPC and the peered networks.
+	APIPrivate bool
+
 	HostedZoneNameServers string
+	// PrivateHostedZone is true when the guest cluster domain has a private
+	// hosted zone associated with the guest cluster VPC.
+	PrivateHostedZone bool
+
+	// LoadBalancerTypes are the types of the load balancers rendered for the
+	// Kubernetes API and the ingress controller. The DNS records point to the
+	// load balancers of the first type. Load balancers of a second type are
+	// kept while migrating between load balancer types.
+	LoadBalancerTypes []string
+	// LoadBalancerHash identifies the settings of the classic ELBs like idle
+	// timeouts, health checks, connection draining and access logs.
+	LoadBalancerHash string
+	// LoadBalancerSwitchTime is the time the DNS records switched to the load
+	// balancers of the first type while the load balancers of a second type are
+	// still kept.
+	LoadBalancerSwitchTime time.Time
+
+	// SecurityGroupsHash identifies the API whitelist and the additional
+	// security group rules configured in the custom object.
+	SecurityGroupsHash string
+
+	// VPCConnectionsHash identifies the additional VPC peerings and the virtual
+	// private gateway configured in the custom object.
+	VPCConnectionsHash string
+
+	// TransitGateway is true when the guest cluster VPC is attached to the
+	// Transit Gateway of the installation instead of being peered with the host
+	// cluster VPC. TransitGatewayAttachmentID is the ID of the attachment once
+	// the guest cluster main stack created it.
+	TransitGateway             bool
+	TransitGatewayAttachmentID string
+
+	// VPCEndpoints is true when the guest cluster VPC has endpoints for the AWS
+	// APIs used by the guest cluster nodes.
+	VPCEndpoints bool
+
+	// VPCFlowLogsTrafficType is the type of traffic captured by the VPC flow
+	// logs of the guest cluster.
+	VPCFlowLogsTrafficType string
+
+	// DefaultImageID is the AMI resolved from the AMI catalog for masters and
+	// node pools not configuring their own AMI. ImageCatalogSource identifies
+	// the catalog configuration it was resolved from. Both are empty for
+	// catalogs whose AMIs are not kept.
+	DefaultImageID     string
+	ImageCatalogSource string
 
 	DockerVolumeResourceName   string
 	MasterImageID              string
@@ -28,6 +104,10 @@ type StackState struct {
 	MasterInstanceResourceName string
 	MasterCloudConfigVersion   string
 	MasterInstanceMonitoring   bool
+	Masters                    []StackStateMaster
+	// MastersToReplace holds the indices of the masters being replaced by an
+	// update of the guest cluster main stack.
+	MastersToReplace []int
 
 	ShouldScale  bool
 	ShouldUpdate bool
@@ -38,8 +118,60 @@ type StackState struct {
 	WorkerInstanceMonitoring bool
 	WorkerInstanceType       string
 	WorkerCloudConfigVersion string
+	WorkerPools              []StackStateWorkerPool
 
 	UpdateStackInput cloudformation.UpdateStackInput
 
 	VersionBundleVersion string
 }
+
+// StackStateMaster is the state of a single master instance. Masters are
+// replaced one at a time during updates, which is why every master carries its
+// own configuration.
+type StackStateMaster struct {
+	CloudConfigVersion       string
+	DockerVolumeResourceName string
+	ImageID                  string
+	InstanceResourceName     string
+	InstanceType             string
+	VersionBundleVersion     string
+}
+
+// StackStateWorkerPool is the state of a single node pool. Every node pool is
+// rendered as its own auto scaling group.
+type StackStateWorkerPool struct {
+	Count              int
+	DockerVolumeSizeGB int
+	// ImageID is the AMI the workers of the node pool are launched from. It is
+	// only known for the desired state. Changes of the AMI are detected by
+	// means of the launch template hash.
+	ImageID      string
+	InstanceType string
+	// LaunchTemplateHash identifies the data of the launch template of the node
+	// pool. It changes whenever the launch template has to change, which
+	// replaces the workers of the node pool.
+	LaunchTemplateHash string
+	Name               string
+	Scaling            StackStateWorkerPoolScaling
+	Spot               StackStateWorkerPoolSpot
+}
+
+// StackStateWorkerPoolScaling holds the bounds of the ASG of a single node
+// pool. The desired capacity of node pools scaled by cluster-autoscaler is
+// left to cluster-autoscaler. Only Max and Min are known for the current
+// state, since they are the only scaling settings provided by the stack
+// outputs.
+type StackStateWorkerPoolScaling struct {
+	Enabled bool
+	Max     int
+	Min     int
+}
+
+// StackStateWorkerPoolSpot is the spot configuration of a single node pool.
+type StackStateWorkerPoolSpot struct {
+	Enabled                             bool
+	InstanceTypes                       []string
+	MaxPrice                            string
+	OnDemandBaseCapacity                int
+	OnDemandPercentageAboveBaseCapacity int
+}
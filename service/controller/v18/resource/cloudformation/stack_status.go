@@ -0,0 +1,106 @@
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v18/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+const (
+	// stackFailedReason is the reason of the Kubernetes events emitted about
+	// failed stacks of a guest cluster.
+	stackFailedReason = "StackFailed"
+
+	// guestMainStack, hostPreStack and hostPostStack identify the stacks of a
+	// guest cluster in logs, events and metrics.
+	guestMainStack = "guest-main"
+	hostPreStack   = "host-pre"
+	hostPostStack  = "host-post"
+)
+
+// reportHostStackStatuses reports the statuses of the host cluster pre and post
+// stacks of the guest cluster. Stacks which do not exist are not reported.
+func (r *Resource) reportHostStackStatuses(ctx context.Context, customObject v1alpha1.AWSConfig) error {
+	var cf *cloudformationservice.CloudFormation
+	{
+		c := cloudformationservice.Config{
+			Client: r.hostClients.CloudFormation,
+		}
+
+		var err error
+		cf, err = cloudformationservice.New(c)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	stacks := []struct {
+		Stack     string
+		StackName string
+	}{
+		{
+			Stack:     hostPreStack,
+			StackName: key.MainHostPreStackName(customObject),
+		},
+		{
+			Stack:     hostPostStack,
+			StackName: key.MainHostPostStackName(customObject),
+		},
+	}
+
+	for _, s := range stacks {
+		_, status, err := cf.DescribeOutputsAndStatus(s.StackName)
+		if cloudformationservice.IsStackNotFound(err) {
+			setStackStatus(key.ClusterID(customObject), s.Stack, "")
+			continue
+		} else if cloudformationservice.IsOutputsNotAccessible(err) {
+			// Fall through. The status is reported regardless.
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = r.reportStackStatus(ctx, customObject, cf, s.Stack, s.StackName, status)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// reportStackStatus exports the given status of a stack of the guest cluster.
+// In case the latest operation of the stack failed, the first failed resource
+// and its reason are looked up in the stack events, logged and emitted as
+// Kubernetes event on the custom object, so that it is visible why the guest
+// cluster is stuck.
+func (r *Resource) reportStackStatus(ctx context.Context, customObject v1alpha1.AWSConfig, cf *cloudformationservice.CloudFormation, stack, stackName, status string) error {
+	setStackStatus(key.ClusterID(customObject), stack, status)
+
+	if !cloudformationservice.IsFailedStatus(status) {
+		return nil
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding the failure of the %s stack in the AWS API", stack))
+
+	f, err := cf.DescribeFailure(stackName)
+	if cloudformationservice.IsFailureNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find the failure of the %s stack in the AWS API", stack))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found the failure of the %s stack in the AWS API", stack))
+
+	message := fmt.Sprintf("the %s stack of guest cluster %#q has status %#q because %s %#q has status %#q: %s", stack, key.ClusterID(customObject), status, f.ResourceType, f.LogicalResourceID, f.ResourceStatus, f.ResourceStatusReason)
+	r.logger.LogCtx(ctx, "level", "warning", "message", message)
+	r.eventRecorder.Event(&customObject, apiv1.EventTypeWarning, stackFailedReason, message)
+
+	return nil
+}
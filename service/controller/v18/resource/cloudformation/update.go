@@ -2,6 +2,9 @@ package cloudformation
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
@@ -10,6 +13,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller"
 	"github.com/giantswarm/operatorkit/controller/context/updateallowedcontext"
+	apiv1 "k8s.io/api/core/v1"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v18/ebs"
@@ -34,52 +38,89 @@ func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange inte
 			return microerror.Mask(err)
 		}
 
-		if stackStateToUpdate.ShouldUpdate && !stackStateToUpdate.ShouldScale {
-			// Fetch the etcd volume information.
-			filterFuncs := []func(t *ec2.Tag) bool{
-				ebs.NewDockerVolumeFilter(customObject),
-				ebs.NewEtcdVolumeFilter(customObject),
-			}
-			volumes, err := sc.EBSService.ListVolumes(customObject, filterFuncs...)
+		updateStackInput := stackStateToUpdate.UpdateStackInput
+		updateStackInput.Parameters = []*cloudformation.Parameter{
+			{
+				ParameterKey:   aws.String(versionBundleVersionParameterKey),
+				ParameterValue: aws.String(key.VersionBundleVersion(customObject)),
+			},
+		}
+
+		// We update the guest cluster main stack using change sets. This allows us
+		// to preview which resources are replaced before anything happens and to
+		// hold back updates replacing resources until they are approved.
+		changeSet := changeSetName(updateStackInput)
+		{
+			summary, ok, err := r.ensureChangeSet(ctx, sc.AWSClient.CloudFormation, updateStackInput, changeSet)
 			if err != nil {
 				return microerror.Mask(err)
 			}
+			if !ok {
+				r.logger.LogCtx(ctx, "level", "debug", "message", "not updating the guest cluster main stack")
+				return nil
+			}
 
-			// First shutdown the instances and wait for it to be stopped. Then detach
-			// the etcd and docker volume without forcing.
-			force := false
-			shutdown := true
-			wait := true
-			for _, v := range volumes {
-				for _, a := range v.Attachments {
-					err := sc.EBSService.DetachVolume(ctx, v.VolumeID, a, force, shutdown, wait)
-					if err != nil {
-						return microerror.Mask(err)
-					}
-				}
+			message := fmt.Sprintf("change set %#q of the guest cluster main stack will %s", changeSet, summary)
+			r.logger.LogCtx(ctx, "level", "debug", "message", message)
+			r.eventRecorder.Event(&customObject, apiv1.EventTypeNormal, changeSetCreatedReason, message)
+
+			if r.replacementApproval && summary.NeedsApproval() && key.ApprovedChangeSet(customObject) != changeSet {
+				message := fmt.Sprintf("change set %#q of the guest cluster main stack replaces or removes resources and has to be approved by annotating the custom object with %s=%s", changeSet, key.ApprovedChangeSetAnnotation, changeSet)
+				r.logger.LogCtx(ctx, "level", "debug", "message", message)
+				r.eventRecorder.Event(&customObject, apiv1.EventTypeWarning, changeSetApprovalRequiredReason, message)
+
+				r.logger.LogCtx(ctx, "level", "debug", "message", "not updating the guest cluster main stack")
+				return nil
 			}
 		}
 
-		customObject, err := key.ToCustomObject(obj)
-		if err != nil {
-			return microerror.Mask(err)
-		}
+		if stackStateToUpdate.ShouldUpdate && !stackStateToUpdate.ShouldScale {
+			// Only the masters being replaced are shut down. Guest clusters running
+			// multiple masters replace one master per update so that the etcd
+			// cluster and the Kubernetes API stay available.
+			for _, i := range stackStateToUpdate.MastersToReplace {
+				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("shutting down master %d", i))
+
+				// Fetch the etcd volume information.
+				filterFuncs := []func(t *ec2.Tag) bool{
+					ebs.NewMasterVolumeFilter(customObject, i),
+				}
+				volumes, err := sc.EBSService.ListVolumes(customObject, filterFuncs...)
+				if err != nil {
+					return microerror.Mask(err)
+				}
 
-		updateStackInput := stackStateToUpdate.UpdateStackInput
-		updateStackInput.Parameters = []*cloudformation.Parameter{
-			{
-				ParameterKey:   aws.String(versionBundleVersionParameterKey),
-				ParameterValue: aws.String(key.VersionBundleVersion(customObject)),
-			},
+				// First shutdown the instances and wait for it to be stopped. Then detach
+				// the etcd and docker volume without forcing.
+				force := false
+				shutdown := true
+				wait := true
+				for _, v := range volumes {
+					for _, a := range v.Attachments {
+						err := sc.EBSService.DetachVolume(ctx, v.VolumeID, a, force, shutdown, wait)
+						if err != nil {
+							return microerror.Mask(err)
+						}
+					}
+				}
+
+				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("shut down master %d", i))
+			}
 		}
 
 		// Once the etcd volume is cleaned up and the master instance is down we can
 		// go ahead to let CloudFormation do its job.
-		_, err = sc.AWSClient.CloudFormation.UpdateStack(&updateStackInput)
+		i := &cloudformation.ExecuteChangeSetInput{
+			ChangeSetName: aws.String(changeSet),
+			StackName:     updateStackInput.StackName,
+		}
+		_, err = sc.AWSClient.CloudFormation.ExecuteChangeSet(i)
 		if err != nil {
 			return microerror.Mask(err)
 		}
 
+		r.eventRecorder.Event(&customObject, apiv1.EventTypeNormal, changeSetExecutedReason, fmt.Sprintf("executed change set %#q of the guest cluster main stack", changeSet))
+
 		r.logger.LogCtx(ctx, "level", "debug", "message", "updated the guest cluster main stack")
 	} else {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not updating the guest cluster main stack")
@@ -138,6 +179,30 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 		return StackState{}, microerror.Mask(err)
 	}
 
+	err = r.validateCluster(ctx, customObject)
+	if err != nil {
+		return StackState{}, microerror.Mask(err)
+	}
+
+	// Guest clusters keep the connectivity to the host cluster they were
+	// created with. Only new guest clusters are attached to the Transit Gateway
+	// once it is configured, since existing ones would lose their peering
+	// routes in the host cluster post stack, which is never updated.
+	if currentStackState.TransitGateway && r.transitGateway.ID == "" {
+		return StackState{}, microerror.Maskf(invalidConfigError, "guest cluster VPC is attached to a Transit Gateway but no Transit Gateway is configured")
+	}
+	desiredStackState.TransitGateway = currentStackState.TransitGateway
+
+	// The scheme of the Kubernetes API load balancer cannot be changed in place
+	// and replacing the load balancer fails because of its custom name, which
+	// is already taken by the load balancer being replaced. So guest clusters
+	// keep the Kubernetes API they were created with.
+	if currentStackState.APIPrivate != desiredStackState.APIPrivate {
+		return StackState{}, microerror.Maskf(invalidConfigError, "Kubernetes API of guest cluster cannot be switched between private and public")
+	}
+
+	desiredStackState.LoadBalancerTypes, desiredStackState.LoadBalancerSwitchTime = migrateLoadBalancerTypes(currentStackState.LoadBalancerTypes, currentStackState.LoadBalancerSwitchTime, desiredStackState.LoadBalancerTypes, time.Now())
+
 	// We enable/disable updates in order to enable them our test installations
 	// but disable them in production installations. That is useful until we have
 	// full confidence in updating guest clusters. Note that updates also manage
@@ -148,6 +213,12 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 		if shouldUpdate(currentStackState, desiredStackState) {
 			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack has to be updated")
 
+			var mastersToReplace []int
+			desiredStackState.Masters, mastersToReplace = r.rollMasters(ctx, currentStackState.Masters, desiredStackState.Masters)
+			if len(desiredStackState.Masters) > 0 {
+				desiredStackState.MasterInstanceResourceName = desiredStackState.Masters[0].InstanceResourceName
+				desiredStackState.DockerVolumeResourceName = desiredStackState.Masters[0].DockerVolumeResourceName
+			}
 			updateStackInput, err := r.computeUpdateState(ctx, customObject, desiredStackState)
 			if err != nil {
 				return StackState{}, microerror.Mask(err)
@@ -155,6 +226,7 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 
 			updateState := StackState{
 				Name:             desiredStackState.Name,
+				MastersToReplace: mastersToReplace,
 				ShouldScale:      false,
 				ShouldUpdate:     true,
 				UpdateStackInput: updateStackInput,
@@ -184,6 +256,9 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 
 			desiredStackState.MasterInstanceResourceName = currentStackState.MasterInstanceResourceName
 			desiredStackState.DockerVolumeResourceName = currentStackState.DockerVolumeResourceName
+			desiredStackState.Masters = currentStackState.Masters
+			desiredStackState.LoadBalancerTypes = currentStackState.LoadBalancerTypes
+			desiredStackState.LoadBalancerSwitchTime = currentStackState.LoadBalancerSwitchTime
 
 			updateStackInput, err := r.computeUpdateState(ctx, customObject, desiredStackState)
 			if err != nil {
@@ -206,11 +281,85 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 	return StackState{}, nil
 }
 
+// rollMasters computes the masters of the guest cluster main stack for an
+// update. Masters are replaced one at a time, so at most the first master
+// running an outdated configuration is replaced by its desired state. All other
+// masters keep their current state until the next update. The indices of the
+// masters being replaced are returned alongside.
+func (r *Resource) rollMasters(ctx context.Context, currentMasters, desiredMasters []StackStateMaster) ([]StackStateMaster, []int) {
+	// Guest clusters without any current master state are created from
+	// scratch.
+	if len(currentMasters) == 0 {
+		return desiredMasters, nil
+	}
+
+	// The etcd members of existing guest clusters cannot be added or removed by
+	// replacing master instances. So we keep the number of masters as it is.
+	if len(currentMasters) != len(desiredMasters) {
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not changing the number of masters from %d to %d since this is not supported for existing guest clusters", len(currentMasters), len(desiredMasters)))
+	}
+
+	var masters []StackStateMaster
+	var mastersToReplace []int
+	for i, c := range currentMasters {
+		if i < len(desiredMasters) && len(mastersToReplace) == 0 && masterNeedsUpdate(c, desiredMasters[i]) {
+			masters = append(masters, desiredMasters[i])
+			mastersToReplace = append(mastersToReplace, i)
+		} else {
+			masters = append(masters, c)
+		}
+	}
+
+	return masters, mastersToReplace
+}
+
+// migrateLoadBalancerTypes computes the load balancer types of the guest
+// cluster main stack for an update. In case the desired load balancer type
+// differs from the current one, the load balancers of the current type are
+// kept alongside the ones of the desired type, while the DNS records switch
+// over to the latter. The time of the switch is returned along with the load
+// balancer types. The load balancers of the previous type are kept until
+// clients resolved the switched DNS records, that is once the TTL of the DNS
+// records passed since the switch. They are removed with the next update
+// afterwards, which has to be approved since it removes resources. Stacks
+// which switched before the time of the switch was kept remove them right
+// away.
+func migrateLoadBalancerTypes(currentTypes []string, switchTime time.Time, desiredTypes []string, now time.Time) ([]string, time.Time) {
+	if len(currentTypes) == 0 {
+		return desiredTypes, time.Time{}
+	}
+	if currentTypes[0] != desiredTypes[0] {
+		return []string{desiredTypes[0], currentTypes[0]}, now
+	}
+	if len(currentTypes) > 1 && !switchTime.IsZero() && now.Before(switchTime.Add(loadBalancerSwitchDelay)) {
+		return currentTypes, switchTime
+	}
+
+	return desiredTypes, time.Time{}
+}
+
+// masterNeedsUpdate determines whether a single master has to be replaced. This
+// is the case when the AMI or the instance type of the master or the version
+// bundle version it was created with changes.
+func masterNeedsUpdate(currentMaster, desiredMaster StackStateMaster) bool {
+	if currentMaster.ImageID != desiredMaster.ImageID {
+		return true
+	}
+	if currentMaster.InstanceType != desiredMaster.InstanceType {
+		return true
+	}
+	if currentMaster.VersionBundleVersion != desiredMaster.VersionBundleVersion {
+		return true
+	}
+
+	return false
+}
+
 // shouldScale determines whether the reconciled guest cluster should be scaled.
 // A guest cluster is only allowed to scale in case nothing but the worker count
-// changes. In case anything else changes as well, scaling is not allowed, since
-// any other changes should be covered by general updates, which is a separate
-// step.
+// or the scaling bounds of node pools change. In case anything else changes as
+// well, scaling is not allowed, since any other changes should be covered by
+// general updates, which is a separate step.
 func (r *Resource) shouldScale(ctx context.Context, currentState, desiredState StackState) bool {
 	if currentState.MasterImageID != desiredState.MasterImageID {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to master image id")
@@ -244,10 +393,66 @@ func (r *Resource) shouldScale(ctx context.Context, currentState, desiredState S
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to version bundle version")
 		return false
 	}
+	if workerPoolsNeedUpdate(currentState.WorkerPools, desiredState.WorkerPools) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to node pools")
+		return false
+	}
+	if !equalStrings(currentState.LoadBalancerTypes, desiredState.LoadBalancerTypes) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to load balancer types")
+		return false
+	}
+	if loadBalancerNeedsUpdate(currentState.LoadBalancerHash, desiredState.LoadBalancerHash) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to load balancer settings")
+		return false
+	}
+	if currentState.PrivateHostedZone != desiredState.PrivateHostedZone {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to private hosted zone")
+		return false
+	}
+	if currentState.VPCEndpoints != desiredState.VPCEndpoints {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to VPC endpoints")
+		return false
+	}
+	if vpcFlowLogsNeedUpdate(currentState.VPCFlowLogsTrafficType, desiredState.VPCFlowLogsTrafficType) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to VPC flow logs")
+		return false
+	}
+	if securityGroupsNeedUpdate(currentState.SecurityGroupsHash, desiredState.SecurityGroupsHash) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to security group rules")
+		return false
+	}
+	if vpcConnectionsNeedUpdate(currentState.VPCConnectionsHash, desiredState.VPCConnectionsHash) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not scaling due to VPC connections")
+		return false
+	}
 
 	if currentState.WorkerCount != desiredState.WorkerCount {
 		return true
 	}
+	for i, p := range currentState.WorkerPools {
+		if workerPoolNeedsScaling(p, desiredState.WorkerPools[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// workerPoolNeedsScaling determines whether a single node pool has to be
+// scaled. This is the case when the bounds of its ASG change. The number of
+// workers is only relevant for node pools not scaled by cluster-autoscaler,
+// since cluster-autoscaler manages the desired capacity of its node pools
+// within their bounds.
+func workerPoolNeedsScaling(currentPool, desiredPool StackStateWorkerPool) bool {
+	if currentPool.Scaling.Max != desiredPool.Scaling.Max {
+		return true
+	}
+	if currentPool.Scaling.Min != desiredPool.Scaling.Min {
+		return true
+	}
+	if !desiredPool.Scaling.Enabled && currentPool.Count != desiredPool.Count {
+		return true
+	}
 
 	return false
 }
@@ -259,6 +464,19 @@ func (r *Resource) shouldScale(ctx context.Context, currentState, desiredState S
 //     The instance type of worker nodes changes (indicates updates).
 //     The size of a docker volume for worker nodes changes.
 //     The version bundle version changes (indicates updates).
+//     Any master still runs an outdated configuration (rolling update).
+//     Node pools are added, removed or change their instance type or the size
+//     of their docker volumes.
+//     The load balancer type changes or a migration between load balancer
+//     types has to be completed.
+//     The settings of the classic ELBs change, e.g. idle timeouts or health
+//     checks.
+//     The private hosted zone is enabled or disabled.
+//     VPC endpoints are enabled or disabled.
+//     The traffic type of the VPC flow logs changes.
+//     The API whitelist or the additional security group rules change.
+//     VPC peerings or the virtual private gateway are added, removed or
+//     changed.
 //
 func shouldUpdate(currentState, desiredState StackState) bool {
 	if currentState.MasterInstanceType != desiredState.MasterInstanceType {
@@ -273,6 +491,137 @@ func shouldUpdate(currentState, desiredState StackState) bool {
 	if currentState.VersionBundleVersion != desiredState.VersionBundleVersion {
 		return true
 	}
+	for i, m := range currentState.Masters {
+		if i < len(desiredState.Masters) && masterNeedsUpdate(m, desiredState.Masters[i]) {
+			return true
+		}
+	}
+	if workerPoolsNeedUpdate(currentState.WorkerPools, desiredState.WorkerPools) {
+		return true
+	}
+	if !equalStrings(currentState.LoadBalancerTypes, desiredState.LoadBalancerTypes) {
+		return true
+	}
+	if loadBalancerNeedsUpdate(currentState.LoadBalancerHash, desiredState.LoadBalancerHash) {
+		return true
+	}
+	if currentState.PrivateHostedZone != desiredState.PrivateHostedZone {
+		return true
+	}
+	if currentState.VPCEndpoints != desiredState.VPCEndpoints {
+		return true
+	}
+	if vpcFlowLogsNeedUpdate(currentState.VPCFlowLogsTrafficType, desiredState.VPCFlowLogsTrafficType) {
+		return true
+	}
+	if securityGroupsNeedUpdate(currentState.SecurityGroupsHash, desiredState.SecurityGroupsHash) {
+		return true
+	}
+	if vpcConnectionsNeedUpdate(currentState.VPCConnectionsHash, desiredState.VPCConnectionsHash) {
+		return true
+	}
+
+	return false
+}
+
+// loadBalancerNeedsUpdate determines whether the settings of the classic ELBs
+// changed. Stacks which do not provide the hash of the settings yet are not
+// updated only because of that.
+func loadBalancerNeedsUpdate(currentHash, desiredHash string) bool {
+	return currentHash != "" && currentHash != desiredHash
+}
+
+// vpcFlowLogsNeedUpdate determines whether the traffic type of the VPC flow
+// logs changed. Stacks which do not provide the traffic type yet get their VPC
+// flow logs with the next update.
+func vpcFlowLogsNeedUpdate(currentTrafficType, desiredTrafficType string) bool {
+	return currentTrafficType != "" && currentTrafficType != desiredTrafficType
+}
+
+// securityGroupsNeedUpdate determines whether the API whitelist or the
+// additional security group rules changed. Security group rules are updated in
+// place, without replacing any instance. Stacks which do not provide the hash
+// yet are not updated only because of that.
+func securityGroupsNeedUpdate(currentHash, desiredHash string) bool {
+	return currentHash != "" && currentHash != desiredHash
+}
+
+// vpcConnectionsNeedUpdate determines whether the VPC peerings or the virtual
+// private gateway changed. Stacks which do not provide the hash yet are not
+// updated only because of that.
+func vpcConnectionsNeedUpdate(currentHash, desiredHash string) bool {
+	return currentHash != "" && currentHash != desiredHash
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// workerPoolsNeedUpdate determines whether the node pools of the guest cluster
+// have to be updated. This is the case when node pools are added, removed or
+// renamed, when the instance type, docker volume size or spot configuration of
+// any node pool changes, or when the launch template of any node pool drifts from its
+// desired data. Changes of the number of workers are handled by scaling.
+func workerPoolsNeedUpdate(currentPools, desiredPools []StackStateWorkerPool) bool {
+	if len(currentPools) != len(desiredPools) {
+		return true
+	}
+
+	for i, c := range currentPools {
+		d := desiredPools[i]
+
+		if c.Name != d.Name {
+			return true
+		}
+		if c.InstanceType != d.InstanceType {
+			return true
+		}
+		if c.DockerVolumeSizeGB != d.DockerVolumeSizeGB {
+			return true
+		}
+		if c.Spot.Enabled != d.Spot.Enabled {
+			return true
+		}
+		if d.Spot.Enabled && spotNeedsUpdate(c.Spot, d.Spot) {
+			return true
+		}
+		// Stacks created before launch templates were supported do not provide
+		// launch template hashes. Their node pools are migrated to launch
+		// templates with the next update.
+		if c.LaunchTemplateHash != "" && c.LaunchTemplateHash != d.LaunchTemplateHash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// spotNeedsUpdate determines whether the spot configuration of a single node
+// pool changes. Changes of the instance types, the max price or the on-demand
+// capacity are applied to the mixed instances policy of the node pool's ASG.
+func spotNeedsUpdate(currentSpot, desiredSpot StackStateWorkerPoolSpot) bool {
+	if !reflect.DeepEqual(currentSpot.InstanceTypes, desiredSpot.InstanceTypes) {
+		return true
+	}
+	if currentSpot.MaxPrice != desiredSpot.MaxPrice {
+		return true
+	}
+	if currentSpot.OnDemandBaseCapacity != desiredSpot.OnDemandBaseCapacity {
+		return true
+	}
+	if currentSpot.OnDemandPercentageAboveBaseCapacity != desiredSpot.OnDemandPercentageAboveBaseCapacity {
+		return true
+	}
 
 	return false
 }
@@ -2,16 +2,20 @@ package cloudformation
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awscloudformation "github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
 	"github.com/giantswarm/operatorkit/controller/context/updateallowedcontext"
+	"k8s.io/client-go/tools/record"
 
 	awsclient "github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 )
 
@@ -384,6 +388,192 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesAllowed(t *testing.T) {
 				StackName: aws.String("desired"),
 			},
 		},
+		{
+			description: "case 11, current state not empty, desired state not empty, VPC endpoints enabled, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+
+				VPCEndpoints: true,
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 12, current state not empty, desired state not empty, different VPC flow logs traffic type, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+
+				VPCFlowLogsTrafficType: "ALL",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+
+				VPCFlowLogsTrafficType: "REJECT",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 13, current state not empty, desired state not empty, different security groups hash, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				SecurityGroupsHash: "0123456789abcdef",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				SecurityGroupsHash: "fedcba9876543210",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 14, current state not empty, desired state not empty, different VPC connections hash, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				VPCConnectionsHash: "0123456789abcdef",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				VPCConnectionsHash: "fedcba9876543210",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
+		{
+			description: "case 15, current state not empty, desired state not empty, private hosted zone enabled, expected desired state",
+			currentState: StackState{
+				Name: "current",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			desiredState: StackState{
+				Name: "desired",
+
+				MasterCloudConfigVersion: "1.0.0",
+				MasterImageID:            "ami-123",
+				MasterInstanceType:       "m3.large",
+
+				PrivateHostedZone: true,
+
+				WorkerCloudConfigVersion: "1.0.0",
+				WorkerCount:              "4",
+				WorkerImageID:            "ami-123",
+				WorkerInstanceType:       "m3.large",
+
+				VersionBundleVersion: "1.0.0",
+			},
+			expectedChange: awscloudformation.UpdateStackInput{
+				StackName: aws.String("desired"),
+			},
+		},
 	}
 
 	var err error
@@ -391,6 +581,8 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesAllowed(t *testing.T) {
 	{
 		c := Config{}
 
+		c.EventRecorder = &record.FakeRecorder{}
+		c.ImageCatalog = ami.NewBuiltin()
 		c.HostClients = &adapter.Clients{
 			IAM: &adapter.IAMClientMock{},
 			EC2: &adapter.EC2ClientMock{},
@@ -843,6 +1035,8 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesNotAllowed(t *testing.T
 	{
 		c := Config{}
 
+		c.EventRecorder = &record.FakeRecorder{}
+		c.ImageCatalog = ami.NewBuiltin()
 		c.HostClients = &adapter.Clients{
 			IAM: &adapter.IAMClientMock{},
 			EC2: &adapter.EC2ClientMock{},
@@ -883,3 +1077,620 @@ func Test_Resource_Cloudformation_newUpdateChange_updatesNotAllowed(t *testing.T
 		})
 	}
 }
+
+func Test_Resource_Cloudformation_newUpdateChange_invalidConfig(t *testing.T) {
+	t.Parallel()
+	customObject := &v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AZ:               "eu-central-1a",
+				LoadBalancerType: "application",
+				Masters: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+				},
+				Region: "eu-central-1",
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+				},
+			},
+		},
+	}
+
+	var err error
+	var newResource *Resource
+	{
+		c := Config{}
+
+		c.EventRecorder = &record.FakeRecorder{}
+		c.ImageCatalog = ami.NewBuiltin()
+		c.HostClients = &adapter.Clients{
+			IAM: &adapter.IAMClientMock{},
+			EC2: &adapter.EC2ClientMock{},
+			STS: &adapter.STSClientMock{},
+		}
+		c.Logger = microloggertest.New()
+		c.EncrypterBackend = "kms"
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ctx := updateallowedcontext.NewContext(context.Background(), make(chan struct{}))
+	updateallowedcontext.SetUpdateAllowed(ctx)
+
+	currentState := StackState{
+		Name:              "current",
+		LoadBalancerTypes: []string{"classic"},
+	}
+	desiredState := StackState{
+		Name:              "current",
+		LoadBalancerTypes: []string{"application"},
+	}
+
+	_, err = newResource.newUpdateChange(ctx, customObject, currentState, desiredState)
+	if !IsInvalidConfig(err) {
+		t.Fatalf("expected invalid config error, got %#v", err)
+	}
+}
+
+func Test_Resource_Cloudformation_newUpdateChange_apiPrivate(t *testing.T) {
+	t.Parallel()
+	customObject := &v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+			AWS: v1alpha1.AWSConfigSpecAWS{
+				AZ: "eu-central-1a",
+				Masters: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+				},
+				Region: "eu-central-1",
+				Workers: []v1alpha1.AWSConfigSpecAWSNode{
+					{},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description       string
+		currentAPIPrivate bool
+		desiredAPIPrivate bool
+		errorMatcher      func(error) bool
+	}{
+		{
+			description:       "case 0, public API kept, expected no error",
+			currentAPIPrivate: false,
+			desiredAPIPrivate: false,
+			errorMatcher:      nil,
+		},
+		{
+			description:       "case 1, private API kept, expected no error",
+			currentAPIPrivate: true,
+			desiredAPIPrivate: true,
+			errorMatcher:      nil,
+		},
+		{
+			description:       "case 2, public API switched to private, expected invalid config error",
+			currentAPIPrivate: false,
+			desiredAPIPrivate: true,
+			errorMatcher:      IsInvalidConfig,
+		},
+		{
+			description:       "case 3, private API switched to public, expected invalid config error",
+			currentAPIPrivate: true,
+			desiredAPIPrivate: false,
+			errorMatcher:      IsInvalidConfig,
+		},
+	}
+
+	var err error
+	var newResource *Resource
+	{
+		c := Config{}
+
+		c.EventRecorder = &record.FakeRecorder{}
+		c.ImageCatalog = ami.NewBuiltin()
+		c.HostClients = &adapter.Clients{
+			IAM: &adapter.IAMClientMock{},
+			EC2: &adapter.EC2ClientMock{},
+			STS: &adapter.STSClientMock{},
+		}
+		c.Logger = microloggertest.New()
+		c.EncrypterBackend = "kms"
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := updateallowedcontext.NewContext(context.Background(), make(chan struct{}))
+			updateallowedcontext.SetUpdateAllowed(ctx)
+
+			currentState := StackState{
+				Name:       "current",
+				APIPrivate: tc.currentAPIPrivate,
+			}
+			desiredState := StackState{
+				Name:       "current",
+				APIPrivate: tc.desiredAPIPrivate,
+			}
+
+			_, err := newResource.newUpdateChange(ctx, customObject, currentState, desiredState)
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("expected %#v got %#v", nil, err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("expected %#v got %#v", "error", nil)
+			case !tc.errorMatcher(err):
+				t.Fatalf("expected %#v got %#v", true, false)
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_rollMasters(t *testing.T) {
+	t.Parallel()
+
+	oldMaster := func(name string) StackStateMaster {
+		return StackStateMaster{
+			InstanceResourceName: name,
+			InstanceType:         "m4.large",
+			VersionBundleVersion: "1.0.0",
+		}
+	}
+	newMaster := func(name string) StackStateMaster {
+		return StackStateMaster{
+			InstanceResourceName: name,
+			InstanceType:         "m4.large",
+			VersionBundleVersion: "2.0.0",
+		}
+	}
+
+	testCases := []struct {
+		description              string
+		currentMasters           []StackStateMaster
+		desiredMasters           []StackStateMaster
+		expectedMasters          []StackStateMaster
+		expectedMastersToReplace []int
+	}{
+		{
+			description:              "case 0, no current masters, all desired masters are created",
+			currentMasters:           nil,
+			desiredMasters:           []StackStateMaster{newMaster("A"), newMaster("B"), newMaster("C")},
+			expectedMasters:          []StackStateMaster{newMaster("A"), newMaster("B"), newMaster("C")},
+			expectedMastersToReplace: nil,
+		},
+		{
+			description:              "case 1, all masters outdated, only the first master is replaced",
+			currentMasters:           []StackStateMaster{oldMaster("A"), oldMaster("B"), oldMaster("C")},
+			desiredMasters:           []StackStateMaster{newMaster("D"), newMaster("E"), newMaster("F")},
+			expectedMasters:          []StackStateMaster{newMaster("D"), oldMaster("B"), oldMaster("C")},
+			expectedMastersToReplace: []int{0},
+		},
+		{
+			description:              "case 2, first master up to date, the second master is replaced",
+			currentMasters:           []StackStateMaster{newMaster("D"), oldMaster("B"), oldMaster("C")},
+			desiredMasters:           []StackStateMaster{newMaster("G"), newMaster("H"), newMaster("I")},
+			expectedMasters:          []StackStateMaster{newMaster("D"), newMaster("H"), oldMaster("C")},
+			expectedMastersToReplace: []int{1},
+		},
+		{
+			description:              "case 3, all masters up to date, no master is replaced",
+			currentMasters:           []StackStateMaster{newMaster("D"), newMaster("H"), newMaster("L")},
+			desiredMasters:           []StackStateMaster{newMaster("M"), newMaster("N"), newMaster("O")},
+			expectedMasters:          []StackStateMaster{newMaster("D"), newMaster("H"), newMaster("L")},
+			expectedMastersToReplace: nil,
+		},
+		{
+			description:              "case 4, number of masters changes, current number of masters is kept",
+			currentMasters:           []StackStateMaster{oldMaster("A")},
+			desiredMasters:           []StackStateMaster{newMaster("D"), newMaster("E"), newMaster("F")},
+			expectedMasters:          []StackStateMaster{newMaster("D")},
+			expectedMastersToReplace: []int{0},
+		},
+	}
+
+	r := &Resource{
+		logger: microloggertest.New(),
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			masters, mastersToReplace := r.rollMasters(context.TODO(), tc.currentMasters, tc.desiredMasters)
+
+			if !reflect.DeepEqual(masters, tc.expectedMasters) {
+				t.Fatalf("expected masters %#v got %#v", tc.expectedMasters, masters)
+			}
+			if !reflect.DeepEqual(mastersToReplace, tc.expectedMastersToReplace) {
+				t.Fatalf("expected masters to replace %#v got %#v", tc.expectedMastersToReplace, mastersToReplace)
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_migrateLoadBalancerTypes(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2019, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		description             string
+		currentTypes            []string
+		switchTime              time.Time
+		desiredTypes            []string
+		expectedTypes           []string
+		expectedSwitchTime      time.Time
+		expectedUpdateAfterward bool
+	}{
+		{
+			description:             "case 0, unchanged load balancer type is kept",
+			currentTypes:            []string{"classic"},
+			desiredTypes:            []string{"classic"},
+			expectedTypes:           []string{"classic"},
+			expectedUpdateAfterward: false,
+		},
+		{
+			description:             "case 1, changed load balancer type keeps the current load balancers",
+			currentTypes:            []string{"classic"},
+			desiredTypes:            []string{"network"},
+			expectedTypes:           []string{"network", "classic"},
+			expectedSwitchTime:      now,
+			expectedUpdateAfterward: true,
+		},
+		{
+			description:             "case 2, migration keeps the previous load balancers until the TTL of the DNS records passed",
+			currentTypes:            []string{"network", "classic"},
+			switchTime:              now.Add(-5 * time.Minute),
+			desiredTypes:            []string{"network"},
+			expectedTypes:           []string{"network", "classic"},
+			expectedSwitchTime:      now.Add(-5 * time.Minute),
+			expectedUpdateAfterward: true,
+		},
+		{
+			description:             "case 3, completed migration removes the previous load balancers",
+			currentTypes:            []string{"network", "classic"},
+			switchTime:              now.Add(-15 * time.Minute),
+			desiredTypes:            []string{"network"},
+			expectedTypes:           []string{"network"},
+			expectedUpdateAfterward: false,
+		},
+		{
+			description:             "case 4, migration without switch time removes the previous load balancers",
+			currentTypes:            []string{"network", "classic"},
+			desiredTypes:            []string{"network"},
+			expectedTypes:           []string{"network"},
+			expectedUpdateAfterward: false,
+		},
+		{
+			description:             "case 5, reverted migration keeps the load balancers the DNS records point to",
+			currentTypes:            []string{"network", "classic"},
+			switchTime:              now.Add(-5 * time.Minute),
+			desiredTypes:            []string{"classic"},
+			expectedTypes:           []string{"classic", "network"},
+			expectedSwitchTime:      now,
+			expectedUpdateAfterward: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			types, switchTime := migrateLoadBalancerTypes(tc.currentTypes, tc.switchTime, tc.desiredTypes, now)
+			if !reflect.DeepEqual(types, tc.expectedTypes) {
+				t.Fatalf("expected load balancer types %#v got %#v", tc.expectedTypes, types)
+			}
+			if !switchTime.Equal(tc.expectedSwitchTime) {
+				t.Fatalf("expected switch time %s got %s", tc.expectedSwitchTime, switchTime)
+			}
+
+			// Once the update is applied, the next reconciliation has to find out
+			// whether the migration has to be completed.
+			update := shouldUpdate(StackState{LoadBalancerTypes: types}, StackState{LoadBalancerTypes: tc.desiredTypes})
+			if update != tc.expectedUpdateAfterward {
+				t.Fatalf("expected update afterward %t got %t", tc.expectedUpdateAfterward, update)
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_loadBalancerHash(t *testing.T) {
+	t.Parallel()
+	defaultHash := loadBalancerHash(v1alpha1.AWSConfig{})
+
+	testCases := []struct {
+		description    string
+		currentHash    string
+		customObject   v1alpha1.AWSConfig
+		expectedUpdate bool
+	}{
+		{
+			description:    "case 0, stacks without load balancer hash are not updated",
+			currentHash:    "",
+			customObject:   v1alpha1.AWSConfig{},
+			expectedUpdate: false,
+		},
+		{
+			description:    "case 1, unchanged settings do not cause updates",
+			currentHash:    defaultHash,
+			customObject:   v1alpha1.AWSConfig{},
+			expectedUpdate: false,
+		},
+		{
+			description: "case 2, explicit default settings do not cause updates",
+			currentHash: defaultHash,
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Ingress: v1alpha1.AWSConfigSpecAWSIngress{
+							ELB: v1alpha1.AWSConfigSpecAWSIngressELB{
+								IdleTimeoutSeconds: 60,
+							},
+						},
+					},
+				},
+			},
+			expectedUpdate: false,
+		},
+		{
+			description: "case 3, changed ingress idle timeout causes an update",
+			currentHash: defaultHash,
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Ingress: v1alpha1.AWSConfigSpecAWSIngress{
+							ELB: v1alpha1.AWSConfigSpecAWSIngressELB{
+								IdleTimeoutSeconds: 3600,
+							},
+						},
+					},
+				},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "case 4, changed health check causes an update",
+			currentHash: defaultHash,
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						ELB: v1alpha1.AWSConfigSpecAWSELB{
+							HealthCheck: v1alpha1.AWSConfigSpecAWSELBHealthCheck{
+								UnhealthyThreshold: 5,
+							},
+						},
+					},
+				},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "case 5, enabled access logs cause an update",
+			currentHash: defaultHash,
+			customObject: v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						ELB: v1alpha1.AWSConfigSpecAWSELB{
+							AccessLogs: v1alpha1.AWSConfigSpecAWSELBAccessLogs{
+								Enabled: true,
+							},
+						},
+					},
+				},
+			},
+			expectedUpdate: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			currentState := StackState{LoadBalancerHash: tc.currentHash}
+			desiredState := StackState{LoadBalancerHash: loadBalancerHash(tc.customObject)}
+
+			update := shouldUpdate(currentState, desiredState)
+			if update != tc.expectedUpdate {
+				t.Fatalf("expected update %t got %t", tc.expectedUpdate, update)
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_workerPoolsNeedUpdate(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description    string
+		currentPools   []StackStateWorkerPool
+		desiredPools   []StackStateWorkerPool
+		expectedUpdate bool
+	}{
+		{
+			description: "unchanged node pools do not need updates",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+			},
+			expectedUpdate: false,
+		},
+		{
+			description: "changed worker count is handled by scaling",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 5, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+			},
+			expectedUpdate: false,
+		},
+		{
+			description: "launch template drift needs update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "b", Name: "default"},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "missing current launch template hash does not need update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", Name: "default"},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "b", Name: "default"},
+			},
+			expectedUpdate: false,
+		},
+		{
+			description: "added node pool needs update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+				{Count: 1, InstanceType: "r4.xlarge", LaunchTemplateHash: "c", Name: "memory"},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "enabling spot needs update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default"},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true}},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "unchanged spot configuration does not need update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			expectedUpdate: false,
+		},
+		{
+			description: "changed spot instance types need update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge", "c5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "changed spot max price needs update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.2", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "changed spot on-demand base capacity needs update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 2, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "changed spot on-demand percentage above base capacity needs update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 10}},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{Enabled: true, InstanceTypes: []string{"m4.xlarge", "m5.xlarge"}, MaxPrice: "0.1", OnDemandBaseCapacity: 1, OnDemandPercentageAboveBaseCapacity: 50}},
+			},
+			expectedUpdate: true,
+		},
+		{
+			description: "changed spot configuration of disabled spot does not need update",
+			currentPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{MaxPrice: "0.1"}},
+			},
+			desiredPools: []StackStateWorkerPool{
+				{Count: 3, InstanceType: "m4.xlarge", LaunchTemplateHash: "a", Name: "default", Spot: StackStateWorkerPoolSpot{MaxPrice: "0.2"}},
+			},
+			expectedUpdate: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			update := workerPoolsNeedUpdate(tc.currentPools, tc.desiredPools)
+			if update != tc.expectedUpdate {
+				t.Fatalf("expected %t got %t", tc.expectedUpdate, update)
+			}
+		})
+	}
+}
+
+func Test_Resource_Cloudformation_workerPoolNeedsScaling(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description     string
+		currentPool     StackStateWorkerPool
+		desiredPool     StackStateWorkerPool
+		expectedScaling bool
+	}{
+		{
+			description:     "unchanged node pool does not need scaling",
+			currentPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Max: 4, Min: 3}},
+			desiredPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Max: 4, Min: 3}},
+			expectedScaling: false,
+		},
+		{
+			description:     "changed worker count needs scaling",
+			currentPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Max: 4, Min: 3}},
+			desiredPool:     StackStateWorkerPool{Count: 5, Scaling: StackStateWorkerPoolScaling{Max: 6, Min: 5}},
+			expectedScaling: true,
+		},
+		{
+			description:     "changed worker count of autoscaled node pool does not need scaling",
+			currentPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Max: 10, Min: 3}},
+			desiredPool:     StackStateWorkerPool{Count: 5, Scaling: StackStateWorkerPoolScaling{Enabled: true, Max: 10, Min: 3}},
+			expectedScaling: false,
+		},
+		{
+			description:     "changed maximum of autoscaled node pool needs scaling",
+			currentPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Max: 10, Min: 3}},
+			desiredPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Enabled: true, Max: 20, Min: 3}},
+			expectedScaling: true,
+		},
+		{
+			description:     "changed minimum of autoscaled node pool needs scaling",
+			currentPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Max: 10, Min: 3}},
+			desiredPool:     StackStateWorkerPool{Count: 3, Scaling: StackStateWorkerPoolScaling{Enabled: true, Max: 10, Min: 1}},
+			expectedScaling: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			scaling := workerPoolNeedsScaling(tc.currentPool, tc.desiredPool)
+			if scaling != tc.expectedScaling {
+				t.Fatalf("expected %t got %t", tc.expectedScaling, scaling)
+			}
+		})
+	}
+}
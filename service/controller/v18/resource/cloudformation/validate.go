@@ -1,19 +1,81 @@
 package cloudformation
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/microerror"
 
+	"github.com/giantswarm/aws-operator/pkg/ipam"
+	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
+const (
+	// defaultRouteCIDR is the destination of the default route subnets of
+	// existing VPCs must have.
+	defaultRouteCIDR = "0.0.0.0/0"
+	// elbSubnetMinFreeIPs is the number of free IP addresses AWS requires in
+	// every subnet an ELB is placed in.
+	elbSubnetMinFreeIPs = 8
+	// Limits of the settings of classic ELBs.
+	elbConnectionDrainingTimeoutMax = 3600
+	elbConnectionDrainingTimeoutMin = 1
+	elbHealthCheckIntervalMax       = 300
+	elbHealthCheckIntervalMin       = 5
+	elbHealthCheckThresholdMax      = 10
+	elbHealthCheckThresholdMin      = 2
+	elbHealthCheckTimeoutMax        = 60
+	elbHealthCheckTimeoutMin        = 2
+	elbIdleTimeoutMax               = 4000
+	elbIdleTimeoutMin               = 1
+	// internetGatewayIDPrefix is the prefix of the IDs of internet gateways.
+	internetGatewayIDPrefix = "igw-"
+	// vpcIDPrefix is the prefix of the IDs of VPCs.
+	vpcIDPrefix = "vpc-"
+	// vpnGatewayIDPrefix is the prefix of the IDs of virtual private gateways.
+	vpnGatewayIDPrefix = "vgw-"
+	// securityGroupRulesMax is the number of additional ingress rules allowed
+	// per security group. AWS allows 60 ingress rules per security group by
+	// default and the remaining ones are reserved for the rules managed by the
+	// operator.
+	securityGroupRulesMax = 40
+)
+
+// nodePoolNameRegexp matches DNS labels. Node pool names are used in resource
+// names, resource tags and comma separated stack outputs, so they are
+// restricted accordingly.
+var nodePoolNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// awsAccountIDRegexp matches the IDs of AWS accounts.
+var awsAccountIDRegexp = regexp.MustCompile(`^[0-9]{12}$`)
+
+// securityGroupRuleDescriptionRegexp matches the characters AWS allows in the
+// descriptions of security group rules.
+var securityGroupRuleDescriptionRegexp = regexp.MustCompile(`^[a-zA-Z0-9. _\-:/()#,@\[\]+=&;{}!$*]{0,255}$`)
+
 type validator func(v1alpha1.AWSConfig) error
 
-func (r *Resource) validateCluster(cluster v1alpha1.AWSConfig) error {
+// validateCluster ensures the settings of the custom object can be applied to
+// the guest cluster main stack. It is called on creation and on updates.
+func (r *Resource) validateCluster(ctx context.Context, cluster v1alpha1.AWSConfig) error {
 	validators := []validator{
-		r.validateHostPeeringRoutes,
+		r.validateAvailabilityZones,
+		r.validateLoadBalancerSettings,
+		r.validateLoadBalancerType,
+		r.validateMasters,
+		r.validateNodePools,
+		r.validatePrivateHostedZone,
+		r.validateSecurityGroups,
+		r.validateVPCFlowLogs,
 	}
 
 	for _, v := range validators {
@@ -22,16 +84,207 @@ func (r *Resource) validateCluster(cluster v1alpha1.AWSConfig) error {
 		}
 	}
 
+	// The VPC connections are validated separately, since the CIDRs of the host
+	// cluster VPC and existing VPCs have to be looked up.
+	{
+		err := r.validateVPCConnections(ctx, cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// validateNewCluster additionally ensures the networks of a guest cluster which
+// is about to be created are free. Once the guest cluster exists, its own
+// subnets, routes and peering would fail these checks, which is why they only
+// run on creation.
+func (r *Resource) validateNewCluster(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	{
+		err := r.validateCluster(ctx, cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	{
+		err := r.validateHostPeeringRoutes(cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	// Existing VPCs are validated separately, since their subnets have to be
+	// looked up in the guest account.
+	if key.IsExistingVPC(cluster) {
+		err := r.validateExistingVPC(ctx, cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	{
+		err := r.validateVPCCIDR(ctx, cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if r.transitGateway.ID != "" {
+		err := r.validateTransitGatewayNetwork(ctx, cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// validateTransitGatewayNetwork ensures the VPC CIDR of a guest cluster
+// attached to the Transit Gateway is part of the IPAM network, since the host
+// cluster route tables only route the IPAM network to the Transit Gateway.
+func (r *Resource) validateTransitGatewayNetwork(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	guestNetwork, err := guestVPCNetwork(ctx, cluster)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, ipamNetwork, err := net.ParseCIDR(r.ipamNetwork)
+	if err != nil {
+		return microerror.Maskf(invalidConfigError, "IPAM network '%s' must be a valid CIDR", r.ipamNetwork)
+	}
+
+	ipamOnes, _ := ipamNetwork.Mask.Size()
+	guestOnes, _ := guestNetwork.Mask.Size()
+	if !ipamNetwork.Contains(guestNetwork.IP) || guestOnes < ipamOnes {
+		return microerror.Maskf(invalidConfigError, "VPC CIDR %s must be part of IPAM network %s routed to the Transit Gateway", guestNetwork.String(), ipamNetwork.String())
+	}
+
+	return nil
+}
+
+// guestVPCNetwork returns the VPC network of the guest cluster, which has to be
+// looked up in the guest account in case of existing VPCs.
+func guestVPCNetwork(ctx context.Context, cluster v1alpha1.AWSConfig) (*net.IPNet, error) {
+	var guestCIDR string
+	if key.IsExistingVPC(cluster) {
+		sc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		guestCIDR, err = adapter.VpcCIDR(adapter.Clients{EC2: sc.AWSClient.EC2}, key.VPCID(cluster))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	} else {
+		guestCIDR = key.CIDR(cluster)
+	}
+
+	_, guestNetwork, err := net.ParseCIDR(guestCIDR)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "VPC CIDR '%s' must be a valid CIDR", guestCIDR)
+	}
+
+	return guestNetwork, nil
+}
+
+// validateVPCCIDR ensures the VPC CIDR of the guest cluster neither overlaps
+// the host cluster VPC nor the VPCs of other guest clusters peered with it,
+// since overlapping networks cannot be routed through the peering.
+func (r *Resource) validateVPCCIDR(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	guestNetwork, err := guestVPCNetwork(ctx, cluster)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	peeredCIDRs := map[string]string{}
+	{
+		hostCIDR, err := adapter.VpcCIDR(*r.hostClients, key.PeerID(cluster))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		peeredCIDRs[hostCIDR] = "host cluster VPC"
+
+		i := &ec2.DescribeVpcPeeringConnectionsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name: aws.String("accepter-vpc-info.vpc-id"),
+					Values: []*string{
+						aws.String(key.PeerID(cluster)),
+					},
+				},
+				{
+					Name: aws.String("status-code"),
+					Values: []*string{
+						aws.String(ec2.VpcPeeringConnectionStateReasonCodeActive),
+					},
+				},
+			},
+		}
+		o, err := r.hostClients.EC2.DescribeVpcPeeringConnections(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, p := range o.VpcPeeringConnections {
+			if p.RequesterVpcInfo == nil || p.RequesterVpcInfo.CidrBlock == nil {
+				continue
+			}
+			peeredCIDRs[aws.StringValue(p.RequesterVpcInfo.CidrBlock)] = fmt.Sprintf("VPC peered through '%s'", aws.StringValue(p.VpcPeeringConnectionId))
+		}
+
+		for _, p := range key.VPCPeerings(cluster) {
+			peeredCIDRs[p.CIDR] = fmt.Sprintf("peer VPC '%s'", p.ID)
+		}
+	}
+
+	for cidr, description := range peeredCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return microerror.Maskf(invalidConfigError, "CIDR '%s' of %s must be a valid CIDR", cidr, description)
+		}
+
+		if ipam.Overlap(*guestNetwork, *network) {
+			return microerror.Maskf(invalidConfigError, "VPC CIDR %s must not overlap CIDR %s of %s", guestNetwork.String(), network.String(), description)
+		}
+	}
+
 	return nil
 }
 
 func (r *Resource) validateHostPeeringRoutes(cluster v1alpha1.AWSConfig) error {
+	// The CIDRs of existing subnets are not part of the custom object. The
+	// peering routes of existing VPCs are validated along with their subnets.
+	if key.IsExistingVPC(cluster) {
+		return nil
+	}
+
+	privateSubnetCIDRs, err := key.PrivateSubnetCIDRs(cluster)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, cidr := range privateSubnetCIDRs {
+		err := r.validateHostPeeringRoute(cluster, cidr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// validateHostPeeringRoute ensures the host cluster VPC does not route the
+// given private subnet CIDR of the guest cluster yet.
+func (r *Resource) validateHostPeeringRoute(cluster v1alpha1.AWSConfig, cidr string) error {
 	input := &ec2.DescribeRouteTablesInput{
 		Filters: []*ec2.Filter{
 			{
 				Name: aws.String("route.destination-cidr-block"),
 				Values: []*string{
-					aws.String(key.PrivateSubnetCIDR(cluster)),
+					aws.String(cidr),
 				},
 			},
 			{
@@ -44,8 +297,489 @@ func (r *Resource) validateHostPeeringRoutes(cluster v1alpha1.AWSConfig) error {
 	}
 	output, err := r.hostClients.EC2.DescribeRouteTables(input)
 	if err == nil && len(output.RouteTables) > 0 {
-		return microerror.Maskf(alreadyExistsError, "route: %s", key.PrivateSubnetCIDR(cluster))
+		return microerror.Maskf(alreadyExistsError, "route: %s", cidr)
+	}
+
+	return nil
+}
+
+// validateExistingVPC ensures the existing VPC and subnets a guest cluster is
+// deployed into are usable. There must be one private and one public subnet of
+// the VPC per availability zone. Every subnet must have enough free IP
+// addresses and a default route. Public subnets must route through an internet
+// gateway.
+func (r *Resource) validateExistingVPC(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	azs := key.AvailabilityZones(cluster)
+	privateSubnetIDs := key.PrivateSubnetIDs(cluster)
+	publicSubnetIDs := key.PublicSubnetIDs(cluster)
+
+	if len(privateSubnetIDs) != len(azs) {
+		return microerror.Maskf(invalidConfigError, "%d private subnets must be given for %d availability zones", len(privateSubnetIDs), len(azs))
+	}
+	if len(publicSubnetIDs) != len(azs) {
+		return microerror.Maskf(invalidConfigError, "%d public subnets must be given for %d availability zones", len(publicSubnetIDs), len(azs))
+	}
+
+	subnets := map[string]*ec2.Subnet{}
+	{
+		i := &ec2.DescribeSubnetsInput{
+			SubnetIds: aws.StringSlice(append(append([]string{}, privateSubnetIDs...), publicSubnetIDs...)),
+		}
+		o, err := sc.AWSClient.EC2.DescribeSubnets(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, s := range o.Subnets {
+			subnets[aws.StringValue(s.SubnetId)] = s
+		}
+	}
+
+	clients := adapter.Clients{
+		EC2: sc.AWSClient.EC2,
+	}
+
+	// Masters and workers are spread evenly across the private subnets.
+	instances := key.MasterCount(cluster)
+	if instances < 1 {
+		instances = 1
+	}
+	for _, p := range key.NodePools(cluster) {
+		instances += key.NodePoolScalingMax(p)
+	}
+	privateMinFreeIPs := (instances + len(azs) - 1) / len(azs)
+
+	for i, az := range azs {
+		for _, s := range []struct {
+			id         string
+			public     bool
+			minFreeIPs int
+		}{
+			{id: privateSubnetIDs[i], public: false, minFreeIPs: privateMinFreeIPs},
+			{id: publicSubnetIDs[i], public: true, minFreeIPs: elbSubnetMinFreeIPs},
+		} {
+			subnet, ok := subnets[s.id]
+			if !ok {
+				return microerror.Maskf(notFoundError, "subnet '%s'", s.id)
+			}
+
+			routeTable, err := adapter.SubnetRouteTable(clients, key.VPCID(cluster), s.id)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			err = validateExistingSubnet(subnet, routeTable, key.VPCID(cluster), az, s.public, s.minFreeIPs)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			if !s.public {
+				err := r.validateHostPeeringRoute(cluster, aws.StringValue(subnet.CidrBlock))
+				if err != nil {
+					return microerror.Mask(err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateExistingSubnet ensures the given existing subnet belongs to the given
+// VPC and availability zone, has at least the given number of free IP
+// addresses and has a default route in the given route table. Public subnets
+// must route through an internet gateway.
+func validateExistingSubnet(subnet *ec2.Subnet, routeTable *ec2.RouteTable, vpcID, az string, public bool, minFreeIPs int) error {
+	id := aws.StringValue(subnet.SubnetId)
+
+	if aws.StringValue(subnet.VpcId) != vpcID {
+		return microerror.Maskf(invalidConfigError, "subnet '%s' must belong to VPC '%s'", id, vpcID)
+	}
+	if aws.StringValue(subnet.AvailabilityZone) != az {
+		return microerror.Maskf(invalidConfigError, "subnet '%s' must be in availability zone '%s'", id, az)
+	}
+	if int(aws.Int64Value(subnet.AvailableIpAddressCount)) < minFreeIPs {
+		return microerror.Maskf(invalidConfigError, "subnet '%s' must have at least %d free IP addresses", id, minFreeIPs)
+	}
+
+	for _, route := range routeTable.Routes {
+		if aws.StringValue(route.DestinationCidrBlock) != defaultRouteCIDR {
+			continue
+		}
+		if aws.StringValue(route.State) != ec2.RouteStateActive {
+			continue
+		}
+		if public && !strings.HasPrefix(aws.StringValue(route.GatewayId), internetGatewayIDPrefix) {
+			continue
+		}
+
+		return nil
+	}
+
+	if public {
+		return microerror.Maskf(invalidConfigError, "public subnet '%s' must have a default route through an internet gateway", id)
+	}
+
+	return microerror.Maskf(invalidConfigError, "private subnet '%s' must have a default route", id)
+}
+
+// validateAvailabilityZones ensures the master availability zone given in the
+// AZ field is the first of the availability zones the guest cluster is spread
+// across, since the master instance is placed in the first private subnet.
+func (r *Resource) validateAvailabilityZones(cluster v1alpha1.AWSConfig) error {
+	azs := cluster.Spec.AWS.AvailabilityZones
+	if len(azs) == 0 {
+		return nil
+	}
+
+	if cluster.Spec.AWS.AZ != "" && cluster.Spec.AWS.AZ != azs[0] {
+		return microerror.Maskf(invalidConfigError, "AZ '%s' must be the first availability zone in %v", cluster.Spec.AWS.AZ, azs)
+	}
+
+	seen := map[string]bool{}
+	for _, az := range azs {
+		if seen[az] {
+			return microerror.Maskf(invalidConfigError, "availability zone '%s' must only be given once", az)
+		}
+		seen[az] = true
+	}
+
+	// Guest clusters deployed into existing VPCs do not carve their subnets out
+	// of the VPC CIDR.
+	if !key.IsExistingVPC(cluster) {
+		_, err := key.PrivateSubnetCIDRs(cluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// validateLoadBalancerSettings ensures the settings of the classic ELBs are
+// within the limits of AWS, so that invalid settings are reported before the
+// stack update fails.
+func (r *Resource) validateLoadBalancerSettings(cluster v1alpha1.AWSConfig) error {
+	for _, t := range []int{key.APIELBIdleTimeoutSeconds(cluster), key.IngressELBIdleTimeoutSeconds(cluster)} {
+		if t < elbIdleTimeoutMin || t > elbIdleTimeoutMax {
+			return microerror.Maskf(invalidConfigError, "ELB idle timeout %d must be between %d and %d seconds", t, elbIdleTimeoutMin, elbIdleTimeoutMax)
+		}
+	}
+
+	healthCheck := key.ELBHealthCheck(cluster)
+	for _, t := range []int{healthCheck.HealthyThreshold, healthCheck.UnhealthyThreshold} {
+		if t < elbHealthCheckThresholdMin || t > elbHealthCheckThresholdMax {
+			return microerror.Maskf(invalidConfigError, "ELB health check threshold %d must be between %d and %d", t, elbHealthCheckThresholdMin, elbHealthCheckThresholdMax)
+		}
+	}
+	if healthCheck.IntervalSeconds < elbHealthCheckIntervalMin || healthCheck.IntervalSeconds > elbHealthCheckIntervalMax {
+		return microerror.Maskf(invalidConfigError, "ELB health check interval %d must be between %d and %d seconds", healthCheck.IntervalSeconds, elbHealthCheckIntervalMin, elbHealthCheckIntervalMax)
+	}
+	if healthCheck.TimeoutSeconds < elbHealthCheckTimeoutMin || healthCheck.TimeoutSeconds > elbHealthCheckTimeoutMax {
+		return microerror.Maskf(invalidConfigError, "ELB health check timeout %d must be between %d and %d seconds", healthCheck.TimeoutSeconds, elbHealthCheckTimeoutMin, elbHealthCheckTimeoutMax)
+	}
+	if healthCheck.TimeoutSeconds >= healthCheck.IntervalSeconds {
+		return microerror.Maskf(invalidConfigError, "ELB health check timeout %d must be lower than the interval %d", healthCheck.TimeoutSeconds, healthCheck.IntervalSeconds)
+	}
+
+	if key.ELBConnectionDrainingEnabled(cluster) {
+		t := key.ELBConnectionDrainingTimeoutSeconds(cluster)
+		if t < elbConnectionDrainingTimeoutMin || t > elbConnectionDrainingTimeoutMax {
+			return microerror.Maskf(invalidConfigError, "ELB connection draining timeout %d must be between %d and %d seconds", t, elbConnectionDrainingTimeoutMin, elbConnectionDrainingTimeoutMax)
+		}
+	}
+
+	if key.ELBAccessLogsEnabled(cluster) {
+		i := key.ELBAccessLogsEmitIntervalMinutes(cluster)
+		if i != 5 && i != 60 {
+			return microerror.Maskf(invalidConfigError, "ELB access logs emit interval %d must be 5 or 60 minutes", i)
+		}
+	}
+
+	return nil
+}
+
+// validateLoadBalancerType ensures the load balancer type of the guest cluster
+// is known.
+func (r *Resource) validateLoadBalancerType(cluster v1alpha1.AWSConfig) error {
+	switch key.LoadBalancerType(cluster) {
+	case key.LoadBalancerTypeClassic, key.LoadBalancerTypeNetwork:
+		return nil
+	default:
+		return microerror.Maskf(invalidConfigError, "load balancer type '%s' must be one of '%s' or '%s'", key.LoadBalancerType(cluster), key.LoadBalancerTypeClassic, key.LoadBalancerTypeNetwork)
+	}
+}
+
+// validateMasters ensures guest clusters with multiple masters can form an etcd
+// cluster. The etcd members find each other using the DNS records managed in
+// the hosted zone of the guest cluster, which is only available when Route53 is
+// enabled.
+func (r *Resource) validateMasters(cluster v1alpha1.AWSConfig) error {
+	if key.MasterCount(cluster) > 1 && !r.route53Enabled {
+		return microerror.Maskf(invalidConfigError, "%d masters require Route53 to be enabled", key.MasterCount(cluster))
+	}
+
+	return nil
+}
+
+// validateNodePools ensures the configured node pools are named uniquely using
+// DNS labels, so that they result in unique resource names, that every node
+// pool has at least one worker and that the spot configuration of node pools
+// running on spot instances is sane. The scaling bounds of node pools scaled by
+// cluster-autoscaler are validated as well, including the ones of the default
+// node pool.
+func (r *Resource) validateNodePools(cluster v1alpha1.AWSConfig) error {
+	seen := map[string]string{}
+	for _, p := range cluster.Spec.AWS.NodePools {
+		if !nodePoolNameRegexp.MatchString(p.Name) {
+			return microerror.Maskf(invalidConfigError, "node pool name '%s' must be a DNS label", p.Name)
+		}
+		resourceName := key.NodePoolResourceName("", p.Name)
+		if other, ok := seen[resourceName]; ok && other == p.Name {
+			return microerror.Maskf(invalidConfigError, "node pool name '%s' must only be given once", p.Name)
+		} else if ok {
+			return microerror.Maskf(invalidConfigError, "node pool names '%s' and '%s' must differ in more than dashes", other, p.Name)
+		}
+		seen[resourceName] = p.Name
+
+		if key.NodePoolCount(p) <= 0 {
+			return microerror.Maskf(invalidConfigError, "node pool '%s' requires at least 1 worker", p.Name)
+		}
+
+		if p.Spot.Enabled {
+			err := validateNodePoolSpot(p)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	for _, p := range key.NodePools(cluster) {
+		if key.NodePoolScalingEnabled(p) {
+			err := validateNodePoolScaling(p)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateNodePoolScaling ensures cluster-autoscaler is able to scale the given
+// node pool. The minimum size must be at least one worker, since rolling
+// updates keep at least one worker in service, and the maximum size must exceed
+// the minimum size.
+func validateNodePoolScaling(nodePool v1alpha1.AWSConfigSpecAWSNodePool) error {
+	scaling := nodePool.Scaling
+
+	if scaling.Min < 1 {
+		return microerror.Maskf(invalidConfigError, "scaling min %d of node pool '%s' must be at least 1", scaling.Min, nodePool.Name)
+	}
+	if scaling.Max <= scaling.Min {
+		return microerror.Maskf(invalidConfigError, "scaling max %d of node pool '%s' must be greater than scaling min %d", scaling.Max, nodePool.Name, scaling.Min)
+	}
+
+	return nil
+}
+
+func validateNodePoolSpot(nodePool v1alpha1.AWSConfigSpecAWSNodePool) error {
+	spot := nodePool.Spot
+
+	if spot.MaxPrice != "" {
+		price, err := strconv.ParseFloat(spot.MaxPrice, 64)
+		if err != nil || price <= 0 {
+			return microerror.Maskf(invalidConfigError, "spot max price '%s' of node pool '%s' must be a positive number", spot.MaxPrice, nodePool.Name)
+		}
+	}
+	if spot.OnDemandBaseCapacity < 0 || spot.OnDemandBaseCapacity > key.NodePoolCount(nodePool) {
+		return microerror.Maskf(invalidConfigError, "on-demand base capacity %d of node pool '%s' must be between 0 and %d", spot.OnDemandBaseCapacity, nodePool.Name, key.NodePoolCount(nodePool))
+	}
+	if spot.OnDemandPercentageAboveBaseCapacity < 0 || spot.OnDemandPercentageAboveBaseCapacity > 100 {
+		return microerror.Maskf(invalidConfigError, "on-demand percentage above base capacity %d of node pool '%s' must be between 0 and 100", spot.OnDemandPercentageAboveBaseCapacity, nodePool.Name)
+	}
+
+	return nil
+}
+
+// validatePrivateHostedZone ensures the private hosted zone is only associated
+// with the host cluster VPC when it is enabled and the records of the guest
+// cluster domain are managed in Route53 at all.
+func (r *Resource) validatePrivateHostedZone(cluster v1alpha1.AWSConfig) error {
+	if key.PrivateHostedZoneEnabled(cluster) && !r.route53Enabled {
+		return microerror.Maskf(invalidConfigError, "private hosted zone must only be enabled when route53 is enabled")
+	}
+	if key.PrivateHostedZoneHostVPC(cluster) && !key.PrivateHostedZoneEnabled(cluster) {
+		return microerror.Maskf(invalidConfigError, "private hosted zone must be enabled in order to be associated with the host cluster VPC")
+	}
+	// The record of a private Kubernetes API is only published in the private
+	// hosted zone. The host cluster can only resolve it when the private hosted
+	// zone is associated with the host cluster VPC.
+	if key.IsAPIPrivate(cluster) && !key.PrivateHostedZoneHostVPC(cluster) {
+		return microerror.Maskf(invalidConfigError, "private hosted zone must be associated with the host cluster VPC when the Kubernetes API is private")
+	}
+
+	return nil
+}
+
+// validateSecurityGroups ensures the API whitelist and the additional security
+// group rules configured in the custom object can be applied to the security
+// groups of the guest cluster. The whitelisted CIDRs are added to the security
+// group of the masters and count towards its rules.
+func (r *Resource) validateSecurityGroups(cluster v1alpha1.AWSConfig) error {
+	for _, cidr := range key.APIWhitelist(cluster) {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return microerror.Maskf(invalidConfigError, "API whitelist CIDR '%s' must be a valid CIDR", cidr)
+		}
+	}
+
+	groups := []struct {
+		name  string
+		rules []v1alpha1.AWSConfigSpecAWSSecurityGroupRule
+		extra int
+	}{
+		{name: "ingress", rules: key.IngressSecurityGroupRules(cluster)},
+		{name: "master", rules: key.MasterSecurityGroupRules(cluster), extra: len(key.APIWhitelist(cluster))},
+		{name: "worker", rules: key.WorkerSecurityGroupRules(cluster)},
+	}
+
+	for _, g := range groups {
+		if len(g.rules)+g.extra > securityGroupRulesMax {
+			return microerror.Maskf(invalidConfigError, "%s security group must not have more than %d additional rules", g.name, securityGroupRulesMax)
+		}
+
+		for _, rule := range g.rules {
+			err := validateSecurityGroupRule(g.name, rule)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateSecurityGroupRule(group string, rule v1alpha1.AWSConfigSpecAWSSecurityGroupRule) error {
+	if rule.Protocol != "tcp" && rule.Protocol != "udp" {
+		return microerror.Maskf(invalidConfigError, "protocol '%s' of %s security group rule must be 'tcp' or 'udp'", rule.Protocol, group)
+	}
+	if rule.FromPort < 1 || rule.FromPort > 65535 {
+		return microerror.Maskf(invalidConfigError, "port %d of %s security group rule must be between 1 and 65535", rule.FromPort, group)
+	}
+	if rule.ToPort != 0 && (rule.ToPort < rule.FromPort || rule.ToPort > 65535) {
+		return microerror.Maskf(invalidConfigError, "to port %d of %s security group rule must be between %d and 65535", rule.ToPort, group, rule.FromPort)
+	}
+	_, _, err := net.ParseCIDR(rule.SourceCIDR)
+	if err != nil {
+		return microerror.Maskf(invalidConfigError, "source CIDR '%s' of %s security group rule must be a valid CIDR", rule.SourceCIDR, group)
+	}
+	if !securityGroupRuleDescriptionRegexp.MatchString(rule.Description) {
+		return microerror.Maskf(invalidConfigError, "description '%s' of %s security group rule must have at most 255 characters allowed by AWS", rule.Description, group)
 	}
 
 	return nil
 }
+
+// validateVPCConnections ensures the additional VPC peerings and the virtual
+// private gateway configured in the custom object can be attached to the guest
+// cluster VPC. Routes to the peer VPCs are added to the private route tables,
+// so their CIDRs must neither overlap each other, the guest cluster VPC nor the
+// host cluster VPC the private subnets are routed to as well.
+func (r *Resource) validateVPCConnections(ctx context.Context, cluster v1alpha1.AWSConfig) error {
+	type namedNetwork struct {
+		description string
+		network     net.IPNet
+	}
+
+	var networks []namedNetwork
+	if len(key.VPCPeerings(cluster)) != 0 {
+		guestCIDR := key.CIDR(cluster)
+		if key.IsExistingVPC(cluster) {
+			sc, err := controllercontext.FromContext(ctx)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			guestCIDR, err = adapter.VpcCIDR(adapter.Clients{EC2: sc.AWSClient.EC2}, key.VPCID(cluster))
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+		_, guestNetwork, err := net.ParseCIDR(guestCIDR)
+		if err == nil {
+			networks = append(networks, namedNetwork{description: "guest cluster VPC", network: *guestNetwork})
+		}
+
+		hostCIDR, err := adapter.VpcCIDR(*r.hostClients, key.PeerID(cluster))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		_, hostNetwork, err := net.ParseCIDR(hostCIDR)
+		if err != nil {
+			return microerror.Maskf(invalidConfigError, "CIDR '%s' of host cluster VPC must be a valid CIDR", hostCIDR)
+		}
+		networks = append(networks, namedNetwork{description: "host cluster VPC", network: *hostNetwork})
+	}
+
+	ids := map[string]bool{}
+	for _, p := range key.VPCPeerings(cluster) {
+		if !strings.HasPrefix(p.ID, vpcIDPrefix) {
+			return microerror.Maskf(invalidConfigError, "peer VPC ID '%s' must start with '%s'", p.ID, vpcIDPrefix)
+		}
+		if ids[p.ID] {
+			return microerror.Maskf(invalidConfigError, "peer VPC '%s' must only be peered once", p.ID)
+		}
+		ids[p.ID] = true
+
+		if p.AccountID != "" && !awsAccountIDRegexp.MatchString(p.AccountID) {
+			return microerror.Maskf(invalidConfigError, "account ID '%s' of peer VPC '%s' must consist of 12 digits", p.AccountID, p.ID)
+		}
+		if p.AccountID != "" && p.RoleARN == "" {
+			return microerror.Maskf(invalidConfigError, "peer VPC '%s' in account '%s' must have a role ARN to accept the peering", p.ID, p.AccountID)
+		}
+
+		_, network, err := net.ParseCIDR(p.CIDR)
+		if err != nil {
+			return microerror.Maskf(invalidConfigError, "CIDR '%s' of peer VPC '%s' must be a valid CIDR", p.CIDR, p.ID)
+		}
+		for _, n := range networks {
+			if ipam.Overlap(*network, n.network) {
+				return microerror.Maskf(invalidConfigError, "CIDR %s of peer VPC '%s' must not overlap CIDR %s of %s", network.String(), p.ID, n.network.String(), n.description)
+			}
+		}
+		networks = append(networks, namedNetwork{description: fmt.Sprintf("peer VPC '%s'", p.ID), network: *network})
+	}
+
+	vpnGatewayID := key.VPNGatewayID(cluster)
+	if vpnGatewayID == "" && len(key.VPNGatewayCIDRs(cluster)) != 0 {
+		return microerror.Maskf(invalidConfigError, "virtual private gateway CIDRs must only be configured together with the virtual private gateway ID")
+	}
+	if vpnGatewayID != "" && !strings.HasPrefix(vpnGatewayID, vpnGatewayIDPrefix) {
+		return microerror.Maskf(invalidConfigError, "virtual private gateway ID '%s' must start with '%s'", vpnGatewayID, vpnGatewayIDPrefix)
+	}
+	for _, cidr := range key.VPNGatewayCIDRs(cluster) {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return microerror.Maskf(invalidConfigError, "virtual private gateway CIDR '%s' must be a valid CIDR", cidr)
+		}
+	}
+
+	return nil
+}
+
+// validateVPCFlowLogs ensures the VPC flow logs capture a known type of
+// traffic.
+func (r *Resource) validateVPCFlowLogs(cluster v1alpha1.AWSConfig) error {
+	switch key.VPCFlowLogsTrafficType(cluster) {
+	case key.VPCFlowLogsTrafficTypeAccept, key.VPCFlowLogsTrafficTypeAll, key.VPCFlowLogsTrafficTypeReject:
+		return nil
+	default:
+		return microerror.Maskf(invalidConfigError, "VPC flow logs traffic type '%s' must be one of '%s', '%s' or '%s'", key.VPCFlowLogsTrafficType(cluster), key.VPCFlowLogsTrafficTypeAccept, key.VPCFlowLogsTrafficTypeAll, key.VPCFlowLogsTrafficTypeReject)
+	}
+}
@@ -1,12 +1,19 @@
 package cloudformation
 
 import (
+	"context"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/tools/record"
 
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
 	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	"github.com/giantswarm/aws-operator/service/controller/v18/ami"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 )
 
 func Test_validateHostPeeringRoutes(t *testing.T) {
@@ -52,6 +59,8 @@ func Test_validateHostPeeringRoutes(t *testing.T) {
 
 			c := Config{}
 
+			c.EventRecorder = &record.FakeRecorder{}
+			c.ImageCatalog = ami.NewBuiltin()
 			c.HostClients = &adapter.Clients{
 				EC2:            ec2Mock,
 				CloudFormation: &adapter.CloudFormationMock{},
@@ -78,3 +87,1215 @@ func Test_validateHostPeeringRoutes(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateAvailabilityZones(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		aws           v1alpha1.AWSConfigSpecAWS
+		expectedError bool
+	}{
+		{
+			description: "no availability zones, do not expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				AZ: "eu-central-1a",
+			},
+			expectedError: false,
+		},
+		{
+			description: "AZ is the first availability zone, do not expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				AZ:                "eu-central-1a",
+				AvailabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.0.0/16",
+					PublicSubnetCIDR:  "10.1.0.0/18",
+					PrivateSubnetCIDR: "10.1.128.0/18",
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "AZ is not the first availability zone, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				AZ:                "eu-central-1b",
+				AvailabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.0.0/16",
+					PublicSubnetCIDR:  "10.1.0.0/18",
+					PrivateSubnetCIDR: "10.1.128.0/18",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "duplicated availability zone, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				AvailabilityZones: []string{"eu-central-1a", "eu-central-1a"},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.0.0/16",
+					PublicSubnetCIDR:  "10.1.0.0/18",
+					PrivateSubnetCIDR: "10.1.128.0/18",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "VPC CIDR too small, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				AvailabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					CIDR:              "10.1.0.0/24",
+					PublicSubnetCIDR:  "10.1.0.0/25",
+					PrivateSubnetCIDR: "10.1.0.128/25",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "existing VPC without VPC CIDR, do not expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				AvailabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+				VPC: v1alpha1.AWSConfigSpecAWSVPC{
+					ID: "vpc-1234",
+				},
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: tc.aws,
+				},
+			}
+
+			r := &Resource{}
+			err := r.validateAvailabilityZones(customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateLoadBalancerSettings(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		aws           v1alpha1.AWSConfigSpecAWS
+		expectedError bool
+	}{
+		{
+			description:   "no settings, do not expect error",
+			aws:           v1alpha1.AWSConfigSpecAWS{},
+			expectedError: false,
+		},
+		{
+			description: "long ingress idle timeout, do not expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				Ingress: v1alpha1.AWSConfigSpecAWSIngress{
+					ELB: v1alpha1.AWSConfigSpecAWSIngressELB{
+						IdleTimeoutSeconds: 3600,
+					},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "too long API idle timeout, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				API: v1alpha1.AWSConfigSpecAWSAPI{
+					ELB: v1alpha1.AWSConfigSpecAWSAPIELB{
+						IdleTimeoutSeconds: 4001,
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "tuned health check, do not expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				ELB: v1alpha1.AWSConfigSpecAWSELB{
+					HealthCheck: v1alpha1.AWSConfigSpecAWSELBHealthCheck{
+						HealthyThreshold:   3,
+						IntervalSeconds:    10,
+						TimeoutSeconds:     5,
+						UnhealthyThreshold: 5,
+					},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "health check threshold too high, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				ELB: v1alpha1.AWSConfigSpecAWSELB{
+					HealthCheck: v1alpha1.AWSConfigSpecAWSELBHealthCheck{
+						UnhealthyThreshold: 11,
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "health check timeout not lower than the interval, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				ELB: v1alpha1.AWSConfigSpecAWSELB{
+					HealthCheck: v1alpha1.AWSConfigSpecAWSELBHealthCheck{
+						IntervalSeconds: 10,
+						TimeoutSeconds:  10,
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "connection draining timeout too long, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				ELB: v1alpha1.AWSConfigSpecAWSELB{
+					ConnectionDraining: v1alpha1.AWSConfigSpecAWSELBConnectionDraining{
+						Enabled:        true,
+						TimeoutSeconds: 3601,
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "access logs emitted every 5 minutes, do not expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				ELB: v1alpha1.AWSConfigSpecAWSELB{
+					AccessLogs: v1alpha1.AWSConfigSpecAWSELBAccessLogs{
+						Enabled:             true,
+						EmitIntervalMinutes: 5,
+					},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "access logs emitted every 10 minutes, expect error",
+			aws: v1alpha1.AWSConfigSpecAWS{
+				ELB: v1alpha1.AWSConfigSpecAWSELB{
+					AccessLogs: v1alpha1.AWSConfigSpecAWSELBAccessLogs{
+						Enabled:             true,
+						EmitIntervalMinutes: 10,
+					},
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: tc.aws,
+				},
+			}
+
+			r := &Resource{}
+			err := r.validateLoadBalancerSettings(customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateLoadBalancerType(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description      string
+		loadBalancerType string
+		expectedError    bool
+	}{
+		{
+			description:      "no load balancer type, do not expect error",
+			loadBalancerType: "",
+			expectedError:    false,
+		},
+		{
+			description:      "classic load balancer type, do not expect error",
+			loadBalancerType: "classic",
+			expectedError:    false,
+		},
+		{
+			description:      "network load balancer type, do not expect error",
+			loadBalancerType: "network",
+			expectedError:    false,
+		},
+		{
+			description:      "unknown load balancer type, expect error",
+			loadBalancerType: "application",
+			expectedError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						LoadBalancerType: tc.loadBalancerType,
+					},
+				},
+			}
+
+			r := &Resource{}
+			err := r.validateLoadBalancerType(customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateNodePools(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		nodePools     []v1alpha1.AWSConfigSpecAWSNodePool
+		expectedError bool
+	}{
+		{
+			description:   "no node pools, do not expect error",
+			nodePools:     nil,
+			expectedError: false,
+		},
+		{
+			description: "valid node pools, do not expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name:    "general",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+				{
+					Name:    "memory-2",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}, {}},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "duplicated node pool name, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name:    "general",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+				{
+					Name:    "general",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "node pool names only differing in dashes, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name:    "gpu-1",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+				{
+					Name:    "gpu1",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "invalid node pool name, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name:    "General,Pool",
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "valid spot node pool, do not expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "spot",
+					Spot: v1alpha1.AWSConfigSpecAWSNodePoolSpot{
+						Enabled:                             true,
+						InstanceTypes:                       []string{"m5.xlarge"},
+						MaxPrice:                            "0.12",
+						OnDemandBaseCapacity:                1,
+						OnDemandPercentageAboveBaseCapacity: 20,
+					},
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}, {}},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "spot node pool with invalid max price, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "spot",
+					Spot: v1alpha1.AWSConfigSpecAWSNodePoolSpot{
+						Enabled:  true,
+						MaxPrice: "cheap",
+					},
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "spot node pool with on-demand base capacity exceeding its workers, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "spot",
+					Spot: v1alpha1.AWSConfigSpecAWSNodePoolSpot{
+						Enabled:              true,
+						OnDemandBaseCapacity: 3,
+					},
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "spot node pool with on-demand percentage above 100, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "spot",
+					Spot: v1alpha1.AWSConfigSpecAWSNodePoolSpot{
+						Enabled:                             true,
+						OnDemandPercentageAboveBaseCapacity: 101,
+					},
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "valid autoscaled node pool, do not expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "general",
+					Scaling: v1alpha1.ClusterScaling{
+						Max: 10,
+						Min: 3,
+					},
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "autoscaled node pool with scaling min of 0, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "general",
+					Scaling: v1alpha1.ClusterScaling{
+						Max: 10,
+						Min: 0,
+					},
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "autoscaled node pool with scaling max not exceeding scaling min, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "general",
+					Scaling: v1alpha1.ClusterScaling{
+						Max: 3,
+						Min: 3,
+					},
+					Workers: []v1alpha1.AWSConfigSpecAWSNode{{}},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "node pool without workers, expect error",
+			nodePools: []v1alpha1.AWSConfigSpecAWSNodePool{
+				{
+					Name: "general",
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						NodePools: tc.nodePools,
+					},
+				},
+			}
+
+			r := &Resource{}
+			err := r.validateNodePools(customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateExistingSubnet(t *testing.T) {
+	t.Parallel()
+	newSubnet := func(vpcID, az string, freeIPs int64) *ec2.Subnet {
+		return &ec2.Subnet{
+			AvailabilityZone:        aws.String(az),
+			AvailableIpAddressCount: aws.Int64(freeIPs),
+			SubnetId:                aws.String("subnet-1234"),
+			VpcId:                   aws.String(vpcID),
+		}
+	}
+	newRouteTable := func(routes ...*ec2.Route) *ec2.RouteTable {
+		return &ec2.RouteTable{
+			Routes: routes,
+		}
+	}
+	newRoute := func(cidr, gatewayID, natGatewayID, state string) *ec2.Route {
+		r := &ec2.Route{
+			DestinationCidrBlock: aws.String(cidr),
+			State:                aws.String(state),
+		}
+		if gatewayID != "" {
+			r.GatewayId = aws.String(gatewayID)
+		}
+		if natGatewayID != "" {
+			r.NatGatewayId = aws.String(natGatewayID)
+		}
+		return r
+	}
+
+	testCases := []struct {
+		description   string
+		subnet        *ec2.Subnet
+		routeTable    *ec2.RouteTable
+		public        bool
+		expectedError bool
+	}{
+		{
+			description:   "private subnet with default route through NAT gateway, do not expect error",
+			subnet:        newSubnet("vpc-1234", "eu-central-1a", 100),
+			routeTable:    newRouteTable(newRoute("10.0.0.0/16", "local", "", "active"), newRoute("0.0.0.0/0", "", "nat-1234", "active")),
+			public:        false,
+			expectedError: false,
+		},
+		{
+			description:   "public subnet with default route through internet gateway, do not expect error",
+			subnet:        newSubnet("vpc-1234", "eu-central-1a", 100),
+			routeTable:    newRouteTable(newRoute("0.0.0.0/0", "igw-1234", "", "active")),
+			public:        true,
+			expectedError: false,
+		},
+		{
+			description:   "public subnet with default route through NAT gateway, expect error",
+			subnet:        newSubnet("vpc-1234", "eu-central-1a", 100),
+			routeTable:    newRouteTable(newRoute("0.0.0.0/0", "", "nat-1234", "active")),
+			public:        true,
+			expectedError: true,
+		},
+		{
+			description:   "private subnet with blackholed default route, expect error",
+			subnet:        newSubnet("vpc-1234", "eu-central-1a", 100),
+			routeTable:    newRouteTable(newRoute("0.0.0.0/0", "", "nat-1234", "blackhole")),
+			public:        false,
+			expectedError: true,
+		},
+		{
+			description:   "private subnet without default route, expect error",
+			subnet:        newSubnet("vpc-1234", "eu-central-1a", 100),
+			routeTable:    newRouteTable(newRoute("10.0.0.0/16", "local", "", "active")),
+			public:        false,
+			expectedError: true,
+		},
+		{
+			description:   "subnet of other VPC, expect error",
+			subnet:        newSubnet("vpc-5678", "eu-central-1a", 100),
+			routeTable:    newRouteTable(newRoute("0.0.0.0/0", "", "nat-1234", "active")),
+			public:        false,
+			expectedError: true,
+		},
+		{
+			description:   "subnet in other availability zone, expect error",
+			subnet:        newSubnet("vpc-1234", "eu-central-1b", 100),
+			routeTable:    newRouteTable(newRoute("0.0.0.0/0", "", "nat-1234", "active")),
+			public:        false,
+			expectedError: true,
+		},
+		{
+			description:   "subnet without enough free IP addresses, expect error",
+			subnet:        newSubnet("vpc-1234", "eu-central-1a", 7),
+			routeTable:    newRouteTable(newRoute("0.0.0.0/0", "", "nat-1234", "active")),
+			public:        false,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := validateExistingSubnet(tc.subnet, tc.routeTable, "vpc-1234", "eu-central-1a", tc.public, 8)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateVPCFlowLogs(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		trafficType   string
+		expectedError bool
+	}{
+		{
+			description:   "no traffic type, do not expect error",
+			trafficType:   "",
+			expectedError: false,
+		},
+		{
+			description:   "accepted traffic, do not expect error",
+			trafficType:   "ACCEPT",
+			expectedError: false,
+		},
+		{
+			description:   "rejected traffic, do not expect error",
+			trafficType:   "REJECT",
+			expectedError: false,
+		},
+		{
+			description:   "unknown traffic type, expect error",
+			trafficType:   "all",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							FlowLogs: v1alpha1.AWSConfigSpecAWSVPCFlowLogs{
+								TrafficType: tc.trafficType,
+							},
+						},
+					},
+				},
+			}
+
+			r := &Resource{}
+			err := r.validateVPCFlowLogs(customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateSecurityGroups(t *testing.T) {
+	t.Parallel()
+	validRule := v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+		Description: "Allow NodePort services from the office.",
+		FromPort:    30000,
+		ToPort:      32767,
+		Protocol:    "tcp",
+		SourceCIDR:  "10.2.0.0/16",
+	}
+
+	testCases := []struct {
+		description    string
+		whitelist      []string
+		securityGroups v1alpha1.AWSConfigSpecAWSSecurityGroups
+		expectedError  bool
+	}{
+		{
+			description:   "nothing configured, do not expect error",
+			expectedError: false,
+		},
+		{
+			description: "valid whitelist and rules, do not expect error",
+			whitelist:   []string{"212.145.136.84/32"},
+			securityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+				Ingress: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{validRule},
+				Master:  []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{validRule},
+				Worker: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+					{
+						FromPort:   9100,
+						Protocol:   "udp",
+						SourceCIDR: "10.2.0.0/16",
+					},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description:   "invalid whitelist CIDR, expect error",
+			whitelist:     []string{"212.145.136.84"},
+			expectedError: true,
+		},
+		{
+			description: "unknown protocol, expect error",
+			securityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+				Worker: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+					{
+						FromPort:   9100,
+						Protocol:   "icmp",
+						SourceCIDR: "10.2.0.0/16",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "missing port, expect error",
+			securityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+				Master: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+					{
+						Protocol:   "tcp",
+						SourceCIDR: "10.2.0.0/16",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "to port lower than from port, expect error",
+			securityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+				Ingress: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+					{
+						FromPort:   8080,
+						ToPort:     8000,
+						Protocol:   "tcp",
+						SourceCIDR: "10.2.0.0/16",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "invalid source CIDR, expect error",
+			securityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+				Worker: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+					{
+						FromPort:   9100,
+						Protocol:   "tcp",
+						SourceCIDR: "10.2.0.0/33",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "description with quotes, expect error",
+			securityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+				Master: []v1alpha1.AWSConfigSpecAWSSecurityGroupRule{
+					{
+						Description: "Allow the office's VPN.",
+						FromPort:    9100,
+						Protocol:    "tcp",
+						SourceCIDR:  "10.2.0.0/16",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "too many master rules including the whitelist, expect error",
+			whitelist:   []string{"212.145.136.84/32"},
+			securityGroups: v1alpha1.AWSConfigSpecAWSSecurityGroups{
+				Master: func() []v1alpha1.AWSConfigSpecAWSSecurityGroupRule {
+					var rules []v1alpha1.AWSConfigSpecAWSSecurityGroupRule
+					for i := 0; i < securityGroupRulesMax; i++ {
+						rules = append(rules, validRule)
+					}
+					return rules
+				}(),
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Whitelist: tc.whitelist,
+						},
+						SecurityGroups: tc.securityGroups,
+					},
+				},
+			}
+
+			r := &Resource{}
+			err := r.validateSecurityGroups(customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validatePrivateHostedZone(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description    string
+		apiPrivate     bool
+		private        v1alpha1.AWSConfigSpecAWSHostedZonesPrivate
+		route53Enabled bool
+		expectedError  bool
+	}{
+		{
+			description:    "private hosted zone disabled, do not expect error",
+			route53Enabled: false,
+			expectedError:  false,
+		},
+		{
+			description:    "private hosted zone associated with host cluster VPC, do not expect error",
+			private:        v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true, HostVPC: true},
+			route53Enabled: true,
+			expectedError:  false,
+		},
+		{
+			description:    "private hosted zone enabled without route53, expect error",
+			private:        v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true},
+			route53Enabled: false,
+			expectedError:  true,
+		},
+		{
+			description:    "host cluster VPC association without private hosted zone, expect error",
+			private:        v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{HostVPC: true},
+			route53Enabled: true,
+			expectedError:  true,
+		},
+		{
+			description:    "private API with private hosted zone associated with host cluster VPC, do not expect error",
+			apiPrivate:     true,
+			private:        v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true, HostVPC: true},
+			route53Enabled: true,
+			expectedError:  false,
+		},
+		{
+			description:    "private API with private hosted zone not associated with host cluster VPC, expect error",
+			apiPrivate:     true,
+			private:        v1alpha1.AWSConfigSpecAWSHostedZonesPrivate{Enabled: true},
+			route53Enabled: true,
+			expectedError:  true,
+		},
+		{
+			description:    "private API without private hosted zone, expect error",
+			apiPrivate:     true,
+			route53Enabled: true,
+			expectedError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						API: v1alpha1.AWSConfigSpecAWSAPI{
+							Private: tc.apiPrivate,
+						},
+						HostedZones: v1alpha1.AWSConfigSpecAWSHostedZones{
+							Private: tc.private,
+						},
+					},
+				},
+			}
+
+			r := &Resource{
+				route53Enabled: tc.route53Enabled,
+			}
+			err := r.validatePrivateHostedZone(customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateVPCConnections(t *testing.T) {
+	t.Parallel()
+	validPeering := v1alpha1.AWSConfigSpecAWSVPCPeering{
+		AccountID: "123456789012",
+		CIDR:      "10.100.0.0/16",
+		ID:        "vpc-0a1b2c3d",
+		Region:    "eu-west-1",
+		RoleARN:   "arn:aws:iam::123456789012:role/peering",
+	}
+
+	testCases := []struct {
+		description     string
+		vpc             v1alpha1.AWSConfigSpecAWSVPC
+		existingVPCCIDR string
+		expectedError   bool
+	}{
+		{
+			description:   "nothing configured, do not expect error",
+			vpc:           v1alpha1.AWSConfigSpecAWSVPC{CIDR: "10.1.0.0/16"},
+			expectedError: false,
+		},
+		{
+			description: "peer CIDR overlapping the host cluster VPC, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+					{
+						CIDR: "10.0.128.0/17",
+						ID:   "vpc-0a1b2c3d",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "peer CIDR distinct from the existing VPC, do not expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				ID:       "vpc-existing",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{validPeering},
+			},
+			existingVPCCIDR: "10.2.0.0/16",
+			expectedError:   false,
+		},
+		{
+			description: "peer CIDR overlapping the existing VPC, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				ID:       "vpc-existing",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{validPeering},
+			},
+			existingVPCCIDR: "10.100.0.0/24",
+			expectedError:   true,
+		},
+		{
+			description: "valid peerings and virtual private gateway, do not expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+					validPeering,
+					{
+						CIDR: "10.101.0.0/16",
+						ID:   "vpc-1a2b3c4d",
+					},
+				},
+				VPNGateway: v1alpha1.AWSConfigSpecAWSVPCVPNGateway{
+					ID:    "vgw-0a1b2c3d",
+					CIDRs: []string{"192.168.0.0/16"},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			description: "invalid peer VPC ID, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+					{
+						CIDR: "10.100.0.0/16",
+						ID:   "subnet-0a1b2c3d",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "peer VPC peered twice, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:     "10.1.0.0/16",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{validPeering, validPeering},
+			},
+			expectedError: true,
+		},
+		{
+			description: "invalid account ID, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+					{
+						AccountID: "1234",
+						CIDR:      "10.100.0.0/16",
+						ID:        "vpc-0a1b2c3d",
+						RoleARN:   "arn:aws:iam::123456789012:role/peering",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "other account without role ARN, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+					{
+						AccountID: "123456789012",
+						CIDR:      "10.100.0.0/16",
+						ID:        "vpc-0a1b2c3d",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "peer CIDR overlapping the guest cluster VPC, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:     "10.100.0.0/24",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{validPeering},
+			},
+			expectedError: true,
+		},
+		{
+			description: "overlapping peer CIDRs, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				Peerings: []v1alpha1.AWSConfigSpecAWSVPCPeering{
+					validPeering,
+					{
+						CIDR: "10.100.128.0/17",
+						ID:   "vpc-1a2b3c4d",
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "invalid virtual private gateway ID, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				VPNGateway: v1alpha1.AWSConfigSpecAWSVPCVPNGateway{
+					ID: "igw-0a1b2c3d",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "virtual private gateway CIDRs without ID, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				VPNGateway: v1alpha1.AWSConfigSpecAWSVPCVPNGateway{
+					CIDRs: []string{"192.168.0.0/16"},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			description: "invalid virtual private gateway CIDR, expect error",
+			vpc: v1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.0.0/16",
+				VPNGateway: v1alpha1.AWSConfigSpecAWSVPCVPNGateway{
+					ID:    "vgw-0a1b2c3d",
+					CIDRs: []string{"192.168.0.0"},
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			hostEC2Mock := &adapter.EC2ClientMock{}
+			hostEC2Mock.SetVPCCIDR("10.0.0.0/16")
+
+			guestEC2Mock := &adapter.EC2ClientMock{}
+			guestEC2Mock.SetVPCCIDR(tc.existingVPCCIDR)
+
+			r := &Resource{
+				hostClients: &adapter.Clients{
+					EC2: hostEC2Mock,
+				},
+			}
+
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						VPC: tc.vpc,
+					},
+				},
+			}
+			customObject.Spec.AWS.VPC.PeerID = "vpc-host"
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{AWSClient: awsclient.Clients{EC2: guestEC2Mock}})
+
+			err := r.validateVPCConnections(ctx, customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateVPCCIDR(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		vpcCIDR       string
+		hostVPCCIDR   string
+		expectedError bool
+	}{
+		{
+			description:   "distinct networks, do not expect error",
+			vpcCIDR:       "10.1.0.0/24",
+			hostVPCCIDR:   "10.0.0.0/16",
+			expectedError: false,
+		},
+		{
+			description:   "adjacent networks, do not expect error",
+			vpcCIDR:       "10.1.0.0/16",
+			hostVPCCIDR:   "10.0.0.0/16",
+			expectedError: false,
+		},
+		{
+			description:   "VPC CIDR within host cluster VPC, expect error",
+			vpcCIDR:       "10.0.1.0/24",
+			hostVPCCIDR:   "10.0.0.0/16",
+			expectedError: true,
+		},
+		{
+			description:   "VPC CIDR containing host cluster VPC, expect error",
+			vpcCIDR:       "10.0.0.0/8",
+			hostVPCCIDR:   "10.0.0.0/16",
+			expectedError: true,
+		},
+		{
+			description:   "missing VPC CIDR, expect error",
+			vpcCIDR:       "",
+			hostVPCCIDR:   "10.0.0.0/16",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ec2Mock := &adapter.EC2ClientMock{}
+			ec2Mock.SetVPCCIDR(tc.hostVPCCIDR)
+
+			r := &Resource{
+				hostClients: &adapter.Clients{
+					EC2: ec2Mock,
+				},
+			}
+
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR:   tc.vpcCIDR,
+							PeerID: "vpc-host",
+						},
+					},
+				},
+			}
+
+			err := r.validateVPCCIDR(context.Background(), customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func Test_validateTransitGatewayNetwork(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description   string
+		vpcCIDR       string
+		ipamNetwork   string
+		expectedError bool
+	}{
+		{
+			description:   "VPC CIDR within IPAM network, do not expect error",
+			vpcCIDR:       "10.1.0.0/24",
+			ipamNetwork:   "10.1.0.0/16",
+			expectedError: false,
+		},
+		{
+			description:   "VPC CIDR equal to IPAM network, do not expect error",
+			vpcCIDR:       "10.1.0.0/16",
+			ipamNetwork:   "10.1.0.0/16",
+			expectedError: false,
+		},
+		{
+			description:   "VPC CIDR outside IPAM network, expect error",
+			vpcCIDR:       "10.2.0.0/24",
+			ipamNetwork:   "10.1.0.0/16",
+			expectedError: true,
+		},
+		{
+			description:   "VPC CIDR containing IPAM network, expect error",
+			vpcCIDR:       "10.0.0.0/8",
+			ipamNetwork:   "10.1.0.0/16",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := &Resource{
+				ipamNetwork: tc.ipamNetwork,
+			}
+
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							CIDR: tc.vpcCIDR,
+						},
+					},
+				},
+			}
+
+			err := r.validateTransitGatewayNetwork(context.Background(), customObject)
+			if tc.expectedError && err == nil {
+				t.Fatalf("expected error didn't happen")
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package delegation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v18/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/dns"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+const (
+	name = "delegationv18"
+)
+
+const (
+	// delegationTTL is the TTL of the NS records delegating the guest cluster
+	// zone. It matches the TTL of the delegation in the host cluster post stack.
+	delegationTTL = 900
+)
+
+type Config struct {
+	DNS    dns.Interface
+	Logger micrologger.Logger
+
+	DNSProvider    string
+	Route53Enabled bool
+}
+
+// Resource delegates the guest cluster zone from the zone of the installation
+// base domain in case the latter is not managed in Route53 of the host cluster
+// account, e.g. when it is served by an on-prem DNS server. The delegation to
+// Route53 zones is managed by the host cluster post stack instead.
+type Resource struct {
+	dns    dns.Interface
+	logger micrologger.Logger
+
+	dnsProvider    string
+	route53Enabled bool
+}
+
+func New(config Config) (*Resource, error) {
+	if config.DNS == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DNS must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.DNSProvider == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.DNSProvider must not be empty", config)
+	}
+
+	r := &Resource{
+		dns:    config.DNS,
+		logger: config.Logger,
+
+		dnsProvider:    config.DNSProvider,
+		route53Enabled: config.Route53Enabled,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return name
+}
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !r.managesDelegation(ctx) {
+		return nil
+	}
+
+	parentZone := key.BaseDomain(customObject)
+	zone := key.ClusterID(customObject) + ".k8s." + parentZone
+
+	var desired dns.Delegation
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the guest cluster zone name servers in the cloud formation stack")
+
+		controllerCtx, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		stackOutputs, stackStatus, err := controllerCtx.CloudFormation.DescribeOutputsAndStatus(key.MainGuestStackName(customObject))
+		if cloudformationservice.IsStackNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster zone name servers in the cloud formation stack")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack is not yet created")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+
+		} else if cloudformationservice.IsOutputsNotAccessible(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster zone name servers in the cloud formation stack")
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("the guest cluster main stack output values are not accessible due to stack status '%s'", stackStatus))
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		nameServers, err := controllerCtx.CloudFormation.GetOutputValue(stackOutputs, key.HostedZoneNameServers)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		desired = dns.Delegation{
+			NameServers: strings.Split(nameServers, ","),
+			TTL:         delegationTTL,
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "found the guest cluster zone name servers in the cloud formation stack")
+	}
+
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding the delegation of the guest cluster zone from zone %#q", parentZone))
+
+		current, err := r.dns.FindDelegation(ctx, parentZone, zone)
+		if dns.IsDelegationNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find the delegation of the guest cluster zone from zone %#q", parentZone))
+
+		} else if err != nil {
+			return microerror.Mask(err)
+
+		} else if equalDelegations(current, desired) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found the delegation of the guest cluster zone from zone %#q", parentZone))
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the delegation is up to date")
+			return nil
+
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found outdated delegation of the guest cluster zone from zone %#q", parentZone))
+		}
+	}
+
+	err = r.dns.EnsureCreatedDelegation(ctx, parentZone, zone, desired)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !r.managesDelegation(ctx) {
+		return nil
+	}
+
+	parentZone := key.BaseDomain(customObject)
+	zone := key.ClusterID(customObject) + ".k8s." + parentZone
+
+	err = r.dns.EnsureDeletedDelegation(ctx, parentZone, zone)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) managesDelegation(ctx context.Context) bool {
+	if !r.route53Enabled {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "route53 disabled")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return false
+	}
+	if r.dnsProvider == dns.Route53Provider {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "the delegation of the guest cluster zone is managed by the host cluster post stack")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return false
+	}
+
+	return true
+}
+
+// equalDelegations compares delegations regardless of the order and the
+// trailing dots of their name servers.
+func equalDelegations(a, b dns.Delegation) bool {
+	if a.TTL != b.TTL || len(a.NameServers) != len(b.NameServers) {
+		return false
+	}
+
+	normalize := func(nameServers []string) []string {
+		var n []string
+		for _, ns := range nameServers {
+			n = append(n, strings.ToLower(strings.TrimSuffix(ns, ".")))
+		}
+		sort.Strings(n)
+		return n
+	}
+
+	an := normalize(a.NameServers)
+	bn := normalize(b.NameServers)
+	for i := range an {
+		if an[i] != bn[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,86 @@
+package delegation
+
+import (
+	"testing"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/dns"
+)
+
+func Test_equalDelegations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		a        dns.Delegation
+		b        dns.Delegation
+		expected bool
+	}{
+		{
+			name: "case 0: equal delegations",
+			a: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org.", "ns-2.awsdns-2.com."},
+				TTL:         900,
+			},
+			b: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org.", "ns-2.awsdns-2.com."},
+				TTL:         900,
+			},
+			expected: true,
+		},
+		{
+			name: "case 1: name servers in different order and without trailing dots",
+			a: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org.", "ns-2.awsdns-2.com."},
+				TTL:         900,
+			},
+			b: dns.Delegation{
+				NameServers: []string{"ns-2.awsdns-2.com", "NS-1.awsdns-1.org"},
+				TTL:         900,
+			},
+			expected: true,
+		},
+		{
+			name: "case 2: different name servers",
+			a: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org.", "ns-2.awsdns-2.com."},
+				TTL:         900,
+			},
+			b: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org.", "ns-3.awsdns-3.net."},
+				TTL:         900,
+			},
+			expected: false,
+		},
+		{
+			name: "case 3: different number of name servers",
+			a: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org.", "ns-2.awsdns-2.com."},
+				TTL:         900,
+			},
+			b: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org."},
+				TTL:         900,
+			},
+			expected: false,
+		},
+		{
+			name: "case 4: different TTL",
+			a: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org."},
+				TTL:         900,
+			},
+			b: dns.Delegation{
+				NameServers: []string{"ns-1.awsdns-1.org."},
+				TTL:         300,
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := equalDelegations(tc.a, tc.b)
+			if result != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, result)
+			}
+		})
+	}
+}
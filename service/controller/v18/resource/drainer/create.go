@@ -18,7 +18,9 @@ import (
 )
 
 // EnsureCreated creates DrainerConfigs for ASG instances in terminating/wait
-// state then lets node-operator to do its job.
+// state then lets node-operator to do its job. Spot instances receiving an
+// interruption notice are drained the same way, since AWS terminates them two
+// minutes after the notice.
 func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
@@ -30,21 +32,23 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	workerASGName := controllerCtx.Status.Drainer.WorkerASGName
-	if workerASGName == "" {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG name is not available yet")
+	workerASGNames := controllerCtx.Status.Drainer.WorkerASGNames
+	if len(workerASGNames) == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG names are not available yet")
 		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
 		return nil
 	}
 
 	var instances []*autoscaling.Instance
+	var inServiceInstances []*autoscaling.Instance
+	// asgNames maps the IDs of the instances found to the names of the ASGs
+	// they belong to.
+	asgNames := map[string]string{}
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding the guest cluster nodes being in state %#q", autoscaling.LifecycleStateTerminatingWait))
 
 		i := &autoscaling.DescribeAutoScalingGroupsInput{
-			AutoScalingGroupNames: []*string{
-				aws.String(workerASGName),
-			},
+			AutoScalingGroupNames: aws.StringSlice(workerASGNames),
 		}
 
 		o, err := controllerCtx.AWSClient.AutoScaling.DescribeAutoScalingGroups(i)
@@ -57,20 +61,36 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 				if *i.LifecycleState == autoscaling.LifecycleStateTerminatingWait {
 					instances = append(instances, i)
 				}
+				if *i.LifecycleState == autoscaling.LifecycleStateInService {
+					inServiceInstances = append(inServiceInstances, i)
+				}
+				asgNames[*i.InstanceId] = *g.AutoScalingGroupName
 			}
 		}
 
-		if len(instances) == 0 {
-			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find the guest cluster nodes being in state %#q", autoscaling.LifecycleStateTerminatingWait))
-			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
-			return nil
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d guest cluster nodes being in state %#q", len(instances), autoscaling.LifecycleStateTerminatingWait))
+	}
+
+	if key.HasSpotNodePools(customObject) && len(inServiceInstances) > 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the guest cluster spot instances marked for termination")
+
+		interrupted, err := r.spotInterruptedInstances(ctx, inServiceInstances)
+		if err != nil {
+			return microerror.Mask(err)
 		}
+		instances = append(instances, interrupted...)
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d guest cluster nodes being in state %#q", len(instances), autoscaling.LifecycleStateTerminatingWait))
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d guest cluster spot instances marked for termination", len(interrupted)))
+	}
+
+	if len(instances) == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the guest cluster nodes to be drained")
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+		return nil
 	}
 
 	{
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensuring drainer configs for %d guest cluster nodes being in state %#q", len(instances), autoscaling.LifecycleStateTerminatingWait))
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensuring drainer configs for %d guest cluster nodes to be drained", len(instances)))
 
 		for _, instance := range instances {
 			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("finding drainer config for guest cluster nodes %#q", *instance.InstanceId))
@@ -98,7 +118,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			if errors.IsNotFound(err) {
 				r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find drainer config for guest cluster node %#q", *instance.InstanceId))
 
-				err := r.createDrainerConfig(ctx, customObject, *instance.InstanceId, privateDNS)
+				err := r.createDrainerConfig(ctx, customObject, *instance.InstanceId, asgNames[*instance.InstanceId], privateDNS)
 				if err != nil {
 					return microerror.Mask(err)
 				}
@@ -110,19 +130,20 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found drainer config for guest cluster node %#q", *instance.InstanceId))
 		}
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured drainer configs for %d guest cluster nodes being in state %#q", len(instances), autoscaling.LifecycleStateTerminatingWait))
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("ensured drainer configs for %d guest cluster nodes to be drained", len(instances)))
 	}
 
 	return nil
 }
 
-func (r *Resource) createDrainerConfig(ctx context.Context, customObject providerv1alpha1.AWSConfig, instanceID, privateDNS string) error {
+func (r *Resource) createDrainerConfig(ctx context.Context, customObject providerv1alpha1.AWSConfig, instanceID, asgName, privateDNS string) error {
 	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating drainer config for guest cluster nodes %#q", instanceID))
 
 	n := customObject.GetNamespace()
 	c := &corev1alpha1.DrainerConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations: map[string]string{
+				key.ASGNameAnnotation:    asgName,
 				key.InstanceIDAnnotation: instanceID,
 			},
 			Labels: map[string]string{
@@ -185,3 +206,54 @@ func (r *Resource) privateDNSForInstance(ctx context.Context, instanceID string)
 
 	return privateDNS, nil
 }
+
+// spotInterruptedInstances returns the given instances running on spot
+// capacity which received an interruption notice. The spot instance requests of
+// these instances are marked for termination.
+func (r *Resource) spotInterruptedInstances(ctx context.Context, instances []*autoscaling.Instance) ([]*autoscaling.Instance, error) {
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var instanceIDs []*string
+	for _, i := range instances {
+		instanceIDs = append(instanceIDs, i.InstanceId)
+	}
+
+	i := &ec2.DescribeSpotInstanceRequestsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-id"),
+				Values: instanceIDs,
+			},
+			{
+				Name: aws.String("status-code"),
+				Values: []*string{
+					aws.String(spotStatusCodeMarkedForTermination),
+				},
+			},
+		},
+	}
+
+	o, err := controllerCtx.AWSClient.EC2.DescribeSpotInstanceRequests(i)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	marked := map[string]bool{}
+	for _, s := range o.SpotInstanceRequests {
+		if s.InstanceId != nil {
+			marked[*s.InstanceId] = true
+		}
+	}
+
+	var interrupted []*autoscaling.Instance
+	for _, i := range instances {
+		if marked[*i.InstanceId] {
+			interrupted = append(interrupted, i)
+		}
+	}
+
+	return interrupted, nil
+}
@@ -8,6 +8,10 @@ import (
 
 const (
 	Name = "drainerv18"
+
+	// spotStatusCodeMarkedForTermination is the status code of spot instance
+	// requests whose instances received an interruption notice.
+	spotStatusCodeMarkedForTermination = "marked-for-termination"
 )
 
 type ResourceConfig struct {
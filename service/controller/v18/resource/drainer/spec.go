@@ -3,6 +3,6 @@ package drainer
 import "github.com/aws/aws-sdk-go/service/autoscaling"
 
 type StackState struct {
-	Instances     []*autoscaling.Instance
-	WorkerASGName string
+	Instances      []*autoscaling.Instance
+	WorkerASGNames []string
 }
@@ -28,9 +28,9 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	workerASGName := controllerCtx.Status.Drainer.WorkerASGName
-	if workerASGName == "" {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG name is not available yet")
+	workerASGNames := controllerCtx.Status.Drainer.WorkerASGNames
+	if len(workerASGNames) == 0 {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "worker ASG names are not available yet")
 		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
 		return nil
 	}
@@ -84,7 +84,15 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 				return microerror.Mask(err)
 			}
 
-			err = r.completeLifecycleHook(ctx, instanceID, workerASGName)
+			// Drainer configs created before node pools were supported do not
+			// transport the ASG name. Their instances belong to the ASG of the
+			// first node pool.
+			asgName := asgNameFromAnnotations(drainerConfig.GetAnnotations())
+			if asgName == "" {
+				asgName = workerASGNames[0]
+			}
+
+			err = r.completeLifecycleHook(ctx, instanceID, asgName)
 			if err != nil {
 				return microerror.Mask(err)
 			}
@@ -155,3 +163,7 @@ func instanceIDFromAnnotations(annotations map[string]string) (string, error) {
 
 	return instanceID, nil
 }
+
+func asgNameFromAnnotations(annotations map[string]string) string {
+	return annotations[key.ASGNameAnnotation]
+}
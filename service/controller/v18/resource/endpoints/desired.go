@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"sort"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
@@ -17,8 +18,8 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
-	instanceName := key.MasterInstanceName(customObject)
-	masterInstance, err := r.findMasterInstance(ctx, instanceName)
+	instanceNames := key.MasterInstanceNames(customObject)
+	masterInstances, err := r.findMasterInstances(ctx, instanceNames)
 	if IsNotFound(err) {
 		// During updates the master instance is shut down and thus cannot be found.
 		// In such cases we cancel the reconciliation for the endpoint resource.
@@ -38,6 +39,21 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
+	// Every running master is published. Masters being replaced during updates
+	// are not running and thus removed from the endpoints until they are back.
+	// The addresses are sorted to keep the endpoints stable across
+	// reconciliations.
+	var addresses []v1.EndpointAddress
+	for _, i := range masterInstances {
+		a := v1.EndpointAddress{
+			IP: *i.PrivateIpAddress,
+		}
+		addresses = append(addresses, a)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].IP < addresses[j].IP
+	})
+
 	endpoints := &v1.Endpoints{
 		ObjectMeta: apismetav1.ObjectMeta{
 			Name:      masterEndpointsName,
@@ -50,11 +66,7 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		},
 		Subsets: []v1.EndpointSubset{
 			{
-				Addresses: []v1.EndpointAddress{
-					{
-						IP: *masterInstance.PrivateIpAddress,
-					},
-				},
+				Addresses: addresses,
 				Ports: []v1.EndpointPort{
 					{
 						Port: httpsPort,
@@ -17,7 +17,9 @@ const (
 	tagKeyName      = "Name"
 )
 
-func (r Resource) findMasterInstance(ctx context.Context, instanceName string) (*ec2.Instance, error) {
+// findMasterInstances returns all running master instances of the guest
+// cluster, looked up by their Name tags.
+func (r Resource) findMasterInstances(ctx context.Context, instanceNames []string) ([]*ec2.Instance, error) {
 
 	sc, err := controllercontext.FromContext(ctx)
 	if err != nil {
@@ -26,10 +28,8 @@ func (r Resource) findMasterInstance(ctx context.Context, instanceName string) (
 
 	filters := []*ec2.Filter{
 		{
-			Name: aws.String(fmt.Sprintf("tag:%s", tagKeyName)),
-			Values: []*string{
-				aws.String(instanceName),
-			},
+			Name:   aws.String(fmt.Sprintf("tag:%s", tagKeyName)),
+			Values: aws.StringSlice(instanceNames),
 		},
 	}
 
@@ -40,23 +40,21 @@ func (r Resource) findMasterInstance(ctx context.Context, instanceName string) (
 		return nil, microerror.Mask(err)
 	}
 
-	var masterInstance *ec2.Instance
-	var instancesFound int
+	var masterInstances []*ec2.Instance
 	for _, reservation := range output.Reservations {
 		for _, instance := range reservation.Instances {
 			if *instance.State.Code == ec2RunningState {
-				masterInstance = instance
-				instancesFound++
+				masterInstances = append(masterInstances, instance)
 			}
 		}
 	}
 
-	if instancesFound < 1 {
-		return nil, microerror.Maskf(notFoundError, "instance: %s", instanceName)
+	if len(masterInstances) < 1 {
+		return nil, microerror.Maskf(notFoundError, "instances: %v", instanceNames)
 	}
-	if instancesFound > 1 {
-		return nil, microerror.Maskf(tooManyResultsError, "instances: %s", instanceName)
+	if len(masterInstances) > len(instanceNames) {
+		return nil, microerror.Maskf(tooManyResultsError, "instances: %v", instanceNames)
 	}
 
-	return masterInstance, nil
+	return masterInstances, nil
 }
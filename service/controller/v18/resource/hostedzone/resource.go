@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/dns"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
@@ -21,6 +23,10 @@ type Config struct {
 	HostRoute53 *route53.Route53
 	Logger      micrologger.Logger
 
+	// DNSProvider is the DNS provider managing the zone of the installation
+	// base domain. The host cluster hosted zones are only looked up in case it
+	// is Route53.
+	DNSProvider    string
 	Route53Enabled bool
 }
 
@@ -28,6 +34,7 @@ type Resource struct {
 	hostRoute53 *route53.Route53
 	logger      micrologger.Logger
 
+	dnsProvider    string
 	route53Enabled bool
 }
 
@@ -43,6 +50,7 @@ func New(config Config) (*Resource, error) {
 		hostRoute53: config.HostRoute53,
 		logger:      config.Logger,
 
+		dnsProvider:    config.DNSProvider,
 		route53Enabled: config.Route53Enabled,
 	}
 
@@ -71,13 +79,17 @@ func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 	return nil
 }
 
-// setStatus searches for HostedZone in AWS API by name for their IDs. Those
-// IDs are set in controller context status for further use.
+// setStatus searches for the public HostedZones in AWS API by name for their
+// IDs. Those IDs are set in controller context status for further use.
 func (r *Resource) setStatus(ctx context.Context, obj interface{}) error {
 	if !r.route53Enabled {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "route53 disabled, skipping execution")
 		return nil
 	}
+	if r.dnsProvider != dns.Route53Provider {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("base domain zone managed by DNS provider %#q, skipping execution", r.dnsProvider))
+		return nil
+	}
 
 	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
@@ -116,6 +128,12 @@ func (r *Resource) setStatus(ctx context.Context, obj interface{}) error {
 			if hz.Name == nil || hz.Id == nil {
 				continue
 			}
+			// Private hosted zones may have the same name as the public ones
+			// the guest cluster domains are delegated from. They cannot be
+			// delegated from and are therefore skipped.
+			if hz.Config != nil && aws.BoolValue(hz.Config.PrivateZone) {
+				continue
+			}
 
 			hzName := *hz.Name
 			hzName = strings.TrimSuffix(hzName, ".")
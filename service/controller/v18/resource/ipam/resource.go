@@ -0,0 +1,368 @@
+// Package ipam provides an operatorkit resource that allocates the VPC CIDRs
+// of guest clusters which do not configure them in their custom object. VPC
+// CIDRs are allocated from the network configured for the installation, so
+// that guest clusters neither overlap each other nor the host cluster VPC. The
+// allocations are recorded in a config map and freed when the guest cluster is
+// deleted.
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/aws-operator/pkg/ipam"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+const (
+	name = "ipamv18"
+
+	// allocationsConfigMapName is the name of the config map recording the VPC
+	// CIDRs allocated for guest clusters, keyed by cluster ID.
+	allocationsConfigMapName      = "aws-operator-ipam-allocations"
+	allocationsConfigMapNamespace = "giantswarm"
+
+	// subnetPrefixMax is the prefix length of the smallest subnet CIDRs AWS
+	// allows.
+	subnetPrefixMax = 28
+	// vpcPrefixMax and vpcPrefixMin are the prefix lengths of the smallest and
+	// largest VPC CIDRs allocated. AWS allows VPC CIDRs up to /28, but the
+	// smallest VPC CIDR has to fit a public and a private subnet of the smallest
+	// size AWS allows.
+	vpcPrefixMax = subnetPrefixMax - 1
+	vpcPrefixMin = 16
+)
+
+// EC2 describes the methods required to be implemented by the EC2 client used
+// to look up the CIDR of the host cluster VPC.
+type EC2 interface {
+	DescribeVpcs(*ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error)
+}
+
+type Config struct {
+	G8sClient versioned.Interface
+	HostEC2   EC2
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Network is the CIDR of the network VPC CIDRs of guest clusters are
+	// allocated from. Allocation is disabled when it is empty.
+	Network string
+	// VPCPrefix is the prefix length of the allocated VPC CIDRs.
+	VPCPrefix int
+}
+
+type Resource struct {
+	g8sClient versioned.Interface
+	hostEC2   EC2
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+
+	network   *net.IPNet
+	vpcPrefix int
+}
+
+func New(config Config) (*Resource, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.HostEC2 == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HostEC2 must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	var network *net.IPNet
+	if config.Network != "" {
+		_, n, err := net.ParseCIDR(config.Network)
+		if err != nil || n.IP.To4() == nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Network must be an IPv4 CIDR", config)
+		}
+		if config.VPCPrefix < vpcPrefixMin || config.VPCPrefix > vpcPrefixMax {
+			return nil, microerror.Maskf(invalidConfigError, "%T.VPCPrefix must be between %d and %d", config, vpcPrefixMin, vpcPrefixMax)
+		}
+		ones, _ := n.Mask.Size()
+		if config.VPCPrefix < ones {
+			return nil, microerror.Maskf(invalidConfigError, "%T.VPCPrefix must not be lower than the prefix length of %T.Network", config, config)
+		}
+
+		network = n
+	}
+
+	r := &Resource{
+		g8sClient: config.G8sClient,
+		hostEC2:   config.HostEC2,
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		network:   network,
+		vpcPrefix: config.VPCPrefix,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return name
+}
+
+// EnsureCreated allocates a VPC CIDR for guest clusters not configuring one in
+// their custom object. The allocation is recorded in the allocations config
+// map before the custom object is updated, so that a failed update of the
+// custom object reuses the allocation in the next reconciliation loop.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	if r.network == nil {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "IPAM disabled, skipping execution")
+		return nil
+	}
+
+	var customObject providerv1alpha1.AWSConfig
+	{
+		o, err := key.ToCustomObject(obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if key.IsExistingVPC(o) || key.CIDR(o) != "" {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "VPC CIDR configured in the CR, skipping allocation")
+			return nil
+		}
+
+		// We have to always fetch the latest version of the resource in order to
+		// update it below using the latest resource version.
+		m, err := r.g8sClient.ProviderV1alpha1().AWSConfigs(o.GetNamespace()).Get(o.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		customObject = *m.DeepCopy()
+	}
+
+	configMap, err := r.ensureAllocationsConfigMap(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cidr, ok := configMap.Data[key.ClusterID(customObject)]
+	if ok {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found allocated VPC CIDR %s", cidr))
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "allocating VPC CIDR")
+
+		allocated, err := r.allocatedNetworks(customObject, configMap)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		subnet, err := ipam.Free(*r.network, net.CIDRMask(r.vpcPrefix, 32), allocated)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		cidr = subnet.String()
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[key.ClusterID(customObject)] = cidr
+
+		// The update fails in case the config map was modified concurrently, so
+		// that the same VPC CIDR is never allocated twice.
+		_, err = r.k8sClient.CoreV1().ConfigMaps(configMap.Namespace).Update(configMap)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("allocated VPC CIDR %s", cidr))
+	}
+
+	{
+		err := setVPCCIDRs(&customObject.Spec, cidr)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updating CR")
+
+		_, err = r.g8sClient.ProviderV1alpha1().AWSConfigs(customObject.GetNamespace()).Update(&customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "updated CR")
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+		reconciliationcanceledcontext.SetCanceled(ctx)
+	}
+
+	return nil
+}
+
+// EnsureDeleted frees the VPC CIDR allocated for the guest cluster. The VPC
+// CIDR stays reserved by the custom object until it is gone, since the VPC CIDRs
+// of all custom objects are considered allocated.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	configMap, err := r.k8sClient.CoreV1().ConfigMaps(allocationsConfigMapNamespace).Get(allocationsConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "no VPC CIDR allocated")
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if _, ok := configMap.Data[key.ClusterID(customObject)]; !ok {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "no VPC CIDR allocated")
+		return nil
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "freeing VPC CIDR")
+
+	delete(configMap.Data, key.ClusterID(customObject))
+
+	_, err = r.k8sClient.CoreV1().ConfigMaps(configMap.Namespace).Update(configMap)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", "freed VPC CIDR")
+
+	return nil
+}
+
+// allocatedNetworks returns the networks VPC CIDRs must not overlap with. These
+// are the VPC CIDRs recorded in the allocations config map, the VPC CIDRs
+// configured in the custom objects of all guest clusters and the CIDR of the
+// host cluster VPC.
+func (r *Resource) allocatedNetworks(customObject providerv1alpha1.AWSConfig, configMap *apiv1.ConfigMap) ([]net.IPNet, error) {
+	var cidrs []string
+
+	for _, cidr := range configMap.Data {
+		cidrs = append(cidrs, cidr)
+	}
+
+	{
+		list, err := r.g8sClient.ProviderV1alpha1().AWSConfigs("").List(metav1.ListOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, o := range list.Items {
+			if key.CIDR(o) != "" {
+				cidrs = append(cidrs, key.CIDR(o))
+			}
+		}
+	}
+
+	{
+		i := &ec2.DescribeVpcsInput{
+			VpcIds: []*string{
+				aws.String(key.PeerID(customObject)),
+			},
+		}
+		o, err := r.hostEC2.DescribeVpcs(i)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if len(o.Vpcs) != 1 {
+			return nil, microerror.Maskf(notFoundError, "host cluster VPC '%s'", key.PeerID(customObject))
+		}
+
+		cidrs = append(cidrs, aws.StringValue(o.Vpcs[0].CidrBlock))
+	}
+
+	var networks []net.IPNet
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "allocated VPC CIDR '%s' must be a valid CIDR", cidr)
+		}
+		networks = append(networks, *n)
+	}
+
+	return networks, nil
+}
+
+// ensureAllocationsConfigMap returns the allocations config map and creates it
+// in case it does not exist yet.
+func (r *Resource) ensureAllocationsConfigMap(ctx context.Context) (*apiv1.ConfigMap, error) {
+	configMap, err := r.k8sClient.CoreV1().ConfigMaps(allocationsConfigMapNamespace).Get(allocationsConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "creating IPAM allocations config map")
+
+		newConfigMap := &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      allocationsConfigMapName,
+				Namespace: allocationsConfigMapNamespace,
+			},
+			Data: map[string]string{},
+		}
+
+		configMap, err = r.k8sClient.CoreV1().ConfigMaps(allocationsConfigMapNamespace).Create(newConfigMap)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "created IPAM allocations config map")
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return configMap, nil
+}
+
+// setVPCCIDRs sets the allocated VPC CIDR in the given spec. The VPC CIDR is
+// split into a public and a private subnet per availability zone. The subnet
+// CIDRs of the custom object are the ones of the first availability zone. The
+// subnets of further availability zones are allocated from the rest of the VPC
+// CIDR with the same size, see key.PublicSubnetCIDRs.
+func setVPCCIDRs(spec *providerv1alpha1.AWSConfigSpec, cidr string) error {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return microerror.Maskf(invalidConfigError, "allocated VPC CIDR '%s' must be a valid CIDR", cidr)
+	}
+
+	azs := len(spec.AWS.AvailabilityZones)
+	if azs == 0 {
+		azs = 1
+	}
+
+	subnets, err := ipam.Split(*network, uint(2*azs))
+	if ipam.IsInvalidParameter(err) {
+		return microerror.Maskf(invalidConfigError, "VPC CIDR %s must fit %d subnets for %d availability zones", cidr, 2*azs, azs)
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	if ones, _ := subnets[0].Mask.Size(); ones > subnetPrefixMax {
+		return microerror.Maskf(invalidConfigError, "VPC CIDR %s must fit subnets of at least /%d for %d availability zones", cidr, subnetPrefixMax, azs)
+	}
+
+	spec.AWS.VPC.CIDR = cidr
+	if spec.AWS.VPC.PublicSubnetCIDR == "" {
+		spec.AWS.VPC.PublicSubnetCIDR = subnets[0].String()
+	}
+	if spec.AWS.VPC.PrivateSubnetCIDR == "" {
+		spec.AWS.VPC.PrivateSubnetCIDR = subnets[1].String()
+	}
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package ipam
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+type ec2Mock struct {
+	vpcCIDR string
+}
+
+func (e *ec2Mock) DescribeVpcs(*ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	output := &ec2.DescribeVpcsOutput{
+		Vpcs: []*ec2.Vpc{
+			{
+				CidrBlock: aws.String(e.vpcCIDR),
+			},
+		},
+	}
+
+	return output, nil
+}
+
+func newCustomObject(name string, vpc providerv1alpha1.AWSConfigSpecAWSVPC) *providerv1alpha1.AWSConfig {
+	vpc.PeerID = "vpc-host"
+
+	return &providerv1alpha1.AWSConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: providerv1alpha1.AWSConfigSpec{
+			AWS: providerv1alpha1.AWSConfigSpecAWS{
+				VPC: vpc,
+			},
+			Cluster: providerv1alpha1.Cluster{
+				ID: name,
+			},
+		},
+	}
+}
+
+func newAllocationsConfigMap(data map[string]string) *apiv1.ConfigMap {
+	return &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      allocationsConfigMapName,
+			Namespace: allocationsConfigMapNamespace,
+		},
+		Data: data,
+	}
+}
+
+func Test_Resource_IPAM_EnsureCreated(t *testing.T) {
+	testCases := []struct {
+		name                string
+		network             string
+		customObject        *providerv1alpha1.AWSConfig
+		g8sObjects          []runtime.Object
+		k8sObjects          []runtime.Object
+		expectedVPC         providerv1alpha1.AWSConfigSpecAWSVPC
+		expectedAllocations map[string]string
+	}{
+		{
+			name:         "case 0: IPAM disabled",
+			network:      "",
+			customObject: newCustomObject("abc12", providerv1alpha1.AWSConfigSpecAWSVPC{}),
+			expectedVPC: providerv1alpha1.AWSConfigSpecAWSVPC{
+				PeerID: "vpc-host",
+			},
+			expectedAllocations: nil,
+		},
+		{
+			name:    "case 1: VPC CIDR configured in the CR",
+			network: "10.1.0.0/16",
+			customObject: newCustomObject("abc12", providerv1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:              "10.5.0.0/24",
+				PrivateSubnetCIDR: "10.5.0.0/25",
+				PublicSubnetCIDR:  "10.5.0.128/25",
+			}),
+			expectedVPC: providerv1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:              "10.5.0.0/24",
+				PeerID:            "vpc-host",
+				PrivateSubnetCIDR: "10.5.0.0/25",
+				PublicSubnetCIDR:  "10.5.0.128/25",
+			},
+			expectedAllocations: nil,
+		},
+		{
+			name:         "case 2: allocate VPC CIDR around other guest clusters and the host cluster",
+			network:      "10.1.0.0/16",
+			customObject: newCustomObject("abc12", providerv1alpha1.AWSConfigSpecAWSVPC{}),
+			g8sObjects: []runtime.Object{
+				newCustomObject("def34", providerv1alpha1.AWSConfigSpecAWSVPC{
+					CIDR: "10.1.1.0/24",
+				}),
+			},
+			k8sObjects: []runtime.Object{
+				newAllocationsConfigMap(map[string]string{
+					"ghi56": "10.1.2.0/24",
+				}),
+			},
+			expectedVPC: providerv1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:              "10.1.3.0/24",
+				PeerID:            "vpc-host",
+				PrivateSubnetCIDR: "10.1.3.128/25",
+				PublicSubnetCIDR:  "10.1.3.0/25",
+			},
+			expectedAllocations: map[string]string{
+				"abc12": "10.1.3.0/24",
+				"ghi56": "10.1.2.0/24",
+			},
+		},
+		{
+			name:         "case 3: reuse VPC CIDR already allocated",
+			network:      "10.1.0.0/16",
+			customObject: newCustomObject("abc12", providerv1alpha1.AWSConfigSpecAWSVPC{}),
+			k8sObjects: []runtime.Object{
+				newAllocationsConfigMap(map[string]string{
+					"abc12": "10.1.7.0/24",
+				}),
+			},
+			expectedVPC: providerv1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:              "10.1.7.0/24",
+				PeerID:            "vpc-host",
+				PrivateSubnetCIDR: "10.1.7.128/25",
+				PublicSubnetCIDR:  "10.1.7.0/25",
+			},
+			expectedAllocations: map[string]string{
+				"abc12": "10.1.7.0/24",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g8sClient := fake.NewSimpleClientset(append(tc.g8sObjects, tc.customObject)...)
+			k8sClient := k8sfake.NewSimpleClientset(tc.k8sObjects...)
+
+			c := Config{
+				G8sClient: g8sClient,
+				HostEC2: &ec2Mock{
+					vpcCIDR: "10.1.0.0/24",
+				},
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+
+				Network:   tc.network,
+				VPCPrefix: 24,
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			err = r.EnsureCreated(context.Background(), tc.customObject)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			customObject, err := g8sClient.ProviderV1alpha1().AWSConfigs(tc.customObject.Namespace).Get(tc.customObject.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if !reflect.DeepEqual(customObject.Spec.AWS.VPC, tc.expectedVPC) {
+				t.Fatalf("VPC == %#v, want %#v", customObject.Spec.AWS.VPC, tc.expectedVPC)
+			}
+
+			var allocations map[string]string
+			configMap, err := k8sClient.CoreV1().ConfigMaps(allocationsConfigMapNamespace).Get(allocationsConfigMapName, metav1.GetOptions{})
+			if err == nil {
+				allocations = configMap.Data
+			}
+			if !reflect.DeepEqual(allocations, tc.expectedAllocations) {
+				t.Fatalf("allocations == %v, want %v", allocations, tc.expectedAllocations)
+			}
+		})
+	}
+}
+
+func Test_Resource_IPAM_EnsureDeleted(t *testing.T) {
+	testCases := []struct {
+		name                string
+		k8sObjects          []runtime.Object
+		expectedAllocations map[string]string
+	}{
+		{
+			name:                "case 0: no allocations config map",
+			k8sObjects:          nil,
+			expectedAllocations: nil,
+		},
+		{
+			name: "case 1: free allocated VPC CIDR",
+			k8sObjects: []runtime.Object{
+				newAllocationsConfigMap(map[string]string{
+					"abc12": "10.1.3.0/24",
+					"ghi56": "10.1.2.0/24",
+				}),
+			},
+			expectedAllocations: map[string]string{
+				"ghi56": "10.1.2.0/24",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			customObject := newCustomObject("abc12", providerv1alpha1.AWSConfigSpecAWSVPC{
+				CIDR: "10.1.3.0/24",
+			})
+			k8sClient := k8sfake.NewSimpleClientset(tc.k8sObjects...)
+
+			c := Config{
+				G8sClient: fake.NewSimpleClientset(customObject),
+				HostEC2:   &ec2Mock{},
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+
+				Network:   "10.1.0.0/16",
+				VPCPrefix: 24,
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			err = r.EnsureDeleted(context.Background(), customObject)
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+
+			var allocations map[string]string
+			configMap, err := k8sClient.CoreV1().ConfigMaps(allocationsConfigMapNamespace).Get(allocationsConfigMapName, metav1.GetOptions{})
+			if err == nil {
+				allocations = configMap.Data
+			}
+			if !reflect.DeepEqual(allocations, tc.expectedAllocations) {
+				t.Fatalf("allocations == %v, want %v", allocations, tc.expectedAllocations)
+			}
+		})
+	}
+}
+
+func Test_Resource_IPAM_New_VPCPrefix(t *testing.T) {
+	testCases := []struct {
+		name          string
+		vpcPrefix     int
+		expectedError bool
+	}{
+		{
+			name:          "case 0: largest VPC CIDR",
+			vpcPrefix:     16,
+			expectedError: false,
+		},
+		{
+			name:          "case 1: smallest VPC CIDR fitting two subnets",
+			vpcPrefix:     27,
+			expectedError: false,
+		},
+		{
+			name:          "case 2: VPC CIDR too small for two subnets",
+			vpcPrefix:     28,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{
+				G8sClient: fake.NewSimpleClientset(),
+				HostEC2:   &ec2Mock{},
+				K8sClient: k8sfake.NewSimpleClientset(),
+				Logger:    microloggertest.New(),
+
+				Network:   "10.1.0.0/16",
+				VPCPrefix: tc.vpcPrefix,
+			}
+
+			_, err := New(c)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error, got %#v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+		})
+	}
+}
+
+func Test_Resource_IPAM_setVPCCIDRs(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cidr              string
+		availabilityZones []string
+		expectedVPC       providerv1alpha1.AWSConfigSpecAWSVPC
+		expectedError     bool
+	}{
+		{
+			name:              "case 0: single availability zone",
+			cidr:              "10.1.3.0/24",
+			availabilityZones: nil,
+			expectedVPC: providerv1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:              "10.1.3.0/24",
+				PrivateSubnetCIDR: "10.1.3.128/25",
+				PublicSubnetCIDR:  "10.1.3.0/25",
+			},
+		},
+		{
+			name:              "case 1: three availability zones",
+			cidr:              "10.1.3.0/24",
+			availabilityZones: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			expectedVPC: providerv1alpha1.AWSConfigSpecAWSVPC{
+				CIDR:              "10.1.3.0/24",
+				PrivateSubnetCIDR: "10.1.3.32/27",
+				PublicSubnetCIDR:  "10.1.3.0/27",
+			},
+		},
+		{
+			name:              "case 2: subnets smaller than /28",
+			cidr:              "10.1.3.0/26",
+			availabilityZones: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+			expectedError:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := providerv1alpha1.AWSConfigSpec{
+				AWS: providerv1alpha1.AWSConfigSpecAWS{
+					AvailabilityZones: tc.availabilityZones,
+				},
+			}
+
+			err := setVPCCIDRs(&spec, tc.cidr)
+			if tc.expectedError {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error, got %#v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %#v", err)
+			}
+			if !reflect.DeepEqual(spec.AWS.VPC, tc.expectedVPC) {
+				t.Fatalf("VPC == %#v, want %#v", spec.AWS.VPC, tc.expectedVPC)
+			}
+		})
+	}
+}
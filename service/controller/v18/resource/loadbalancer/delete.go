@@ -6,14 +6,15 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 	"github.com/giantswarm/aws-operator/service/controller/v18/key"
 )
 
-// EnsureDeleted ensures that any ELBs from Kubernetes LoadBalancer services
-// are deleted. This is needed because the use the VPC public subnet.
+// EnsureDeleted ensures that any ELBs and NLBs from Kubernetes LoadBalancer
+// services are deleted. This is needed because the use the VPC public subnet.
 func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
@@ -47,5 +48,43 @@ func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting load balancers because there aren't any")
 	}
 
+	nlbState, err := r.clusterNetworkLoadBalancers(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Target groups can only be deleted once they are not used by any load
+	// balancer anymore. So the NLBs are deleted first.
+	if nlbState != nil && (len(nlbState.LoadBalancerARNs) > 0 || len(nlbState.TargetGroupARNs) > 0) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleting %d network load balancers and %d target groups", len(nlbState.LoadBalancerARNs), len(nlbState.TargetGroupARNs)))
+
+		sc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, arn := range nlbState.LoadBalancerARNs {
+			_, err := sc.AWSClient.ELBv2.DeleteLoadBalancer(&elbv2.DeleteLoadBalancerInput{
+				LoadBalancerArn: aws.String(arn),
+			})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		for _, arn := range nlbState.TargetGroupARNs {
+			_, err := sc.AWSClient.ELBv2.DeleteTargetGroup(&elbv2.DeleteTargetGroupInput{
+				TargetGroupArn: aws.String(arn),
+			})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("deleted %d network load balancers and %d target groups", len(nlbState.LoadBalancerARNs), len(nlbState.TargetGroupARNs)))
+	} else {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not deleting network load balancers because there aren't any")
+	}
+
 	return nil
 }
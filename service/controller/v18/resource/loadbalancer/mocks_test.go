@@ -4,6 +4,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 )
 
 type ELBClientMock struct {
@@ -51,3 +53,56 @@ func (e *ELBClientMock) DescribeTags(*elb.DescribeTagsInput) (*elb.DescribeTagsO
 
 	return output, nil
 }
+
+type ELBv2ClientMock struct {
+	elbv2iface.ELBV2API
+
+	loadBalancers []ELBv2ResourceMock
+	targetGroups  []ELBv2ResourceMock
+}
+
+type ELBv2ResourceMock struct {
+	arn  string
+	tags []*elbv2.Tag
+}
+
+func (e *ELBv2ClientMock) DescribeLoadBalancers(*elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+	output := &elbv2.DescribeLoadBalancersOutput{}
+
+	for _, lb := range e.loadBalancers {
+		output.LoadBalancers = append(output.LoadBalancers, &elbv2.LoadBalancer{
+			LoadBalancerArn: aws.String(lb.arn),
+		})
+	}
+
+	return output, nil
+}
+
+func (e *ELBv2ClientMock) DescribeTags(input *elbv2.DescribeTagsInput) (*elbv2.DescribeTagsOutput, error) {
+	output := &elbv2.DescribeTagsOutput{}
+
+	for _, arn := range input.ResourceArns {
+		for _, r := range append(e.loadBalancers, e.targetGroups...) {
+			if r.arn == *arn {
+				output.TagDescriptions = append(output.TagDescriptions, &elbv2.TagDescription{
+					ResourceArn: aws.String(r.arn),
+					Tags:        r.tags,
+				})
+			}
+		}
+	}
+
+	return output, nil
+}
+
+func (e *ELBv2ClientMock) DescribeTargetGroups(*elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
+	output := &elbv2.DescribeTargetGroupsOutput{}
+
+	for _, tg := range e.targetGroups {
+		output.TargetGroups = append(output.TargetGroups, &elbv2.TargetGroup{
+			TargetGroupArn: aws.String(tg.arn),
+		})
+	}
+
+	return output, nil
+}
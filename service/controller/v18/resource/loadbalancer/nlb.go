@@ -0,0 +1,127 @@
+package loadbalancer
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+func (r *Resource) clusterNetworkLoadBalancers(ctx context.Context, customObject v1alpha1.AWSConfig) (*NetworkLoadBalancerState, error) {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// We get all load balancers and target groups because the API does not
+	// allow tag filters.
+	var allLBARNs []*string
+	{
+		i := &elbv2.DescribeLoadBalancersInput{}
+		for {
+			o, err := sc.AWSClient.ELBv2.DescribeLoadBalancers(i)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			for _, lb := range o.LoadBalancers {
+				allLBARNs = append(allLBARNs, lb.LoadBalancerArn)
+			}
+
+			if o.NextMarker == nil {
+				break
+			}
+			i.Marker = o.NextMarker
+		}
+	}
+
+	var allTGARNs []*string
+	{
+		i := &elbv2.DescribeTargetGroupsInput{}
+		for {
+			o, err := sc.AWSClient.ELBv2.DescribeTargetGroups(i)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			for _, tg := range o.TargetGroups {
+				allTGARNs = append(allTGARNs, tg.TargetGroupArn)
+			}
+
+			if o.NextMarker == nil {
+				break
+			}
+			i.Marker = o.NextMarker
+		}
+	}
+
+	lbARNs, err := r.clusterServiceResources(ctx, customObject, allLBARNs)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	tgARNs, err := r.clusterServiceResources(ctx, customObject, allTGARNs)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	nlbState := &NetworkLoadBalancerState{
+		LoadBalancerARNs: lbARNs,
+		TargetGroupARNs:  tgARNs,
+	}
+
+	return nlbState, nil
+}
+
+// clusterServiceResources returns the ARNs of the given ELBv2 resources which
+// are tagged as belonging to Kubernetes services of the guest cluster.
+func (r *Resource) clusterServiceResources(ctx context.Context, customObject v1alpha1.AWSConfig, arns []*string) ([]string, error) {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	clusterARNs := []string{}
+
+	for _, chunk := range splitLoadBalancers(arns, loadBalancerTagChunkSize) {
+		tagsInput := &elbv2.DescribeTagsInput{
+			ResourceArns: chunk,
+		}
+		tagsOutput, err := sc.AWSClient.ELBv2.DescribeTags(tagsInput)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, d := range tagsOutput.TagDescriptions {
+			if containsClusterTagV2(d.Tags, customObject) && containsServiceTagV2(d.Tags) {
+				clusterARNs = append(clusterARNs, *d.ResourceArn)
+			}
+		}
+	}
+
+	return clusterARNs, nil
+}
+
+func containsClusterTagV2(tags []*elbv2.Tag, customObject v1alpha1.AWSConfig) bool {
+	tagKey := key.ClusterCloudProviderTag(customObject)
+
+	for _, tag := range tags {
+		if *tag.Key == tagKey && *tag.Value == cloudProviderClusterTagValue {
+			return true
+		}
+	}
+	return false
+}
+
+func containsServiceTagV2(tags []*elbv2.Tag) bool {
+	for _, tag := range tags {
+		if *tag.Key == cloudProviderServiceTagKey {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package loadbalancer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+)
+
+func Test_clusterNetworkLoadBalancers(t *testing.T) {
+	t.Parallel()
+	customObject := v1alpha1.AWSConfig{
+		Spec: v1alpha1.AWSConfigSpec{
+			Cluster: v1alpha1.Cluster{
+				ID: "test-cluster",
+			},
+		},
+	}
+
+	serviceTags := func(clusterID string) []*elbv2.Tag {
+		return []*elbv2.Tag{
+			{
+				Key:   aws.String("kubernetes.io/cluster/" + clusterID),
+				Value: aws.String("owned"),
+			},
+			{
+				Key:   aws.String("kubernetes.io/service-name"),
+				Value: aws.String("hello-world"),
+			},
+		}
+	}
+
+	testCases := []struct {
+		description   string
+		loadBalancers []ELBv2ResourceMock
+		targetGroups  []ELBv2ResourceMock
+		expectedState *NetworkLoadBalancerState
+	}{
+		{
+			description: "no network load balancers",
+			expectedState: &NetworkLoadBalancerState{
+				LoadBalancerARNs: []string{},
+				TargetGroupARNs:  []string{},
+			},
+		},
+		{
+			description: "network load balancer with target group",
+			loadBalancers: []ELBv2ResourceMock{
+				{arn: "arn:nlb-1", tags: serviceTags("test-cluster")},
+			},
+			targetGroups: []ELBv2ResourceMock{
+				{arn: "arn:tg-1", tags: serviceTags("test-cluster")},
+			},
+			expectedState: &NetworkLoadBalancerState{
+				LoadBalancerARNs: []string{"arn:nlb-1"},
+				TargetGroupARNs:  []string{"arn:tg-1"},
+			},
+		},
+		{
+			description: "network load balancers of other clusters and the guest cluster stack are not matched",
+			loadBalancers: []ELBv2ResourceMock{
+				{arn: "arn:nlb-1", tags: serviceTags("test-cluster")},
+				{arn: "arn:nlb-2", tags: serviceTags("other-cluster")},
+				{arn: "arn:nlb-3", tags: serviceTags("test-cluster")[:1]},
+			},
+			targetGroups: []ELBv2ResourceMock{
+				{arn: "arn:tg-2", tags: serviceTags("other-cluster")},
+				{arn: "arn:tg-3"},
+			},
+			expectedState: &NetworkLoadBalancerState{
+				LoadBalancerARNs: []string{"arn:nlb-1"},
+				TargetGroupARNs:  []string{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c := Config{
+				Logger: microloggertest.New(),
+			}
+			newResource, err := New(c)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			awsClients := awsclient.Clients{
+				ELBv2: &ELBv2ClientMock{
+					loadBalancers: tc.loadBalancers,
+					targetGroups:  tc.targetGroups,
+				},
+			}
+			ctx := context.TODO()
+			ctx = controllercontext.NewContext(ctx, controllercontext.Context{AWSClient: awsClients})
+
+			result, err := newResource.clusterNetworkLoadBalancers(ctx, customObject)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tc.expectedState) {
+				t.Fatalf("expected state %#v got %#v", tc.expectedState, result)
+			}
+		})
+	}
+}
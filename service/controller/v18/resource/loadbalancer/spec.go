@@ -2,10 +2,12 @@ package loadbalancer
 
 import (
 	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 )
 
 type Clients struct {
-	ELB ELBClient
+	ELB   ELBClient
+	ELBv2 ELBv2Client
 }
 
 // ELBClient describes the methods required to be implemented by an ELB AWS
@@ -16,6 +18,23 @@ type ELBClient interface {
 	DescribeTags(*elb.DescribeTagsInput) (*elb.DescribeTagsOutput, error)
 }
 
+// ELBv2Client describes the methods required to be implemented by an ELBv2 AWS
+// client. The ELBv2 API provides support for NLBs and their target groups.
+type ELBv2Client interface {
+	DeleteLoadBalancer(*elbv2.DeleteLoadBalancerInput) (*elbv2.DeleteLoadBalancerOutput, error)
+	DeleteTargetGroup(*elbv2.DeleteTargetGroupInput) (*elbv2.DeleteTargetGroupOutput, error)
+	DescribeLoadBalancers(*elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error)
+	DescribeTags(*elbv2.DescribeTagsInput) (*elbv2.DescribeTagsOutput, error)
+	DescribeTargetGroups(*elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error)
+}
+
 type LoadBalancerState struct {
 	LoadBalancerNames []string
 }
+
+// NetworkLoadBalancerState holds the NLBs of Kubernetes LoadBalancer services
+// and their target groups.
+type NetworkLoadBalancerState struct {
+	LoadBalancerARNs []string
+	TargetGroupARNs  []string
+}
@@ -0,0 +1,274 @@
+// Package privatehostedzone provides an operatorkit resource that associates
+// the private hosted zone of the guest cluster domain with the host cluster
+// VPC. The private hosted zone is managed in the guest cluster main stack,
+// which can only associate it with VPCs of the guest account. Associating a VPC
+// of the host account requires an authorization of the guest account, so it is
+// managed by means of the Route53 API.
+package privatehostedzone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/adapter"
+	cloudformationservice "github.com/giantswarm/aws-operator/service/controller/v18/cloudformation"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+const (
+	name = "privatehostedzonev18"
+)
+
+type Config struct {
+	HostRoute53 *route53.Route53
+	HostSTS     stsiface.STSAPI
+	Logger      micrologger.Logger
+
+	Route53Enabled bool
+}
+
+type Resource struct {
+	hostRoute53 *route53.Route53
+	hostSTS     stsiface.STSAPI
+	logger      micrologger.Logger
+
+	route53Enabled bool
+}
+
+func New(config Config) (*Resource, error) {
+	if config.HostRoute53 == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HostRoute53 must not be empty", config)
+	}
+	if config.HostSTS == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.HostSTS must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		hostRoute53: config.HostRoute53,
+		hostSTS:     config.HostSTS,
+		logger:      config.Logger,
+
+		route53Enabled: config.Route53Enabled,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return name
+}
+
+// EnsureCreated associates the private hosted zone with the host cluster VPC
+// when configured in the custom object and removes the association otherwise.
+// The private hosted zone is created with the guest cluster main stack, so the
+// association is only managed once the stack provides the ID of the zone.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !r.route53Enabled {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "route53 disabled, skipping execution")
+		return nil
+	}
+
+	desired := key.PrivateHostedZoneEnabled(customObject) && key.PrivateHostedZoneHostVPC(customObject)
+
+	err = r.ensureHostVPCAssociation(ctx, customObject, desired)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// EnsureDeleted removes the association of the private hosted zone with the
+// host cluster VPC before the guest cluster main stack deletes the zone.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !r.route53Enabled {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "route53 disabled, skipping execution")
+		return nil
+	}
+
+	err = r.ensureHostVPCAssociation(ctx, customObject, false)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) ensureHostVPCAssociation(ctx context.Context, customObject v1alpha1.AWSConfig, desired bool) error {
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var hostedZoneID string
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the private hosted zone ID in the cloud formation stack")
+
+		stackOutputs, stackStatus, err := controllerCtx.CloudFormation.DescribeOutputsAndStatus(key.MainGuestStackName(customObject))
+		if cloudformationservice.IsStackNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the private hosted zone ID in the cloud formation stack")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster main stack is not yet created")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+
+		} else if cloudformationservice.IsOutputsNotAccessible(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the private hosted zone ID in the cloud formation stack")
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("the guest cluster main stack output values are not accessible due to stack status '%s'", stackStatus))
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		hostedZoneID, err = controllerCtx.CloudFormation.GetOutputValue(stackOutputs, key.PrivateHostedZoneIDKey)
+		if cloudformationservice.IsOutputNotFound(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the private hosted zone ID in the cloud formation stack")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "the guest cluster does not have a private hosted zone")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource")
+			return nil
+
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found the private hosted zone ID %q in the cloud formation stack", hostedZoneID))
+	}
+
+	hostVPC := &route53.VPC{
+		VPCId:     aws.String(key.PeerID(customObject)),
+		VPCRegion: aws.String(key.Region(customObject)),
+	}
+
+	var associated bool
+	{
+		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the host cluster VPC association of the private hosted zone")
+
+		i := &route53.GetHostedZoneInput{
+			Id: aws.String(hostedZoneID),
+		}
+		o, err := controllerCtx.AWSClient.Route53.GetHostedZone(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, v := range o.VPCs {
+			if aws.StringValue(v.VPCId) == aws.StringValue(hostVPC.VPCId) {
+				associated = true
+				break
+			}
+		}
+
+		if associated {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "found the host cluster VPC association of the private hosted zone")
+		} else {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "did not find the host cluster VPC association of the private hosted zone")
+		}
+	}
+
+	if desired && !associated {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "associating the private hosted zone with the host cluster VPC")
+
+		crossAccount, err := r.isCrossAccount(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// VPCs of other accounts can only be associated once the account owning
+		// the private hosted zone authorized it. The authorization is deleted
+		// again once the association exists, since it is not required anymore.
+		if crossAccount {
+			i := &route53.CreateVPCAssociationAuthorizationInput{
+				HostedZoneId: aws.String(hostedZoneID),
+				VPC:          hostVPC,
+			}
+			_, err := controllerCtx.AWSClient.Route53.CreateVPCAssociationAuthorization(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		{
+			i := &route53.AssociateVPCWithHostedZoneInput{
+				Comment:      aws.String(fmt.Sprintf("host cluster VPC of guest cluster %s", key.ClusterID(customObject))),
+				HostedZoneId: aws.String(hostedZoneID),
+				VPC:          hostVPC,
+			}
+			_, err := r.hostRoute53.AssociateVPCWithHostedZone(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		if crossAccount {
+			i := &route53.DeleteVPCAssociationAuthorizationInput{
+				HostedZoneId: aws.String(hostedZoneID),
+				VPC:          hostVPC,
+			}
+			_, err := controllerCtx.AWSClient.Route53.DeleteVPCAssociationAuthorization(i)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "associated the private hosted zone with the host cluster VPC")
+	}
+
+	if !desired && associated {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "disassociating the private hosted zone from the host cluster VPC")
+
+		i := &route53.DisassociateVPCFromHostedZoneInput{
+			HostedZoneId: aws.String(hostedZoneID),
+			VPC:          hostVPC,
+		}
+		_, err := r.hostRoute53.DisassociateVPCFromHostedZone(i)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", "disassociated the private hosted zone from the host cluster VPC")
+	}
+
+	return nil
+}
+
+// isCrossAccount returns true when the guest cluster runs in another account
+// than the host cluster.
+func (r *Resource) isCrossAccount(ctx context.Context) (bool, error) {
+	controllerCtx, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	guestAccountID, err := adapter.AccountID(adapter.Clients{STS: controllerCtx.AWSClient.STS})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	hostAccountID, err := adapter.AccountID(adapter.Clients{STS: r.hostSTS})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return guestAccountID != hostAccountID, nil
+}
@@ -83,6 +83,16 @@ func (r *Resource) ApplyCreateChange(ctx context.Context, obj, createChange inte
 			if err != nil {
 				return microerror.Mask(err)
 			}
+
+			if bucketInput.Policy != "" {
+				_, err = sc.AWSClient.S3.PutBucketPolicy(&s3.PutBucketPolicyInput{
+					Bucket: aws.String(key.TargetLogBucketName(customObject)),
+					Policy: aws.String(bucketInput.Policy),
+				})
+				if err != nil {
+					return microerror.Mask(err)
+				}
+			}
 		}
 
 		if bucketInput.LoggingEnabled {
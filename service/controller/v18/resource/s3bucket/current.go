@@ -58,6 +58,14 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 		inputBucket.LoggingEnabled = isLoggingEnabled(lc)
 		inputBucket.IsLoggingBucket = isLoggingBucket(inputBucketName, lc)
 
+		if inputBucketName == key.TargetLogBucketName(customObject) {
+			policy, err := r.getPolicy(ctx, inputBucketName)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			inputBucket.Policy = policy
+		}
+
 		currentBucketState = append(currentBucketState, inputBucket)
 	}
 
@@ -102,6 +110,25 @@ func (r *Resource) getLoggingConfiguration(ctx context.Context, name string) (*s
 	return bucketLoggingOutput, nil
 }
 
+func (r *Resource) getPolicy(ctx context.Context, name string) (string, error) {
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	bucketPolicyInput := &s3.GetBucketPolicyInput{
+		Bucket: aws.String(name),
+	}
+	bucketPolicyOutput, err := sc.AWSClient.S3.GetBucketPolicy(bucketPolicyInput)
+	if IsBucketPolicyNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return aws.StringValue(bucketPolicyOutput.Policy), nil
+}
+
 func isLoggingEnabled(lc *s3.GetBucketLoggingOutput) bool {
 	if lc.LoggingEnabled != nil {
 		return true
@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 )
@@ -67,7 +68,16 @@ func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange inte
 		})
 		if IsBucketNotFound(err) {
 			// Fall through.
-			return nil
+			continue
+		} else if IsBucketNotEmpty(err) {
+			// The VPC flow logs and the ELB access logs are written to the logging
+			// bucket until the guest cluster main stack is deleted, which happens
+			// after the buckets are deleted. Objects written in the meantime are
+			// deleted in the next reconciliation loop.
+			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("S3 bucket %q is not empty yet", bucketInput.Name))
+			r.logger.LogCtx(ctx, "level", "debug", "message", "keeping finalizers")
+			finalizerskeptcontext.SetKept(ctx)
+			continue
 		} else if err != nil {
 			return microerror.Mask(err)
 		}
@@ -2,10 +2,17 @@ package s3bucket
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 )
 
 func Test_Resource_S3Bucket_newDelete(t *testing.T) {
@@ -111,3 +118,91 @@ func Test_Resource_S3Bucket_newDelete(t *testing.T) {
 		})
 	}
 }
+
+func Test_Resource_S3Bucket_ApplyDeleteChange(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description            string
+		deleteChange           []BucketState
+		missingBuckets         []string
+		expectedDeletedBuckets []string
+	}{
+		{
+			description: "all buckets exist, expected all buckets deleted",
+			deleteChange: []BucketState{
+				{Name: "first"},
+				{Name: "second"},
+			},
+			missingBuckets:         nil,
+			expectedDeletedBuckets: []string{"first", "second"},
+		},
+		{
+			description: "first bucket is already gone, expected remaining buckets deleted",
+			deleteChange: []BucketState{
+				{Name: "first"},
+				{Name: "second"},
+			},
+			missingBuckets:         []string{"first"},
+			expectedDeletedBuckets: []string{"second"},
+		},
+	}
+
+	var err error
+	var newResource *Resource
+	{
+		resourceConfig := DefaultConfig()
+		resourceConfig.Logger = microloggertest.New()
+		resourceConfig.InstallationName = "test-install"
+
+		newResource, err = New(resourceConfig)
+		if err != nil {
+			t.Error("expected", nil, "got", err)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			s3Client := &deleteS3ClientMock{
+				missingBuckets: tc.missingBuckets,
+			}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				AWSClient: awsclient.Clients{
+					S3: s3Client,
+				},
+			})
+
+			err := newResource.ApplyDeleteChange(ctx, nil, tc.deleteChange)
+			if err != nil {
+				t.Fatalf("expected '%v' got '%#v'", nil, err)
+			}
+
+			if !reflect.DeepEqual(s3Client.deletedBuckets, tc.expectedDeletedBuckets) {
+				t.Fatalf("expected deleted buckets %v got %v", tc.expectedDeletedBuckets, s3Client.deletedBuckets)
+			}
+		})
+	}
+}
+
+type deleteS3ClientMock struct {
+	s3iface.S3API
+
+	deletedBuckets []string
+	missingBuckets []string
+}
+
+func (m *deleteS3ClientMock) DeleteBucket(i *s3.DeleteBucketInput) (*s3.DeleteBucketOutput, error) {
+	for _, b := range m.missingBuckets {
+		if b == *i.Bucket {
+			return nil, awserr.New(s3.ErrCodeNoSuchBucket, "bucket not found", nil)
+		}
+	}
+
+	m.deletedBuckets = append(m.deletedBuckets, *i.Bucket)
+
+	return &s3.DeleteBucketOutput{}, nil
+}
+
+func (m *deleteS3ClientMock) ListObjectsV2(*s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	return &s3.ListObjectsV2Output{}, nil
+}
@@ -25,6 +25,11 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		return nil, microerror.Mask(err)
 	}
 
+	policy, err := loggingBucketPolicy(customObject, accountID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	// First bucket must be the delivery log bucket because otherwise
 	// other buckets can not forward logs to it
 	bucketsState := []BucketState{
@@ -32,6 +37,7 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 			Name:            key.TargetLogBucketName(customObject),
 			IsLoggingBucket: true,
 			LoggingEnabled:  true,
+			Policy:          policy,
 		},
 		{
 			Name:            key.BucketName(customObject, accountID),
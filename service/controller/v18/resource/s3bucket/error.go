@@ -49,6 +49,34 @@ func IsBucketNotFound(err error) bool {
 	return false
 }
 
+// IsBucketPolicyNotFound asserts the error upstream's API returns for buckets
+// without policy.
+func IsBucketPolicyNotFound(err error) bool {
+	aerr, ok := microerror.Cause(err).(awserr.Error)
+	if !ok {
+		return false
+	}
+	if aerr.Code() == "NoSuchBucketPolicy" {
+		return true
+	}
+
+	return false
+}
+
+// IsBucketNotEmpty asserts the error upstream's API returns when deleting
+// buckets which still contain objects.
+func IsBucketNotEmpty(err error) bool {
+	aerr, ok := microerror.Cause(err).(awserr.Error)
+	if !ok {
+		return false
+	}
+	if aerr.Code() == "BucketNotEmpty" {
+		return true
+	}
+
+	return false
+}
+
 // IsBucketAlreadyExists asserts bucket already exists error from upstream's
 // API code.
 func IsBucketAlreadyExists(err error) bool {
@@ -0,0 +1,136 @@
+package s3bucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// logDeliveryService is the service principal delivering VPC flow logs to S3.
+const logDeliveryService = "delivery.logs.amazonaws.com"
+
+// elbAccountIDs maps regions to the AWS accounts of Elastic Load Balancing
+// which write the ELB access logs. See
+// https://docs.aws.amazon.com/elasticloadbalancing/latest/classic/enable-access-logs.html.
+var elbAccountIDs = map[string]string{
+	"ap-northeast-1": "582318560864",
+	"ap-northeast-2": "600734575887",
+	"ap-northeast-3": "383597477331",
+	"ap-south-1":     "718504428378",
+	"ap-southeast-1": "114774131450",
+	"ap-southeast-2": "783225319266",
+	"ca-central-1":   "985666609251",
+	"cn-north-1":     "638102146993",
+	"cn-northwest-1": "037604701340",
+	"eu-central-1":   "054676820928",
+	"eu-west-1":      "156460612806",
+	"eu-west-2":      "652711504416",
+	"eu-west-3":      "009996457667",
+	"sa-east-1":      "507241528517",
+	"us-east-1":      "127311923021",
+	"us-east-2":      "033677994240",
+	"us-west-1":      "027434742980",
+	"us-west-2":      "797873946194",
+}
+
+type bucketPolicy struct {
+	Version   string
+	Statement []bucketPolicyStatement
+}
+
+type bucketPolicyStatement struct {
+	Sid       string
+	Effect    string
+	Principal bucketPolicyPrincipal
+	Action    string
+	Resource  string
+	Condition map[string]map[string]string `json:",omitempty"`
+}
+
+type bucketPolicyPrincipal struct {
+	AWS     string `json:",omitempty"`
+	Service string `json:",omitempty"`
+}
+
+// loggingBucketPolicy returns the policy of the logging bucket. It allows the
+// log delivery service to write the VPC flow logs of the guest cluster and
+// Elastic Load Balancing to write the access logs of the ELBs of the guest
+// cluster. ELB access logs are not allowed in regions without known ELB
+// account. See
+// https://docs.aws.amazon.com/vpc/latest/userguide/flow-logs-s3.html.
+func loggingBucketPolicy(customObject v1alpha1.AWSConfig, accountID string) (string, error) {
+	bucketARN := fmt.Sprintf("arn:%s:s3:::%s", key.RegionARN(customObject), key.TargetLogBucketName(customObject))
+
+	p := bucketPolicy{
+		Version: "2012-10-17",
+		Statement: []bucketPolicyStatement{
+			{
+				Sid:    "VPCFlowLogsWrite",
+				Effect: "Allow",
+				Principal: bucketPolicyPrincipal{
+					Service: logDeliveryService,
+				},
+				Action:   "s3:PutObject",
+				Resource: fmt.Sprintf("%s/%s/AWSLogs/%s/*", bucketARN, key.VPCFlowLogsPrefix, accountID),
+				Condition: map[string]map[string]string{
+					"StringEquals": {
+						"s3:x-amz-acl": "bucket-owner-full-control",
+					},
+				},
+			},
+			{
+				Sid:    "VPCFlowLogsAclCheck",
+				Effect: "Allow",
+				Principal: bucketPolicyPrincipal{
+					Service: logDeliveryService,
+				},
+				Action:   "s3:GetBucketAcl",
+				Resource: bucketARN,
+			},
+		},
+	}
+
+	elbAccountID, ok := elbAccountIDs[key.Region(customObject)]
+	if ok {
+		s := bucketPolicyStatement{
+			Sid:    "ELBAccessLogs",
+			Effect: "Allow",
+			Principal: bucketPolicyPrincipal{
+				AWS: fmt.Sprintf("arn:%s:iam::%s:root", key.RegionARN(customObject), elbAccountID),
+			},
+			Action:   "s3:PutObject",
+			Resource: fmt.Sprintf("%s/*/AWSLogs/%s/*", bucketARN, accountID),
+		}
+		p.Statement = append(p.Statement, s)
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return string(b), nil
+}
+
+// equalPolicies compares bucket policies semantically because S3 does not
+// return policies byte by byte the way they were put.
+func equalPolicies(a, b string) bool {
+	if a == "" || b == "" {
+		return a == b
+	}
+
+	var pa, pb interface{}
+	if err := json.Unmarshal([]byte(a), &pa); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &pb); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(pa, pb)
+}
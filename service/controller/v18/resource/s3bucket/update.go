@@ -2,12 +2,41 @@ package s3bucket
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
 )
 
 func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	updateBucketsState, err := toBucketState(updateChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, bucketInput := range updateBucketsState {
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updating policy of S3 bucket %q", bucketInput.Name))
+
+		_, err = sc.AWSClient.S3.PutBucketPolicy(&s3.PutBucketPolicyInput{
+			Bucket: aws.String(bucketInput.Name),
+			Policy: aws.String(bucketInput.Policy),
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updated policy of S3 bucket %q", bucketInput.Name))
+	}
+
 	return nil
 }
 
@@ -29,7 +58,34 @@ func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desire
 	return patch, nil
 }
 
-// newUpdateChange is a no-op because S3 buckets are not updated.
+// newUpdateChange returns the existing buckets whose policy differs from the
+// desired one. Buckets are not updated otherwise. Existing logging buckets
+// receive the policy allowing ELB access logs this way.
 func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
-	return nil, nil
+	currentBuckets, err := toBucketState(currentState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	desiredBuckets, err := toBucketState(desiredState)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var updateState []BucketState
+	for _, desiredBucket := range desiredBuckets {
+		if desiredBucket.Policy == "" {
+			continue
+		}
+
+		for _, currentBucket := range currentBuckets {
+			if currentBucket.Name != desiredBucket.Name {
+				continue
+			}
+			if !equalPolicies(currentBucket.Policy, desiredBucket.Policy) {
+				updateState = append(updateState, desiredBucket)
+			}
+		}
+	}
+
+	return updateState, nil
 }
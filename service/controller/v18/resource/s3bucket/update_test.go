@@ -0,0 +1,180 @@
+package s3bucket
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+func Test_Resource_S3Bucket_newUpdate(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description          string
+		currentState         []BucketState
+		desiredState         []BucketState
+		expectedBucketsState []BucketState
+	}{
+		{
+			description:          "current and desired state empty, expected empty",
+			currentState:         []BucketState{},
+			desiredState:         []BucketState{},
+			expectedBucketsState: nil,
+		},
+		{
+			description:  "current state empty, desired state with policy, expected empty because the bucket gets created",
+			currentState: []BucketState{},
+			desiredState: []BucketState{
+				{
+					Name:   "5xchu-g8s-access-logs",
+					Policy: `{"Version":"2012-10-17"}`,
+				},
+			},
+			expectedBucketsState: nil,
+		},
+		{
+			description: "current state without policy, desired state with policy, expected desired state",
+			currentState: []BucketState{
+				{
+					Name: "5xchu-g8s-access-logs",
+				},
+				{
+					Name: "000000000000-g8s-5xchu",
+				},
+			},
+			desiredState: []BucketState{
+				{
+					Name:   "5xchu-g8s-access-logs",
+					Policy: `{"Version":"2012-10-17"}`,
+				},
+				{
+					Name: "000000000000-g8s-5xchu",
+				},
+			},
+			expectedBucketsState: []BucketState{
+				{
+					Name:   "5xchu-g8s-access-logs",
+					Policy: `{"Version":"2012-10-17"}`,
+				},
+			},
+		},
+		{
+			description: "current state with differently formatted equal policy, expected empty",
+			currentState: []BucketState{
+				{
+					Name:   "5xchu-g8s-access-logs",
+					Policy: `{ "Version": "2012-10-17" }`,
+				},
+			},
+			desiredState: []BucketState{
+				{
+					Name:   "5xchu-g8s-access-logs",
+					Policy: `{"Version":"2012-10-17"}`,
+				},
+			},
+			expectedBucketsState: nil,
+		},
+		{
+			description: "current state with policy, desired state without policy, expected empty",
+			currentState: []BucketState{
+				{
+					Name:   "5xchu-g8s-access-logs",
+					Policy: `{"Version":"2012-10-17"}`,
+				},
+			},
+			desiredState: []BucketState{
+				{
+					Name: "5xchu-g8s-access-logs",
+				},
+			},
+			expectedBucketsState: nil,
+		},
+	}
+
+	var err error
+	var newResource *Resource
+	{
+		resourceConfig := DefaultConfig()
+		resourceConfig.Logger = microloggertest.New()
+		resourceConfig.InstallationName = "test-install"
+
+		newResource, err = New(resourceConfig)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			obj := &v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "5xchu",
+					},
+				},
+			}
+
+			result, err := newResource.newUpdateChange(context.TODO(), obj, tc.currentState, tc.desiredState)
+			if err != nil {
+				t.Fatalf("expected '%v' got '%#v'", nil, err)
+			}
+			updateChanges, ok := result.([]BucketState)
+			if !ok {
+				t.Fatalf("expected '%T', got '%T'", updateChanges, result)
+			}
+			if !reflect.DeepEqual(tc.expectedBucketsState, updateChanges) {
+				t.Fatalf("expected %#v, got %#v", tc.expectedBucketsState, updateChanges)
+			}
+		})
+	}
+}
+
+func Test_Resource_S3Bucket_loggingBucketPolicy(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description    string
+		region         string
+		expectedPolicy string
+	}{
+		{
+			description:    "unknown region, expected policy without ELB access logs",
+			region:         "",
+			expectedPolicy: `{"Version":"2012-10-17","Statement":[{"Sid":"VPCFlowLogsWrite","Effect":"Allow","Principal":{"Service":"delivery.logs.amazonaws.com"},"Action":"s3:PutObject","Resource":"arn:aws:s3:::5xchu-g8s-access-logs/vpc-flow-logs/AWSLogs/000000000000/*","Condition":{"StringEquals":{"s3:x-amz-acl":"bucket-owner-full-control"}}},{"Sid":"VPCFlowLogsAclCheck","Effect":"Allow","Principal":{"Service":"delivery.logs.amazonaws.com"},"Action":"s3:GetBucketAcl","Resource":"arn:aws:s3:::5xchu-g8s-access-logs"}]}`,
+		},
+		{
+			description:    "eu-central-1",
+			region:         "eu-central-1",
+			expectedPolicy: `{"Version":"2012-10-17","Statement":[{"Sid":"VPCFlowLogsWrite","Effect":"Allow","Principal":{"Service":"delivery.logs.amazonaws.com"},"Action":"s3:PutObject","Resource":"arn:aws:s3:::5xchu-g8s-access-logs/vpc-flow-logs/AWSLogs/000000000000/*","Condition":{"StringEquals":{"s3:x-amz-acl":"bucket-owner-full-control"}}},{"Sid":"VPCFlowLogsAclCheck","Effect":"Allow","Principal":{"Service":"delivery.logs.amazonaws.com"},"Action":"s3:GetBucketAcl","Resource":"arn:aws:s3:::5xchu-g8s-access-logs"},{"Sid":"ELBAccessLogs","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::054676820928:root"},"Action":"s3:PutObject","Resource":"arn:aws:s3:::5xchu-g8s-access-logs/*/AWSLogs/000000000000/*"}]}`,
+		},
+		{
+			description:    "cn-north-1",
+			region:         "cn-north-1",
+			expectedPolicy: `{"Version":"2012-10-17","Statement":[{"Sid":"VPCFlowLogsWrite","Effect":"Allow","Principal":{"Service":"delivery.logs.amazonaws.com"},"Action":"s3:PutObject","Resource":"arn:aws-cn:s3:::5xchu-g8s-access-logs/vpc-flow-logs/AWSLogs/000000000000/*","Condition":{"StringEquals":{"s3:x-amz-acl":"bucket-owner-full-control"}}},{"Sid":"VPCFlowLogsAclCheck","Effect":"Allow","Principal":{"Service":"delivery.logs.amazonaws.com"},"Action":"s3:GetBucketAcl","Resource":"arn:aws-cn:s3:::5xchu-g8s-access-logs"},{"Sid":"ELBAccessLogs","Effect":"Allow","Principal":{"AWS":"arn:aws-cn:iam::638102146993:root"},"Action":"s3:PutObject","Resource":"arn:aws-cn:s3:::5xchu-g8s-access-logs/*/AWSLogs/000000000000/*"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			customObject := v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						Region: tc.region,
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "5xchu",
+					},
+				},
+			}
+
+			policy, err := loggingBucketPolicy(customObject, "000000000000")
+			if err != nil {
+				t.Fatalf("expected '%v' got '%#v'", nil, err)
+			}
+			if policy != tc.expectedPolicy {
+				t.Fatalf("expected %q, got %q", tc.expectedPolicy, policy)
+			}
+		})
+	}
+}
@@ -75,6 +75,7 @@ func Test_CurrentState(t *testing.T) {
 			{
 				c := Config{}
 				c.CertWatcher = legacytest.NewService()
+				c.CertsSearcher = &CertsSearcherMock{}
 				c.CloudConfig = cloudconfig
 				c.Encrypter = &encrypter.EncrypterMock{}
 				c.Logger = microloggertest.New()
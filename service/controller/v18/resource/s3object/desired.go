@@ -3,8 +3,10 @@ package s3object
 import (
 	"context"
 
+	"github.com/giantswarm/certs"
 	"github.com/giantswarm/legacycerts/legacy"
 	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
 	"github.com/giantswarm/randomkeys"
 
 	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
@@ -30,7 +32,8 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 	}
 
 	var accountID string
-	var certs legacy.AssetsBundle
+	var clusterCerts legacy.AssetsBundle
+	var etcdPeerTLS certs.TLS
 	var tlsAssets *legacy.CompactTLSAssets
 	var clusterKeys randomkeys.Cluster
 	{
@@ -38,11 +41,11 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
-		certs, err = r.certWatcher.SearchCerts(key.ClusterID(customObject))
+		clusterCerts, err = r.certWatcher.SearchCerts(key.ClusterID(customObject))
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
-		tlsAssets, err = r.encrypter.EncryptTLSAssets(ctx, customObject, certs)
+		tlsAssets, err = r.encrypter.EncryptTLSAssets(ctx, customObject, clusterCerts)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -52,10 +55,28 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 		}
 	}
 
+	// The etcd peer certificate of guest clusters with multiple masters is
+	// issued by cert-operator once the certconfig resource created its
+	// CertConfig. Masters can not form the etcd cluster without it, so the
+	// reconciliation is canceled until it got issued. The certificate is not
+	// needed anymore when the guest cluster is deleted.
+	if key.MasterCount(customObject) > 1 && !key.IsDeleted(customObject) {
+		etcdPeerTLS, err = r.certsSearcher.SearchTLS(key.ClusterID(customObject), certs.Cert(key.EtcdPeerCert))
+		if certs.IsTimeout(err) {
+			r.logger.LogCtx(ctx, "level", "debug", "message", "etcd peer certificate not issued yet")
+			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling reconciliation")
+			reconciliationcanceledcontext.SetCanceled(ctx)
+
+			return nil, nil
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	output := map[string]BucketObjectState{}
 
 	{
-		b, err := r.cloudConfig.NewMasterTemplate(ctx, customObject, *tlsAssets, clusterKeys)
+		b, err := r.cloudConfig.NewMasterTemplate(ctx, customObject, *tlsAssets, etcdPeerTLS, clusterKeys)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
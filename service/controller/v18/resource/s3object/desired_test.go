@@ -69,6 +69,7 @@ func Test_DesiredState(t *testing.T) {
 				c.Logger = microloggertest.New()
 				c.Encrypter = &encrypter.EncrypterMock{}
 				c.CertWatcher = legacytest.NewService()
+				c.CertsSearcher = &CertsSearcherMock{}
 				c.CloudConfig = cloudconfig
 				c.RandomKeySearcher = randomkeystest.NewSearcher()
 				newResource, err = New(c)
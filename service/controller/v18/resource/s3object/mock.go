@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/certs"
 	"github.com/giantswarm/legacycerts/legacy"
 	"github.com/giantswarm/randomkeys"
 )
@@ -71,14 +72,28 @@ type CloudConfigMock struct {
 	template string
 }
 
-func (c *CloudConfigMock) NewMasterTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, certs legacy.CompactTLSAssets, randomKeys randomkeys.Cluster) (string, error) {
+func (c *CloudConfigMock) NewMasterTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts legacy.CompactTLSAssets, etcdPeerTLS certs.TLS, randomKeys randomkeys.Cluster) (string, error) {
 	return c.template, nil
 }
 
-func (c *CloudConfigMock) NewWorkerTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, certs legacy.CompactTLSAssets) (string, error) {
+func (c *CloudConfigMock) NewWorkerTemplate(ctx context.Context, customObject v1alpha1.AWSConfig, clusterCerts legacy.CompactTLSAssets) (string, error) {
 	return c.template, nil
 }
 
+type CertsSearcherMock struct {
+	certs.Interface
+}
+
+func (c *CertsSearcherMock) SearchTLS(clusterID string, cert certs.Cert) (certs.TLS, error) {
+	tls := certs.TLS{
+		CA:  []byte(fmt.Sprintf("%s-%s-ca", clusterID, cert)),
+		Crt: []byte(fmt.Sprintf("%s-%s-crt", clusterID, cert)),
+		Key: []byte(fmt.Sprintf("%s-%s-key", clusterID, cert)),
+	}
+
+	return tls, nil
+}
+
 type KMSClientMock struct {
 	kmsiface.KMSAPI
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/giantswarm/certs"
 	"github.com/giantswarm/legacycerts/legacy"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
@@ -22,6 +23,7 @@ const (
 // Config represents the configuration used to create a new cloudformation resource.
 type Config struct {
 	CertWatcher       legacy.Searcher
+	CertsSearcher     certs.Interface
 	CloudConfig       cloudconfig.Interface
 	Encrypter         encrypter.Interface
 	Logger            micrologger.Logger
@@ -31,6 +33,7 @@ type Config struct {
 // Resource implements the cloudformation resource.
 type Resource struct {
 	certWatcher       legacy.Searcher
+	certsSearcher     certs.Interface
 	cloudConfig       cloudconfig.Interface
 	encrypter         encrypter.Interface
 	logger            micrologger.Logger
@@ -42,6 +45,9 @@ func New(config Config) (*Resource, error) {
 	if config.CertWatcher == nil {
 		return nil, microerror.Maskf(invalidConfigError, "config.CertWatcher must not be empty")
 	}
+	if config.CertsSearcher == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CertsSearcher must not be empty", config)
+	}
 	if config.CloudConfig == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.CloudConfig must not be empty", config)
 	}
@@ -57,6 +63,7 @@ func New(config Config) (*Resource, error) {
 
 	r := &Resource{
 		certWatcher:       config.CertWatcher,
+		certsSearcher:     config.CertsSearcher,
 		cloudConfig:       config.CloudConfig,
 		encrypter:         config.Encrypter,
 		logger:            config.Logger,
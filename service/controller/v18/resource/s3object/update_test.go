@@ -154,6 +154,7 @@ func Test_Resource_S3Object_newUpdate(t *testing.T) {
 			{
 				c := Config{}
 				c.CertWatcher = legacytest.NewService()
+				c.CertsSearcher = &CertsSearcherMock{}
 				c.CloudConfig = cloudconfig
 				c.Encrypter = &encrypter.EncrypterMock{}
 				c.Logger = microloggertest.New()
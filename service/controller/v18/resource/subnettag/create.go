@@ -0,0 +1,56 @@
+package subnettag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// EnsureCreated tags the existing subnets of the guest cluster with the cloud
+// provider tag of the guest cluster. Tagging is idempotent, which is why the
+// subnets are tagged on every reconciliation.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !key.IsExistingVPC(customObject) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not tagging subnets because the guest cluster does not use an existing VPC")
+		return nil
+	}
+
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	subnetIDs := subnetIDs(customObject)
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("tagging %d existing subnets", len(subnetIDs)))
+
+	i := &ec2.CreateTagsInput{
+		Resources: aws.StringSlice(subnetIDs),
+		Tags: []*ec2.Tag{
+			{
+				Key:   aws.String(key.ClusterCloudProviderTag(customObject)),
+				Value: aws.String(key.CloudProviderTagSharedValue),
+			},
+		},
+	}
+
+	_, err = sc.AWSClient.EC2.CreateTags(i)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("tagged %d existing subnets", len(subnetIDs)))
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package subnettag
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	awsclient "github.com/giantswarm/aws-operator/client/aws"
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+)
+
+func Test_Resource_SubnetTag_EnsureCreated(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		description  string
+		obj          interface{}
+		expectedTags map[string][]string
+	}{
+		{
+			description: "guest cluster with its own VPC, expected no tags",
+			obj: &v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					Cluster: v1alpha1.Cluster{
+						ID: "5xchu",
+					},
+				},
+			},
+			expectedTags: nil,
+		},
+		{
+			description: "guest cluster in an existing VPC, expected shared tags on all subnets",
+			obj: &v1alpha1.AWSConfig{
+				Spec: v1alpha1.AWSConfigSpec{
+					AWS: v1alpha1.AWSConfigSpecAWS{
+						VPC: v1alpha1.AWSConfigSpecAWSVPC{
+							ID:               "vpc-1234",
+							PrivateSubnetIDs: []string{"subnet-private-a", "subnet-private-b"},
+							PublicSubnetIDs:  []string{"subnet-public-a", "subnet-public-b"},
+						},
+					},
+					Cluster: v1alpha1.Cluster{
+						ID: "5xchu",
+					},
+				},
+			},
+			expectedTags: map[string][]string{
+				"kubernetes.io/cluster/5xchu=shared": {
+					"subnet-private-a",
+					"subnet-private-b",
+					"subnet-public-a",
+					"subnet-public-b",
+				},
+			},
+		},
+	}
+
+	var err error
+	var newResource *Resource
+	{
+		c := Config{
+			Logger: microloggertest.New(),
+		}
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ec2Client := &EC2ClientMock{}
+
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+				AWSClient: awsclient.Clients{
+					EC2: ec2Client,
+				},
+			})
+
+			err := newResource.EnsureCreated(ctx, tc.obj)
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+
+			if !reflect.DeepEqual(ec2Client.createdTags, tc.expectedTags) {
+				t.Fatalf("expected created tags %v got %v", tc.expectedTags, ec2Client.createdTags)
+			}
+			if ec2Client.deletedTags != nil {
+				t.Fatalf("expected deleted tags %v got %v", nil, ec2Client.deletedTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package subnettag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-operator/service/controller/v18/controllercontext"
+	"github.com/giantswarm/aws-operator/service/controller/v18/key"
+)
+
+// EnsureDeleted removes the cloud provider tag of the guest cluster from its
+// existing subnets. The subnets themselves are left intact, since they are not
+// owned by the guest cluster.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !key.IsExistingVPC(customObject) {
+		r.logger.LogCtx(ctx, "level", "debug", "message", "not untagging subnets because the guest cluster does not use an existing VPC")
+		return nil
+	}
+
+	sc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	subnetIDs := subnetIDs(customObject)
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("untagging %d existing subnets", len(subnetIDs)))
+
+	// Only the shared tag is removed, so that tags of the same key not set by
+	// the operator are kept.
+	i := &ec2.DeleteTagsInput{
+		Resources: aws.StringSlice(subnetIDs),
+		Tags: []*ec2.Tag{
+			{
+				Key:   aws.String(key.ClusterCloudProviderTag(customObject)),
+				Value: aws.String(key.CloudProviderTagSharedValue),
+			},
+		},
+	}
+
+	_, err = sc.AWSClient.EC2.DeleteTags(i)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("untagged %d existing subnets", len(subnetIDs)))
+
+	return nil
+}
+
+func subnetIDs(customObject v1alpha1.AWSConfig) []string {
+	var ids []string
+
+	ids = append(ids, key.PrivateSubnetIDs(customObject)...)
+	ids = append(ids, key.PublicSubnetIDs(customObject)...)
+
+	return ids
+}
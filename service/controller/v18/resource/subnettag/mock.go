@@ -0,0 +1,39 @@
+package subnettag
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// EC2ClientMock records the resources and tags of the tagging requests.
+type EC2ClientMock struct {
+	ec2iface.EC2API
+
+	createdTags map[string][]string
+	deletedTags map[string][]string
+}
+
+func (e *EC2ClientMock) CreateTags(i *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	if e.createdTags == nil {
+		e.createdTags = map[string][]string{}
+	}
+	for _, t := range i.Tags {
+		tag := aws.StringValue(t.Key) + "=" + aws.StringValue(t.Value)
+		e.createdTags[tag] = append(e.createdTags[tag], aws.StringValueSlice(i.Resources)...)
+	}
+
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (e *EC2ClientMock) DeleteTags(i *ec2.DeleteTagsInput) (*ec2.DeleteTagsOutput, error) {
+	if e.deletedTags == nil {
+		e.deletedTags = map[string][]string{}
+	}
+	for _, t := range i.Tags {
+		tag := aws.StringValue(t.Key) + "=" + aws.StringValue(t.Value)
+		e.deletedTags[tag] = append(e.deletedTags[tag], aws.StringValueSlice(i.Resources)...)
+	}
+
+	return &ec2.DeleteTagsOutput{}, nil
+}
@@ -0,0 +1,43 @@
+package subnettag
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "subnettagv18"
+)
+
+// Config represents the configuration used to create a new subnettag resource.
+type Config struct {
+	Logger micrologger.Logger
+}
+
+// Resource implements the subnettag resource. It tags the existing subnets of
+// guest clusters deployed into existing VPCs with the cloud provider tag of the
+// guest cluster, so that Kubernetes is able to place load balancers in them.
+// The subnets are tagged as shared, since they are not owned by the guest
+// cluster.
+type Resource struct {
+	logger micrologger.Logger
+}
+
+// New creates a new configured subnettag resource.
+func New(config Config) (*Resource, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		logger: config.Logger,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -3,7 +3,9 @@ package workerasgname
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
@@ -40,7 +42,8 @@ func (r *Resource) Name() string {
 	return Name
 }
 
-// EnsureCreated retrieves worker ASG name from CF stack when it is ready.
+// EnsureCreated retrieves the worker ASG names of all node pools from CF stack
+// when it is ready.
 func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
@@ -52,7 +55,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	var workerASGName string
+	var workerASGNames []string
 	{
 		r.logger.LogCtx(ctx, "level", "debug", "message", "finding the guest cluster worker ASG name in the cloud formation stack")
 
@@ -73,7 +76,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 			return microerror.Mask(err)
 		}
 
-		workerASGName, err = controllerCtx.CloudFormation.GetOutputValue(stackOutputs, key.WorkerASGKey)
+		workerASGNames, err = getWorkerASGNames(&controllerCtx.CloudFormation, stackOutputs)
 		if cloudformationservice.IsOutputNotFound(err) {
 			// Since we are transitioning between versions we will have situations in
 			// which old clusters are updated to new versions and miss the ASG name in
@@ -95,7 +98,7 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		r.logger.LogCtx(ctx, "level", "debug", "message", "found the guest cluster worker ASG name in the cloud formation stack")
 	}
 
-	controllerCtx.Status.Drainer.WorkerASGName = workerASGName
+	controllerCtx.Status.Drainer.WorkerASGNames = workerASGNames
 
 	return nil
 }
@@ -103,3 +106,20 @@ func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 	return nil
 }
+
+// getWorkerASGNames returns the ASG names of all node pools. Guest clusters
+// created before node pools were supported only provide the ASG name of their
+// single worker ASG.
+func getWorkerASGNames(cf *cloudformationservice.CloudFormation, stackOutputs []*cloudformation.Output) ([]string, error) {
+	v, err := cf.GetOutputValue(stackOutputs, key.WorkerASGNamesKey)
+	if cloudformationservice.IsOutputNotFound(err) {
+		v, err = cf.GetOutputValue(stackOutputs, key.WorkerASGKey)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return strings.Split(v, ","), nil
+}
@@ -0,0 +1,3 @@
+package cloudconfig
+
+const DataKey = `{{ .DataKey }}`
@@ -1,10 +1,45 @@
 package cloudconfig
 
 const DecryptKeysAssetsScript = `#!/bin/bash -e
+
+data_key_path=/etc/kubernetes/data-key.enc
+
+data_key_dir=$(mktemp -d /run/decrypt-keys-assets.XXXXXXXX)
+trap "rm -rf $data_key_dir" EXIT
+
+# Assets are encrypted with AES-GCM using the cluster's data key. They are
+# decrypted by the decrypt command of the operator image, which verifies the
+# authentication tag of every asset before writing it. The base64 encoded
+# plaintext data key is kept on tmpfs and passed to the command on stdin, so
+# that it never shows up in the arguments of a process.
+decrypt_assets () {
+    echo decrypting keys assets
+    rkt run \
+      --volume=datakey,kind=host,source=$data_key_dir,readOnly=true \
+      --mount=volume=datakey,target=/data-key \
+      --volume=assets,kind=host,source=/etc/kubernetes/encryption,readOnly=false \
+      --mount=volume=assets,target=/etc/kubernetes/encryption \
+      --uuid-file-save=/var/run/coreos/decrypt-keys-assets-decrypter.uuid \
+      --net=none \
+      --insecure-options=image \
+      docker://{{ .DecrypterImage }} --exec=/bin/sh -- \
+        -ec \
+        'assets=$(find /etc/kubernetes/encryption -name "*.enc")
+        if [ -n "$assets" ]; then
+          /opt/aws-operator/aws-operator decrypt $assets < /data-key/plaintext
+        fi'
+
+    rkt rm --uuid-file=/var/run/coreos/decrypt-keys-assets-decrypter.uuid || :
+}
 {{ if eq .EncrypterType "vault" }}
 token_path=/var/token
 nonce_path=/var/nonce
 
+# Requests are scoped to the Vault namespace the auth method and the transit
+# secrets engine are mounted in, if any.
+vault_headers=()
+{{ if .VaultNamespace }}vault_headers+=(--header "X-Vault-Namespace: {{ .VaultNamespace }}"){{ end }}
+
 wait_for_vault_elb(){
     local service_name="$1"
     local state="${2:-active}"
@@ -31,7 +66,7 @@ token_exists () {
 token_is_valid() {
   #  https://www.vaultproject.io/api/auth/token/index.html#lookup-a-token-self-
   echo "Checking token validity"
-  token_lookup=$(curl -k \
+  token_lookup=$(curl -k "${vault_headers[@]}" \
     --request GET \
     --silent \
     --header "X-Vault-Token: $(cat $token_path)" \
@@ -59,26 +94,22 @@ main () {
         logger $0 "current vault token is still valid"
     fi
 
-    echo decrypting keys assets
-    shopt -s nullglob
-    for encKey in $(find /etc/kubernetes/encryption -name "*.enc"); do
-      echo decrypting $encKey
-      f=$(mktemp $encKey.XXXXXXXX)
-      cat <<EOF > data.json
+    echo decrypting data key
+    cat <<EOF > data.json
 {
-  "ciphertext": "$(cat $encKey)"
+  "ciphertext": "$(cat $data_key_path)"
 }
 EOF
-      curl -k \
-        --header "X-Vault-Token: $(cat $token_path)" \
-        --silent \
-        --request POST \
-        --data @data.json \
-        {{ .VaultAddress }}/v1/transit/decrypt/{{ .EncryptionKey }} | \
-        jq -r .data.plaintext | base64 -d > $f
-      mv -f $f ${encKey%.enc}
-      rm -f data.json
-    done;
+    curl -k "${vault_headers[@]}" \
+      --header "X-Vault-Token: $(cat $token_path)" \
+      --silent \
+      --request POST \
+      --data @data.json \
+      {{ .VaultAddress }}/v1/{{ .VaultTransitMountPath }}/decrypt/{{ .EncryptionKey }} | \
+      jq -r .data.plaintext > $data_key_dir/plaintext
+    rm -f data.json
+
+    decrypt_assets
     echo done.
 }
 
@@ -106,11 +137,11 @@ EOF
 )
     fi
 
-    curl -k \
+    curl -k "${vault_headers[@]}" \
       --request POST \
       --silent \
       --data "$login_payload" \
-      {{ .VaultAddress }}/v1/auth/aws/login | tee  \
+      {{ .VaultAddress }}/v1/auth/{{ .VaultAWSMountPath }}/login | tee  \
       >(jq -r .auth.client_token > $token_path) \
       >(jq -r .auth.metadata.nonce > $nonce_path)
 }
@@ -118,29 +149,28 @@ EOF
 main
 {{ else }}
 rkt run \
-  --volume=keys,kind=host,source=/etc/kubernetes/encryption,readOnly=false \
-  --mount=volume=keys,target=/etc/kubernetes/encryption \
+  --volume=datakey,kind=host,source=$data_key_path,readOnly=true \
+  --mount=volume=datakey,target=/etc/kubernetes/data-key.enc \
+  --volume=plaintext,kind=host,source=$data_key_dir,readOnly=false \
+  --mount=volume=plaintext,target=/data-key \
   --uuid-file-save=/var/run/coreos/decrypt-keys-assets.uuid \
   --volume=dns,kind=host,source=/etc/resolv.conf,readOnly=true --mount volume=dns,target=/etc/resolv.conf \
   --net=host \
   --trust-keys-from-https \
   quay.io/coreos/awscli:025a357f05242fdad6a81e8a6b520098aa65a600 --exec=/bin/bash -- \
     -ec \
-    'echo decrypting keys assets
-    shopt -s nullglob
-    for encKey in $(find /etc/kubernetes/encryption -name "*.enc"); do
-      echo decrypting $encKey
-      f=$(mktemp $encKey.XXXXXXXX)
-      /usr/bin/aws \
-        --region {{.AWS.Region}} kms decrypt \
-        --ciphertext-blob fileb://$encKey \
-        --output text \
-        --query Plaintext \
-      | base64 -d > $f
-      mv -f $f ${encKey%.enc}
-    done;
-    echo done.'
+    'echo decrypting data key
+    base64 -d /etc/kubernetes/data-key.enc > /tmp/data-key.bin
+    /usr/bin/aws \
+      --region {{.AWS.Region}} kms decrypt \
+      --ciphertext-blob fileb:///tmp/data-key.bin \
+      --output text \
+      --query Plaintext \
+    > /data-key/plaintext'
 
 rkt rm --uuid-file=/var/run/coreos/decrypt-keys-assets.uuid || :
+
+decrypt_assets
+echo done.
 {{ end }}
 `
@@ -0,0 +1,42 @@
+package cloudconfig
+
+// EtcdClusterConf is a drop-in for the etcd3 unit of k8scloudconfig, which
+// configures etcd to run as a single member. Guest clusters with multiple
+// masters form an etcd cluster instead. Every member is reachable via its own
+// DNS record in the hosted zone of the guest cluster and presents the etcd peer
+// certificate, which is issued for the domains of all members. The member name
+// is provided by the small cloudconfig of the master instance. The initial
+// cluster state is provided by the etcd-cluster-state script.
+const EtcdClusterConf = `
+[Service]
+EnvironmentFile=/etc/etcd-member-environment
+EnvironmentFile=-/run/etcd-cluster-state
+ExecStartPre=/opt/bin/etcd-cluster-state
+ExecStart=
+ExecStart=/usr/bin/docker run \
+    -v /etc/ssl/certs/ca-certificates.crt:/etc/ssl/certs/ca-certificates.crt \
+    -v /etc/kubernetes/ssl/etcd/:/etc/etcd \
+    -v /var/lib/etcd/:/var/lib/etcd  \
+    --net=host  \
+    --name $NAME \
+    $IMAGE \
+    etcd \
+    --name ${ETCD_MEMBER_NAME} \
+    --trusted-ca-file /etc/etcd/server-ca.pem \
+    --cert-file /etc/etcd/server-crt.pem \
+    --key-file /etc/etcd/server-key.pem\
+    --client-cert-auth=true \
+    --peer-trusted-ca-file /etc/etcd/peer-ca.pem \
+    --peer-cert-file /etc/etcd/peer-crt.pem \
+    --peer-key-file /etc/etcd/peer-key.pem \
+    --peer-client-cert-auth=true \
+    --advertise-client-urls=https://{{ .Cluster.Etcd.Domain }}:{{ .Cluster.Etcd.Port }} \
+    --initial-advertise-peer-urls=https://${ETCD_MEMBER_NAME}.{{ .Cluster.ID }}.k8s.{{ .AWS.HostedZones.API.Name }}:2380 \
+    --listen-client-urls=https://0.0.0.0:2379 \
+    --listen-peer-urls=https://${DEFAULT_IPV4}:2380 \
+    --initial-cluster-token k8s-etcd-cluster \
+    --initial-cluster {{ range $i, $m := .AWS.Masters }}{{ if $i }},{{ end }}etcd{{ $i }}=https://etcd{{ $i }}.{{ $.Cluster.ID }}.k8s.{{ $.AWS.HostedZones.API.Name }}:2380{{ end }} \
+    --initial-cluster-state ${ETCD_INITIAL_CLUSTER_STATE} \
+    --data-dir=/var/lib/etcd \
+    --enable-v2
+`
@@ -0,0 +1,66 @@
+package cloudconfig
+
+// EtcdClusterStateScript finds out whether the etcd member of a master
+// bootstraps a new etcd cluster together with the other masters or joins the
+// existing etcd cluster, e.g. because the master got replaced and lost its
+// data dir. Members joining the existing etcd cluster are added to it before
+// etcd starts. Stale members of replaced masters are removed first. The initial
+// cluster state is written to the environment file read by the etcd3 unit.
+const EtcdClusterStateScript = `#!/bin/bash -e
+
+state_path=/run/etcd-cluster-state
+peer_url=https://${ETCD_MEMBER_NAME}.{{ .Cluster.ID }}.k8s.{{ .AWS.HostedZones.API.Name }}:2380
+
+etcdctl () {
+    docker run --rm --net=host \
+      -v /etc/kubernetes/ssl/etcd/:/etc/etcd \
+      -e ETCDCTL_API=3 \
+      $IMAGE \
+      etcdctl \
+      --cacert /etc/etcd/client-ca.pem \
+      --cert /etc/etcd/client-crt.pem \
+      --key /etc/etcd/client-key.pem \
+      --endpoints https://{{ .Cluster.Etcd.Domain }}:{{ .Cluster.Etcd.Port }} \
+      --dial-timeout 5s \
+      "$@"
+}
+
+write_state () {
+    echo "ETCD_INITIAL_CLUSTER_STATE=$1" > $state_path
+}
+
+# etcd ignores the initial cluster state of members which already have a data
+# dir.
+if [ -d /var/lib/etcd/member ]; then
+    write_state existing
+    exit 0
+fi
+
+# The etcd cluster does not exist yet in case its members can not be listed,
+# so the member bootstraps it together with the other masters.
+if ! members=$(etcdctl member list); then
+    echo "etcd cluster not found, bootstrapping new etcd cluster"
+    write_state new
+    exit 0
+fi
+
+member=$(echo "$members" | grep ", ${peer_url}," || true)
+member_id=$(echo "$member" | cut -d, -f1)
+member_status=$(echo "$member" | cut -d, -f2 | tr -d ' ')
+
+# Members which never started are either part of the initial etcd cluster or
+# were already added to the existing etcd cluster. Started members without
+# data dir belong to the replaced master and are removed.
+if [ "$member_status" == "started" ]; then
+    echo "removing stale etcd member $member_id"
+    etcdctl member remove $member_id
+    member_status=""
+fi
+
+if [ -z "$member_status" ]; then
+    echo "adding etcd member ${ETCD_MEMBER_NAME}"
+    etcdctl member add ${ETCD_MEMBER_NAME} --peer-urls=${peer_url}
+fi
+
+write_state existing
+`
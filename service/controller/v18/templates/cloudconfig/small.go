@@ -17,7 +17,12 @@ const Small = `#!/bin/bash
 # with it as an argument.
 
 . /etc/environment
-
+{{ if .EtcdMemberName }}
+# Guest clusters with multiple masters run an etcd cluster. The final
+# cloudconfig is the same for all masters, so the name of the etcd member
+# running on this master is provided here.
+echo "ETCD_MEMBER_NAME={{ .EtcdMemberName }}" > /etc/etcd-member-environment
+{{ end }}
 # Wait for S3 bucket to be available.
 retry=30
 
@@ -2,31 +2,78 @@ package guest
 
 const AutoScalingGroup = `{{define "autoscaling_group"}}
 {{- $v := .Guest.AutoScalingGroup }}
-  {{ $v.ASGType }}AutoScalingGroup:
+{{- range $p := $v.NodePools }}
+  {{ $p.ASGType }}AutoScalingGroup:
     Type: "AWS::AutoScaling::AutoScalingGroup"
     Properties:
       VPCZoneIdentifier:
-        - !Ref PrivateSubnet
-      AvailabilityZones: [{{ $v.WorkerAZ }}]
-      DesiredCapacity: {{ $v.ASGMinSize }}
-      MinSize: {{ $v.ASGMinSize }}
-      MaxSize: {{ $v.ASGMaxSize }}
-      LaunchConfigurationName: !Ref {{ $v.ASGType }}LaunchConfiguration
+      {{- range $v.PrivateSubnets }}
+        - !Ref {{ . }}
+      {{- end }}
+      AvailabilityZones: [{{ range $i, $az := $v.WorkerAZs }}{{ if $i }}, {{ end }}{{ $az }}{{ end }}]
+      {{- if not $p.ScalingEnabled }}
+      DesiredCapacity: {{ $p.ASGMinSize }}
+      {{- end }}
+      MinSize: {{ $p.ASGMinSize }}
+      MaxSize: {{ $p.ASGMaxSize }}
+      {{- if $p.Spot.Enabled }}
+      MixedInstancesPolicy:
+        InstancesDistribution:
+          OnDemandBaseCapacity: {{ $p.Spot.OnDemandBaseCapacity }}
+          OnDemandPercentageAboveBaseCapacity: {{ $p.Spot.OnDemandPercentageAboveBaseCapacity }}
+          SpotAllocationStrategy: lowest-price
+          SpotInstancePools: {{ len $p.Spot.InstanceTypes }}
+          {{- if $p.Spot.MaxPrice }}
+          SpotMaxPrice: "{{ $p.Spot.MaxPrice }}"
+          {{- end }}
+        LaunchTemplate:
+          LaunchTemplateSpecification:
+            LaunchTemplateId: !Ref {{ $p.ASGType }}LaunchTemplate
+            Version: !GetAtt {{ $p.ASGType }}LaunchTemplate.LatestVersionNumber
+          Overrides:
+          {{- range $p.Spot.InstanceTypes }}
+            - InstanceType: {{ . }}
+          {{- end }}
+      {{- else }}
+      LaunchTemplate:
+        LaunchTemplateId: !Ref {{ $p.ASGType }}LaunchTemplate
+        Version: !GetAtt {{ $p.ASGType }}LaunchTemplate.LatestVersionNumber
+      {{- end }}
+      {{- if $v.IngressLoadBalancer }}
       LoadBalancerNames:
         - !Ref IngressLoadBalancer
+      {{- end }}
+      {{- if $v.IngressTargetGroups }}
+      TargetGroupARNs:
+      {{- range $v.IngressTargetGroups }}
+        - !Ref {{ . }}
+      {{- end }}
+      {{- end }}
       HealthCheckGracePeriod: {{ $v.HealthCheckGracePeriod }}
       MetricsCollection:
         - Granularity: "1Minute"
       Tags:
         - Key: Name
-          Value: {{ $v.ClusterID }}-{{ $v.ASGType }}
+          Value: {{ $v.ClusterID }}-{{ $p.ASGType }}
           PropagateAtLaunch: true
+        - Key: giantswarm.io/node-pool
+          Value: {{ $p.Name }}
+          PropagateAtLaunch: true
+        {{- if $p.ScalingEnabled }}
+        - Key: {{ $v.ClusterAutoscalerTags.Enabled }}
+          Value: "true"
+          PropagateAtLaunch: false
+        - Key: {{ $v.ClusterAutoscalerTags.Cluster }}
+          Value: owned
+          PropagateAtLaunch: false
+        {{- end }}
     UpdatePolicy:
       AutoScalingRollingUpdate:
         # minimum amount of instances that must always be running during a rolling update
-        MinInstancesInService: {{ $v.MinInstancesInService }}
+        MinInstancesInService: {{ $p.MinInstancesInService }}
         # only do a rolling update of this amount of instances max
-        MaxBatchSize: {{ $v.MaxBatchSize }}
+        MaxBatchSize: {{ $p.MaxBatchSize }}
         # after creating a new instance, pause operations on the ASG for this amount of time
         PauseTime: {{ $v.RollingUpdatePauseTime }}
+{{- end }}
 {{end}}`
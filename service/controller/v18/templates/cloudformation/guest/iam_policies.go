@@ -85,6 +85,24 @@ const IAMPolicies = `{{define "iam_policies"}}
           - Effect: "Allow"
             Action: "ec2:DetachVolume"
             Resource: "*"
+{{ if $v.ClusterAutoscalerTag }}
+          - Effect: "Allow"
+            Action:
+              - "autoscaling:DescribeAutoScalingGroups"
+              - "autoscaling:DescribeAutoScalingInstances"
+              - "autoscaling:DescribeLaunchConfigurations"
+              - "autoscaling:DescribeTags"
+            Resource: "*"
+
+          - Effect: "Allow"
+            Action:
+              - "autoscaling:SetDesiredCapacity"
+              - "autoscaling:TerminateInstanceInAutoScalingGroup"
+            Resource: "*"
+            Condition:
+              StringEquals:
+                "autoscaling:ResourceTag/{{ $v.ClusterAutoscalerTag }}": "owned"
+{{ end }}
 {{ if $v.KMSKeyARN }}
           - Effect: "Allow"
             Action: "kms:Decrypt"
@@ -2,59 +2,61 @@ package guest
 
 const Instance = `{{ define "instance" }}
 {{- $v := .Guest.Instance }}
-  {{ $v.Master.Instance.ResourceName }}:
+{{- range $v.Masters }}
+  {{ .Instance.ResourceName }}:
     Type: "AWS::EC2::Instance"
     Description: Master instance
     DependsOn:
-    - {{ $v.Master.DockerVolume.ResourceName }}
-    - EtcdVolume
+    - {{ .DockerVolume.ResourceName }}
+    - {{ .EtcdVolume.ResourceName }}
     Properties:
-      AvailabilityZone: {{ $v.Master.AZ }}
+      AvailabilityZone: {{ .AZ }}
       IamInstanceProfile: !Ref MasterInstanceProfile
-      ImageId: {{ $v.Image.ID }}
-      InstanceType: {{ $v.Master.Instance.Type }}
-      Monitoring: {{ $v.Master.Instance.Monitoring }}
+      ImageId: {{ .Image.ID }}
+      InstanceType: {{ .Instance.Type }}
+      Monitoring: {{ .Instance.Monitoring }}
       SecurityGroupIds:
       - !Ref MasterSecurityGroup
-      SubnetId: !Ref PrivateSubnet
-      UserData: {{ $v.Master.CloudConfig }}
+      SubnetId: !Ref {{ .PrivateSubnet }}
+      UserData: {{ .CloudConfig }}
       Tags:
       - Key: Name
-        Value: {{ $v.Cluster.ID }}-master
-  {{ $v.Master.DockerVolume.ResourceName }}:
+        Value: {{ .Instance.Name }}
+  {{ .DockerVolume.ResourceName }}:
     Type: AWS::EC2::Volume
     Properties:
-{{ if eq $v.Master.EncrypterBackend "kms" }}
+{{ if eq .EncrypterBackend "kms" }}
       Encrypted: true
 {{ end }}
       Size: 50
       VolumeType: gp2
-      AvailabilityZone: {{ $v.Master.AZ }}
+      AvailabilityZone: {{ .AZ }}
       Tags:
       - Key: Name
-        Value: {{ $v.Master.DockerVolume.Name }}
-  EtcdVolume:
+        Value: {{ .DockerVolume.Name }}
+  {{ .EtcdVolume.ResourceName }}:
     Type: AWS::EC2::Volume
     Properties:
-{{ if eq $v.Master.EncrypterBackend "kms" }}
+{{ if eq .EncrypterBackend "kms" }}
       Encrypted: true
 {{ end }}
       Size: 100
       VolumeType: gp2
-      AvailabilityZone: {{ $v.Master.AZ }}
+      AvailabilityZone: {{ .AZ }}
       Tags:
       - Key: Name
-        Value: {{ $v.Master.EtcdVolume.Name }}
-  {{ $v.Master.Instance.ResourceName }}DockerMountPoint:
+        Value: {{ .EtcdVolume.Name }}
+  {{ .Instance.ResourceName }}DockerMountPoint:
     Type: AWS::EC2::VolumeAttachment
     Properties:
-      InstanceId: !Ref {{ $v.Master.Instance.ResourceName }}
-      VolumeId: !Ref {{ $v.Master.DockerVolume.ResourceName }}
+      InstanceId: !Ref {{ .Instance.ResourceName }}
+      VolumeId: !Ref {{ .DockerVolume.ResourceName }}
       Device: /dev/xvdc
-  {{ $v.Master.Instance.ResourceName }}EtcdMountPoint:
+  {{ .Instance.ResourceName }}EtcdMountPoint:
     Type: AWS::EC2::VolumeAttachment
     Properties:
-      InstanceId: !Ref {{ $v.Master.Instance.ResourceName }}
-      VolumeId: !Ref EtcdVolume
+      InstanceId: !Ref {{ .Instance.ResourceName }}
+      VolumeId: !Ref {{ .EtcdVolume.ResourceName }}
       Device: /dev/xvdh
+{{- end }}
 {{ end }}`
@@ -2,6 +2,7 @@ package guest
 
 const InternetGateway = `{{define "internet_gateway"}}
 {{- $v := .Guest.InternetGateway }}
+  {{- if not $v.ExistingVPC }}
   InternetGateway:
     Type: AWS::EC2::InternetGateway
     Properties:
@@ -28,4 +29,5 @@ const InternetGateway = `{{define "internet_gateway"}}
       DestinationCidrBlock: 0.0.0.0/0
       GatewayId:
         Ref: InternetGateway
+  {{- end }}
 {{end}}`
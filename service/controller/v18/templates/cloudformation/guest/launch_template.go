@@ -0,0 +1,35 @@
+package guest
+
+const LaunchTemplate = `{{define "launch_template"}}
+{{- $v := .Guest.LaunchTemplate }}
+{{- range $p := $v.NodePools }}
+  {{ $p.ASGType }}LaunchTemplate:
+    Type: "AWS::EC2::LaunchTemplate"
+    Properties:
+      LaunchTemplateData:
+        BlockDeviceMappings:
+        {{- range $p.WorkerBlockDeviceMappings }}
+        - DeviceName: "{{ .DeviceName }}"
+          Ebs:
+            DeleteOnTermination: {{ .DeleteOnTermination }}
+            VolumeSize: {{ .VolumeSize }}
+            VolumeType: {{ .VolumeType }}
+        {{- end }}
+        IamInstanceProfile:
+          Name: !Ref WorkerInstanceProfile
+        ImageId: {{ $p.WorkerImageID }}
+        InstanceType: {{ $p.WorkerInstanceType }}
+        Monitoring:
+          Enabled: {{ $v.WorkerInstanceMonitoring }}
+        SecurityGroupIds:
+        - !GetAtt WorkerSecurityGroup.GroupId
+        TagSpecifications:
+        - ResourceType: volume
+          Tags:
+          - Key: Name
+            Value: {{ $v.ClusterID }}-{{ $p.ASGType }}
+          - Key: giantswarm.io/node-pool
+            Value: {{ $p.Name }}
+        UserData: {{ $v.WorkerSmallCloudConfig }}
+{{- end }}
+{{end}}`
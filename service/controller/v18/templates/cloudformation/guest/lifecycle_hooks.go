@@ -2,13 +2,15 @@ package guest
 
 const LifecycleHooks = `{{ define "lifecycle_hooks" }}
 {{- $v := .Guest.LifecycleHooks }}
-  {{ $v.Worker.LifecycleHook.Name }}LifecycleHook:
+{{- range $w := $v.Workers }}
+  {{ $w.LifecycleHook.ResourceName }}LifecycleHook:
     Type: "AWS::AutoScaling::LifecycleHook"
     Properties:
       AutoScalingGroupName:
-        Ref: {{ $v.Worker.ASG.Ref }}
+        Ref: {{ $w.ASG.Ref }}
       DefaultResult: CONTINUE
       HeartbeatTimeout: 3600
-      LifecycleHookName: {{ $v.Worker.LifecycleHook.Name }}
+      LifecycleHookName: {{ $w.LifecycleHook.Name }}
       LifecycleTransition: "autoscaling:EC2_INSTANCE_TERMINATING"
+{{- end }}
 {{ end }}`
@@ -2,11 +2,25 @@ package guest
 
 const LoadBalancers = `{{define "load_balancers"}}
 {{- $v := .Guest.LoadBalancers }}
+{{- if $v.ClassicLoadBalancers }}
   ApiLoadBalancer:
     Type: AWS::ElasticLoadBalancing::LoadBalancer
     Properties:
+      {{- if $v.ELBAccessLogsEnabled }}
+      AccessLoggingPolicy:
+        EmitInterval: {{ $v.ELBAccessLogsEmitInterval }}
+        Enabled: true
+        S3BucketName: {{ $v.ELBAccessLogsBucketName }}
+        S3BucketPrefix: {{ $v.APIElbName }}
+      {{- end }}
+      {{- if $v.ELBConnectionDrainingEnabled }}
+      ConnectionDrainingPolicy:
+        Enabled: true
+        Timeout: {{ $v.ELBConnectionDrainingTimeout }}
+      {{- end }}
       ConnectionSettings:
-        IdleTimeout: 1200
+        IdleTimeout: {{ $v.APIElbIdleTimeout }}
+      CrossZone: {{ $v.ELBCrossZone }}
       HealthCheck:
         HealthyThreshold: {{ $v.ELBHealthCheckHealthyThreshold }}
         Interval: {{ $v.ELBHealthCheckInterval }}
@@ -14,7 +28,9 @@ const LoadBalancers = `{{define "load_balancers"}}
         Timeout: {{ $v.ELBHealthCheckTimeout }}
         UnhealthyThreshold: {{ $v.ELBHealthCheckUnhealthyThreshold }}
       Instances:
-      - !Ref {{ $v.MasterInstanceResourceName }}
+      {{- range $v.MasterInstanceResourceNames }}
+      - !Ref {{ . }}
+      {{- end }}
       Listeners:
       {{ range $v.APIElbPortsToOpen}}
       - InstancePort: {{ .PortInstance }}
@@ -27,14 +43,31 @@ const LoadBalancers = `{{define "load_balancers"}}
       SecurityGroups:
         - !Ref MasterSecurityGroup
       Subnets:
-        - !Ref PublicSubnet
+      {{- range $v.APIElbSubnets }}
+        - !Ref {{ . }}
+      {{- end }}
 
   IngressLoadBalancer:
     Type: AWS::ElasticLoadBalancing::LoadBalancer
+    {{- if not $v.ExistingVPC }}
     DependsOn: VPCGatewayAttachment
+    {{- end }}
     Properties:
+      {{- if $v.ELBAccessLogsEnabled }}
+      AccessLoggingPolicy:
+        EmitInterval: {{ $v.ELBAccessLogsEmitInterval }}
+        Enabled: true
+        S3BucketName: {{ $v.ELBAccessLogsBucketName }}
+        S3BucketPrefix: {{ $v.IngressElbName }}
+      {{- end }}
+      {{- if $v.ELBConnectionDrainingEnabled }}
+      ConnectionDrainingPolicy:
+        Enabled: true
+        Timeout: {{ $v.ELBConnectionDrainingTimeout }}
+      {{- end }}
       ConnectionSettings:
-        IdleTimeout: 60
+        IdleTimeout: {{ $v.IngressElbIdleTimeout }}
+      CrossZone: {{ $v.ELBCrossZone }}
       HealthCheck:
         HealthyThreshold: {{ $v.ELBHealthCheckHealthyThreshold }}
         Interval: {{ $v.ELBHealthCheckInterval }}
@@ -63,5 +96,90 @@ const LoadBalancers = `{{define "load_balancers"}}
       SecurityGroups:
         - !Ref IngressSecurityGroup
       Subnets:
-        - !Ref PublicSubnet
+      {{- range $v.PublicSubnets }}
+        - !Ref {{ . }}
+      {{- end }}
+{{- end }}
+{{- if $v.NetworkLoadBalancers }}
+
+  ApiNetworkLoadBalancer:
+    Type: AWS::ElasticLoadBalancingV2::LoadBalancer
+    Properties:
+      LoadBalancerAttributes:
+      - Key: load_balancing.cross_zone.enabled
+        Value: "true"
+      Scheme: {{ $v.APIElbScheme }}
+      Subnets:
+      {{- range $v.APIElbSubnets }}
+        - !Ref {{ . }}
+      {{- end }}
+      Type: network
+  {{- range $v.APITargetGroups }}
+  {{ .ResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::TargetGroup
+    Properties:
+      HealthCheckIntervalSeconds: {{ $v.NLBHealthCheckInterval }}
+      HealthCheckProtocol: TCP
+      HealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      Port: {{ .PortInstance }}
+      Protocol: TCP
+      Targets:
+      {{- range $v.MasterInstanceResourceNames }}
+      - Id: !Ref {{ . }}
+      {{- end }}
+      TargetType: instance
+      UnhealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      VpcId: !Ref VPC
+  {{ .ListenerResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::Listener
+    Properties:
+      DefaultActions:
+      - TargetGroupArn: !Ref {{ .ResourceName }}
+        Type: forward
+      LoadBalancerArn: !Ref ApiNetworkLoadBalancer
+      Port: {{ .PortELB }}
+      Protocol: TCP
+  {{- end }}
+
+  IngressNetworkLoadBalancer:
+    Type: AWS::ElasticLoadBalancingV2::LoadBalancer
+    {{- if not $v.ExistingVPC }}
+    DependsOn: VPCGatewayAttachment
+    {{- end }}
+    Properties:
+      LoadBalancerAttributes:
+      - Key: load_balancing.cross_zone.enabled
+        Value: "true"
+      Scheme: {{ $v.IngressElbScheme }}
+      Subnets:
+      {{- range $v.PublicSubnets }}
+        - !Ref {{ . }}
+      {{- end }}
+      Type: network
+  {{- range $v.IngressTargetGroups }}
+  {{ .ResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::TargetGroup
+    Properties:
+      HealthCheckIntervalSeconds: {{ $v.NLBHealthCheckInterval }}
+      HealthCheckProtocol: TCP
+      HealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      Port: {{ .PortInstance }}
+      Protocol: TCP
+      TargetGroupAttributes:
+      - Key: proxy_protocol_v2.enabled
+        Value: "true"
+      TargetType: instance
+      UnhealthyThresholdCount: {{ $v.NLBHealthCheckThreshold }}
+      VpcId: !Ref VPC
+  {{ .ListenerResourceName }}:
+    Type: AWS::ElasticLoadBalancingV2::Listener
+    Properties:
+      DefaultActions:
+      - TargetGroupArn: !Ref {{ .ResourceName }}
+        Type: forward
+      LoadBalancerArn: !Ref IngressNetworkLoadBalancer
+      Port: {{ .PortELB }}
+      Protocol: TCP
+  {{- end }}
+{{- end }}
 {{end}}`
@@ -6,6 +6,7 @@ Parameters:
   VersionBundleVersionParameter:
     Type: String
     Description: Sets the VersionBundleVersion used to generate the template. 
+  {{- template "vpc_parameters" .}}
 Resources:
   {{template "vpc" .}}
   {{template "iam_policies" .}}
@@ -14,9 +15,11 @@ Resources:
   {{template "subnets" .}}
   {{template "internet_gateway" .}}
   {{template "nat_gateway" .}}
+  {{template "vpc_endpoints" .}}
+  {{template "vpc_connections" .}}
   {{template "instance" .}}
   {{template "load_balancers" .}}
-  {{template "launch_configuration" .}}
+  {{template "launch_template" .}}
   {{template "lifecycle_hooks" .}}
   {{template "autoscaling_group" .}}
   {{template "record_sets" .}}
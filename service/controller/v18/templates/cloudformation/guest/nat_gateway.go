@@ -2,26 +2,28 @@ package guest
 
 const NatGateway = `{{define "nat_gateway"}}
   {{- $v := .Guest.NATGateway }}
-  NATGateway:
+  {{- range $v.Gateways }}
+  {{ .Name }}:
     Type: AWS::EC2::NatGateway
     Properties:
       AllocationId:
         Fn::GetAtt:
-        - NATEIP
+        - {{ .EIPName }}
         - AllocationId
-      SubnetId: !Ref PublicSubnet
+      SubnetId: !Ref {{ .PublicSubnetName }}
       Tags:
         - Key: Name
           Value: {{ $v.ClusterID }}
-  NATEIP:
+  {{ .EIPName }}:
     Type: AWS::EC2::EIP
     Properties:
       Domain: vpc
-  NATRoute:
+  {{ .RouteName }}:
     Type: AWS::EC2::Route
     Properties:
-      RouteTableId: !Ref PrivateRouteTable
+      RouteTableId: !Ref {{ .PrivateRouteTableName }}
       DestinationCidrBlock: 0.0.0.0/0
       NatGatewayId:
-        Ref: "NATGateway"
+        Ref: "{{ .Name }}"
+  {{- end }}
 {{end}}`
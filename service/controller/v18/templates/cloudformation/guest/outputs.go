@@ -3,22 +3,68 @@ package guest
 const Outputs = `{{define "outputs"}}
 {{- $v := .Guest.Outputs }}
 Outputs:
+  {{- if $v.APIPrivate }}
+  APIPrivate:
+    Value: true
+  {{- end }}
   DockerVolumeResourceName:
     Value: {{ $v.Master.DockerVolume.ResourceName }}
+  DockerVolumeResourceNames:
+    Value: {{ $v.Masters.DockerVolumeResourceNames }}
+  {{- if $v.ImageCatalog.Source }}
+  DefaultImageID:
+    Value: {{ $v.ImageCatalog.DefaultImageID }}
+  ImageCatalogSource:
+    Value: "{{ $v.ImageCatalog.Source }}"
+  {{- end }}
   {{ if $v.Route53Enabled }}
   HostedZoneNameServers:
     Value: !Join [ ',', !GetAtt 'HostedZone.NameServers' ]
   {{ end }}
+  {{- if $v.LoadBalancerHash }}
+  LoadBalancerHash:
+    Value: {{ $v.LoadBalancerHash }}
+  {{- end }}
+  {{- if $v.LoadBalancerSwitchTime }}
+  LoadBalancerSwitchTime:
+    Value: "{{ $v.LoadBalancerSwitchTime }}"
+  {{- end }}
+  LoadBalancerTypes:
+    Value: {{ $v.LoadBalancerTypes }}
   MasterImageID:
     Value: {{ $v.Master.ImageID }}
+  MasterImageIDs:
+    Value: {{ $v.Masters.ImageIDs }}
   MasterInstanceResourceName:
     Value: {{ $v.Master.Instance.ResourceName }}
+  MasterInstanceResourceNames:
+    Value: {{ $v.Masters.InstanceResourceNames }}
   MasterInstanceType:
     Value: {{ $v.Master.Instance.Type }}
+  MasterInstanceTypes:
+    Value: {{ $v.Masters.InstanceTypes }}
   MasterCloudConfigVersion:
     Value: {{ $v.Master.CloudConfig.Version }}
+  MasterCloudConfigVersions:
+    Value: {{ $v.Masters.CloudConfigVersions }}
+  MasterVersionBundleVersions:
+    Value: {{ $v.Masters.VersionBundleVersions }}
+  {{- if $v.PrivateHostedZone }}
+  PrivateHostedZoneID:
+    Value: !Ref PrivateHostedZone
+  {{- end }}
+  {{- if $v.SecurityGroupsHash }}
+  SecurityGroupsHash:
+    Value: {{ $v.SecurityGroupsHash }}
+  {{- end }}
+  {{- if $v.TransitGateway }}
+  TransitGatewayAttachmentID:
+    Value: !Ref TransitGatewayAttachment
+  {{- end }}
   {{ $v.Worker.ASG.Key }}:
     Value: !Ref {{ $v.Worker.ASG.Ref }}
+  {{ $v.WorkerPools.ASGNamesKey }}:
+    Value: !Join [ ',', [ {{ range $i, $r := $v.WorkerPools.ASGRefs }}{{ if $i }}, {{ end }}!Ref {{ $r }}{{ end }} ] ]
   WorkerCount:
     Value: {{ $v.Worker.Count }}
   WorkerDockerVolumeSizeGB:
@@ -29,7 +75,47 @@ Outputs:
     Value: {{ $v.Worker.InstanceType }}
   WorkerCloudConfigVersion:
     Value: {{ $v.Worker.CloudConfig.Version }}
+  WorkerPoolCounts:
+    Value: {{ $v.WorkerPools.Counts }}
+  WorkerPoolDockerVolumeSizesGB:
+    Value: {{ $v.WorkerPools.DockerVolumeSizesGB }}
+  WorkerPoolInstanceTypes:
+    Value: {{ $v.WorkerPools.InstanceTypes }}
+  {{- if $v.WorkerPools.LaunchTemplateHashes }}
+  WorkerPoolLaunchTemplateHashes:
+    Value: "{{ $v.WorkerPools.LaunchTemplateHashes }}"
+  {{- end }}
+  WorkerPoolNames:
+    Value: {{ $v.WorkerPools.Names }}
+  WorkerPoolScalingMaxes:
+    Value: {{ $v.WorkerPools.ScalingMaxes }}
+  WorkerPoolScalingMins:
+    Value: {{ $v.WorkerPools.ScalingMins }}
+  WorkerPoolSpotEnabled:
+    Value: {{ $v.WorkerPools.SpotEnabled }}
+  {{- if $v.WorkerPools.SpotInstanceTypes }}
+  WorkerPoolSpotInstanceTypes:
+    Value: "{{ $v.WorkerPools.SpotInstanceTypes }}"
+  {{- end }}
+  {{- if $v.WorkerPools.SpotMaxPrices }}
+  WorkerPoolSpotMaxPrices:
+    Value: "{{ $v.WorkerPools.SpotMaxPrices }}"
+  {{- end }}
+  WorkerPoolSpotOnDemandBaseCapacities:
+    Value: {{ $v.WorkerPools.SpotOnDemandBaseCapacities }}
+  WorkerPoolSpotOnDemandPercentagesAboveBaseCapacity:
+    Value: {{ $v.WorkerPools.SpotOnDemandPercentagesAboveBaseCapacity }}
   VersionBundleVersion:
     Value:
       Ref: VersionBundleVersionParameter
+  {{- if $v.VPCConnectionsHash }}
+  VPCConnectionsHash:
+    Value: {{ $v.VPCConnectionsHash }}
+  {{- end }}
+  {{- if $v.VPCEndpoints }}
+  VPCEndpoints:
+    Value: true
+  {{- end }}
+  VPCFlowLogsTrafficType:
+    Value: {{ $v.VPCFlowLogsTrafficType }}
 {{end}}`
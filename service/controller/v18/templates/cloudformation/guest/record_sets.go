@@ -7,31 +7,55 @@ const RecordSets = `{{define "record_sets"}}
     Type: 'AWS::Route53::HostedZone'
     Properties:
       Name: '{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+  {{- if $v.PublicAPIRecordSet }}
   ApiRecordSet:
     Type: AWS::Route53::RecordSet
     Properties:
       AliasTarget:
-        DNSName: !GetAtt ApiLoadBalancer.DNSName
-        HostedZoneId: !GetAtt ApiLoadBalancer.CanonicalHostedZoneNameID
+        DNSName: !GetAtt {{ $v.APILoadBalancer.ResourceName }}.DNSName
+        HostedZoneId: !GetAtt {{ $v.APILoadBalancer.ResourceName }}.{{ $v.APILoadBalancer.HostedZoneIDAttribute }}
         EvaluateTargetHealth: false
       Name: 'api.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
       HostedZoneId: !Ref 'HostedZone'
       Type: A
+  {{- end }}
+  {{- if $v.PublicEtcdRecordSets }}
   EtcdRecordSet:
     Type: AWS::Route53::RecordSet
     Properties:
       Name: 'etcd.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
       HostedZoneId: !Ref 'HostedZone'
+      {{- if $v.EtcdMembers }}
+      TTL: '60'
+      Type: A
+      ResourceRecords:
+        {{- range $v.MasterInstanceResourceNames }}
+        - !GetAtt {{ . }}.PrivateIp
+        {{- end }}
+      {{- else }}
       TTL: '900'
       Type: CNAME
       ResourceRecords:
-        - !GetAtt {{ $v.MasterInstanceResourceName }}.PrivateDnsName
+        - !GetAtt {{ index $v.MasterInstanceResourceNames 0 }}.PrivateDnsName
+      {{- end }}
+  {{- range $v.EtcdMembers }}
+  {{ .ResourceName }}:
+    Type: AWS::Route53::RecordSet
+    Properties:
+      Name: '{{ .Name }}.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      HostedZoneId: !Ref 'HostedZone'
+      TTL: '60'
+      Type: A
+      ResourceRecords:
+        - !GetAtt {{ .MasterInstanceResourceName }}.PrivateIp
+  {{- end }}
+  {{- end }}
   IngressRecordSet:
     Type: AWS::Route53::RecordSet
     Properties:
       AliasTarget:
-        DNSName: !GetAtt IngressLoadBalancer.DNSName
-        HostedZoneId: !GetAtt IngressLoadBalancer.CanonicalHostedZoneNameID
+        DNSName: !GetAtt {{ $v.IngressLoadBalancer.ResourceName }}.DNSName
+        HostedZoneId: !GetAtt {{ $v.IngressLoadBalancer.ResourceName }}.{{ $v.IngressLoadBalancer.HostedZoneIDAttribute }}
         EvaluateTargetHealth: false
       Name: 'ingress.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
       HostedZoneId: !Ref 'HostedZone'
@@ -45,5 +69,72 @@ const RecordSets = `{{define "record_sets"}}
       Type: CNAME
       ResourceRecords:
         - !Ref 'IngressRecordSet'
+  {{- if $v.PrivateHostedZone }}
+  PrivateHostedZone:
+    Type: 'AWS::Route53::HostedZone'
+    Properties:
+      Name: '{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      VPCs:
+        - VPCId: !Ref VPC
+          VPCRegion: !Ref 'AWS::Region'
+  PrivateApiRecordSet:
+    Type: AWS::Route53::RecordSet
+    Properties:
+      AliasTarget:
+        DNSName: !GetAtt {{ $v.APILoadBalancer.ResourceName }}.DNSName
+        HostedZoneId: !GetAtt {{ $v.APILoadBalancer.ResourceName }}.{{ $v.APILoadBalancer.HostedZoneIDAttribute }}
+        EvaluateTargetHealth: false
+      Name: 'api.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      HostedZoneId: !Ref 'PrivateHostedZone'
+      Type: A
+  PrivateEtcdRecordSet:
+    Type: AWS::Route53::RecordSet
+    Properties:
+      Name: 'etcd.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      HostedZoneId: !Ref 'PrivateHostedZone'
+      {{- if $v.EtcdMembers }}
+      TTL: '60'
+      Type: A
+      ResourceRecords:
+        {{- range $v.MasterInstanceResourceNames }}
+        - !GetAtt {{ . }}.PrivateIp
+        {{- end }}
+      {{- else }}
+      TTL: '900'
+      Type: CNAME
+      ResourceRecords:
+        - !GetAtt {{ index $v.MasterInstanceResourceNames 0 }}.PrivateDnsName
+      {{- end }}
+  {{- range $v.EtcdMembers }}
+  Private{{ .ResourceName }}:
+    Type: AWS::Route53::RecordSet
+    Properties:
+      Name: '{{ .Name }}.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      HostedZoneId: !Ref 'PrivateHostedZone'
+      TTL: '60'
+      Type: A
+      ResourceRecords:
+        - !GetAtt {{ .MasterInstanceResourceName }}.PrivateIp
+  {{- end }}
+  PrivateIngressRecordSet:
+    Type: AWS::Route53::RecordSet
+    Properties:
+      AliasTarget:
+        DNSName: !GetAtt {{ $v.IngressLoadBalancer.ResourceName }}.DNSName
+        HostedZoneId: !GetAtt {{ $v.IngressLoadBalancer.ResourceName }}.{{ $v.IngressLoadBalancer.HostedZoneIDAttribute }}
+        EvaluateTargetHealth: false
+      Name: 'ingress.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      HostedZoneId: !Ref 'PrivateHostedZone'
+      Type: A
+  PrivateIngressWildcardRecordSet:
+    Type: AWS::Route53::RecordSet
+    Properties:
+      Name: '*.{{ $v.ClusterID }}.k8s.{{ $v.BaseDomain }}.'
+      HostedZoneId: !Ref 'PrivateHostedZone'
+      TTL: '900'
+      Type: CNAME
+      ResourceRecords:
+        - !Ref 'PrivateIngressRecordSet'
+  {{- end }}
 {{end}}
 {{end}}`
@@ -2,6 +2,7 @@ package guest
 
 const RouteTables = `{{ define "route_tables" }}
 {{- $v := .Guest.RouteTables }}
+{{- if not $v.ExistingVPC }}
   PublicRouteTable:
     Type: AWS::EC2::RouteTable
     Properties:
@@ -9,20 +10,34 @@ const RouteTables = `{{ define "route_tables" }}
       Tags:
       - Key: Name
         Value: {{ $v.PublicRouteTableName }}
-
-  PrivateRouteTable:
+{{- end }}
+{{ range $v.PrivateRouteTables }}
+{{- if not .RouteTableID }}
+  {{ .ResourceName }}:
     Type: AWS::EC2::RouteTable
     Properties:
       VpcId: !Ref VPC
       Tags:
       - Key: Name
-        Value: {{ $v.PrivateRouteTableName }}
-
-  VPCPeeringRoute:
+        Value: {{ .Name }}
+{{ end }}
+  {{ .VPCPeeringRouteName }}:
     Type: AWS::EC2::Route
+    {{- if $v.TransitGatewayID }}
+    DependsOn: TransitGatewayAttachment
+    {{- end }}
     Properties:
-      RouteTableId: !Ref PrivateRouteTable
+      {{- if .RouteTableID }}
+      RouteTableId: {{ .RouteTableID }}
+      {{- else }}
+      RouteTableId: !Ref {{ .ResourceName }}
+      {{- end }}
       DestinationCidrBlock: {{ $v.HostClusterCIDR }}
+      {{- if $v.TransitGatewayID }}
+      TransitGatewayId: {{ $v.TransitGatewayID }}
+      {{- else }}
       VpcPeeringConnectionId:
         Ref: "VPCPeeringConnection"
-{{ end }}`
+      {{- end }}
+{{ end }}
+{{- end }}`
@@ -10,19 +10,23 @@ const SecurityGroups = `{{define "security_groups" }}
       SecurityGroupIngress:
       {{ range $v.MasterSecurityGroupRules }}
       -
-        Description: {{ .Description }}
+        {{- if .Description }}
+        Description: '{{ .Description }}'
+        {{- end }}
         IpProtocol: {{ .Protocol }}
         FromPort: {{ .Port }}
-        ToPort: {{ .Port }}
+        ToPort: {{ if .ToPort }}{{ .ToPort }}{{ else }}{{ .Port }}{{ end }}
         CidrIp: {{ .SourceCIDR }}
       {{ end }}
       {{- if $v.APIWhitelistEnabled }}
+      {{- range $v.NATGatewayEIPNames }}
       -
         Description: Allow NAT gateway IP
         IpProtocol: tcp
         FromPort: 443
         ToPort: 443
-        CidrIp: !Join [ "/", [ !Ref NATEIP, "32" ] ]
+        CidrIp: !Join [ "/", [ !Ref {{ . }}, "32" ] ]
+      {{- end }}
       {{- end }}
       Tags:
         - Key: Name
@@ -36,9 +40,12 @@ const SecurityGroups = `{{define "security_groups" }}
       SecurityGroupIngress:
       {{ range $v.WorkerSecurityGroupRules }}
       -
+        {{- if .Description }}
+        Description: '{{ .Description }}'
+        {{- end }}
         IpProtocol: {{ .Protocol }}
         FromPort: {{ .Port }}
-        ToPort: {{ .Port }}
+        ToPort: {{ if .ToPort }}{{ .ToPort }}{{ else }}{{ .Port }}{{ end }}
         {{ if .SourceCIDR }}
         CidrIp: {{ .SourceCIDR }}
         {{ else }}
@@ -57,9 +64,12 @@ const SecurityGroups = `{{define "security_groups" }}
       SecurityGroupIngress:
       {{ range $v.IngressSecurityGroupRules }}
       -
+        {{- if .Description }}
+        Description: '{{ .Description }}'
+        {{- end }}
         IpProtocol: {{ .Protocol }}
         FromPort: {{ .Port }}
-        ToPort: {{ .Port }}
+        ToPort: {{ if .ToPort }}{{ .ToPort }}{{ else }}{{ .Port }}{{ end }}
         CidrIp: {{ .SourceCIDR }}
       {{ end }}
       Tags:
@@ -2,37 +2,40 @@ package guest
 
 const Subnets = `{{ define "subnets" }}
 {{- $v := .Guest.Subnets }}
-  PublicSubnet:
+{{- range $v.PublicSubnets }}
+  {{ .ResourceName }}:
     Type: AWS::EC2::Subnet
     Properties:
-      AvailabilityZone: {{ $v.PublicSubnetAZ }}
-      CidrBlock: {{ $v.PublicSubnetCIDR }}
-      MapPublicIpOnLaunch: {{ $v.PublicSubnetMapPublicIPOnLaunch }}
+      AvailabilityZone: {{ .AvailabilityZone }}
+      CidrBlock: {{ .CIDR }}
+      MapPublicIpOnLaunch: {{ .MapPublicIPOnLaunch }}
       Tags:
       - Key: Name
-        Value: {{ $v.PublicSubnetName }}
+        Value: {{ .Name }}
       VpcId: !Ref VPC
 
-  PublicSubnetRouteTableAssociation:
+  {{ .RouteTableAssociation.ResourceName }}:
     Type: AWS::EC2::SubnetRouteTableAssociation
     Properties:
-      RouteTableId: !Ref PublicRouteTable
-      SubnetId: !Ref PublicSubnet
-
-  PrivateSubnet:
+      RouteTableId: !Ref {{ .RouteTableAssociation.RouteTableName }}
+      SubnetId: !Ref {{ .ResourceName }}
+{{ end }}
+{{- range $v.PrivateSubnets }}
+  {{ .ResourceName }}:
     Type: AWS::EC2::Subnet
     Properties:
-      AvailabilityZone: {{ $v.PrivateSubnetAZ }}
-      CidrBlock: {{ $v.PrivateSubnetCIDR }}
-      MapPublicIpOnLaunch: {{ $v.PrivateSubnetMapPublicIPOnLaunch }}
+      AvailabilityZone: {{ .AvailabilityZone }}
+      CidrBlock: {{ .CIDR }}
+      MapPublicIpOnLaunch: {{ .MapPublicIPOnLaunch }}
       Tags:
       - Key: Name
-        Value: {{ $v.PrivateSubnetName }}
+        Value: {{ .Name }}
       VpcId: !Ref VPC
 
-  PrivateSubnetRouteTableAssociation:
+  {{ .RouteTableAssociation.ResourceName }}:
     Type: AWS::EC2::SubnetRouteTableAssociation
     Properties:
-      RouteTableId: !Ref PrivateRouteTable
-      SubnetId: !Ref PrivateSubnet
-{{ end }}`
+      RouteTableId: !Ref {{ .RouteTableAssociation.RouteTableName }}
+      SubnetId: !Ref {{ .ResourceName }}
+{{ end }}
+{{- end }}`
@@ -2,6 +2,7 @@ package guest
 
 const VPC = `{{define "vpc"}}
 {{- $v := .Guest.VPC }}
+  {{- if not $v.ExistingVPCID }}
   VPC:
     Type: AWS::EC2::VPC
     Properties:
@@ -13,6 +14,29 @@ const VPC = `{{define "vpc"}}
         Value: {{ $v.ClusterID }}
       - Key: Installation
         Value: {{ $v.InstallationName }}
+  {{- end }}
+  VPCFlowLog:
+    Type: AWS::EC2::FlowLog
+    Properties:
+      LogDestination: {{ $v.FlowLogsDestination }}
+      LogDestinationType: s3
+      ResourceId: !Ref VPC
+      ResourceType: VPC
+      TrafficType: {{ $v.FlowLogsTrafficType }}
+  {{- if $v.TransitGatewayID }}
+  TransitGatewayAttachment:
+    Type: AWS::EC2::TransitGatewayAttachment
+    Properties:
+      SubnetIds:
+      {{- range $v.TransitGatewaySubnets }}
+        - !Ref {{ . }}
+      {{- end }}
+      TransitGatewayId: {{ $v.TransitGatewayID }}
+      VpcId: !Ref VPC
+      Tags:
+        - Key: Name
+          Value: {{ $v.ClusterID }}
+  {{- else }}
   VPCPeeringConnection:
     Type: 'AWS::EC2::VPCPeeringConnection'
     Properties:
@@ -23,4 +47,21 @@ const VPC = `{{define "vpc"}}
       Tags:
         - Key: Name
           Value: {{ $v.ClusterID }}
+  {{- end }}
+{{end}}`
+
+const VPCParameters = `{{define "vpc_parameters"}}
+{{- $v := .Guest.VPC }}
+{{- if $v.ExistingVPCID }}
+  VPC:
+    Type: AWS::EC2::VPC::Id
+    Description: Existing VPC the guest cluster is deployed into.
+    Default: {{ $v.ExistingVPCID }}
+  {{- range $v.ExistingSubnets }}
+  {{ .ResourceName }}:
+    Type: AWS::EC2::Subnet::Id
+    Description: Existing subnet the guest cluster is deployed into.
+    Default: {{ .ID }}
+  {{- end }}
+{{- end }}
 {{end}}`
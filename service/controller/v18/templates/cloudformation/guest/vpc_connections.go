@@ -0,0 +1,72 @@
+package guest
+
+const VPCConnections = `{{ define "vpc_connections" }}
+{{- $v := .Guest.VPCConnections }}
+{{- range $p := $v.Peerings }}
+  {{ $p.ResourceName }}:
+    Type: AWS::EC2::VPCPeeringConnection
+    Properties:
+      VpcId: !Ref VPC
+      PeerVpcId: {{ $p.VPCID }}
+      {{- if $p.AccountID }}
+      PeerOwnerId: '{{ $p.AccountID }}'
+      {{- end }}
+      {{- if $p.Region }}
+      PeerRegion: {{ $p.Region }}
+      {{- end }}
+      {{- if $p.RoleARN }}
+      PeerRoleArn: {{ $p.RoleARN }}
+      {{- end }}
+      Tags:
+        - Key: Name
+          Value: {{ $p.ResourceName }}
+  {{- range $i, $rt := $v.PrivateRouteTables }}
+  {{ index $p.RouteResourceNames $i }}:
+    Type: AWS::EC2::Route
+    Properties:
+      {{- if $rt.ID }}
+      RouteTableId: {{ $rt.ID }}
+      {{- else }}
+      RouteTableId: !Ref {{ $rt.ResourceName }}
+      {{- end }}
+      DestinationCidrBlock: {{ $p.CIDR }}
+      VpcPeeringConnectionId: !Ref {{ $p.ResourceName }}
+  {{- end }}
+  {{ $p.IngressResourceName }}:
+    Type: AWS::EC2::SecurityGroupIngress
+    Properties:
+      Description: Allow traffic from peer VPC {{ $p.VPCID }}
+      GroupId: !Ref WorkerSecurityGroup
+      IpProtocol: -1
+      CidrIp: {{ $p.CIDR }}
+{{- end }}
+{{- if $v.VPNGateway.ID }}
+  VPNGatewayAttachment:
+    Type: AWS::EC2::VPCGatewayAttachment
+    Properties:
+      VpcId: !Ref VPC
+      VpnGatewayId: {{ $v.VPNGateway.ID }}
+  VPNGatewayRoutePropagation:
+    Type: AWS::EC2::VPNGatewayRoutePropagation
+    DependsOn: VPNGatewayAttachment
+    Properties:
+      RouteTableIds:
+      {{- range $v.PrivateRouteTables }}
+      {{- if .ID }}
+        - {{ .ID }}
+      {{- else }}
+        - !Ref {{ .ResourceName }}
+      {{- end }}
+      {{- end }}
+      VpnGatewayId: {{ $v.VPNGateway.ID }}
+  {{- range $v.VPNGateway.Ingress }}
+  {{ .ResourceName }}:
+    Type: AWS::EC2::SecurityGroupIngress
+    Properties:
+      Description: Allow traffic from the virtual private gateway
+      GroupId: !Ref WorkerSecurityGroup
+      IpProtocol: -1
+      CidrIp: {{ .CIDR }}
+  {{- end }}
+{{- end }}
+{{ end }}`
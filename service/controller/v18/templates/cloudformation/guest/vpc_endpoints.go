@@ -0,0 +1,51 @@
+package guest
+
+const VPCEndpoints = `{{ define "vpc_endpoints" }}
+{{- $v := .Guest.VPCEndpoints }}
+{{- if $v.Enabled }}
+  VPCEndpointSecurityGroup:
+    Type: AWS::EC2::SecurityGroup
+    Properties:
+      GroupDescription: {{ $v.SecurityGroupName }}
+      VpcId: !Ref VPC
+      SecurityGroupIngress:
+      -
+        Description: Allow HTTPS from within the VPC
+        IpProtocol: tcp
+        FromPort: 443
+        ToPort: 443
+        CidrIp: {{ $v.VPCCIDR }}
+      Tags:
+        - Key: Name
+          Value: {{ $v.SecurityGroupName }}
+  {{ $v.GatewayEndpoint.ResourceName }}:
+    Type: AWS::EC2::VPCEndpoint
+    Properties:
+      RouteTableIds:
+      {{- range $v.PrivateRouteTables }}
+      {{- if .ID }}
+        - {{ .ID }}
+      {{- else }}
+        - !Ref {{ .ResourceName }}
+      {{- end }}
+      {{- end }}
+      ServiceName: {{ $v.GatewayEndpoint.ServiceName }}
+      VpcEndpointType: Gateway
+      VpcId: !Ref VPC
+  {{- range $v.InterfaceEndpoints }}
+  {{ .ResourceName }}:
+    Type: AWS::EC2::VPCEndpoint
+    Properties:
+      PrivateDnsEnabled: true
+      SecurityGroupIds:
+        - !Ref VPCEndpointSecurityGroup
+      ServiceName: {{ .ServiceName }}
+      SubnetIds:
+      {{- range $v.PrivateSubnets }}
+        - !Ref {{ . }}
+      {{- end }}
+      VpcEndpointType: Interface
+      VpcId: !Ref VPC
+  {{- end }}
+{{- end }}
+{{ end }}`
@@ -5,4 +5,5 @@ Description: Main Host Post-Guest CloudFormation stack.
 Resources:
   {{template "record_sets" .}}
   {{template "route_tables" .}}
+  {{template "transit_gateway" .}}
 {{end}}`
@@ -0,0 +1,17 @@
+package hostpost
+
+const TransitGateway = `{{ define "transit_gateway" }}
+{{- $v := .HostPost.TransitGateway }}
+{{- if $v.AttachmentID }}
+  TransitGatewayRouteTableAssociation:
+    Type: AWS::EC2::TransitGatewayRouteTableAssociation
+    Properties:
+      TransitGatewayAttachmentId: {{ $v.AttachmentID }}
+      TransitGatewayRouteTableId: {{ $v.RouteTableID }}
+  TransitGatewayRouteTablePropagation:
+    Type: AWS::EC2::TransitGatewayRouteTablePropagation
+    Properties:
+      TransitGatewayAttachmentId: {{ $v.AttachmentID }}
+      TransitGatewayRouteTableId: {{ $v.RouteTableID }}
+{{- end }}
+{{ end }}`
@@ -0,0 +1,16 @@
+package hostpre
+
+const ResourceShares = `{{ define "resource_shares" }}
+{{- $v := .HostPre.ResourceShares }}
+{{- if $v.TransitGatewayARN }}
+  TransitGatewayResourceShare:
+    Type: AWS::RAM::ResourceShare
+    Properties:
+      AllowExternalPrincipals: false
+      Name: {{ $v.Name }}
+      Principals:
+        - '{{ $v.GuestAccountID }}'
+      ResourceArns:
+        - {{ $v.TransitGatewayARN }}
+{{- end }}
+{{ end }}`
@@ -9,7 +9,127 @@ func VersionBundle() versionbundle.Bundle {
 		Changelogs: []versionbundle.Changelog{
 			{
 				Component:   "aws-operator",
-				Description: "Add your changes here.",
+				Description: "Spread guest cluster subnets, NAT gateways and worker nodes across multiple availability zones.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Run multiple masters forming an etcd cluster behind the API load balancer and replace them one at a time during updates.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Run multiple worker node pools, each with its own instance type, docker volume size and auto scaling group.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Run node pools on spot instances with diversified instance types and on-demand base capacity, and drain spot instances receiving an interruption notice.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Launch worker nodes from versioned EC2 launch templates instead of launch configurations and only roll workers when their launch template changes.",
+				Kind:        versionbundle.KindChanged,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Let cluster-autoscaler scale worker node pools within the scaling bounds configured in the custom object.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Update guest cluster main stacks using change sets, emit their changes as events and optionally require approving changes replacing resources.",
+				Kind:        versionbundle.KindChanged,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Diagnose failed guest cluster stacks using their stack events, emit the first failed resource as event and export stack statuses as metrics.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Optionally recover guest cluster main stacks stuck in ROLLBACK_COMPLETE or UPDATE_ROLLBACK_FAILED when annotated with aws-operator.giantswarm.io/stack-recovery=true.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Launch masters and workers from the AMIs configured in the custom object and resolve all other AMIs from a builtin, config map or EC2 based AMI catalog.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Deploy guest clusters into existing VPCs and subnets referenced in the custom object without taking ownership of them.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Allow guest clusters to expose their Kubernetes API only through an internal ELB in their private subnets.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Allow guest clusters to use NLBs instead of classic ELBs for the Kubernetes API and the ingress controller. Existing classic ELBs are kept until the DNS records switched over and the change set removing them is approved.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Honour the idle timeouts of the Kubernetes API and ingress ELBs configured in the custom object and allow configuring ELB health checks, cross-zone balancing, connection draining and access logs.",
+				Kind:        versionbundle.KindChanged,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Allow attaching the VPCs of new guest clusters to a Transit Gateway of the installation instead of peering them with the host cluster VPC.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Allow creating VPC endpoints for S3, EC2, STS, KMS, ECR and CloudWatch Logs in guest cluster VPCs, so that node traffic to AWS APIs does not go through the NAT gateways.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Deliver the VPC flow logs of guest clusters to their logging buckets. The captured traffic type can be configured in the custom object.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Allow whitelisting CIDRs for the Kubernetes API and adding ingress rules to the master, worker and ingress security groups of guest clusters in the custom object. Changes are applied without replacing instances.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Allocate non-overlapping VPC CIDRs for guest clusters not configuring them in the custom object from a network configured for the installation. Refuse to create guest clusters whose VPC CIDR overlaps the host cluster VPC or other peered VPCs.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Peer guest cluster VPCs with additional VPCs of the same or other AWS accounts and attach virtual private gateways declared in the custom object. Routes and worker security group rules for the connected networks are managed in the guest cluster main stack.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Optionally manage the records of the guest cluster domain in a private hosted zone associated with the guest cluster VPC and optionally the host cluster VPC. Etcd records and the record of a private Kubernetes API are then not resolvable publicly anymore.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Optionally retire the intermediate bridgezone delegation once no guest cluster relies on it anymore and delete the intermediate zone after the TTL of the delegation and a configurable grace period passed.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Delegate guest cluster zones from base domain zones served by DNS servers accepting RFC2136 dynamic updates when the installation is configured to use the rfc2136 DNS provider instead of Route53.",
+				Kind:        versionbundle.KindAdded,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Encrypt TLS assets and random keys locally with a cached per-cluster data key instead of calling the encryption backend for every asset.",
+				Kind:        versionbundle.KindChanged,
+			},
+			{
+				Component:   "aws-operator",
+				Description: "Support Kubernetes and AppRole auth, configurable mount paths, namespaces and CA certificates for the Vault encrypter and renew Vault tokens before they expire.",
 				Kind:        versionbundle.KindAdded,
 			},
 		},
@@ -119,15 +119,34 @@ func New(config Config) (*Service, error) {
 			},
 			AccessLogsExpiration:  config.Viper.GetInt(config.Flag.Service.AWS.S3AccessLogsExpiration),
 			AdvancedMonitoringEC2: config.Viper.GetBool(config.Flag.Service.AWS.AdvancedMonitoringEC2),
-			DeleteLoggingBucket:   config.Viper.GetBool(config.Flag.Service.AWS.LoggingBucket.Delete),
-			EncrypterBackend:      config.Viper.GetString(config.Flag.Service.AWS.Encrypter),
+			BridgeZoneRetirement: controller.ClusterConfigBridgeZoneRetirement{
+				Enabled:     config.Viper.GetBool(config.Flag.Service.AWS.Route53.BridgeZone.RetirementEnabled),
+				GracePeriod: config.Viper.GetDuration(config.Flag.Service.AWS.Route53.BridgeZone.RetirementGracePeriod),
+			},
+			DeleteLoggingBucket: config.Viper.GetBool(config.Flag.Service.AWS.LoggingBucket.Delete),
+			DNS: controller.ClusterConfigDNS{
+				Provider:             config.Viper.GetString(config.Flag.Service.AWS.DNS.Provider),
+				RFC2136Server:        config.Viper.GetString(config.Flag.Service.AWS.DNS.RFC2136.Server),
+				RFC2136TSIGAlgorithm: config.Viper.GetString(config.Flag.Service.AWS.DNS.RFC2136.TSIGAlgorithm),
+				RFC2136TSIGKeyName:   config.Viper.GetString(config.Flag.Service.AWS.DNS.RFC2136.TSIGKeyName),
+				RFC2136TSIGSecret:    config.Viper.GetString(config.Flag.Service.AWS.DNS.RFC2136.TSIGSecret),
+			},
+			EncrypterBackend: config.Viper.GetString(config.Flag.Service.AWS.Encrypter),
 			GuestAWSConfig: controller.ClusterConfigAWSConfig{
 				AccessKeyID:     config.Viper.GetString(config.Flag.Service.AWS.AccessKey.ID),
 				AccessKeySecret: config.Viper.GetString(config.Flag.Service.AWS.AccessKey.Secret),
 				SessionToken:    config.Viper.GetString(config.Flag.Service.AWS.AccessKey.Session),
 				Region:          config.Viper.GetString(config.Flag.Service.AWS.Region),
 			},
-			GuestUpdateEnabled: config.Viper.GetBool(config.Flag.Service.Guest.Update.Enabled),
+			GuestAMI: controller.ClusterConfigAMI{
+				Catalog:            config.Viper.GetString(config.Flag.Service.Guest.AMI.Catalog),
+				ConfigMapName:      config.Viper.GetString(config.Flag.Service.Guest.AMI.ConfigMap.Name),
+				ConfigMapNamespace: config.Viper.GetString(config.Flag.Service.Guest.AMI.ConfigMap.Namespace),
+				EC2NamePattern:     config.Viper.GetString(config.Flag.Service.Guest.AMI.EC2.NamePattern),
+				EC2Owner:           config.Viper.GetString(config.Flag.Service.Guest.AMI.EC2.Owner),
+			},
+			GuestUpdateEnabled:             config.Viper.GetBool(config.Flag.Service.Guest.Update.Enabled),
+			GuestUpdateReplacementApproval: config.Viper.GetBool(config.Flag.Service.Guest.Update.ReplacementApproval),
 			HostAWSConfig: controller.ClusterConfigAWSConfig{
 				AccessKeyID:     config.Viper.GetString(config.Flag.Service.AWS.HostAccessKey.ID),
 				AccessKeySecret: config.Viper.GetString(config.Flag.Service.AWS.HostAccessKey.Secret),
@@ -136,6 +155,10 @@ func New(config Config) (*Service, error) {
 			},
 			IncludeTags:      config.Viper.GetBool(config.Flag.Service.AWS.IncludeTags),
 			InstallationName: config.Viper.GetString(config.Flag.Service.Installation.Name),
+			IPAM: controller.ClusterConfigIPAM{
+				Network:   config.Viper.GetString(config.Flag.Service.Installation.Guest.IPAM.Network),
+				VPCPrefix: config.Viper.GetInt(config.Flag.Service.Installation.Guest.IPAM.VPCPrefix),
+			},
 			OIDC: controller.ClusterConfigOIDC{
 				ClientID:      config.Viper.GetString(config.Flag.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.ClientID),
 				IssuerURL:     config.Viper.GetString(config.Flag.Service.Installation.Guest.Kubernetes.API.Auth.Provider.OIDC.IssuerURL),
@@ -150,7 +173,25 @@ func New(config Config) (*Service, error) {
 			Route53Enabled:         config.Viper.GetBool(config.Flag.Service.AWS.Route53.Enabled),
 			RegistryDomain:         config.Viper.GetString(config.Flag.Service.RegistryDomain),
 			SSOPublicKey:           config.Viper.GetString(config.Flag.Service.Guest.SSH.SSOPublicKey),
-			VaultAddress:           config.Viper.GetString(config.Flag.Service.AWS.VaultAddress),
+			TransitGateway: controller.ClusterConfigTransitGateway{
+				ID:           config.Viper.GetString(config.Flag.Service.AWS.TransitGateway.ID),
+				RouteTableID: config.Viper.GetString(config.Flag.Service.AWS.TransitGateway.RouteTableID),
+			},
+			Vault: controller.ClusterConfigVault{
+				AppRoleMountPath:    config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.AppRole.MountPath),
+				AppRoleID:           config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.AppRole.RoleID),
+				AppRoleSecretID:     config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.AppRole.SecretID),
+				AuthMethod:          config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.Method),
+				AWSMountPath:        config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.AWS.MountPath),
+				AWSRole:             config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.AWS.Role),
+				CAFile:              config.Viper.GetString(config.Flag.Service.AWS.Vault.CAFile),
+				KubernetesMountPath: config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.Kubernetes.MountPath),
+				KubernetesRole:      config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.Kubernetes.Role),
+				KubernetesTokenFile: config.Viper.GetString(config.Flag.Service.AWS.Vault.Auth.Kubernetes.TokenFile),
+				Namespace:           config.Viper.GetString(config.Flag.Service.AWS.Vault.Namespace),
+				TransitMountPath:    config.Viper.GetString(config.Flag.Service.AWS.Vault.TransitMountPath),
+			},
+			VaultAddress: config.Viper.GetString(config.Flag.Service.AWS.VaultAddress),
 		}
 
 		clusterController, err = controller.NewCluster(c)
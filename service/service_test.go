@@ -17,9 +17,11 @@ func commonViperSettings(f *flag.Flag, v *viper.Viper) {
 	v.Set(f.Service.AWS.HostAccessKey.Secret, "accessKeySecret")
 	v.Set(f.Service.AWS.HostAccessKey.Session, "session")
 	v.Set(f.Service.AWS.AdvancedMonitoringEC2, true)
+	v.Set(f.Service.AWS.DNS.Provider, "route53")
 	v.Set(f.Service.AWS.S3AccessLogsExpiration, 365)
 	v.Set(f.Service.AWS.Region, "myregion")
 	v.Set(f.Service.AWS.PubKeyFile, "test")
+	v.Set(f.Service.Guest.AMI.Catalog, "builtin")
 	v.Set(f.Service.Guest.SSH.SSOPublicKey, "test")
 
 	v.Set(f.Service.Installation.Name, "test")
@@ -72,6 +74,10 @@ func Test_Service_New(t *testing.T) {
 				v := viper.New()
 				commonViperSettings(f, v)
 				v.Set(f.Service.AWS.Encrypter, "vault")
+				v.Set(f.Service.AWS.Vault.Auth.AWS.MountPath, "aws")
+				v.Set(f.Service.AWS.Vault.Auth.AWS.Role, "encrypter")
+				v.Set(f.Service.AWS.Vault.Auth.Method, "aws")
+				v.Set(f.Service.AWS.Vault.TransitMountPath, "transit")
 				v.Set(f.Service.AWS.VaultAddress, "http://vault")
 
 				return Config{
@@ -87,6 +93,62 @@ func Test_Service_New(t *testing.T) {
 			},
 			expectedErrorHandler: nil,
 		},
+		{
+			description: "production like config is valid - vault kubernetes auth",
+			config: func() Config {
+				f := flag.New()
+
+				v := viper.New()
+				commonViperSettings(f, v)
+				v.Set(f.Service.AWS.Encrypter, "vault")
+				v.Set(f.Service.AWS.Vault.Auth.AWS.MountPath, "aws")
+				v.Set(f.Service.AWS.Vault.Auth.Kubernetes.MountPath, "kubernetes")
+				v.Set(f.Service.AWS.Vault.Auth.Kubernetes.Role, "aws-operator")
+				v.Set(f.Service.AWS.Vault.Auth.Kubernetes.TokenFile, "/var/run/secrets/kubernetes.io/serviceaccount/token")
+				v.Set(f.Service.AWS.Vault.Auth.Method, "kubernetes")
+				v.Set(f.Service.AWS.Vault.Namespace, "giantswarm")
+				v.Set(f.Service.AWS.Vault.TransitMountPath, "aws-operator/transit")
+				v.Set(f.Service.AWS.VaultAddress, "https://vault")
+
+				return Config{
+					Logger: microloggertest.New(),
+					Flag:   f,
+					Viper:  v,
+
+					Description: "test",
+					GitCommit:   "test",
+					ProjectName: "test",
+					Source:      "test",
+				}
+			},
+			expectedErrorHandler: nil,
+		},
+		{
+			description: "production like config is valid - rfc2136",
+			config: func() Config {
+				f := flag.New()
+
+				v := viper.New()
+				commonViperSettings(f, v)
+				v.Set(f.Service.AWS.DNS.Provider, "rfc2136")
+				v.Set(f.Service.AWS.DNS.RFC2136.Server, "10.0.0.2:53")
+				v.Set(f.Service.AWS.DNS.RFC2136.TSIGAlgorithm, "hmac-sha256")
+				v.Set(f.Service.AWS.DNS.RFC2136.TSIGKeyName, "aws-operator")
+				v.Set(f.Service.AWS.DNS.RFC2136.TSIGSecret, "c2VjcmV0")
+
+				return Config{
+					Logger: microloggertest.New(),
+					Flag:   f,
+					Viper:  v,
+
+					Description: "test",
+					GitCommit:   "test",
+					ProjectName: "test",
+					Source:      "test",
+				}
+			},
+			expectedErrorHandler: nil,
+		},
 	}
 
 	for _, tc := range tests {